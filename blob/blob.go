@@ -0,0 +1,52 @@
+// Package blob stores the binary content, such as the images and animations
+// of exercises, that does not belong in the database. Content is stored under
+// a key in a Store, either a directory of the local filesystem or a bucket of
+// an S3 compatible object store.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned when no content is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// MaxKeyLength the longest key accepted by a store
+const MaxKeyLength = 512
+
+// Store the content stored under each key. Keys are paths of segments
+// separated by "/", each of letters, digits, '.', '_' and '-'.
+type Store interface {
+	// Put store the data under the key, replacing any existing content
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get open the content stored under the key, ErrNotFound if there is none.
+	// The caller must close the content.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete remove the content stored under the key, deleting a key without
+	// content is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey ensure the key is usable by every store. Keys that could escape
+// the root of a store, such as those with ".." segments, are rejected.
+func ValidKey(key string) error {
+	if len(key) == 0 || len(key) > MaxKeyLength {
+		return fmt.Errorf("blob key must be between 1 and %d characters", MaxKeyLength)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if len(segment) == 0 || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key '%s'", key)
+		}
+		for _, r := range segment {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+				r == '.' || r == '_' || r == '-') {
+				return fmt.Errorf("invalid blob key '%s'", key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package chart renders simple bar and line charts as SVG images using only
+// the standard library, for clients that are unable to draw charts themselves.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The kinds of charts rendered
+const (
+	KindBar  = "bar"
+	KindLine = "line"
+)
+
+// The default and allowed sizes, in pixels, of a chart
+const (
+	DefaultWidth  = 640
+	DefaultHeight = 320
+	MinSize       = 160
+	MaxSize       = 2000
+)
+
+// The margins, in pixels, around the plot area holding the axis labels and title
+const (
+	marginLeft   = 64
+	marginRight  = 16
+	marginTop    = 36
+	marginBottom = 40
+)
+
+// yTicks the approximate number of intervals the value axis is divided into
+const yTicks = 5
+
+// charWidth the approximate width, in pixels, of a character of a label
+const charWidth = 7
+
+// Point a value plotted on the chart along with the label shown for it on
+// the horizontal axis. Points are evenly spaced in the order given.
+type Point struct {
+	Label string
+	Value float64
+}
+
+// Chart a bar or line chart of a single series of values. Unit is shown as the
+// label of the value axis. A zero Width or Height uses the default size.
+type Chart struct {
+	Title  string
+	Unit   string
+	Kind   string
+	Width  int
+	Height int
+	Points []Point
+}
+
+// Validate check the kind and size of the chart
+func (c *Chart) Validate() error {
+	if c.Kind != KindBar && c.Kind != KindLine {
+		return fmt.Errorf("invalid chart kind '%s', must be %s or %s", c.Kind, KindBar, KindLine)
+	}
+	for _, size := range []int{c.Width, c.Height} {
+		if size != 0 && (size < MinSize || size > MaxSize) {
+			return fmt.Errorf("chart width and height must be between %d and %d pixels", MinSize, MaxSize)
+		}
+	}
+	return nil
+}
+
+// niceStep the interval between the ticks of an axis spanning max, a 1, 2 or
+// 5 multiple of a power of ten
+func niceStep(max float64) float64 {
+	raw := max / yTicks
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatTick format the value of a tick with the decimals the step requires
+func formatTick(value float64, step float64) string {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+// Render write the chart as a SVG image
+func Render(out io.Writer, c *Chart) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	width, height := c.Width, c.Height
+	if width == 0 {
+		width = DefaultWidth
+	}
+	if height == 0 {
+		height = DefaultHeight
+	}
+	plotW := float64(width - marginLeft - marginRight)
+	plotH := float64(height - marginTop - marginBottom)
+	left, top := float64(marginLeft), float64(marginTop)
+	bottom := top + plotH
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" `+
+		`font-family="sans-serif" font-size="11">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="22" text-anchor="middle" font-size="14" font-weight="bold">%s</text>`+"\n",
+		width/2, html.EscapeString(c.Title))
+
+	max := 0.0
+	for _, p := range c.Points {
+		max = math.Max(max, p.Value)
+	}
+	if max == 0 {
+		max = 1
+	}
+	step := niceStep(max)
+	yMax := math.Ceil(max/step) * step
+	y := func(v float64) float64 { return bottom - v/yMax*plotH }
+
+	// Value axis, grid lines and unit
+	b.WriteString(`<g class="grid" stroke="#e0e0e0">` + "\n")
+	ticks := int(math.Round(yMax / step))
+	for i := 0; i <= ticks; i++ {
+		v := float64(i) * step
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f"/>`+"\n", left, y(v), left+plotW, y(v))
+	}
+	b.WriteString("</g>\n")
+	b.WriteString(`<g class="y-axis" text-anchor="end" fill="#555555">` + "\n")
+	for i := 0; i <= ticks; i++ {
+		v := float64(i) * step
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%s</text>`+"\n", left-6, y(v)+4, formatTick(v, step))
+	}
+	b.WriteString("</g>\n")
+	if len(c.Unit) > 0 {
+		fmt.Fprintf(&b, `<text x="14" y="%.1f" text-anchor="middle" fill="#555555" transform="rotate(-90 14 %.1f)">%s</text>`+"\n",
+			top+plotH/2, top+plotH/2, html.EscapeString(c.Unit))
+	}
+	fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#555555"/>`+"\n", left, bottom, left+plotW, bottom)
+
+	if len(c.Points) == 0 {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#555555">No data</text>`+"\n",
+			left+plotW/2, top+plotH/2)
+		b.WriteString("</svg>\n")
+		_, err := out.Write(b.Bytes())
+		return err
+	}
+
+	slot := plotW / float64(len(c.Points))
+	x := func(i int) float64 { return left + slot*(float64(i)+0.5) }
+
+	// Category labels, thinned so that they do not overlap
+	longest := 0
+	for _, p := range c.Points {
+		if len(p.Label) > longest {
+			longest = len(p.Label)
+		}
+	}
+	every := int(math.Ceil(float64(longest*charWidth+8) / slot))
+	if every < 1 {
+		every = 1
+	}
+	b.WriteString(`<g class="x-axis" text-anchor="middle" fill="#555555">` + "\n")
+	for i := 0; i < len(c.Points); i += every {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%s</text>`+"\n", x(i), bottom+16, html.EscapeString(c.Points[i].Label))
+	}
+	b.WriteString("</g>\n")
+
+	// The values, each with a tooltip of its label and value
+	b.WriteString(`<g class="series" fill="#3b78c2" stroke="#3b78c2">` + "\n")
+	if c.Kind == KindLine {
+		b.WriteString(`<polyline fill="none" stroke-width="2" points="`)
+		for i, p := range c.Points {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			fmt.Fprintf(&b, "%.1f,%.1f", x(i), y(p.Value))
+		}
+		b.WriteString(`"/>` + "\n")
+	}
+	for i, p := range c.Points {
+		tooltip := html.EscapeString(strings.TrimSpace(fmt.Sprintf("%s: %s %s", p.Label,
+			strconv.FormatFloat(p.Value, 'f', -1, 64), c.Unit)))
+		if c.Kind == KindBar {
+			barW := slot * 0.8
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%s</title></rect>`+"\n",
+				x(i)-barW/2, y(p.Value), barW, bottom-y(p.Value), tooltip)
+		} else {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3"><title>%s</title></circle>`+"\n",
+				x(i), y(p.Value), tooltip)
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+	_, err := out.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,93 @@
+package chart_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/enpointe/activity/chart"
+	"github.com/stretchr/testify/assert"
+)
+
+// elements count the elements of the SVG document by name, failing if the
+// document is not well formed
+func elements(t *testing.T, data []byte) map[string]int {
+	counts := map[string]int{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if !assert.NoError(t, err) {
+			return counts
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestRenderBar(t *testing.T) {
+	c := chart.Chart{
+		Title: "Weekly volume, Squat & Lunge",
+		Unit:  "kg",
+		Kind:  chart.KindBar,
+		Points: []chart.Point{
+			{Label: "Nov 4", Value: 1200},
+			{Label: "Nov 11", Value: 0},
+			{Label: "Nov 18", Value: 1900.5},
+		},
+	}
+	var b bytes.Buffer
+	assert.NoError(t, chart.Render(&b, &c))
+	svg := b.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="640" height="320"`))
+	assert.Contains(t, svg, "Squat &amp; Lunge")
+	assert.Contains(t, svg, "<title>Nov 18: 1900.5 kg</title>")
+	counts := elements(t, b.Bytes())
+	// The background and a bar for each point
+	assert.Equal(t, 4, counts["rect"])
+	assert.Equal(t, 0, counts["polyline"])
+	// The highest tick is the first multiple of the step of 500 above the largest value
+	assert.Contains(t, svg, ">2000</text>")
+}
+
+func TestRenderLine(t *testing.T) {
+	c := chart.Chart{
+		Title:  "Distance",
+		Unit:   "km",
+		Kind:   chart.KindLine,
+		Width:  chart.MinSize,
+		Height: chart.MinSize,
+		Points: []chart.Point{},
+	}
+	for _, v := range []float64{0.2, 0.35, 0.5, 0.8, 0.6} {
+		c.Points = append(c.Points, chart.Point{Label: "2019-11-18", Value: v})
+	}
+	var b bytes.Buffer
+	assert.NoError(t, chart.Render(&b, &c))
+	counts := elements(t, b.Bytes())
+	assert.Equal(t, 1, counts["polyline"])
+	assert.Equal(t, 5, counts["circle"])
+	assert.Contains(t, b.String(), ">0.8</text>")
+	// Labels are thinned to fit the width
+	assert.True(t, strings.Count(b.String(), ">2019-11-18</text>") < 5)
+}
+
+func TestRenderEmpty(t *testing.T) {
+	var b bytes.Buffer
+	assert.NoError(t, chart.Render(&b, &chart.Chart{Kind: chart.KindLine}))
+	elements(t, b.Bytes())
+	assert.Contains(t, b.String(), "No data")
+}
+
+func TestRenderInvalid(t *testing.T) {
+	var b bytes.Buffer
+	assert.Error(t, chart.Render(&b, &chart.Chart{Kind: "pie"}))
+	assert.Error(t, chart.Render(&b, &chart.Chart{Kind: chart.KindBar, Width: chart.MaxSize + 1}))
+	assert.Error(t, chart.Render(&b, &chart.Chart{Kind: chart.KindBar, Height: 10}))
+	assert.Equal(t, 0, b.Len())
+}
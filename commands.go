@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/migrate"
+	"github.com/enpointe/activity/models/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// commandUsage the usage of the commands that can be run instead of the HTTP server
+const commandUsage = `Commands:
+  migrate status           list the known migrations and whether they have been applied
+  migrate up [version]     apply pending migrations, up to and including version if specified
+  migrate down [version]   revert applied migrations newer than version, defaults to the previous version
+  import <collection> <file> [skip|upsert]
+                           import users or exercises from a JSON, JSON Lines or CSV file, the format
+                           is determined by the file extension. Existing entries are skipped by default
+  export <collection> <file> [passwords]
+                           export users, exercises or logs as JSON Lines or CSV, the format is determined
+                           by the file extension. A file of - writes JSON Lines to stdout. The hashed
+                           passwords of users are only exported if passwords is specified
+`
+
+// runCommand run the command specified on the command line. Returns the exit code.
+func runCommand(server *controllers.ServerService, args []string) int {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(server, args[1:])
+	case "import":
+		return runImport(server, args[1:])
+	case "export":
+		return runExport(server, args[1:])
+	default:
+		fmt.Printf("unknown command '%s'\n\n%s", args[0], commandUsage)
+		return -1
+	}
+}
+
+// runMigrate perform the migrate command
+func runMigrate(server *controllers.ServerService, args []string) int {
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Print(commandUsage)
+		return -1
+	}
+	version := 0
+	if len(args) == 2 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 {
+			fmt.Printf("invalid migration version '%s'\n", args[1])
+			return -1
+		}
+		version = v
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Minute)
+	defer cancel()
+	migrator, err := migrate.New(migrate.Env{Database: server.Database, Prefix: server.Prefix()})
+	if err != nil {
+		fmt.Println(err)
+		return -2
+	}
+
+	switch args[0] {
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Println(err)
+			return -2
+		}
+		if len(status) == 0 {
+			fmt.Println("No migrations defined")
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.Applied {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-30s  %s\n", s.Version, applied, s.Description)
+		}
+	case "up":
+		versions, err := migrator.Up(ctx, version)
+		for _, v := range versions {
+			fmt.Println("Applied migration", v)
+		}
+		if err != nil {
+			fmt.Println(err)
+			return -2
+		}
+		if len(versions) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		if len(args) == 1 {
+			current, err := migrator.Current(ctx)
+			if err != nil {
+				fmt.Println(err)
+				return -2
+			}
+			if current == 0 {
+				fmt.Println("No migrations to revert")
+				return 0
+			}
+			version = previousVersion(migrate.Registered(), current)
+		}
+		versions, err := migrator.Down(ctx, version)
+		for _, v := range versions {
+			fmt.Println("Reverted migration", v)
+		}
+		if err != nil {
+			fmt.Println(err)
+			return -2
+		}
+	default:
+		fmt.Printf("unknown migrate command '%s'\n\n%s", args[0], commandUsage)
+		return -1
+	}
+	return 0
+}
+
+// runImport perform the import command
+func runImport(server *controllers.ServerService, args []string) int {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Print(commandUsage)
+		return -1
+	}
+	collection, filename := args[0], args[1]
+	format := db.ImportJSON
+	if ext := filepath.Ext(filename); len(ext) > 0 {
+		f, err := db.ParseImportFormat(ext)
+		if err != nil {
+			fmt.Println(err)
+			return -1
+		}
+		format = f
+	}
+	mode := db.ImportSkipExisting
+	if len(args) == 3 {
+		m, err := db.ParseImportMode(args[2])
+		if err != nil {
+			fmt.Println(err)
+			return -1
+		}
+		mode = m
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		fmt.Println(err)
+		return -1
+	}
+	defer f.Close()
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Minute)
+	defer cancel()
+	result, err := server.ImportCollection(ctx, collection, f, format, mode)
+	if err != nil {
+		fmt.Println(err)
+		return -2
+	}
+	for _, e := range result.Errors {
+		fmt.Printf("%s:%d: %s\n", filename, e.Line, e.Message)
+	}
+	fmt.Printf("%d inserted, %d updated, %d skipped, %d errors\n",
+		result.Inserted, result.Updated, result.Skipped, len(result.Errors))
+	if len(result.Errors) > 0 {
+		return -3
+	}
+	return 0
+}
+
+// runExport perform the export command
+func runExport(server *controllers.ServerService, args []string) int {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Print(commandUsage)
+		return -1
+	}
+	collection, filename := args[0], args[1]
+	includePasswords := false
+	if len(args) == 3 {
+		if args[2] != "passwords" {
+			fmt.Print(commandUsage)
+			return -1
+		}
+		includePasswords = true
+	}
+
+	format := db.ExportJSONLines
+	out := os.Stdout
+	if filename != "-" {
+		if ext := filepath.Ext(filename); len(ext) > 0 {
+			f, err := db.ParseExportFormat(ext)
+			if err != nil {
+				fmt.Println(err)
+				return -1
+			}
+			format = f
+		}
+		f, err := os.Create(filename)
+		if err != nil {
+			fmt.Println(err)
+			return -1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Minute)
+	defer cancel()
+	err := server.ExportCollection(ctx, collection, out, format, includePasswords)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return -2
+	}
+	return 0
+}
+
+// previousVersion returns the version of the migration that precedes current,
+// zero if there is none
+func previousVersion(migrations []migrate.Migration, current int) int {
+	previous := 0
+	for _, m := range migrations {
+		if m.Version >= current {
+			break
+		}
+		previous = m.Version
+	}
+	return previous
+}
+
+// warnPendingMigrations warn if the database has migrations that still need to be applied
+func warnPendingMigrations(server *controllers.ServerService) {
+	migrator, err := migrate.New(migrate.Env{Database: server.Database, Prefix: server.Prefix()})
+	if err != nil {
+		log.Error(err)
+		return
+	}
+	pending, err := migrator.Pending(context.TODO())
+	if err != nil {
+		log.Errorf("unable to determine migration status, %s", err)
+		return
+	}
+	if pending > 0 {
+		msg := fmt.Sprintf("database has %d pending migrations, run '%s migrate up'", pending, os.Args[0])
+		log.Warn(msg)
+		fmt.Println(msg)
+	}
+}
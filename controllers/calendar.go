@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/enpointe/activity/ical"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateCalendarFeed create the secret token granting access to the iCalendar
+// feed of a user, replacing any previous token. The feed is served, without
+// requiring a login, at the path returned so that it can be subscribed to by
+// calendar applications.
+//
+// A basic privilege user can only create the feed of their own calendar, admin
+// and staff privileged users can create the feed of any user.
+//
+// @Summary Create the calendar feed of a user
+// @Description Create a secret token granting access to the iCalendar feed of the logged and
+// @Description scheduled sessions of a user, revoking any previous token.
+// @Tags client.CalendarFeed
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 201 {object} client.CalendarFeed
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/calendar/feed [post]
+func (s *ServerService) CreateCalendarFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("CreateCalendarFeed request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	token, err := calendarService.CreateFeedToken(ctx, ps.ByName("id"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s created the calendar feed of user %s", claims.ID, claims.Username, ps.ByName("id"))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(client.CalendarFeed{Token: token, Path: fmt.Sprintf("/calendar/%s.ics", token)})
+}
+
+// RevokeCalendarFeed revoke the token granting access to the iCalendar feed of a user
+//
+// @Summary Revoke the calendar feed of a user
+// @Description Revoke the secret token granting access to the iCalendar feed of a user.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/calendar/feed [delete]
+func (s *ServerService) RevokeCalendarFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("RevokeCalendarFeed request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = calendarService.RevokeFeedToken(ctx, ps.ByName("id")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s revoked the calendar feed of user %s", claims.ID, claims.Username, ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCalendarFeed return the iCalendar feed of the user holding the token of
+// the path. The feed holds a VEVENT for each session logged over the last
+// year, each session scheduled by an assigned training plan that is yet to
+// be completed and each session imported into the calendar of the user. No
+// login is required, access is granted by the secret token.
+//
+// @Summary Get the calendar feed of a user
+// @Description Get the logged and scheduled sessions of a user as an iCalendar (RFC 5545) file.
+// @Param token path string true "The calendar feed token followed by .ics"
+// @Produce  text/calendar
+// @Success 200 {string} string "The iCalendar file"
+// @Failure 404 {object} APIError "Not Found, if the token is unknown or revoked"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /calendar/{token} [get]
+func (s *ServerService) GetCalendarFeed(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetCalendarFeed request")
+	token := strings.TrimSuffix(ps.ByName("token"), ".ics")
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	calendar, err := calendarService.Feed(ctx, token)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "text/calendar; charset=utf-8")
+	w.Header().Set("content-disposition", `attachment; filename="workouts.ics"`)
+	w.WriteHeader(http.StatusOK)
+	if err = ical.Encode(w, calendar); err != nil {
+		log.Errorf("failed to write calendar feed, %s", err)
+	}
+}
+
+// ImportCalendar create a scheduled session for each event of the iCalendar
+// file held in the body of the request. Importing a calendar again updates
+// the sessions previously imported from its events and cancelled events
+// remove their session. The optional tz query parameter, an IANA time zone
+// name, is the time zone of floating times, by default UTC.
+//
+// A basic privilege user can only import into their own calendar, admin and
+// staff privileged users can import into the calendar of any user.
+//
+// @Summary Import an iCalendar file of scheduled sessions
+// @Description Create or update a scheduled session for each event of an iCalendar (RFC 5545) file.
+// @Tags client.ImportResult
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param tz query string false "IANA time zone of floating times, defaults to UTC"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  text/calendar
+// @Produce  json
+// @Success 200 {object} client.ImportResult
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 413 {object} APIError "Request Entity Too Large"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the body is not an iCalendar file"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/calendar [post]
+func (s *ServerService) ImportCalendar(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("ImportCalendar request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); len(tz) > 0 {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			errorWithJSON(w, "tz must be a IANA time zone name", http.StatusBadRequest)
+			return
+		}
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, db.MaxCalendarSize+1))
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > db.MaxCalendarSize {
+		errorWithJSON(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result, err := calendarService.Import(ctx, ps.ByName("id"), data, loc)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s imported a calendar for user %s, %d inserted, %d updated, %d skipped, %d errors",
+		claims.ID, claims.Username, ps.ByName("id"),
+		result.Inserted, result.Updated, result.Skipped, len(result.Errors))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetScheduledEvents return the sessions imported into the calendar of a user
+// in the order they start. The optional from and to query parameters, RFC3339
+// times, limit the sessions returned to those overlapping the range.
+//
+// @Summary Get the scheduled sessions of a user
+// @Description Get the sessions imported into the calendar of a user.
+// @Tags client.ScheduledEvent
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param from query string false "Only include sessions ending at or after this RFC3339 time"
+// @Param to query string false "Only include sessions starting before this RFC3339 time"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.ScheduledEvent
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/calendar/events [get]
+func (s *ServerService) GetScheduledEvents(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetScheduledEvents request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	from, err := parseTime(r, "from")
+	if err != nil {
+		errorWithJSON(w, "from must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(r, "to")
+	if err != nil {
+		errorWithJSON(w, "to must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events, err := calendarService.GetAll(ctx, ps.ByName("id"), from, to)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(events)
+}
+
+// DeleteScheduledEvent remove a session imported into the calendar of a user
+//
+// @Summary Delete a scheduled session
+// @Description Delete a session imported into the calendar of a user.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param event_id path string true "ID of the scheduled session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/calendar/events/{event_id} [delete]
+func (s *ServerService) DeleteScheduledEvent(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeleteScheduledEvent request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = calendarService.Delete(ctx, ps.ByName("id"), ps.ByName("event")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s deleted scheduled session %s of user %s",
+		claims.ID, claims.Username, ps.ByName("event"), ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCalendar = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+	"BEGIN:VEVENT\r\nUID:tempo@example.com\r\nDTSTART:%sT063000\r\nDTEND:%sT073000\r\n" +
+	"SUMMARY:Tempo run\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestCalendar(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+
+	// Import a session scheduled for tomorrow in the time zone of the user
+	date := time.Now().AddDate(0, 0, 1).Format("20060102")
+	body := strings.NewReader(strings.Replace(testCalendar, "%s", date, 2))
+	request := httptest.NewRequest(http.MethodPost, "http://calendar?tz=America/Los_Angeles", body)
+	request.AddCookie(cookie)
+	response := httptest.NewRecorder()
+	server.ImportCalendar(response, request, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var result client.ImportResult
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Equal(t, 1, result.Inserted)
+
+	response = workoutRequest(t, server.GetScheduledEvents, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var events []client.ScheduledEvent
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&events))
+	if assert.Equal(t, 1, len(events)) {
+		assert.Equal(t, "Tempo run", events[0].Title)
+	}
+
+	response = workoutRequest(t, server.CreateCalendarFeed, http.MethodPost, cookie, nil, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var feed client.CalendarFeed
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&feed))
+
+	// The feed does not require a login
+	request = httptest.NewRequest(http.MethodGet, "http://localhost"+feed.Path, nil)
+	response = httptest.NewRecorder()
+	server.GetCalendarFeed(response, request, httprouter.Params{{Key: "token", Value: feed.Token + ".ics"}})
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("content-type"), "text/calendar"))
+	assert.Contains(t, response.Body.String(), "SUMMARY:Tempo run")
+
+	response = workoutRequest(t, server.RevokeCalendarFeed, http.MethodDelete, cookie, nil, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = httptest.NewRecorder()
+	server.GetCalendarFeed(response, request, httprouter.Params{{Key: "token", Value: feed.Token + ".ics"}})
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	request = httptest.NewRequest(http.MethodPost, "http://calendar", bytes.NewBufferString("not a calendar"))
+	request.AddCookie(cookie)
+	response = httptest.NewRecorder()
+	server.ImportCalendar(response, request, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	if len(events) > 0 {
+		eventPs := append(ps, httprouter.Param{Key: "event", Value: events[0].ID})
+		response = workoutRequest(t, server.DeleteScheduledEvent, http.MethodDelete, cookie, nil, eventPs)
+		assert.Equal(t, http.StatusNoContent, response.Code)
+	}
+
+	// Basic users can only access their own calendar
+	ps = httprouter.Params{{Key: "id", Value: testBasic2ID}}
+	response = workoutRequest(t, server.CreateCalendarFeed, http.MethodPost, cookie, nil, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
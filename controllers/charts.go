@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/enpointe/activity/chart"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetUserChart render a progress chart of a user as a SVG image. The chart
+// path parameter selects the chart: volume, a bar chart of the volume lifted
+// per period, distance, a line chart of the distance covered per period, or
+// records, a line chart of the best one rep max estimated so far. The records
+// chart requires the exercise query parameter, which restricts the other charts
+// to an exercise. The from, to, period and tz query parameters select the
+// sessions and periods as for GetUserStats, and width and height the size of
+// the image in pixels.
+//
+// A basic privilege user can only view their own charts, admin and
+// staff privileged users can view the charts of any user.
+//
+// @Summary Get a progress chart of a user
+// @Description Render the volume lifted or distance covered per day, week or month, or the
+// @Description progression of the estimated one rep max of an exercise, as a SVG image.
+// @Tags client.Stats
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param chart path string true "The chart, volume, distance or records"
+// @Param exercise query string false "Only include this exercise, required by the records chart"
+// @Param from query string false "Only include sessions started at or after this RFC3339 time"
+// @Param to query string false "Only include sessions started before this RFC3339 time"
+// @Param period query string false "Group sessions by day, week or month, defaults to week"
+// @Param tz query string false "IANA time zone periods start in, defaults to UTC"
+// @Param width query int false "Width of the image in pixels, defaults to 640"
+// @Param height query int false "Height of the image in pixels, defaults to 320"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  image/svg+xml
+// @Success 200 {string} string "The SVG image"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the chart, period, range or size is invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/charts/{chart} [get]
+func (s *ServerService) GetUserChart(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetUserChart request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	query := db.ChartQuery{
+		StatsQuery: db.StatsQuery{
+			UserID:     ps.ByName("id"),
+			ExerciseID: r.URL.Query().Get("exercise"),
+			Period:     r.URL.Query().Get("period"),
+		},
+		Chart: ps.ByName("chart"),
+	}
+	var err error
+	if query.From, err = parseTime(r, "from"); err != nil {
+		errorWithJSON(w, "from must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseTime(r, "to"); err != nil {
+		errorWithJSON(w, "to must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	if tz := r.URL.Query().Get("tz"); len(tz) > 0 {
+		if query.Location, err = time.LoadLocation(tz); err != nil {
+			errorWithJSON(w, "tz must be a IANA time zone name", http.StatusBadRequest)
+			return
+		}
+	}
+	if width := r.URL.Query().Get("width"); len(width) > 0 {
+		if query.Width, err = strconv.Atoi(width); err != nil {
+			errorWithJSON(w, "width must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if height := r.URL.Query().Get("height"); len(height) > 0 {
+		if query.Height, err = strconv.Atoi(height); err != nil {
+			errorWithJSON(w, "height must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c, err := workoutService.Chart(ctx, query)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	if err = chart.Render(w, c); err != nil {
+		log.Errorf("failed to render chart %s", err)
+	}
+}
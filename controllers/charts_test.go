@@ -0,0 +1,63 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserChart(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	workout := client.Workout{Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat,
+		Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 100, Unit: db.UnitKilogram}}}}}
+	response := workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, workout, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	chart := func(name string, query string, userID string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "http://charts?"+query, nil)
+		request.AddCookie(cookie)
+		response := httptest.NewRecorder()
+		server.GetUserChart(response, request,
+			httprouter.Params{{Key: "id", Value: userID}, {Key: "chart", Value: name}})
+		return response
+	}
+	response = chart(db.ChartRecords, "exercise="+squat+"&width=400&height=200", testBasic1ID)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "image/svg+xml", response.Header().Get("content-type"))
+	svg := response.Body.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.Contains(t, svg, `width="400" height="200"`)
+	assert.Contains(t, svg, "Squat, Estimated one rep max")
+
+	response = chart(db.ChartVolume, "period=day", testBasic1ID)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// The records chart requires an exercise, sizes are bounded
+	response = chart(db.ChartRecords, "", testBasic1ID)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = chart(db.ChartVolume, "width=10", testBasic1ID)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = chart(db.ChartVolume, "width=wide", testBasic1ID)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = chart("pie", "", testBasic1ID)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = chart(db.ChartVolume, "exercise=5dab5fa871aab123354e5cb6", testBasic1ID)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	// Basic users can only view their own charts
+	response = chart(db.ChartVolume, "", testBasic2ID)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
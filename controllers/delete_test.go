@@ -1,13 +1,16 @@
 package controllers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
 	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -97,3 +100,30 @@ func TestDeleteBasicPrivileges(t *testing.T) {
 	}
 	deleteTest(t, creds, testData)
 }
+
+// Test the private exercises of a deleted user are deleted with the user
+func TestDeleteUserExercises(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	ctx := context.TODO()
+	service, err := db.NewExerciseService(server.Database, log.StandardLogger())
+	assert.NoError(t, err)
+	deleted, err := service.CreatePrivate(ctx, testBasic1ID, &client.Exercise{Name: "Garage Sled Push"})
+	assert.NoError(t, err)
+	kept, err := service.CreatePrivate(ctx, testBasic2ID, &client.Exercise{Name: "Garage Sled Push"})
+	assert.NoError(t, err)
+
+	creds := client.Credentials{Username: testAdmin1Username, Password: testAdmin1UserPassword}
+	tokenCookie := login(t, server, creds)
+	defer logout(t, server, tokenCookie)
+	request := httptest.NewRequest(http.MethodDelete, "http://user/Delete/"+testBasic1ID, nil)
+	request.AddCookie(tokenCookie)
+	response := httptest.NewRecorder()
+	server.DeleteUser(response, request, httprouter.Params{{Key: "id", Value: testBasic1ID}})
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	_, err = service.GetByID(ctx, deleted)
+	assert.Error(t, err, "private exercise of the deleted user not deleted")
+	_, err = service.GetByID(ctx, kept)
+	assert.NoError(t, err, "private exercise of another user deleted")
+}
@@ -0,0 +1,323 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/enpointe/activity/perm"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetExercises return the exercises of the shared catalog along with the
+// private exercises of the user, ordered by name, optionally filtered by
+// their taxonomy. The category, muscle, primary, equipment, difficulty and
+// measurement query parameters restrict the exercises returned to those with
+// the attribute, muscle matching either a primary or secondary muscle group
+// and primary only a primary muscle group. The name and description of each
+// exercise are translated into the most preferred locale of the
+// Accept-Language header with a translation, falling back to the default
+// locale.
+//
+// Any logged in user can view the exercises.
+//
+// @Summary Get the exercises
+// @Description Get the shared and private exercises of the user, filtered by category, muscle
+// @Description group, equipment, difficulty and measurement.
+// @Tags client.Exercise
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param category query string false "Only return exercises of this category, strength, cardio or flexibility"
+// @Param muscle query string false "Only return exercises working this primary or secondary muscle group"
+// @Param primary query string false "Only return exercises with this primary muscle group"
+// @Param equipment query string false "Only return exercises using this equipment"
+// @Param difficulty query string false "Only return exercises of this difficulty, beginner, intermediate or advanced"
+// @Param measurement query string false "Only return exercises measured in reps, time or distance"
+// @Param Accept-Language header string false "The preferred locales of the names and descriptions"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.Exercise
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 422 {object} APIError "Unprocessable Entity, if a filter value is not part of the taxonomy"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /exercises [get]
+func (s *ServerService) GetExercises(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetExercises request")
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return
+	}
+	query := r.URL.Query()
+	filter := db.ExerciseFilter{
+		UserID:      claims.ID,
+		Locales:     db.ParseAcceptLanguage(r.Header.Get("Accept-Language")),
+		Category:    query.Get("category"),
+		Muscle:      query.Get("muscle"),
+		Primary:     query.Get("primary"),
+		Equipment:   query.Get("equipment"),
+		Difficulty:  query.Get("difficulty"),
+		Measurement: query.Get("measurement"),
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	exercises, err := exerciseService.Find(ctx, &filter)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("vary", "Accept-Language")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exercises)
+}
+
+// SearchExercises return the shared and private exercises of the user matching
+// the q query parameter, most relevant first. The name, aliases and
+// description of the exercises are searched, matches in the name and aliases
+// ranking higher. When no exercise contains the words of
+// the search the exercises are matched by prefix, allowing for typos. The page
+// and limit query parameters select the page of results returned. The results
+// are translated as for GetExercises, their translations being searched too.
+//
+// Any logged in user can search the exercises.
+//
+// @Summary Search the exercises
+// @Description Full text search of the name, aliases and description of the exercises with relevance
+// @Description ranking, pagination and prefix and typo tolerant matching.
+// @Tags client.ExerciseSearch
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param q query string true "The text to search for"
+// @Param page query int false "The page of results, starting at 1"
+// @Param limit query int false "The number of results in a page, defaults to 20, at most 100"
+// @Param Accept-Language header string false "The preferred locales of the names and descriptions"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.ExerciseSearch
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the search text, page or limit is invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /exercises/search [get]
+func (s *ServerService) SearchExercises(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("SearchExercises request")
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return
+	}
+	query := db.SearchQuery{UserID: claims.ID, Text: r.URL.Query().Get("q"),
+		Locales: db.ParseAcceptLanguage(r.Header.Get("Accept-Language"))}
+	var err error
+	if page := r.URL.Query().Get("page"); len(page) > 0 {
+		if query.Page, err = strconv.Atoi(page); err != nil {
+			errorWithJSON(w, "page must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := r.URL.Query().Get("limit"); len(limit) > 0 {
+		if query.Limit, err = strconv.Atoi(limit); err != nil {
+			errorWithJSON(w, "limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result, err := exerciseService.Search(ctx, query)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("vary", "Accept-Language")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// MergeExercises fold the duplicate exercise identified by the from query
+// parameter into the exercise identified by the into query parameter. The
+// workout sessions, templates, goals and personal records referencing the
+// duplicate are repointed to the merged exercise, the name and aliases of the
+// duplicate become aliases of the merged exercise and the duplicate is deleted.
+//
+// Only admin privileged users can perform this operation.
+//
+// @Summary Merge a duplicate exercise into another
+// @Description Fold a duplicate exercise into another, repointing the workout sessions,
+// @Description templates and goals referencing it. Only admin privileged users can perform
+// @Description this operation.
+// @Tags client.ExerciseMerge
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param from query string true "The id of the duplicate exercise"
+// @Param into query string true "The id of the exercise the duplicate is merged into"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.ExerciseMerge
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if either exercise does not exist"
+// @Failure 409 {object} APIError "Conflict, if the aliases of the merged exercise conflict with another exercise"
+// @Failure 422 {object} APIError "Unprocessable Entity, if an exercise is merged into itself"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /exercises/merge [post]
+func (s *ServerService) MergeExercises(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("MergeExercises request")
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return
+	}
+	if !claims.Privilege.Grants(perm.Admin) {
+		errorWithJSON(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	from, into := r.URL.Query().Get("from"), r.URL.Query().Get("into")
+	if len(from) == 0 || len(into) == 0 {
+		errorWithJSON(w, "from and into must be specified", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Minute)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result, err := exerciseService.Merge(ctx, from, into)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s merged exercise %s into %s, %d workouts, %d templates, %d goals",
+		claims.ID, claims.Username, from, into, result.Workouts, result.Templates, result.Goals)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// CreateUserExercise add a private exercise for a user, visible only to the
+// user, for movements missing from the shared catalog. The POST request should
+// contain a JSON payload with the fields of client.Exercise. The name and
+// aliases of the exercise must not conflict with the shared catalog or the
+// other private exercises of the user.
+//
+// A basic privilege user can only add exercises for themselves, admin and
+// staff privileged users can add exercises for any user.
+//
+// @Summary Add a private exercise
+// @Description Add an exercise visible only to the user alongside the shared catalog.
+// @Tags client.Exercise Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param Exercise body client.Exercise true "The exercise"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 409 {object} APIError "Conflict, if the name or an alias matches an exercise visible to the user"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the exercise fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/exercises [post]
+func (s *ServerService) CreateUserExercise(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("CreateUserExercise request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var exercise client.Exercise
+	if err := json.NewDecoder(r.Body).Decode(&exercise); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := exerciseService.CreatePrivate(ctx, ps.ByName("id"), &exercise)
+	if err != nil {
+		log.Debugf("%s:%s failed to create private exercise, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s created private exercise %s", claims.ID, claims.Username, id)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// PromoteUserExercise move a private exercise of a user into the shared
+// catalog, making it visible to every user. The name and aliases of the
+// exercise must not conflict with the shared catalog.
+//
+// Only admin and staff privileged users can promote exercises.
+//
+// @Summary Promote a private exercise into the shared catalog
+// @Description Move a private exercise of a user into the shared catalog.
+// @Tags client.Exercise
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param exercise_id path string true "ID of the private exercise"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.Exercise
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the user has no such private exercise"
+// @Failure 409 {object} APIError "Conflict, if the name or an alias matches an exercise of the shared catalog"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/exercises/{exercise_id}/promote [post]
+func (s *ServerService) PromoteUserExercise(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("PromoteUserExercise request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	exercise, err := exerciseService.Promote(ctx, ps.ByName("id"), ps.ByName("exercise"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s promoted exercise %s of %s", claims.ID, claims.Username, exercise.ID, ps.ByName("id"))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(exercise)
+}
@@ -0,0 +1,198 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExercises(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	service, err := db.NewExerciseService(server.Database, log.StandardLogger())
+	assert.NoError(t, err)
+	for _, e := range []client.Exercise{
+		{Name: "Squat", Category: db.CategoryStrength, PrimaryMuscles: []string{"quadriceps"},
+			Equipment: []string{"barbell"}},
+		{Name: "Running", Category: db.CategoryCardio, SecondaryMuscles: []string{"quadriceps"},
+			Measurement: db.MeasureDistance},
+		{Name: "Stretch", Category: db.CategoryFlexibility},
+	} {
+		assert.NoError(t, service.Create(context.TODO(), &e))
+	}
+
+	get := func(query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "http://exercises?"+query, nil)
+		if cookie != nil {
+			request.AddCookie(cookie)
+		}
+		response := httptest.NewRecorder()
+		server.GetExercises(response, request, nil)
+		return response
+	}
+	response := get("", nil)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+	response = get("muscle=quadriceps", cookie)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var exercises []client.Exercise
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&exercises))
+	if assert.Equal(t, 2, len(exercises)) {
+		assert.Equal(t, "Running", exercises[0].Name)
+		assert.Equal(t, db.MeasureDistance, exercises[0].Measurement)
+		assert.Equal(t, "Squat", exercises[1].Name)
+	}
+
+	response = get("primary=quadriceps&equipment=barbell", cookie)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&exercises))
+	assert.Equal(t, 1, len(exercises))
+
+	response = get("difficulty=expert", cookie)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+func TestSearchExercises(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	service, err := db.NewExerciseService(server.Database, log.StandardLogger())
+	assert.NoError(t, err)
+	assert.NoError(t, service.Create(context.TODO(), &client.Exercise{Name: "Burpee",
+		Description: "The burpee, or squat thrust, is a full body exercise"}))
+	assert.NoError(t, service.Create(context.TODO(), &client.Exercise{Name: "Squat"}))
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	search := func(query string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "http://exercises/search?"+query, nil)
+		request.AddCookie(cookie)
+		response := httptest.NewRecorder()
+		server.SearchExercises(response, request, nil)
+		return response
+	}
+	response := search("q=squat+thrust")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var result client.ExerciseSearch
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Equal(t, 2, result.Total)
+
+	response = search("q=thrsut&limit=1")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	if assert.Equal(t, 1, len(result.Results)) {
+		assert.Equal(t, "Burpee", result.Results[0].Name)
+	}
+
+	response = search("q=")
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = search("q=squat&page=first")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestMergeExercises(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	service, err := db.NewExerciseService(server.Database, log.StandardLogger())
+	assert.NoError(t, err)
+	squat := addExercise(t, server, "Squat")
+	duplicate := addExercise(t, server, "Back Squat")
+
+	merge := func(query string, creds client.Credentials) *httptest.ResponseRecorder {
+		cookie := login(t, server, creds)
+		defer logout(t, server, cookie)
+		request := httptest.NewRequest(http.MethodPost, "http://exercises/merge?"+query, nil)
+		request.AddCookie(cookie)
+		response := httptest.NewRecorder()
+		server.MergeExercises(response, request, nil)
+		return response
+	}
+	admin := client.Credentials{Username: testAdmin1Username, Password: testAdmin1UserPassword}
+	basic := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	response := merge("from="+duplicate+"&into="+squat, basic)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = merge("from="+duplicate, admin)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = merge("from="+duplicate+"&into="+squat, admin)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var result client.ExerciseMerge
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Equal(t, squat, result.Exercise.ID)
+	assert.Equal(t, []string{"Back Squat"}, result.Exercise.Aliases)
+	e, err := service.GetByName(context.TODO(), "back squat")
+	assert.NoError(t, err)
+	assert.Equal(t, squat, e.ID)
+
+	response = merge("from="+duplicate+"&into="+squat, admin)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestPrivateExercises(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	addExercise(t, server, "Squat")
+	basic1 := login(t, server, client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword})
+	defer logout(t, server, basic1)
+	basic2 := login(t, server, client.Credentials{Username: testBasic2Username, Password: testBasic2UserPassword})
+	defer logout(t, server, basic2)
+	staff := login(t, server, client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword})
+	defer logout(t, server, staff)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	response := workoutRequest(t, server.CreateUserExercise, http.MethodPost, basic1,
+		client.Exercise{Name: "Sled Push"}, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var identity controllers.Identity
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&identity))
+	response = workoutRequest(t, server.CreateUserExercise, http.MethodPost, basic1,
+		client.Exercise{Name: "squat"}, ps)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	response = workoutRequest(t, server.CreateUserExercise, http.MethodPost, basic2,
+		client.Exercise{Name: "Sled Pull"}, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	// The private exercise is listed for its owner only
+	list := func(cookie *http.Cookie) []client.Exercise {
+		response := workoutRequest(t, server.GetExercises, http.MethodGet, cookie, nil, nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+		var exercises []client.Exercise
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&exercises))
+		return exercises
+	}
+	if exercises := list(basic1); assert.Equal(t, 2, len(exercises)) {
+		assert.Equal(t, "Sled Push", exercises[0].Name)
+		assert.Equal(t, testBasic1ID, exercises[0].Owner)
+	}
+	assert.Equal(t, 1, len(list(basic2)))
+
+	// Other users can not log the private exercise
+	workout := client.Workout{Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: identity.ID}}}
+	response = workoutRequest(t, server.CreateWorkout, http.MethodPost, basic2, workout,
+		httprouter.Params{{Key: "id", Value: testBasic2ID}})
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = workoutRequest(t, server.CreateWorkout, http.MethodPost, basic1, workout, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// Staff promote the exercise into the shared catalog
+	promotePs := append(ps, httprouter.Param{Key: "exercise", Value: identity.ID})
+	response = workoutRequest(t, server.PromoteUserExercise, http.MethodPost, basic1, nil, promotePs)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = workoutRequest(t, server.PromoteUserExercise, http.MethodPost, staff, nil, promotePs)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 2, len(list(basic2)))
+	response = workoutRequest(t, server.PromoteUserExercise, http.MethodPost, staff, nil, promotePs)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/enpointe/activity/perm"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// Export stream the contents of a collection as JSON Lines or CSV.
+// The collection query parameter identifies the data to export, either users,
+// exercises or logs. The format query parameter selects jsonl (the default) or csv.
+// JSON Lines output uses MongoDB Extended JSON and can be loaded back via Import
+// or mongoimport. The hashed passwords of users are only included when the
+// passwords query parameter is set to true.
+//
+// Only admin privileged users can perform this operation.
+//
+// @Summary Export users, exercises or logs
+// @Description Stream the contents of a collection as JSON Lines or CSV.
+// @Description The hashed passwords of users are redacted unless explicitly requested.
+// @Description Only admin privileged users can perform this operation.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Param collection query string true "The collection to export, users, exercises or logs"
+// @Param format query string false "The format of the data, jsonl or csv"
+// @Param passwords query bool false "Include the hashed passwords of users"
+// @Produce  application/x-ndjson
+// @Produce  text/csv
+// @Success 200 {string} string "The exported documents"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 405 {object} APIError "Method Not Allowed"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /export [get]
+func (s *ServerService) Export(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	log.Trace("Export request")
+	if r.Method != "GET" {
+		errorWithJSON(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return
+	}
+
+	// Only allow operation if the user is an administrator
+	if !claims.Privilege.Grants(perm.Admin) {
+		errorWithJSON(w,
+			http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	format := db.ExportJSONLines
+	if f := query.Get("format"); len(f) > 0 {
+		var err error
+		format, err = db.ParseExportFormat(f)
+		if err != nil {
+			errorWithJSON(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	includePasswords := false
+	if p := query.Get("passwords"); len(p) > 0 {
+		var err error
+		includePasswords, err = strconv.ParseBool(p)
+		if err != nil {
+			errorWithJSON(w, "passwords must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	collection := query.Get("collection")
+	if !exportable(collection) {
+		errorWithJSON(w, ErrUnknownCollection.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Minute)
+	defer cancel()
+	w.Header().Set("content-type", format.ContentType())
+	w.Header().Set("content-disposition",
+		fmt.Sprintf("attachment; filename=\"%s.%s\"", collection, format))
+	w.WriteHeader(http.StatusOK)
+	err := s.ExportCollection(ctx, collection, w, format, includePasswords)
+	if err != nil {
+		// The response is already being streamed, all that can be done is log the failure
+		log.Errorf("%s:%s export of %s failed, %s", claims.ID, claims.Username, collection, err)
+		return
+	}
+	log.Infof("%s:%s exported %s, passwords included: %t",
+		claims.ID, claims.Username, collection, includePasswords)
+}
+
+// exportable reports whether the named collection can be exported
+func exportable(collection string) bool {
+	switch collection {
+	case db.UsersCollection, db.ExerciseCollection, db.LogsCollection:
+		return true
+	}
+	return false
+}
+
+// ExportCollection write the contents of the named collection, either users, exercises
+// or logs, to w. The hashed passwords of users are only included if includePasswords is set.
+func (s *ServerService) ExportCollection(ctx context.Context, collection string, w io.Writer,
+	format db.ExportFormat, includePasswords bool) error {
+	switch collection {
+	case db.UsersCollection:
+		userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
+		if err != nil {
+			return err
+		}
+		return userService.Export(ctx, w, format, includePasswords)
+	case db.ExerciseCollection:
+		exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+		if err != nil {
+			return err
+		}
+		return exerciseService.Export(ctx, w, format)
+	case db.LogsCollection:
+		logs := s.Database.Collection(db.CollectionName(db.LogsCollection, s.serviceOptions()...))
+		return db.Export(ctx, logs, w, db.ExportOptions{Format: format})
+	default:
+		return ErrUnknownCollection
+	}
+}
@@ -0,0 +1,66 @@
+package controllers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type exportTestData struct {
+	url              string
+	expectedResponse int
+	passwords        bool
+}
+
+func exportTest(t *testing.T, creds client.Credentials, testData []exportTestData) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	tokenCookie := login(t, server, creds)
+	defer logout(t, server, tokenCookie)
+
+	for _, d := range testData {
+		t.Run(fmt.Sprintf("Export-%s", d.url),
+			func(t *testing.T) {
+				request := httptest.NewRequest(http.MethodGet, d.url, nil)
+				request.AddCookie(tokenCookie)
+				response := httptest.NewRecorder()
+				server.Export(response, request, nil)
+				assert.Equalf(t, d.expectedResponse, response.Code,
+					"%s attempted export %s, expected '%s' got '%s'", creds.Username, d.url,
+					http.StatusText(d.expectedResponse), http.StatusText(response.Code))
+				if response.Code == http.StatusOK {
+					if d.passwords {
+						assert.Contains(t, response.Body.String(), "$2a$")
+					} else {
+						assert.NotContains(t, response.Body.String(), "$2a$")
+					}
+				}
+			})
+	}
+}
+
+func TestAdminExport(t *testing.T) {
+	creds := client.Credentials{Username: testAdmin1Username, Password: testAdmin1UserPassword}
+	testData := []exportTestData{
+		exportTestData{"http://export?collection=users", http.StatusOK, false},
+		exportTestData{"http://export?collection=users&format=csv&passwords=true", http.StatusOK, true},
+		exportTestData{"http://export?collection=exercises&format=csv", http.StatusOK, false},
+		exportTestData{"http://export?collection=logs", http.StatusOK, false},
+		exportTestData{"http://export?collection=migrations", http.StatusBadRequest, false},
+		exportTestData{"http://export?collection=users&format=xml", http.StatusBadRequest, false},
+		exportTestData{"http://export?collection=users&passwords=maybe", http.StatusBadRequest, false},
+	}
+	exportTest(t, creds, testData)
+}
+
+func TestStaffExport(t *testing.T) {
+	creds := client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword}
+	testData := []exportTestData{
+		exportTestData{"http://export?collection=users", http.StatusForbidden, false},
+	}
+	exportTest(t, creds, testData)
+}
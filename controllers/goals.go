@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateGoal set a goal for a user. The POST request should contain a JSON
+// payload with the fields of client.Goal. Progress towards the goal is
+// computed from the sessions already logged and updated as sessions are
+// logged, modified and deleted.
+//
+// A basic privilege user can only set goals for themselves, admin and
+// staff privileged users can set goals for any user.
+//
+// @Summary Set a goal
+// @Description Set a goal for a user, ie run 50 km this month or squat 100 kg. Goals cover
+// @Description a day, week or month, or from a start until a deadline.
+// @Tags client.Goal Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param Goal body client.Goal true "The goal"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the goal fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/goals [post]
+func (s *ServerService) CreateGoal(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("CreateGoal request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var goal client.Goal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	goalService, err := db.NewGoalService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := goalService.Create(ctx, ps.ByName("id"), &goal)
+	if err != nil {
+		log.Debugf("%s:%s failed to create goal, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s created goal %s for user %s", claims.ID, claims.Username, id, ps.ByName("id"))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// GetGoals return the goals of a user, those with the latest deadline first.
+// The optional status query parameter, active, achieved or missed, restricts
+// the goals returned to those with that status.
+//
+// @Summary Get the goals of a user
+// @Description Get the goals of a user with their progress and the history of their status.
+// @Tags client.Goal
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param status query string false "Only return goals with this status, active, achieved or missed"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.Goal
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/goals [get]
+func (s *ServerService) GetGoals(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetGoals request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	goalService, err := db.NewGoalService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	goals, err := goalService.GetAll(ctx, ps.ByName("id"), r.URL.Query().Get("status"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(goals)
+}
+
+// GetGoal return a single goal of a user
+//
+// @Summary Get a goal
+// @Description Get a goal of a user with its progress and the history of its status.
+// @Tags client.Goal
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param goal_id path string true "ID of the goal"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.Goal
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/goals/{goal_id} [get]
+func (s *ServerService) GetGoal(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetGoal request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	goalService, err := db.NewGoalService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	goal, err := goalService.GetByID(ctx, ps.ByName("id"), ps.ByName("goal"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(goal)
+}
+
+// DeleteGoal delete a goal of a user
+//
+// @Summary Delete a goal
+// @Description Delete a goal of a user along with its history.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param goal_id path string true "ID of the goal"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/goals/{goal_id} [delete]
+func (s *ServerService) DeleteGoal(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeleteGoal request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	goalService, err := db.NewGoalService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = goalService.Delete(ctx, ps.ByName("id"), ps.ByName("goal")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s deleted goal %s of user %s", claims.ID, claims.Username, ps.ByName("goal"), ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoals(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	goal := client.Goal{Metric: "max-load", ExerciseID: squat, Target: 100, Unit: "kg", Period: "month"}
+	response := workoutRequest(t, server.CreateGoal, http.MethodPost, cookie, goal, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var identity controllers.Identity
+	json.NewDecoder(response.Body).Decode(&identity)
+
+	workout := client.Workout{Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat,
+		Sets: []client.StrengthSet{client.StrengthSet{Reps: 1, Load: 100, Unit: "kg"}}}}}
+	response = workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, workout, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	goalPs := append(ps, httprouter.Param{Key: "goal", Value: identity.ID})
+	response = workoutRequest(t, server.GetGoal, http.MethodGet, cookie, nil, goalPs)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var result client.Goal
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Equal(t, "achieved", result.Status)
+	assert.Equal(t, 100.0, result.Percent)
+
+	var goals []client.Goal
+	response = workoutRequest(t, server.GetGoals, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&goals))
+	assert.Equal(t, 1, len(goals))
+
+	goal.Metric = "calories"
+	response = workoutRequest(t, server.CreateGoal, http.MethodPost, cookie, goal, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	// A basic user can not set goals for another user
+	response = workoutRequest(t, server.CreateGoal, http.MethodPost, cookie, goal,
+		httprouter.Params{{Key: "id", Value: testBasic2ID}})
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	response = workoutRequest(t, server.DeleteGoal, http.MethodDelete, cookie, nil, goalPs)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.GetGoal, http.MethodGet, cookie, nil, goalPs)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/enpointe/activity/perm"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrUnknownCollection returned when a request names a collection that is not supported
+var ErrUnknownCollection = errors.New("unknown collection")
+
+// importFormat determine the format of the request body. The format query
+// parameter takes precedence over the Content-Type of the request.
+func importFormat(r *http.Request) (db.ImportFormat, error) {
+	if format := r.URL.Query().Get("format"); len(format) > 0 {
+		return db.ParseImportFormat(format)
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return db.ImportCSV, nil
+	default:
+		return db.ImportJSON, nil
+	}
+}
+
+// Import bulk load users or exercises into the database.
+// The collection query parameter identifies the data being loaded, either users
+// or exercises. The body of the request may be JSON Lines (one document per line),
+// a JSON array or CSV with a header line naming the fields. JSON documents may use
+// MongoDB Extended JSON as produced by mongoexport. The format is determined from
+// the format query parameter, or if not set, from the Content-Type of the request.
+//
+// The mode query parameter determines how a record that matches an existing entry
+// is handled, skip (the default) leaves the existing entry as is, upsert replaces it.
+// Every record is validated, the errors for records that could not be imported are
+// reported along with the line of the record.
+//
+// Only admin privileged users can perform this operation.
+//
+// @Summary Bulk import users or exercises
+// @Description Bulk import users or exercises from JSON Lines, a JSON array or CSV.
+// @Description Each record is validated, records that fail validation are reported
+// @Description with their line number. Only admin privileged users can perform this operation.
+// @Tags client.ImportResult
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Param collection query string true "The collection to import into, users or exercises"
+// @Param format query string false "The format of the data, json, jsonl or csv"
+// @Param mode query string false "How to handle records matching existing entries, skip or upsert"
+// @Accept  json
+// @Accept  text/csv
+// @Produce  json
+// @Success 200 {object} client.ImportResult
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 405 {object} APIError "Method Not Allowed"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the data could not be read"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /import [post]
+func (s *ServerService) Import(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	log.Trace("Import request")
+	if r.Method != "POST" {
+		errorWithJSON(w, http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed)
+		return
+	}
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return
+	}
+
+	// Only allow operation if the user is an administrator
+	if !claims.Privilege.Grants(perm.Admin) {
+		errorWithJSON(w,
+			http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	format, err := importFormat(r)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mode := db.ImportSkipExisting
+	if m := r.URL.Query().Get("mode"); len(m) > 0 {
+		mode, err = db.ParseImportMode(m)
+		if err != nil {
+			errorWithJSON(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Minute)
+	defer cancel()
+	collection := r.URL.Query().Get("collection")
+	result, err := s.ImportCollection(ctx, collection, r.Body, format, mode)
+	if err == ErrUnknownCollection {
+		errorWithJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	log.Infof("%s:%s imported %s, %d inserted, %d updated, %d skipped, %d errors",
+		claims.ID, claims.Username, collection,
+		result.Inserted, result.Updated, result.Skipped, len(result.Errors))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ImportCollection import the data in r into the named collection, either users or exercises
+func (s *ServerService) ImportCollection(ctx context.Context, collection string, r io.Reader,
+	format db.ImportFormat, mode db.ImportMode) (*client.ImportResult, error) {
+	switch collection {
+	case db.UsersCollection:
+		userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		return userService.Import(ctx, r, format, mode)
+	case db.ExerciseCollection:
+		exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		return exerciseService.Import(ctx, r, format, mode)
+	default:
+		return nil, ErrUnknownCollection
+	}
+}
@@ -0,0 +1,100 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type importTestData struct {
+	url              string
+	contentType      string
+	body             string
+	expectedResponse int
+	expectedInserted int
+}
+
+func importTest(t *testing.T, creds client.Credentials, testData []importTestData) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	tokenCookie := login(t, server, creds)
+	defer logout(t, server, tokenCookie)
+
+	for _, d := range testData {
+		t.Run(fmt.Sprintf("Import-%s", d.url),
+			func(t *testing.T) {
+				request := httptest.NewRequest(http.MethodPost, d.url, strings.NewReader(d.body))
+				request.Header.Set("Content-Type", d.contentType)
+				request.AddCookie(tokenCookie)
+				response := httptest.NewRecorder()
+				server.Import(response, request, nil)
+				assert.Equalf(t, d.expectedResponse, response.Code,
+					"%s attempted import %s, expected '%s' got '%s'", creds.Username, d.url,
+					http.StatusText(d.expectedResponse), http.StatusText(response.Code))
+				if response.Code == http.StatusOK {
+					var result client.ImportResult
+					err := json.NewDecoder(response.Body).Decode(&result)
+					assert.NoError(t, err)
+					assert.Equal(t, d.expectedInserted, result.Inserted)
+				}
+			})
+	}
+}
+
+func TestAdminImport(t *testing.T) {
+	creds := client.Credentials{Username: testAdmin1Username, Password: testAdmin1UserPassword}
+	testData := []importTestData{
+		importTestData{
+			url:              "http://import?collection=users",
+			contentType:      "text/csv",
+			body:             "username,password,privilege\ncustomer3,changeMe,basic\nadmin1,changeMe,admin\n",
+			expectedResponse: http.StatusOK,
+			expectedInserted: 1,
+		},
+		importTestData{
+			url:              "http://import?collection=exercises&format=jsonl&mode=upsert",
+			contentType:      "application/x-ndjson",
+			body:             "{\"name\":\"Squat\"}\n{\"name\":\"Lunge\"}\n",
+			expectedResponse: http.StatusOK,
+			expectedInserted: 2,
+		},
+		importTestData{ // Unknown collection
+			url:              "http://import?collection=logins",
+			contentType:      "application/json",
+			body:             "[]",
+			expectedResponse: http.StatusBadRequest,
+		},
+		importTestData{ // Unknown mode
+			url:              "http://import?collection=users&mode=merge",
+			contentType:      "application/json",
+			body:             "[]",
+			expectedResponse: http.StatusBadRequest,
+		},
+		importTestData{ // Unreadable data
+			url:              "http://import?collection=users",
+			contentType:      "application/json",
+			body:             "[{",
+			expectedResponse: http.StatusUnprocessableEntity,
+		},
+	}
+	importTest(t, creds, testData)
+}
+
+func TestStaffImport(t *testing.T) {
+	creds := client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword}
+	testData := []importTestData{
+		importTestData{
+			url:              "http://import?collection=exercises",
+			contentType:      "application/json",
+			body:             "[{\"name\":\"Squat\"}]",
+			expectedResponse: http.StatusForbidden,
+		},
+	}
+	importTest(t, creds, testData)
+}
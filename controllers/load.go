@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetTrainingLoad return the training load analytics of a user: the time
+// spent in each heart rate zone and the load of each session, along with the
+// acute:chronic workload ratio of each day. The optional from and to query
+// parameters, RFC3339 times, set the range of days reported, by default the
+// last 28 days, and tz, an IANA time zone name, the time zone days start in.
+// The method query parameter selects the load, trimp (the default) which
+// requires the heart rates of the user set via UpdateHeartRates, or rpe. A
+// warning is reported for each day the ratio exceeds the threshold query
+// parameter, by default 1.5.
+//
+// A basic privilege user can only view their own training load, admin and
+// staff privileged users can view the training load of any user.
+//
+// @Summary Get the training load of a user
+// @Description Get the time in heart rate zones and TRIMP or session RPE load of each session
+// @Description of a user, and the daily acute:chronic workload ratio over rolling 7 and 28 day
+// @Description windows with warnings when the ratio exceeds the threshold.
+// @Tags client.TrainingLoad
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param from query string false "Only report days starting at or after this RFC3339 time"
+// @Param to query string false "Only report days starting before this RFC3339 time"
+// @Param method query string false "The session load, trimp or rpe, defaults to trimp"
+// @Param threshold query number false "The acute:chronic workload ratio above which a warning is reported, defaults to 1.5"
+// @Param tz query string false "IANA time zone days start in, defaults to UTC"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.TrainingLoad
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the method, threshold or range is invalid or the heart rates of the user are not set"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/load [get]
+func (s *ServerService) GetTrainingLoad(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetTrainingLoad request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	query := db.LoadQuery{
+		UserID: ps.ByName("id"),
+		Method: r.URL.Query().Get("method"),
+	}
+	var err error
+	if query.From, err = parseTime(r, "from"); err != nil {
+		errorWithJSON(w, "from must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseTime(r, "to"); err != nil {
+		errorWithJSON(w, "to must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	if threshold := r.URL.Query().Get("threshold"); len(threshold) > 0 {
+		if query.Threshold, err = strconv.ParseFloat(threshold, 64); err != nil {
+			errorWithJSON(w, "threshold must be a number", http.StatusBadRequest)
+			return
+		}
+	}
+	if tz := r.URL.Query().Get("tz"); len(tz) > 0 {
+		if query.Location, err = time.LoadLocation(tz); err != nil {
+			errorWithJSON(w, "tz must be a IANA time zone name", http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	load, err := workoutService.TrainingLoad(ctx, query)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(load)
+}
@@ -0,0 +1,55 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrainingLoad(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	running := addExercise(t, server, "Running")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	workout := client.Workout{Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: running,
+		Cardio: &client.CardioMetrics{DurationSeconds: 1800, AvgHeartRate: 150}}}}
+	response := workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, workout, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// The TRIMP requires the heart rates of the user
+	response = workoutRequest(t, server.GetTrainingLoad, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = workoutRequest(t, server.UpdateHeartRates, http.MethodPut, cookie,
+		client.HeartRates{Max: 190, Resting: 50}, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.UpdateHeartRates, http.MethodPut, cookie,
+		client.HeartRates{Max: 190, Resting: 200}, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = workoutRequest(t, server.GetTrainingLoad, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var load client.TrainingLoad
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&load))
+	assert.Equal(t, "trimp", load.Method)
+	if assert.Equal(t, 1, len(load.Sessions)) {
+		assert.Equal(t, []int{0, 0, 1800, 0, 0}, load.Sessions[0].ZoneSeconds)
+		assert.True(t, load.Sessions[0].TRIMP > 0)
+	}
+	assert.Equal(t, 28, len(load.Days))
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), load.Days[len(load.Days)-1].Date)
+
+	// Basic users can only view their own training load
+	ps = httprouter.Params{{Key: "id", Value: testBasic2ID}}
+	response = workoutRequest(t, server.GetTrainingLoad, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
@@ -57,7 +57,7 @@ func (s *ServerService) Login(w http.ResponseWriter, r *http.Request, _ httprout
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
 
-	userService, err := db.NewUserService(s.Database)
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
 	if err != nil {
 		errorWithJSON(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
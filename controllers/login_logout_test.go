@@ -46,15 +46,15 @@ const testBasic2UserPassword string = testAdmin1UserPassword
 // via the clear flag the current user collection entires can be
 // dropped. Setting the load flag causes the predefined user collection
 // entires in TestUserFilename to be inserted into the user collection.
-func setup(t *testing.T, userLoadFile string) *controllers.ServerService {
+func setup(t *testing.T, userLoadFile string, opts ...controllers.ServerOption) *controllers.ServerService {
 	clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
 	opt := controllers.DBOptions(clientOptions)
 
 	// We need to have at least one admin user present in our database
 	// to proceed.
 
-	server, err := controllers.NewServerService(true, opt,
-		controllers.DBName(testDatabase))
+	server, err := controllers.NewServerService(true,
+		append([]controllers.ServerOption{opt, controllers.DBName(testDatabase)}, opts...)...)
 	assert.NoError(t, err)
 	err = server.DeleteAll()
 	assert.NoError(t, err)
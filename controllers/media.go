@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/enpointe/activity/blob"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// mediaCacheAge how long clients may cache the images of exercises, an image
+// is never changed once uploaded
+const mediaCacheAge = 7 * 24 * time.Hour
+
+// AddMedia add an image, animation or video link demonstrating an exercise.
+// The POST request should be a multipart form holding either a PNG, JPEG or
+// GIF image in the file field or the URL of a video in the url field, along
+// with an optional caption. A thumbnail of each image is generated and both
+// are stored in the blob store of the server.
+//
+// Only admin and staff privileged users can add media to exercises.
+//
+// @Summary Add an image, animation or video link to an exercise
+// @Description Upload a PNG, JPEG or GIF image, or link a video, demonstrating an exercise.
+// @Description GIFs of more than one frame are animations. A thumbnail is generated for each image.
+// @Tags client.Media
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param exercise query string true "ID of the exercise"
+// @Param file formData file false "The PNG, JPEG or GIF image"
+// @Param url formData string false "The http or https URL of a video, when no file is uploaded"
+// @Param caption formData string false "A caption describing the media"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  multipart/form-data
+// @Produce  json
+// @Success 201 {object} client.Media "Created"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the exercise does not exist"
+// @Failure 413 {object} APIError "Request Entity Too Large"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the image is not supported or the link invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /media [post]
+func (s *ServerService) AddMedia(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("AddMedia request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	exerciseID := r.URL.Query().Get("exercise")
+	if len(exerciseID) == 0 {
+		errorWithJSON(w, "exercise must be specified", http.StatusBadRequest)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, db.MaxMediaSize+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		errorWithJSON(w, fmt.Sprintf("unable to read upload, %s", err), http.StatusBadRequest)
+		return
+	}
+	var data []byte
+	file, _, err := r.FormFile("file")
+	if err == nil {
+		defer file.Close()
+		if data, err = ioutil.ReadAll(io.LimitReader(file, db.MaxMediaSize+1)); err != nil {
+			errorWithJSON(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(data) > db.MaxMediaSize {
+			errorWithJSON(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+	link := r.FormValue("url")
+	if data == nil && len(link) == 0 {
+		errorWithJSON(w, "either a file or url must be specified", http.StatusBadRequest)
+		return
+	}
+	caption := r.FormValue("caption")
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var media *client.Media
+	if data != nil {
+		media, err = exerciseService.AddMedia(ctx, s.blobs, exerciseID, data, caption)
+	} else {
+		media, err = exerciseService.AddVideoLink(ctx, exerciseID, link, caption)
+	}
+	if err != nil {
+		log.Debugf("%s:%s failed to add media to exercise %s, %s", claims.ID, claims.Username, exerciseID, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s added %s %s to exercise %s", claims.ID, claims.Username, media.Kind, media.ID, exerciseID)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(media)
+}
+
+// GetMedia return an image or animation of an exercise
+//
+// Any logged in user can view the media of the exercises visible to them.
+//
+// @Summary Get an image or animation of an exercise
+// @Description Get an uploaded image or animation of an exercise.
+// @Tags client.Media
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param media_id path string true "ID of the media"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  png
+// @Produce  jpeg
+// @Produce  gif
+// @Success 200 {string} string "The image or animation"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 404 {object} APIError "Not Found, if the media does not exist or is a video link"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /media/{media_id} [get]
+func (s *ServerService) GetMedia(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetMedia request")
+	s.serveMedia(w, r, ps, false)
+}
+
+// GetMediaThumbnail return the thumbnail of an image or animation of an exercise
+//
+// Any logged in user can view the media of the exercises visible to them.
+//
+// @Summary Get the thumbnail of an image or animation of an exercise
+// @Description Get the thumbnail, at most 256 pixels wide and high, of an image or animation of an exercise.
+// @Tags client.Media
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param media_id path string true "ID of the media"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  png
+// @Produce  jpeg
+// @Success 200 {string} string "The thumbnail"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 404 {object} APIError "Not Found, if the media does not exist or is a video link"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /media/{media_id}/thumbnail [get]
+func (s *ServerService) GetMediaThumbnail(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetMediaThumbnail request")
+	s.serveMedia(w, r, ps, true)
+}
+
+// serveMedia write the image, or its thumbnail, of the media from the blob store
+func (s *ServerService) serveMedia(w http.ResponseWriter, r *http.Request, ps httprouter.Params, thumbnail bool) {
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	media, err := exerciseService.FindMedia(ctx, claims.ID, ps.ByName("media"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	key, contentType := media.Key, media.ContentType
+	if thumbnail {
+		key, contentType = media.ThumbnailKey, media.ThumbnailType
+	}
+	if len(key) == 0 {
+		errorWithJSON(w, fmt.Sprintf("media '%s' is a link to %s", ps.ByName("media"), media.Link),
+			http.StatusNotFound)
+		return
+	}
+	data, err := s.blobs.Get(ctx, key)
+	if err == blob.ErrNotFound {
+		errorWithJSON(w, fmt.Sprintf("media '%s' not found", ps.ByName("media")), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer data.Close()
+	w.Header().Set("content-type", contentType)
+	w.Header().Set("cache-control", "private, max-age="+strconv.Itoa(int(mediaCacheAge.Seconds())))
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, data); err != nil {
+		log.Warnf("failed to write media %s, %s", ps.ByName("media"), err)
+	}
+}
+
+// DeleteMedia remove an image, animation or video link from its exercise.
+// Images and their thumbnails are removed from the blob store.
+//
+// Only admin and staff privileged users can remove the media of exercises.
+//
+// @Summary Remove an image, animation or video link from an exercise
+// @Description Remove the media from its exercise, deleting any image and thumbnail.
+// @Tags client.Media
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param media_id path string true "ID of the media"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the media does not exist"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /media/{media_id} [delete]
+func (s *ServerService) DeleteMedia(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeleteMedia request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = exerciseService.DeleteMedia(ctx, s.blobs, ps.ByName("media")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s deleted media %s", claims.ID, claims.Username, ps.ByName("media"))
+	w.WriteHeader(http.StatusNoContent)
+}
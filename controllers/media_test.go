@@ -0,0 +1,127 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/enpointe/activity/blob"
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// mediaRequest helper function that builds a multipart upload of an image, or
+// a video link if no image is given, for the exercise
+func mediaRequest(t *testing.T, exerciseID string, data []byte, link string, cookie *http.Cookie) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if data != nil {
+		part, err := writer.CreateFormFile("file", "image")
+		assert.NoError(t, err)
+		part.Write(data)
+	}
+	if len(link) > 0 {
+		writer.WriteField("url", link)
+	}
+	writer.WriteField("caption", "start position")
+	assert.NoError(t, writer.Close())
+	request := httptest.NewRequest(http.MethodPost, "http://media?exercise="+exerciseID, &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.AddCookie(cookie)
+	return request
+}
+
+func TestExerciseMedia(t *testing.T) {
+	dir, err := ioutil.TempDir("", "media")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	server := setup(t, testMultiUserFilenameJSON, controllers.MediaStore(&blob.FileStore{Dir: dir}))
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	staff := login(t, server, client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword})
+	defer logout(t, server, staff)
+	basic := login(t, server, client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword})
+	defer logout(t, server, basic)
+
+	img := image.NewRGBA(image.Rect(0, 0, 512, 300))
+	for x := 0; x < 512; x++ {
+		for y := 0; y < 300; y++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	var data bytes.Buffer
+	assert.NoError(t, png.Encode(&data, img))
+
+	testData := []struct {
+		exerciseID       string
+		data             []byte
+		link             string
+		cookie           *http.Cookie
+		expectedResponse int
+	}{
+		{squat, data.Bytes(), "", basic, http.StatusForbidden},
+		{squat, nil, "", staff, http.StatusBadRequest},
+		{squat, []byte("not an image"), "", staff, http.StatusUnprocessableEntity},
+		{squat, nil, "ftp://example.com/squat", staff, http.StatusUnprocessableEntity},
+		{testBasic1ID, data.Bytes(), "", staff, http.StatusNotFound},
+		{squat, nil, "https://example.com/squat", staff, http.StatusCreated},
+		{squat, data.Bytes(), "", staff, http.StatusCreated},
+	}
+	var media client.Media
+	for i, d := range testData {
+		response := httptest.NewRecorder()
+		server.AddMedia(response, mediaRequest(t, d.exerciseID, d.data, d.link, d.cookie), nil)
+		assert.Equal(t, d.expectedResponse, response.Code, "test %d", i)
+		if response.Code == http.StatusCreated {
+			assert.NoError(t, json.NewDecoder(response.Body).Decode(&media))
+		}
+	}
+	assert.Equal(t, "image", media.Kind)
+	assert.Equal(t, "/media/"+media.ID, media.URL)
+	assert.Equal(t, 512, media.Width)
+
+	// The URLs of the media are returned with the exercise
+	response := workoutRequest(t, server.GetExercises, http.MethodGet, basic, nil, nil)
+	var exercises []client.Exercise
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&exercises))
+	if assert.Equal(t, 1, len(exercises)) && assert.Equal(t, 2, len(exercises[0].Media)) {
+		assert.Equal(t, "https://example.com/squat", exercises[0].Media[0].URL)
+		assert.Empty(t, exercises[0].Media[0].ThumbnailURL)
+		assert.Equal(t, media, exercises[0].Media[1])
+	}
+
+	// The image and its thumbnail are served to any user
+	ps := httprouter.Params{{Key: "media", Value: media.ID}}
+	response = workoutRequest(t, server.GetMedia, http.MethodGet, basic, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "image/png", response.Header().Get("content-type"))
+	assert.Equal(t, data.Bytes(), response.Body.Bytes())
+	response = workoutRequest(t, server.GetMediaThumbnail, http.MethodGet, basic, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	thumbnail, err := png.Decode(response.Body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, image.Rect(0, 0, 256, 150), thumbnail.Bounds())
+	}
+
+	response = workoutRequest(t, server.DeleteMedia, http.MethodDelete, basic, nil, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = workoutRequest(t, server.DeleteMedia, http.MethodDelete, staff, nil, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.GetMedia, http.MethodGet, basic, nil, ps)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = workoutRequest(t, server.DeleteMedia, http.MethodDelete, staff, nil, ps)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	files, err := ioutil.ReadDir(dir + "/exercises/" + squat)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
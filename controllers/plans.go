@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/enpointe/activity/perm"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// authorizeStaff validate the claims of the request and ensure the user
+// making the request is a staff or admin privileged user
+func authorizeStaff(w http.ResponseWriter, r *http.Request) (*Claims, bool) {
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return nil, false
+	}
+	if !claims.Privilege.Grants(perm.Staff) {
+		errorWithJSON(w,
+			http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+	return claims, true
+}
+
+// CreatePlan author a multi-week training plan. The POST request should
+// contain a JSON payload with the fields of client.Plan. Each week of the
+// plan schedules sessions, on days 1 to 7 of the week, from the workout
+// templates of the author.
+//
+// Only admin and staff privileged users can author training plans.
+//
+// @Summary Author a training plan
+// @Description Author a multi-week training plan of sessions scheduled from the templates of the author.
+// @Tags client.Plan Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param Plan body client.Plan true "The training plan"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the plan fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /plans [post]
+func (s *ServerService) CreatePlan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("CreatePlan request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	var plan client.Plan
+	if err := json.NewDecoder(r.Body).Decode(&plan); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := planService.Create(ctx, claims.ID, &plan)
+	if err != nil {
+		log.Debugf("%s:%s failed to create plan, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s created plan %s", claims.ID, claims.Username, id)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// GetPlans return the training plans ordered by name. Only admin and staff
+// privileged users can retrieve training plans.
+//
+// @Summary Get the training plans
+// @Description Get all training plans ordered by name.
+// @Tags client.Plan
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.Plan
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /plans [get]
+func (s *ServerService) GetPlans(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetPlans request")
+	if _, ok := authorizeStaff(w, r); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plans, err := planService.GetAll(ctx)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plans)
+}
+
+// GetPlan return a single training plan. Only admin and staff privileged
+// users can retrieve training plans.
+//
+// @Summary Get a training plan
+// @Description Get a training plan along with the sessions scheduled each week.
+// @Tags client.Plan
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param plan_id path string true "ID of the plan"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.Plan
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /plans/{plan_id} [get]
+func (s *ServerService) GetPlan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetPlan request")
+	if _, ok := authorizeStaff(w, r); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	plan, err := planService.GetByID(ctx, ps.ByName("plan"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// DeletePlan delete a training plan. A plan assigned to users can not be
+// deleted until it has been unassigned. Only admin and staff privileged
+// users can delete training plans.
+//
+// @Summary Delete a training plan
+// @Description Delete a training plan that is not assigned to any user.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param plan_id path string true "ID of the plan"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the plan is assigned to users"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /plans/{plan_id} [delete]
+func (s *ServerService) DeletePlan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeletePlan request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = planService.Delete(ctx, ps.ByName("plan")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s deleted plan %s", claims.ID, claims.Username, ps.ByName("plan"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AssignPlan assign a training plan to a basic privilege user. The POST
+// request should contain a JSON payload with the planId, the start date,
+// YYYY-MM-DD, and optionally the timezone of the assignment.
+//
+// Only admin and staff privileged users can assign training plans.
+//
+// @Summary Assign a training plan to a user
+// @Description Assign a training plan to a basic user starting on a date.
+// @Tags client.PlanAssignment Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param PlanAssignment body client.PlanAssignment true "The plan and start date"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the user does not exist"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the assignment fails validation or the user is not a basic user"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/plans [post]
+func (s *ServerService) AssignPlan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("AssignPlan request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	var assignment client.PlanAssignment
+	if err := json.NewDecoder(r.Body).Decode(&assignment); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	userInfo, err := userService.GetByID(ctx, ps.ByName("id"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if perm.Convert(userInfo.Privilege) != perm.Basic {
+		errorWithJSON(w, "training plans can only be assigned to basic users", http.StatusUnprocessableEntity)
+		return
+	}
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := planService.Assign(ctx, ps.ByName("id"), claims.ID, &assignment)
+	if err != nil {
+		log.Debugf("%s:%s failed to assign plan, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s assigned plan %s to user %s", claims.ID, claims.Username, assignment.PlanID, ps.ByName("id"))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// GetAssignments return the training plans assigned to a user, the most
+// recently started first, along with the adherence of the user to each plan.
+// Adherence is computed by matching the sessions logged to those scheduled.
+//
+// @Summary Get the training plans assigned to a user
+// @Description Get the training plans assigned to a user along with the percentage of scheduled sessions completed.
+// @Tags client.PlanAssignment
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.PlanAssignment
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/plans [get]
+func (s *ServerService) GetAssignments(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetAssignments request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	assignments, err := planService.GetAssignments(ctx, ps.ByName("id"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(assignments)
+}
+
+// UnassignPlan remove the assignment of a training plan to a user. Sessions
+// already logged are not changed. Only admin and staff privileged users can
+// unassign training plans.
+//
+// @Summary Unassign a training plan
+// @Description Remove the assignment of a training plan to a user.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param assignment_id path string true "ID of the assignment"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/plans/{assignment_id} [delete]
+func (s *ServerService) UnassignPlan(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UnassignPlan request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = planService.Unassign(ctx, ps.ByName("id"), ps.ByName("assignment")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s removed assignment %s of user %s",
+		claims.ID, claims.Username, ps.ByName("assignment"), ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSchedule return the workout sessions the training plans assigned to a
+// user schedule for a day, along with whether they have been completed. The
+// optional date query parameter, YYYY-MM-DD, selects the day, by default
+// today in the time zone of each assignment.
+//
+// @Summary Get the scheduled workouts of a user
+// @Description Get the workout sessions scheduled for the user today, or on a date, by their training plans.
+// @Tags client.ScheduledWorkout
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param date query string false "The date, YYYY-MM-DD, by default today"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.ScheduledWorkout
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the date is malformed"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/schedule [get]
+func (s *ServerService) GetSchedule(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetSchedule request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	schedule, err := planService.Schedule(ctx, ps.ByName("id"), r.URL.Query().Get("date"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
@@ -0,0 +1,89 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlans(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	staffPs := httprouter.Params{{Key: "id", Value: testStaff1ID}}
+	template := client.WorkoutTemplate{Name: "Leg day",
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat}}}
+	response := workoutRequest(t, server.CreateTemplate, http.MethodPost, cookie, template, staffPs)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var templateID controllers.Identity
+	json.NewDecoder(response.Body).Decode(&templateID)
+
+	plan := client.Plan{Name: "Strength", Weeks: []client.PlanWeek{client.PlanWeek{
+		Sessions: []client.PlanSession{client.PlanSession{Day: 1, TemplateID: templateID.ID}}}}}
+	response = workoutRequest(t, server.CreatePlan, http.MethodPost, cookie, plan, nil)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var planID controllers.Identity
+	json.NewDecoder(response.Body).Decode(&planID)
+
+	// Plans can only be assigned to basic users
+	today := time.Now().UTC().Format("2006-01-02")
+	assignment := client.PlanAssignment{PlanID: planID.ID, Start: today}
+	response = workoutRequest(t, server.AssignPlan, http.MethodPost, cookie, assignment, staffPs)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	response = workoutRequest(t, server.AssignPlan, http.MethodPost, cookie, assignment, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var assignmentID controllers.Identity
+	json.NewDecoder(response.Body).Decode(&assignmentID)
+
+	response = workoutRequest(t, server.DeletePlan, http.MethodDelete, cookie, nil,
+		httprouter.Params{{Key: "plan", Value: planID.ID}})
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	// The basic user sees the session scheduled today and their adherence
+	basic := login(t, server, client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword})
+	defer logout(t, server, basic)
+	var schedule []client.ScheduledWorkout
+	response = workoutRequest(t, server.GetSchedule, http.MethodGet, basic, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&schedule))
+	if assert.Equal(t, 1, len(schedule)) {
+		assert.Equal(t, "Leg day", schedule[0].Template.Name)
+		assert.False(t, schedule[0].Completed)
+	}
+
+	workout := client.Workout{TemplateID: templateID.ID, Exercises: template.Exercises}
+	response = workoutRequest(t, server.CreateWorkout, http.MethodPost, basic, workout, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	var assignments []client.PlanAssignment
+	response = workoutRequest(t, server.GetAssignments, http.MethodGet, basic, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&assignments))
+	if assert.Equal(t, 1, len(assignments)) {
+		assert.Equal(t, 100.0, assignments[0].Adherence.Percent)
+	}
+
+	// Basic users can not author or assign plans
+	response = workoutRequest(t, server.CreatePlan, http.MethodPost, basic, plan, nil)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = workoutRequest(t, server.AssignPlan, http.MethodPost, basic, assignment, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	assignmentPs := append(ps, httprouter.Param{Key: "assignment", Value: assignmentID.ID})
+	response = workoutRequest(t, server.UnassignPlan, http.MethodDelete, cookie, nil, assignmentPs)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.DeletePlan, http.MethodDelete, cookie, nil,
+		httprouter.Params{{Key: "plan", Value: planID.ID}})
+	assert.Equal(t, http.StatusNoContent, response.Code)
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetRecords return the current personal records of a user. The optional
+// exercise query parameter restricts the records to a single exercise.
+//
+// A basic privilege user can only view their own records, admin and
+// staff privileged users can view the records of any user.
+//
+// @Summary Get the personal records of a user
+// @Description Get the current personal records of a user, the estimated one rep max, the
+// @Description heaviest load for each number of reps, the fastest 5k and 10k and the longest duration.
+// @Tags client.PersonalRecord
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param exercise query string false "Only return the records of this exercise"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.PersonalRecord
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/records [get]
+func (s *ServerService) GetRecords(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetRecords request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	records, err := workoutService.GetRecords(ctx, ps.ByName("id"), r.URL.Query().Get("exercise"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(records)
+}
+
+// GetRecordHistory return every personal record set by a user, in the order
+// they were achieved. The optional exercise and kind query parameters restrict
+// the records to a single exercise and kind of record.
+//
+// @Summary Get the personal record history of a user
+// @Description Get every personal record set by a user, oldest first, including the record each beat.
+// @Tags client.PersonalRecord
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param exercise query string false "Only return the records of this exercise"
+// @Param kind query string false "Only return records of this kind, ie max-load"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.PersonalRecord
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/records/history [get]
+func (s *ServerService) GetRecordHistory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetRecordHistory request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	query := r.URL.Query()
+	records, err := workoutService.GetRecordHistory(ctx, ps.ByName("id"),
+		query.Get("exercise"), query.Get("kind"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(records)
+}
@@ -0,0 +1,53 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRecords(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	for _, load := range []float64{100, 110} {
+		workout := client.Workout{
+			Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat,
+				Sets: []client.StrengthSet{client.StrengthSet{Reps: 1, Load: load, Unit: "kg"}}}},
+		}
+		response := workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, workout, ps)
+		assert.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	var records []client.PersonalRecord
+	response := workoutRequest(t, server.GetRecords, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&records))
+	if assert.Equal(t, 3, len(records)) {
+		assert.Equal(t, 110.0, records[0].Value)
+		assert.Equal(t, "kg", records[0].Unit)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "http://records/history?kind=max-load&exercise="+squat, nil)
+	request.AddCookie(cookie)
+	recorder := httptest.NewRecorder()
+	server.GetRecordHistory(recorder, request, ps)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&records))
+	assert.Equal(t, 2, len(records))
+
+	// A basic user can not view the records of another user
+	response = workoutRequest(t, server.GetRecordHistory, http.MethodGet, cookie, nil,
+		httprouter.Params{{Key: "id", Value: testBasic2ID}})
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
@@ -3,12 +3,15 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/enpointe/activity/blob"
 	"github.com/enpointe/activity/models/client"
 	"github.com/enpointe/activity/models/db"
 	"github.com/enpointe/activity/perm"
 	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -16,12 +19,18 @@ import (
 // DefaultDatabase the database name to use as the default
 const DefaultDatabase string = "activity"
 
+// DefaultMediaDir the directory the media of exercises are stored in when no
+// blob store has been configured
+const DefaultMediaDir string = "uploads"
+
 // ServerService some comment
 type ServerService struct {
 	dbName      string
+	prefix      string
 	adminPasswd []byte
 	dbClOpts    *options.ClientOptions
 	client      *mongo.Client
+	blobs       blob.Store
 	Database    *mongo.Database
 }
 
@@ -42,6 +51,23 @@ func DBName(name string) ServerOption {
 	}
 }
 
+// CollectionPrefix specifies a prefix to prepend to the name of each
+// collection used by the server. This allows multiple environments,
+// ie test and production, to share a single database.
+func CollectionPrefix(prefix string) ServerOption {
+	return func(s *ServerService) {
+		s.prefix = prefix
+	}
+}
+
+// MediaStore specifies the blob store holding the images and animations of
+// exercises, by default they are stored in the DefaultMediaDir directory
+func MediaStore(store blob.Store) ServerOption {
+	return func(s *ServerService) {
+		s.blobs = store
+	}
+}
+
 // CreateAdminUser create the user "admin" and assign it the specified password.
 // If the admin user already exists the password will be updated to the
 // specified password
@@ -63,6 +89,9 @@ func NewServerService(skipAdminCheck bool, opts ...ServerOption) (*ServerService
 	if server.dbClOpts == nil {
 		server.dbClOpts = options.Client().ApplyURI("mongodb://localhost:27017")
 	}
+	if server.blobs == nil {
+		server.blobs = &blob.FileStore{Dir: DefaultMediaDir}
+	}
 
 	mClient, err := mongo.NewClient(server.dbClOpts)
 	if err != nil {
@@ -79,6 +108,14 @@ func NewServerService(skipAdminCheck bool, opts ...ServerOption) (*ServerService
 	}
 	server.Database = mClient.Database(server.dbName)
 
+	// Ensure the indexes that enforce uniqueness and support the
+	// common queries of each collection are in place
+	err = db.EnsureIndexes(ctx, server.Database, server.serviceOptions()...)
+	if err != nil {
+		err = fmt.Errorf("server startup error, %s", err)
+		return nil, err
+	}
+
 	if skipAdminCheck {
 		return server, nil
 	}
@@ -87,7 +124,7 @@ func NewServerService(skipAdminCheck bool, opts ...ServerOption) (*ServerService
 	// level user is required. Check to ensure that the admin
 	// privilege user exists. If no admin privileged user exists
 	// then abort startup
-	userService, err := db.NewUserService(server.Database)
+	userService, err := db.NewUserService(server.Database, server.serviceOptions()...)
 	if err != nil {
 		err = fmt.Errorf("server startup error, failure connecting to database: %s", err)
 		return nil, err
@@ -126,9 +163,37 @@ func NewServerService(skipAdminCheck bool, opts ...ServerOption) (*ServerService
 	return server, nil
 }
 
-// DeleteAll delete all collections in the database
+// Prefix returns the prefix prepended to the name of each collection used by the server
+func (s *ServerService) Prefix() string {
+	return s.prefix
+}
+
+// serviceOptions the options used when creating the database services
+func (s *ServerService) serviceOptions() []db.ServiceOption {
+	return []db.ServiceOption{db.CollectionPrefix(s.prefix)}
+}
+
+// DeleteAll delete all collections in the database. If a collection
+// prefix has been configured only the collections with that prefix
+// are deleted.
 func (s *ServerService) DeleteAll() error {
-	return s.Database.Drop(context.TODO())
+	ctx := context.TODO()
+	if len(s.prefix) == 0 {
+		return s.Database.Drop(ctx)
+	}
+	names, err := s.Database.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, s.prefix) {
+			continue
+		}
+		if err := s.Database.Collection(name).Drop(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Shutdown performs any clean up activites related to the running the service.
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetUserStats return the training statistics and activity streaks of a user.
+// The optional exercise query parameter restricts the statistics to the
+// entries of an exercise. The optional from and to query parameters, RFC3339 times, set the range of
+// sessions included, by default the last 90 days. The period query parameter,
+// day, week or month, selects how the sessions are grouped over time and tz, an
+// IANA time zone name such as America/Los_Angeles, the time zone in which
+// periods start. Streaks cover all sessions of the user and are computed using
+// the time zone and rest allowances set via UpdateStreakSettings.
+//
+// A basic privilege user can only view their own statistics, admin and
+// staff privileged users can view the statistics of any user.
+//
+// @Summary Get the training statistics of a user
+// @Description Get the total and average sessions, duration, distance and volume lifted
+// @Description of a user, overall, per exercise and per day, week or month, and their
+// @Description current and longest daily and weekly activity streaks.
+// @Tags client.Stats
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param exercise query string false "Only include the entries of this exercise"
+// @Param from query string false "Only include sessions started at or after this RFC3339 time"
+// @Param to query string false "Only include sessions started before this RFC3339 time"
+// @Param period query string false "Group sessions by day, week or month, defaults to week"
+// @Param tz query string false "IANA time zone periods start in, defaults to UTC"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.Stats
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the period or range is invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/stats [get]
+func (s *ServerService) GetUserStats(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetUserStats request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	query := db.StatsQuery{
+		UserID:     ps.ByName("id"),
+		ExerciseID: r.URL.Query().Get("exercise"),
+		Period:     r.URL.Query().Get("period"),
+	}
+	var err error
+	if query.From, err = parseTime(r, "from"); err != nil {
+		errorWithJSON(w, "from must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	if query.To, err = parseTime(r, "to"); err != nil {
+		errorWithJSON(w, "to must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	if tz := r.URL.Query().Get("tz"); len(tz) > 0 {
+		if query.Location, err = time.LoadLocation(tz); err != nil {
+			errorWithJSON(w, "tz must be a IANA time zone name", http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	stats, err := workoutService.Stats(ctx, query)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// UpdateStreakSettings change how the activity streaks of a user, reported by
+// GetUserStats, are computed. The PUT request should contain a JSON payload with
+// the fields of client.StreakSettings.
+//
+// A basic privilege user can only change their own settings, admin and
+// staff privileged users can change the settings of any user.
+//
+// @Summary Change the streak settings of a user
+// @Description Set the time zone days start in and the number of rest days, or weeks,
+// @Description allowed without breaking a daily, or weekly, streak.
+// @Tags client.StreakSettings
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param StreakSettings body client.StreakSettings true "The streak settings"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the time zone or rest allowances are invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/streaks [put]
+func (s *ServerService) UpdateStreakSettings(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UpdateStreakSettings request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var settings client.StreakSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = workoutService.SetStreakSettings(ctx, ps.ByName("id"), &settings); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s updated the streak settings of user %s", claims.ID, claims.Username, ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserStats(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	workout := client.Workout{
+		Start: time.Now().UTC().Add(-time.Hour),
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat,
+			Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 100, Unit: "kg"}}}},
+	}
+	response := workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, workout, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	response = workoutRequest(t, server.GetUserStats, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var stats client.Stats
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&stats))
+	assert.Equal(t, 1, stats.Totals.Sessions)
+	assert.Equal(t, 500.0, stats.Totals.VolumeKg)
+	assert.Equal(t, 1, len(stats.Exercises))
+	if assert.NotNil(t, stats.Streaks) {
+		assert.Equal(t, 1, stats.Streaks.Daily.Current)
+		assert.Equal(t, "UTC", stats.Streaks.Timezone)
+	}
+
+	response = workoutRequest(t, server.UpdateStreakSettings, http.MethodPut, cookie,
+		client.StreakSettings{Timezone: "America/New_York", RestDays: 1}, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.UpdateStreakSettings, http.MethodPut, cookie,
+		client.StreakSettings{RestDays: 30}, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = workoutRequest(t, server.GetUserStats, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&stats))
+	assert.Equal(t, 1, stats.Streaks.RestDays)
+
+	// A basic user can not view the statistics of another user
+	response = workoutRequest(t, server.GetUserStats, http.MethodGet, cookie, nil,
+		httprouter.Params{{Key: "id", Value: testBasic2ID}})
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	testData := []struct {
+		query            string
+		expectedResponse int
+	}{
+		{"period=month&tz=America/New_York", http.StatusOK},
+		{"period=year", http.StatusUnprocessableEntity},
+		{"tz=Nowhere/Special", http.StatusBadRequest},
+		{"from=yesterday", http.StatusBadRequest},
+	}
+	for _, d := range testData {
+		t.Run(d.query, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "http://stats?"+d.query, nil)
+			request.AddCookie(cookie)
+			response := httptest.NewRecorder()
+			server.GetUserStats(response, request, ps)
+			assert.Equal(t, d.expectedResponse, response.Code)
+		})
+	}
+}
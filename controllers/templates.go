@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateTemplate define a named workout template for a user. The POST request
+// should contain a JSON payload with the fields of client.WorkoutTemplate. The
+// sets and cardio metrics of each exercise are the targets of the routine.
+//
+// A basic privilege user can only define templates for themselves, admin and
+// staff privileged users can define templates for any user.
+//
+// @Summary Define a workout template
+// @Description Define a named routine of exercises, with target sets, reps and duration, for a user.
+// @Tags client.WorkoutTemplate Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param WorkoutTemplate body client.WorkoutTemplate true "The workout template"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 409 {object} APIError "Conflict, if the user already has a template with the name"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the template fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/templates [post]
+func (s *ServerService) CreateTemplate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("CreateTemplate request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var template client.WorkoutTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	s.createInTemplates(w, claims, "created template", func(ctx context.Context, ts *db.TemplateService) (string, error) {
+		return ts.Create(ctx, ps.ByName("id"), &template)
+	})
+}
+
+// GetTemplates return the workout templates of a user ordered by name
+//
+// @Summary Get the workout templates of a user
+// @Description Get the workout templates of a user ordered by name.
+// @Tags client.WorkoutTemplate
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.WorkoutTemplate
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/templates [get]
+func (s *ServerService) GetTemplates(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetTemplates request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	templateService, err := db.NewTemplateService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	templates, err := templateService.GetAll(ctx, ps.ByName("id"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(templates)
+}
+
+// GetTemplate return a single workout template of a user
+//
+// @Summary Get a workout template
+// @Description Get a workout template of a user, including the target sets of each exercise.
+// @Tags client.WorkoutTemplate
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param template_id path string true "ID of the template"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.WorkoutTemplate
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/templates/{template_id} [get]
+func (s *ServerService) GetTemplate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetTemplate request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	templateService, err := db.NewTemplateService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	template, err := templateService.GetByID(ctx, ps.ByName("id"), ps.ByName("template"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(template)
+}
+
+// UpdateTemplate replace the name, notes and exercises of a workout template.
+// Sessions already logged from the template are not changed.
+//
+// @Summary Update a workout template
+// @Description Replace the name, notes and exercises of a workout template of a user.
+// @Tags client.WorkoutTemplate
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param template_id path string true "ID of the template"
+// @Param WorkoutTemplate body client.WorkoutTemplate true "The workout template"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the user already has a template with the name"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the template fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/templates/{template_id} [put]
+func (s *ServerService) UpdateTemplate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UpdateTemplate request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var template client.WorkoutTemplate
+	if err := json.NewDecoder(r.Body).Decode(&template); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	templateService, err := db.NewTemplateService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = templateService.Update(ctx, ps.ByName("id"), ps.ByName("template"), &template); err != nil {
+		log.Debugf("%s:%s failed to update template, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s updated template %s of user %s",
+		claims.ID, claims.Username, ps.ByName("template"), ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteTemplate delete a workout template of a user. Sessions already
+// logged from the template are not deleted.
+//
+// @Summary Delete a workout template
+// @Description Delete a workout template of a user.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param template_id path string true "ID of the template"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/templates/{template_id} [delete]
+func (s *ServerService) DeleteTemplate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeleteTemplate request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	templateService, err := db.NewTemplateService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = templateService.Delete(ctx, ps.ByName("id"), ps.ByName("template")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s deleted template %s of user %s",
+		claims.ID, claims.Username, ps.ByName("template"), ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// InstantiateTemplate log a workout session for a user from one of their
+// templates. The exercises of the session are pre-populated with the targets
+// of the template, the actuals can then be recorded by updating the sets and
+// cardio metrics of the session. The optional start query parameter, a RFC3339
+// time, sets the start of the session, by default the current time.
+//
+// @Summary Log a workout session from a template
+// @Description Log a workout session pre-populated with the exercises and targets of a template.
+// @Tags Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param template_id path string true "ID of the template"
+// @Param start query string false "The RFC3339 start time of the session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 422 {object} APIError "Unprocessable Entity, if an exercise of the template no longer exists"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/templates/{template_id}/workouts [post]
+func (s *ServerService) InstantiateTemplate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("InstantiateTemplate request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	start, err := parseTime(r, "start")
+	if err != nil {
+		errorWithJSON(w, "start must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	s.createInTemplates(w, claims, "logged workout from template", func(ctx context.Context, ts *db.TemplateService) (string, error) {
+		return ts.Instantiate(ctx, ps.ByName("id"), ps.ByName("template"), start)
+	})
+}
+
+// createInTemplates perform a operation of the template service that creates
+// a new entry and report the id of the entry to the client
+func (s *ServerService) createInTemplates(w http.ResponseWriter, claims *Claims, action string,
+	op func(context.Context, *db.TemplateService) (string, error)) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	templateService, err := db.NewTemplateService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := op(ctx, templateService)
+	if err != nil {
+		log.Debugf("%s:%s failed to %s, %s", claims.ID, claims.Username, action, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s %s %s", claims.ID, claims.Username, action, id)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
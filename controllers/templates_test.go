@@ -0,0 +1,89 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	// Staff define templates for their members
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	template := client.WorkoutTemplate{Name: "Leg day", Exercises: []client.WorkoutExercise{
+		client.WorkoutExercise{ExerciseID: squat, Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 100}}}}}
+	response := workoutRequest(t, server.CreateTemplate, http.MethodPost, cookie, template, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var identity controllers.Identity
+	json.NewDecoder(response.Body).Decode(&identity)
+
+	response = workoutRequest(t, server.CreateTemplate, http.MethodPost, cookie, template, ps)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	templatePs := append(ps, httprouter.Param{Key: "template", Value: identity.ID})
+	response = workoutRequest(t, server.InstantiateTemplate, http.MethodPost, cookie, nil, templatePs)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var workoutID controllers.Identity
+	json.NewDecoder(response.Body).Decode(&workoutID)
+
+	workoutPs := append(ps, httprouter.Param{Key: "workout", Value: workoutID.ID})
+	response = workoutRequest(t, server.GetWorkout, http.MethodGet, cookie, nil, workoutPs)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var workout client.Workout
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&workout))
+	if assert.Equal(t, 1, len(workout.Exercises)) && assert.Equal(t, 1, len(workout.Exercises[0].Sets)) {
+		assert.Equal(t, 100.0, workout.Exercises[0].Sets[0].Load)
+	}
+
+	template.Name = "Squats"
+	response = workoutRequest(t, server.UpdateTemplate, http.MethodPut, cookie, template, templatePs)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	var templates []client.WorkoutTemplate
+	response = workoutRequest(t, server.GetTemplates, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&templates))
+	if assert.Equal(t, 1, len(templates)) {
+		assert.Equal(t, "Squats", templates[0].Name)
+	}
+
+	template.Exercises = nil
+	response = workoutRequest(t, server.CreateTemplate, http.MethodPost, cookie, template, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = workoutRequest(t, server.DeleteTemplate, http.MethodDelete, cookie, nil, templatePs)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.GetTemplate, http.MethodGet, cookie, nil, templatePs)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestTemplatePrivileges(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	template := client.WorkoutTemplate{Name: "Leg day",
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat}}}
+	response := workoutRequest(t, server.CreateTemplate, http.MethodPost, cookie, template,
+		httprouter.Params{{Key: "id", Value: testBasic1ID}})
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	// A basic user can not define templates for another user
+	response = workoutRequest(t, server.CreateTemplate, http.MethodPost, cookie, template,
+		httprouter.Params{{Key: "id", Value: testBasic2ID}})
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
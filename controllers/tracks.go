@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// UploadTrack create a workout session from a GPX, TCX or FIT file recorded by a
+// watch or bike computer. The POST request should be a multipart form holding
+// the file in the file field and, optionally, the ID of the exercise performed
+// in the exerciseId field. If no exercise is specified the exercise named after
+// the sport recorded in the file is used. The distance, moving time, elevation
+// gain and heart rate of a GPX or TCX track are computed from its trackpoints,
+// a FIT file provides the totals, laps, power and cadence of each session.
+//
+// A basic privilege user can only upload tracks for themselves, admin and
+// staff privileged users can upload tracks for any user.
+//
+// @Summary Create a workout session from a GPX, TCX or FIT file
+// @Description Create a workout session from a GPX, TCX or FIT file. The distance, moving time,
+// @Description elevation gain and heart rate are computed from the trackpoints of GPX and TCX
+// @Description files, FIT files also provide laps, power and cadence. The raw file is stored
+// @Description with the session.
+// @Tags Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param file formData file true "The GPX, TCX or FIT file"
+// @Param exerciseId formData string false "ID of the exercise performed, defaults to the exercise named after the sport"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  multipart/form-data
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 413 {object} APIError "Request Entity Too Large"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the file can not be read or the exercise does not exist"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/tracks [post]
+func (s *ServerService) UploadTrack(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UploadTrack request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, db.MaxTrackSize+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		errorWithJSON(w, fmt.Sprintf("unable to read upload, %s", err), http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		errorWithJSON(w, "no track file specified", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(file, db.MaxTrackSize+1))
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(data) > db.MaxTrackSize {
+		errorWithJSON(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	exerciseID := r.FormValue("exerciseId")
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Some clients send the full path of the file, only the name is kept
+	filename := filepath.Base(filepath.ToSlash(header.Filename))
+	id, err := workoutService.CreateFromTrack(ctx, ps.ByName("id"), exerciseID, filename, data)
+	if err != nil {
+		log.Debugf("%s:%s failed to upload track %s, %s", claims.ID, claims.Username, filename, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s created workout %s from track %s",
+		claims.ID, claims.Username, id, filename)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// GetWorkoutTrack return the raw GPX, TCX or FIT file a workout session was created from
+//
+// @Summary Get the track a workout session was created from
+// @Description Download the raw GPX, TCX or FIT file a workout session was created from.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  application/gpx+xml
+// @Produce  application/vnd.garmin.tcx+xml
+// @Produce  application/vnd.ant.fit
+// @Success 200 {string} string "The track file"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the session does not exist or was not created from a track"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/track [get]
+func (s *ServerService) GetWorkoutTrack(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetWorkoutTrack request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	raw, err := workoutService.GetTrack(ctx, ps.ByName("id"), ps.ByName("workout"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", raw.Format.ContentType())
+	w.Header().Set("content-disposition", fmt.Sprintf("attachment; filename=%q", raw.Filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(raw.Data)
+}
@@ -0,0 +1,146 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+// uploadRequest helper function that builds a multipart upload of the track file
+func uploadRequest(t *testing.T, filename string, data []byte, exerciseID string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if len(filename) > 0 {
+		part, err := writer.CreateFormFile("file", filename)
+		assert.NoError(t, err)
+		part.Write(data)
+	}
+	writer.WriteField("exerciseId", exerciseID)
+	assert.NoError(t, writer.Close())
+	request := httptest.NewRequest(http.MethodPost, "http://tracks", &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestUploadTrack(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	running := addExercise(t, server, "running")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+	gpx, err := ioutil.ReadFile("../track/testdata/run.gpx")
+	assert.NoError(t, err)
+
+	testData := []struct {
+		userID           string
+		filename         string
+		data             []byte
+		exerciseID       string
+		expectedResponse int
+	}{
+		{testBasic1ID, "run.gpx", gpx, running, http.StatusCreated},
+		{testBasic2ID, "run.gpx", gpx, running, http.StatusForbidden},
+		{testBasic1ID, "", nil, running, http.StatusBadRequest},
+		{testBasic1ID, "run.gpx", gpx, "", http.StatusCreated},
+		{testBasic1ID, "run.gpx", gpx, testBasic1ID, http.StatusUnprocessableEntity},
+		{testBasic1ID, "run.gpx", []byte("<gpx>"), running, http.StatusUnprocessableEntity},
+		{testBasic1ID, "run.csv", []byte("time,lat,lon"), running, http.StatusUnprocessableEntity},
+	}
+	for _, d := range testData {
+		request := uploadRequest(t, d.filename, d.data, d.exerciseID)
+		request.AddCookie(cookie)
+		response := httptest.NewRecorder()
+		ps := httprouter.Params{{Key: "id", Value: d.userID}}
+		server.UploadTrack(response, request, ps)
+		if !assert.Equalf(t, d.expectedResponse, response.Code, "upload %s for %s", d.filename, d.userID) ||
+			response.Code != http.StatusCreated {
+			continue
+		}
+
+		// The session holds the metrics computed from the track
+		var identity controllers.Identity
+		json.NewDecoder(response.Body).Decode(&identity)
+		ps = append(ps, httprouter.Param{Key: "workout", Value: identity.ID})
+		response = workoutRequest(t, server.GetWorkout, http.MethodGet, cookie, nil, ps)
+		assert.Equal(t, http.StatusOK, response.Code)
+		var workout client.Workout
+		json.NewDecoder(response.Body).Decode(&workout)
+		assert.Equal(t, "Morning Run", workout.Notes)
+		assert.NotEmpty(t, workout.TrackID)
+		if assert.Equal(t, 1, len(workout.Exercises)) && assert.NotNil(t, workout.Exercises[0].Cardio) {
+			cardio := workout.Exercises[0].Cardio
+			assert.Equal(t, running, workout.Exercises[0].ExerciseID)
+			assert.Equal(t, 0.445, cardio.Distance)
+			assert.Equal(t, 120, cardio.DurationSeconds)
+			assert.Equal(t, 7.0, cardio.ElevationGain)
+			assert.Equal(t, 142, cardio.AvgHeartRate)
+			assert.Equal(t, 160, cardio.MaxHeartRate)
+		}
+
+		// The raw track can be retrieved
+		response = workoutRequest(t, server.GetWorkoutTrack, http.MethodGet, cookie, nil, ps)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/gpx+xml", response.Header().Get("content-type"))
+		assert.Equal(t, gpx, response.Body.Bytes())
+	}
+}
+
+func TestUploadFIT(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	running := addExercise(t, server, "Running")
+	creds := client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+
+	// The exercise is found via the sport recorded in the file
+	data, err := ioutil.ReadFile("../fit/testdata/run.fit")
+	assert.NoError(t, err)
+	request := uploadRequest(t, "run.fit", data, "")
+	request.AddCookie(cookie)
+	response := httptest.NewRecorder()
+	server.UploadTrack(response, request, ps)
+	if !assert.Equal(t, http.StatusCreated, response.Code) {
+		return
+	}
+	var identity controllers.Identity
+	json.NewDecoder(response.Body).Decode(&identity)
+	ps = append(ps, httprouter.Param{Key: "workout", Value: identity.ID})
+	response = workoutRequest(t, server.GetWorkout, http.MethodGet, cookie, nil, ps)
+	var workout client.Workout
+	json.NewDecoder(response.Body).Decode(&workout)
+	if assert.Equal(t, 1, len(workout.Exercises)) && assert.NotNil(t, workout.Exercises[0].Cardio) {
+		cardio := workout.Exercises[0].Cardio
+		assert.Equal(t, running, workout.Exercises[0].ExerciseID)
+		assert.Equal(t, 1.8, cardio.Distance)
+		assert.Equal(t, 600, cardio.DurationSeconds)
+		assert.Equal(t, 240, cardio.AvgPower)
+		assert.Equal(t, 81, cardio.AvgCadence)
+		if assert.Equal(t, 2, len(cardio.Laps)) {
+			assert.Equal(t, 0.9, cardio.Laps[1].Distance)
+			assert.Equal(t, 150, cardio.Laps[1].AvgHeartRate)
+		}
+	}
+	response = workoutRequest(t, server.GetWorkoutTrack, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, "application/vnd.ant.fit", response.Header().Get("content-type"))
+
+	// No exercise matches cycling, the first sport of the multisport activity
+	data, err = ioutil.ReadFile("../fit/testdata/brick.fit")
+	assert.NoError(t, err)
+	request = uploadRequest(t, "brick.fit", data, "")
+	request.AddCookie(cookie)
+	response = httptest.NewRecorder()
+	server.UploadTrack(response, request, ps[:1])
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetTranslations return the translations of the name and description of an
+// exercise, by locale
+//
+// Only admin and staff privileged users can view the translations of exercises.
+//
+// @Summary Get the translations of an exercise
+// @Description Get the name and description of an exercise in each translated locale.
+// @Tags client.Translation
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param exercise_id path string true "ID of the exercise"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.Translations
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the exercise does not exist"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /translations/{exercise_id} [get]
+func (s *ServerService) GetTranslations(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetTranslations request")
+	if _, ok := authorizeStaff(w, r); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	translations, err := exerciseService.GetTranslations(ctx, ps.ByName("exercise"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(translations)
+}
+
+// SetTranslation add, or replace, the translation of the name and description
+// of an exercise into a locale. The PUT request should contain a JSON payload
+// with the fields of client.Translation. The locale is a BCP 47 language tag,
+// such as de or en-GB, other than the default locale.
+//
+// Only admin and staff privileged users can translate exercises.
+//
+// @Summary Translate an exercise
+// @Description Set the name and description of an exercise in a locale.
+// @Tags client.Translation
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param exercise_id path string true "ID of the exercise"
+// @Param locale path string true "The locale, a BCP 47 language tag"
+// @Param Translation body client.Translation true "The name and description in the locale"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} client.Translation
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the exercise does not exist"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the locale or translation is invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /translations/{exercise_id}/{locale} [put]
+func (s *ServerService) SetTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("SetTranslation request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	var translation client.Translation
+	if err := json.NewDecoder(r.Body).Decode(&translation); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result, err := exerciseService.SetTranslation(ctx, ps.ByName("exercise"), ps.ByName("locale"), &translation)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s translated exercise %s into %s", claims.ID, claims.Username, ps.ByName("exercise"),
+		ps.ByName("locale"))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// DeleteTranslation remove the translation of an exercise into a locale, the
+// exercise then falling back to the default locale for users of the locale
+//
+// Only admin and staff privileged users can remove translations.
+//
+// @Summary Remove the translation of an exercise
+// @Description Remove the name and description of an exercise in a locale.
+// @Tags client.Translation
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param exercise_id path string true "ID of the exercise"
+// @Param locale path string true "The locale, a BCP 47 language tag"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found, if the exercise has no translation into the locale"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the locale is invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /translations/{exercise_id}/{locale} [delete]
+func (s *ServerService) DeleteTranslation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeleteTranslation request")
+	claims, ok := authorizeStaff(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = exerciseService.DeleteTranslation(ctx, ps.ByName("exercise"), ps.ByName("locale")); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s removed the %s translation of exercise %s", claims.ID, claims.Username,
+		ps.ByName("locale"), ps.ByName("exercise"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslations(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	jack := addExercise(t, server, "Jumping Jack")
+	staff := login(t, server, client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword})
+	defer logout(t, server, staff)
+	basic := login(t, server, client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword})
+	defer logout(t, server, basic)
+
+	testData := []struct {
+		cookie           *http.Cookie
+		exerciseID       string
+		locale           string
+		translation      interface{}
+		expectedResponse int
+	}{
+		{basic, jack, "en-GB", client.Translation{Name: "Star Jump"}, http.StatusForbidden},
+		{staff, jack, "en-GB", "Star Jump", http.StatusUnsupportedMediaType},
+		{staff, jack, "en", client.Translation{Name: "Star Jump"}, http.StatusUnprocessableEntity},
+		{staff, jack, "english", client.Translation{Name: "Star Jump"}, http.StatusUnprocessableEntity},
+		{staff, jack, "de", client.Translation{}, http.StatusUnprocessableEntity},
+		{staff, testBasic1ID, "en-GB", client.Translation{Name: "Star Jump"}, http.StatusNotFound},
+		{staff, jack, "en-gb", client.Translation{Name: "Star Jump"}, http.StatusOK},
+		{staff, jack, "de", client.Translation{Name: "Hampelmann", Description: "Eine Sprungübung"}, http.StatusOK},
+	}
+	for _, d := range testData {
+		ps := httprouter.Params{{Key: "exercise", Value: d.exerciseID}, {Key: "locale", Value: d.locale}}
+		response := workoutRequest(t, server.SetTranslation, http.MethodPut, d.cookie, d.translation, ps)
+		assert.Equal(t, d.expectedResponse, response.Code, "%s %v", d.locale, d.translation)
+	}
+	ps := httprouter.Params{{Key: "exercise", Value: jack}}
+	response := workoutRequest(t, server.GetTranslations, http.MethodGet, basic, nil, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = workoutRequest(t, server.GetTranslations, http.MethodGet, staff, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var translations map[string]client.Translation
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&translations))
+	assert.Equal(t, "Star Jump", translations["en-GB"].Name)
+	assert.Equal(t, "Hampelmann", translations["de"].Name)
+
+	// The exercises are returned in the preferred locale of the request
+	list := func(acceptLanguage string) client.Exercise {
+		request := httptest.NewRequest(http.MethodGet, "http://exercises", nil)
+		request.Header.Set("Accept-Language", acceptLanguage)
+		request.AddCookie(basic)
+		response := httptest.NewRecorder()
+		server.GetExercises(response, request, nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "Accept-Language", response.Header().Get("vary"))
+		var exercises []client.Exercise
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&exercises))
+		if !assert.Equal(t, 1, len(exercises)) {
+			return client.Exercise{}
+		}
+		assert.Nil(t, exercises[0].Translations)
+		return exercises[0]
+	}
+	assert.Equal(t, "Jumping Jack", list("").Name)
+	assert.Equal(t, "Star Jump", list("en-GB,en;q=0.9").Name)
+	exercise := list("de-DE, en;q=0.5")
+	assert.Equal(t, "Hampelmann", exercise.Name)
+	assert.Equal(t, "de", exercise.Locale)
+	assert.Equal(t, "Eine Sprungübung", exercise.Description)
+	assert.Equal(t, "Jumping Jack", list("fr;q=1, en-US;q=0.8, de;q=0.5").Name)
+
+	ps = append(ps, httprouter.Param{Key: "locale", Value: "de"})
+	response = workoutRequest(t, server.DeleteTranslation, http.MethodDelete, basic, nil, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = workoutRequest(t, server.DeleteTranslation, http.MethodDelete, staff, nil, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.DeleteTranslation, http.MethodDelete, staff, nil, ps)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "Jumping Jack", list("de").Name)
+}
@@ -249,3 +249,25 @@ func TestBasicUpdatePassword(t *testing.T) {
 	}
 	testUserUpdatePassword(t, creds, testData)
 }
+
+func TestUpdateBodyWeight(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	response := workoutRequest(t, server.UpdateBodyWeight, http.MethodPut, cookie,
+		client.BodyWeight{Weight: 180, Unit: "lb"}, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.UpdateBodyWeight, http.MethodPut, cookie,
+		client.BodyWeight{Weight: 5}, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	// Basic users can only set their own weight
+	ps = httprouter.Params{{Key: "id", Value: testBasic2ID}}
+	response = workoutRequest(t, server.UpdateBodyWeight, http.MethodPut, cookie,
+		client.BodyWeight{Weight: 80}, ps)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
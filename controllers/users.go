@@ -115,7 +115,7 @@ func (s *ServerService) CreateUser(w http.ResponseWriter, r *http.Request, _ htt
 		user.Username, user.Privilege)
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
-	userService, err := db.NewUserService(s.Database)
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
 	if err != nil {
 		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -137,6 +137,11 @@ func (s *ServerService) CreateUser(w http.ResponseWriter, r *http.Request, _ htt
 	// 	}
 	//
 	id, err := userService.Create(ctx, &user)
+	if db.IsConflict(err) {
+		log.Debug("Failed to create user ", err)
+		errorWithJSON(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Error("Failed to create user ", err)
 		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
@@ -227,7 +232,7 @@ func (s *ServerService) DeleteUser(w http.ResponseWriter, r *http.Request, _ htt
 
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
-	userService, err := db.NewUserService(s.Database)
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
 	if err != nil {
 		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -264,6 +269,61 @@ func (s *ServerService) DeleteUser(w http.ResponseWriter, r *http.Request, _ htt
 	}
 	log.Infof("%s:%s successfully deleted user %s", claims.ID, claims.Username, id)
 
+	// Remove the workout sessions logged by the user
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err == nil {
+		var logs int
+		logs, err = workoutService.DeleteByUser(ctx, id)
+		log.Debugf("deleted %d workouts of user %s", logs, id)
+	}
+	if err != nil {
+		log.Errorf("failed to delete workouts of user %s, %s", id, err)
+	}
+
+	// Remove the workout templates of the user
+	templateService, err := db.NewTemplateService(s.Database, s.serviceOptions()...)
+	if err == nil {
+		var templates int
+		templates, err = templateService.DeleteByUser(ctx, id)
+		log.Debugf("deleted %d templates of user %s", templates, id)
+	}
+	if err != nil {
+		log.Errorf("failed to delete templates of user %s, %s", id, err)
+	}
+
+	// Remove the training plans assigned to the user
+	planService, err := db.NewPlanService(s.Database, s.serviceOptions()...)
+	if err == nil {
+		var assignments int
+		assignments, err = planService.DeleteByUser(ctx, id)
+		log.Debugf("deleted %d plan assignments of user %s", assignments, id)
+	}
+	if err != nil {
+		log.Errorf("failed to delete plan assignments of user %s, %s", id, err)
+	}
+
+	// Remove the sessions imported into the calendar of the user
+	calendarService, err := db.NewCalendarService(s.Database, s.serviceOptions()...)
+	if err == nil {
+		var scheduled int
+		scheduled, err = calendarService.DeleteByUser(ctx, id)
+		log.Debugf("deleted %d scheduled sessions of user %s", scheduled, id)
+	}
+	if err != nil {
+		log.Errorf("failed to delete scheduled sessions of user %s, %s", id, err)
+	}
+
+	// Remove the private exercises of the user, along with their media
+	exerciseService, err := db.NewExerciseService(s.Database, log.StandardLogger(), s.serviceOptions()...)
+	if err == nil {
+		var exercises int
+		exercises, err = exerciseService.DeleteByOwner(ctx, s.blobs, id)
+		log.Debugf("deleted %d private exercises of user %s", exercises, id)
+	}
+	if err != nil {
+		log.Errorf("failed to delete private exercises of user %s, %s", id, err)
+	}
+
 	// Return a count of the # of entries deleted
 	result := DeleteCount{cnt}
 	w.Header().Set("content-type", "application/json")
@@ -340,7 +400,7 @@ func (s *ServerService) GetUser(w http.ResponseWriter, r *http.Request, _ httpro
 
 	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
 	defer cancel()
-	userService, err := db.NewUserService(s.Database)
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
 	if err != nil {
 		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -402,7 +462,7 @@ func (s *ServerService) GetUsers(w http.ResponseWriter, r *http.Request, _ httpr
 
 	ctx, cancel := context.WithTimeout(context.TODO(), 120*time.Second)
 	defer cancel()
-	userService, err := db.NewUserService(s.Database)
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
 	if err != nil {
 		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -477,7 +537,7 @@ func (s *ServerService) UpdateUserPassword(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	userService, err := db.NewUserService(s.Database)
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
 	if err != nil {
 		errorWithJSON(w,
 			http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -565,3 +625,107 @@ func (s *ServerService) UpdateUserPassword(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 	return
 }
+
+// UpdateBodyWeight set the body weight of a user. The PUT request should
+// contain a JSON payload with the fields of client.BodyWeight. The weight is
+// used to estimate the energy expended during the sessions subsequently logged.
+//
+// A basic privilege user can only set their own weight, admin and staff
+// privileged users can set the weight of any user.
+//
+// @Summary Set the body weight of a user
+// @Description Set the body weight, in kg or lb, used to estimate the kcal expended during workouts.
+// @Tags client.BodyWeight
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param BodyWeight body client.BodyWeight true "The body weight"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the weight or unit is invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/weight [put]
+func (s *ServerService) UpdateBodyWeight(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UpdateBodyWeight request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var weight client.BodyWeight
+	if err := json.NewDecoder(r.Body).Decode(&weight); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = userService.SetBodyWeight(ctx, ps.ByName("id"), &weight); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s updated the body weight of user %s", claims.ID, claims.Username, ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateHeartRates set the maximum and resting heart rates of a user. The PUT
+// request should contain a JSON payload with the fields of client.HeartRates.
+// The heart rates are used to compute the heart rate zones and training load
+// reported by GetTrainingLoad.
+//
+// A basic privilege user can only set their own heart rates, admin and staff
+// privileged users can set the heart rates of any user.
+//
+// @Summary Set the heart rates of a user
+// @Description Set the maximum and resting heart rates, in beats per minute, used to compute
+// @Description heart rate zones and training load.
+// @Tags client.HeartRates
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param HeartRates body client.HeartRates true "The heart rates"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the heart rates are invalid"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/heartrates [put]
+func (s *ServerService) UpdateHeartRates(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UpdateHeartRates request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var rates client.HeartRates
+	if err := json.NewDecoder(r.Body).Decode(&rates); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	userService, err := db.NewUserService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = userService.SetHeartRates(ctx, ps.ByName("id"), &rates); err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s updated the heart rates of user %s", claims.ID, claims.Username, ps.ByName("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,505 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/enpointe/activity/perm"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// authorizeUserData validate the claims of the request and ensure the user is
+// allowed to access the data of the user identified by the id path parameter.
+// A basic privilege user can only access their own data, admin and staff
+// privileged users can access the data of any user. Returns false, after
+// reporting the error to the client, if access is not allowed.
+func authorizeUserData(w http.ResponseWriter, r *http.Request, ps httprouter.Params) (*Claims, bool) {
+	claims, httpStatus := validateClaim(w, r)
+	if httpStatus != http.StatusOK {
+		errorWithJSON(w, http.StatusText(httpStatus), httpStatus)
+		return nil, false
+	}
+	userID := ps.ByName("id")
+	if len(userID) == 0 {
+		errorWithJSON(w, "no user id specified", http.StatusBadRequest)
+		return nil, false
+	}
+	if !claims.Privilege.Grants(perm.Staff) && claims.ID != userID {
+		log.Tracef("User not authorized claims.ID %s != %s", claims.ID, userID)
+		errorWithJSON(w,
+			http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return nil, false
+	}
+	return claims, true
+}
+
+// errorStatus the http status code reported for an error returned by a database service
+func errorStatus(err error) int {
+	switch {
+	case db.IsNotFound(err):
+		return http.StatusNotFound
+	case db.IsValidation(err):
+		return http.StatusUnprocessableEntity
+	case db.IsConflict(err):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// parseTime parse the optional RFC3339 time held in the query parameter
+func parseTime(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// CreateWorkout log a workout session for a user. The POST request should
+// contain a JSON payload with the fields of client.Workout. The exercises of
+// the session, and the sets of each exercise, are stored in the order given.
+//
+// A basic privilege user can only log workouts for themselves, admin and
+// staff privileged users can log workouts for any user.
+//
+// @Summary Log a workout session
+// @Description Log a workout session, made up of exercises and their sets, for a user.
+// @Description Loads may be specified in kg or lb and are stored in kg.
+// @Tags client.Workout Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param Workout body client.Workout true "The workout session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the workout fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts [post]
+func (s *ServerService) CreateWorkout(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("CreateWorkout request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var workout client.Workout
+	if err := json.NewDecoder(r.Body).Decode(&workout); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := workoutService.Create(ctx, ps.ByName("id"), &workout)
+	if err != nil {
+		log.Debugf("%s:%s failed to create workout, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s created workout %s for user %s",
+		claims.ID, claims.Username, id, ps.ByName("id"))
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// GetWorkouts return the workout sessions of a user, most recent first.
+// The optional from and to query parameters, RFC3339 times, restrict the
+// sessions returned to those started within that period.
+//
+// @Summary Get the workout sessions of a user
+// @Description Get the workout sessions of a user, most recent first.
+// @Tags client.Workout
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param from query string false "Only return sessions started at or after this RFC3339 time"
+// @Param to query string false "Only return sessions started before this RFC3339 time"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {array} client.Workout
+// @Failure 400 {object} APIError "Bad Request"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts [get]
+func (s *ServerService) GetWorkouts(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetWorkouts request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	from, err := parseTime(r, "from")
+	if err != nil {
+		errorWithJSON(w, "from must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(r, "to")
+	if err != nil {
+		errorWithJSON(w, "to must be a RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	workouts, err := workoutService.GetAll(ctx, ps.ByName("id"), from, to)
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workouts)
+}
+
+// GetWorkout return a single workout session of a user
+//
+// @Summary Get a workout session
+// @Description Get a workout session of a user, including the sets of each exercise.
+// @Tags client.Workout
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Produce  json
+// @Success 200 {object} client.Workout
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id} [get]
+func (s *ServerService) GetWorkout(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("GetWorkout request")
+	if _, ok := authorizeUserData(w, r, ps); !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	workout, err := workoutService.GetByID(ctx, ps.ByName("id"), ps.ByName("workout"))
+	if err != nil {
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workout)
+}
+
+// DeleteWorkout delete a workout session of a user
+//
+// @Summary Delete a workout session
+// @Description Delete a workout session of a user along with all its exercises and sets.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id} [delete]
+func (s *ServerService) DeleteWorkout(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("DeleteWorkout request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	s.modifyWorkout(w, claims, "deleted workout", func(ctx context.Context, ws *db.WorkoutService) error {
+		return ws.Delete(ctx, ps.ByName("id"), ps.ByName("workout"))
+	})
+}
+
+// AddWorkoutExercise append an exercise to a workout session. The POST request
+// should contain a JSON payload with the fields of client.WorkoutExercise.
+//
+// @Summary Add an exercise to a workout session
+// @Description Append an exercise, and optionally its sets, to a workout session.
+// @Tags client.WorkoutExercise Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @Param WorkoutExercise body client.WorkoutExercise true "The exercise performed"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the session was modified by a concurrent request"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the exercise fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/exercises [post]
+func (s *ServerService) AddWorkoutExercise(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("AddWorkoutExercise request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var entry client.WorkoutExercise
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	s.createInWorkout(w, claims, "added exercise", func(ctx context.Context, ws *db.WorkoutService) (string, error) {
+		return ws.AddExercise(ctx, ps.ByName("id"), ps.ByName("workout"), &entry)
+	})
+}
+
+// UpdateCardio replace the cardio metrics recorded for an exercise of a workout session.
+// The PUT request should contain a JSON payload with the fields of client.CardioMetrics.
+//
+// @Summary Record the cardio metrics of an exercise of a workout session
+// @Description Replace the duration, distance, heart rate and elevation gain of an exercise.
+// @Description Distances may be specified in km or mi and are stored in meters.
+// @Description Pace and speed are computed from the duration and distance.
+// @Tags client.CardioMetrics
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @Param entry_id path string true "ID of the exercise entry within the session"
+// @Param CardioMetrics body client.CardioMetrics true "The cardio metrics of the exercise"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the session was modified by a concurrent request"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the metrics fail validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/cardio [put]
+func (s *ServerService) UpdateCardio(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UpdateCardio request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var metrics client.CardioMetrics
+	if err := json.NewDecoder(r.Body).Decode(&metrics); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	s.modifyWorkout(w, claims, "updated cardio metrics", func(ctx context.Context, ws *db.WorkoutService) error {
+		return ws.SetCardio(ctx, ps.ByName("id"), ps.ByName("workout"), ps.ByName("entry"), &metrics)
+	})
+}
+
+// RemoveWorkoutExercise remove an exercise, and its sets, from a workout session
+//
+// @Summary Remove an exercise from a workout session
+// @Description Remove an exercise, and all its sets, from a workout session.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @Param entry_id path string true "ID of the exercise entry within the session"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the session was modified by a concurrent request"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/exercises/{entry_id} [delete]
+func (s *ServerService) RemoveWorkoutExercise(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("RemoveWorkoutExercise request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	s.modifyWorkout(w, claims, "removed exercise", func(ctx context.Context, ws *db.WorkoutService) error {
+		return ws.RemoveExercise(ctx, ps.ByName("id"), ps.ByName("workout"), ps.ByName("entry"))
+	})
+}
+
+// AddSet append a set to an exercise of a workout session. The POST request
+// should contain a JSON payload with the fields of client.StrengthSet.
+//
+// @Summary Add a set to an exercise of a workout session
+// @Description Append a set of reps, load, rest time and RPE to an exercise of a workout session.
+// @Tags client.StrengthSet Identity
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @Param entry_id path string true "ID of the exercise entry within the session"
+// @Param StrengthSet body client.StrengthSet true "The set performed"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Produce  json
+// @Success 201 {object} Identity "Created"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the session was modified by a concurrent request"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the set fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/sets [post]
+func (s *ServerService) AddSet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("AddSet request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var set client.StrengthSet
+	if err := json.NewDecoder(r.Body).Decode(&set); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	s.createInWorkout(w, claims, "added set", func(ctx context.Context, ws *db.WorkoutService) (string, error) {
+		return ws.AddSet(ctx, ps.ByName("id"), ps.ByName("workout"), ps.ByName("entry"), &set)
+	})
+}
+
+// UpdateSet replace the values of a set of an exercise of a workout session.
+// The PUT request should contain a JSON payload with the fields of client.StrengthSet.
+//
+// @Summary Edit a set of an exercise of a workout session
+// @Description Replace the reps, load, rest time and RPE of a set.
+// @Tags client.StrengthSet
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @Param entry_id path string true "ID of the exercise entry within the session"
+// @Param set_id path string true "ID of the set"
+// @Param StrengthSet body client.StrengthSet true "The new values of the set"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Accept  json
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the session was modified by a concurrent request"
+// @Failure 415 {object} APIError "UnsupportedMediaType, request occurred without a required application/json content"
+// @Failure 422 {object} APIError "Unprocessable Entity, if the set fails validation"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/sets/{set_id} [put]
+func (s *ServerService) UpdateSet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("UpdateSet request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	var set client.StrengthSet
+	if err := json.NewDecoder(r.Body).Decode(&set); err != nil {
+		errorWithJSON(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	s.modifyWorkout(w, claims, "updated set", func(ctx context.Context, ws *db.WorkoutService) error {
+		return ws.UpdateSet(ctx, ps.ByName("id"), ps.ByName("workout"), ps.ByName("entry"),
+			ps.ByName("set"), &set)
+	})
+}
+
+// RemoveSet remove a set from an exercise of a workout session
+//
+// @Summary Remove a set from an exercise of a workout session
+// @Description Remove a set from an exercise of a workout session.
+// @Security ApiKeyAuth
+// @in header
+// @name Authorization
+// @Param user_id path string true "ID of the user"
+// @Param workout_id path string true "ID of the workout session"
+// @Param entry_id path string true "ID of the exercise entry within the session"
+// @Param set_id path string true "ID of the set"
+// @param Authorization header string true "The JWT authorization token acquired at login"
+// @Success 204 "No Content"
+// @Failure 401 {object} APIError "Unauthorized, if the user not authorized"
+// @Failure 403 {object} APIError "Forbidden, if the user lacks permission to perform the requested operation"
+// @Failure 404 {object} APIError "Not Found"
+// @Failure 409 {object} APIError "Conflict, if the session was modified by a concurrent request"
+// @Failure 500 {object} APIError "Internal Server Error"
+// @Router /users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/sets/{set_id} [delete]
+func (s *ServerService) RemoveSet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	log.Trace("RemoveSet request")
+	claims, ok := authorizeUserData(w, r, ps)
+	if !ok {
+		return
+	}
+	s.modifyWorkout(w, claims, "removed set", func(ctx context.Context, ws *db.WorkoutService) error {
+		return ws.RemoveSet(ctx, ps.ByName("id"), ps.ByName("workout"), ps.ByName("entry"), ps.ByName("set"))
+	})
+}
+
+// createInWorkout perform an operation that adds an entry to a workout
+// session, returning the id of the new entry to the client
+func (s *ServerService) createInWorkout(w http.ResponseWriter, claims *Claims, action string,
+	op func(context.Context, *db.WorkoutService) (string, error)) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id, err := op(ctx, workoutService)
+	if err != nil {
+		log.Debugf("%s:%s workout request failed, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s %s %s", claims.ID, claims.Username, action, id)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Identity{id})
+}
+
+// modifyWorkout perform an operation that modifies or deletes a workout session
+func (s *ServerService) modifyWorkout(w http.ResponseWriter, claims *Claims, action string,
+	op func(context.Context, *db.WorkoutService) error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 60*time.Second)
+	defer cancel()
+	workoutService, err := db.NewWorkoutService(s.Database, s.serviceOptions()...)
+	if err != nil {
+		errorWithJSON(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = op(ctx, workoutService); err != nil {
+		log.Debugf("%s:%s workout request failed, %s", claims.ID, claims.Username, err)
+		errorWithJSON(w, err.Error(), errorStatus(err))
+		return
+	}
+	log.Infof("%s:%s %s", claims.ID, claims.Username, action)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/enpointe/activity/controllers"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// addExercise helper function that adds an exercise to the database
+// and returns its ID
+func addExercise(t *testing.T, server *controllers.ServerService, name string) string {
+	ctx := context.TODO()
+	service, err := db.NewExerciseService(server.Database, log.StandardLogger())
+	assert.NoError(t, err)
+	err = service.Create(ctx, &client.Exercise{Name: name})
+	assert.NoError(t, err)
+	e, err := service.GetByName(ctx, name)
+	assert.NoError(t, err)
+	return e.ID
+}
+
+// workoutRequest helper function that invokes a workout handler with the
+// specified path parameters and returns the response
+func workoutRequest(t *testing.T, handler httprouter.Handle, method string, cookie *http.Cookie,
+	body interface{}, ps httprouter.Params) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		assert.NoError(t, err)
+	}
+	request := httptest.NewRequest(method, "http://workouts", bytes.NewBuffer(data))
+	request.AddCookie(cookie)
+	response := httptest.NewRecorder()
+	handler(response, request, ps)
+	return response
+}
+
+func TestWorkoutPrivileges(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	workout := client.Workout{Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: squat}}}
+
+	testData := []struct {
+		creds            client.Credentials
+		userID           string
+		expectedResponse int
+	}{
+		{client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword},
+			testBasic1ID, http.StatusCreated},
+		{client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword},
+			testBasic2ID, http.StatusForbidden},
+		{client.Credentials{Username: testStaff1Username, Password: testStaff1UserPassword},
+			testBasic2ID, http.StatusCreated},
+		{client.Credentials{Username: testAdmin1Username, Password: testAdmin1UserPassword},
+			testBasic2ID, http.StatusCreated},
+	}
+	for _, d := range testData {
+		t.Run(fmt.Sprintf("%s-%s", d.creds.Username, d.userID),
+			func(t *testing.T) {
+				cookie := login(t, server, d.creds)
+				defer logout(t, server, cookie)
+				response := workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, workout,
+					httprouter.Params{{Key: "id", Value: d.userID}})
+				assert.Equal(t, d.expectedResponse, response.Code)
+			})
+	}
+}
+
+func TestWorkoutSets(t *testing.T) {
+	server := setup(t, testMultiUserFilenameJSON)
+	defer teardown(t, server)
+	squat := addExercise(t, server, "Squat")
+	creds := client.Credentials{Username: testBasic1Username, Password: testBasic1UserPassword}
+	cookie := login(t, server, creds)
+	defer logout(t, server, cookie)
+
+	// Create a session and log a set of squats
+	var identity controllers.Identity
+	ps := httprouter.Params{{Key: "id", Value: testBasic1ID}}
+	response := workoutRequest(t, server.CreateWorkout, http.MethodPost, cookie, client.Workout{}, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	json.NewDecoder(response.Body).Decode(&identity)
+	ps = append(ps, httprouter.Param{Key: "workout", Value: identity.ID})
+
+	response = workoutRequest(t, server.AddWorkoutExercise, http.MethodPost, cookie,
+		client.WorkoutExercise{ExerciseID: "5db8e02b0e7aa732afd7fbc1"}, ps)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = workoutRequest(t, server.AddWorkoutExercise, http.MethodPost, cookie,
+		client.WorkoutExercise{ExerciseID: squat}, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	json.NewDecoder(response.Body).Decode(&identity)
+	ps = append(ps, httprouter.Param{Key: "entry", Value: identity.ID})
+
+	response = workoutRequest(t, server.AddSet, http.MethodPost, cookie,
+		client.StrengthSet{Reps: 5, Load: 135, Unit: "lb", RPE: 7}, ps)
+	assert.Equal(t, http.StatusCreated, response.Code)
+	json.NewDecoder(response.Body).Decode(&identity)
+	setParams := append(ps, httprouter.Param{Key: "set", Value: identity.ID})
+
+	response = workoutRequest(t, server.UpdateSet, http.MethodPut, cookie,
+		client.StrengthSet{Reps: 5, Load: 11, RPE: 11}, setParams)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = workoutRequest(t, server.UpdateSet, http.MethodPut, cookie,
+		client.StrengthSet{Reps: 4, Load: 145, Unit: "lb", RPE: 8}, setParams)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+
+	// Check the set as stored
+	response = workoutRequest(t, server.GetWorkout, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var workout client.Workout
+	err := json.NewDecoder(response.Body).Decode(&workout)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(workout.Exercises)) && assert.Equal(t, 1, len(workout.Exercises[0].Sets)) {
+		assert.Equal(t, 4, workout.Exercises[0].Sets[0].Reps)
+		assert.Equal(t, 145.0, workout.Exercises[0].Sets[0].Load)
+		assert.Equal(t, "lb", workout.Exercises[0].Sets[0].Unit)
+	}
+
+	response = workoutRequest(t, server.RemoveSet, http.MethodDelete, cookie, nil, setParams)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.RemoveSet, http.MethodDelete, cookie, nil, setParams)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = workoutRequest(t, server.RemoveWorkoutExercise, http.MethodDelete, cookie, nil, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.DeleteWorkout, http.MethodDelete, cookie, nil, ps)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = workoutRequest(t, server.GetWorkout, http.MethodGet, cookie, nil, ps)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
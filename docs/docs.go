@@ -1,6 +1,6 @@
 // GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
 // This file was generated by swaggo/swag at
-// 2019-11-22 15:02:35.589326 -0700 MST m=+0.111521484
+// 2026-10-18 22:46:51.989065119 +0000 UTC m=+0.230240510
 
 package docs
 
@@ -29,64 +29,31 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Log a user into the activity server, allowing the user to\nacquire authorization to execute methods for this application.\nThe privileges associated with a users account (client.UserInfo.Privilege)\nwill dictat what methods can be invoked by the user.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/calendar/{token}": {
+            "get": {
+                "description": "Get the logged and scheduled sessions of a user as an iCalendar (RFC 5545) file.",
                 "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "client.Credentials",
-                    "client.UserInfo"
+                    "text/calendar"
                 ],
-                "summary": "Login log a user into server",
+                "summary": "Get the calendar feed of a user",
                 "parameters": [
                     {
-                        "description": "Login Credentials",
-                        "name": "Credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/client.Credentials"
-                        }
+                        "type": "string",
+                        "description": "The calendar feed token followed by .ics",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/client.UserInfo"
-                        },
-                        "headers": {
-                            "auth": {
-                                "type": "string",
-                                "description": "JWT Authentication Token"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "The iCalendar file",
                         "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Not Found, if the token is unknown or revoked",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
@@ -100,22 +67,64 @@ var doc = `{
                 }
             }
         },
-        "/logout": {
-            "post": {
+        "/exercises": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Log out the current user.\nBy nature of how JWT tokens work if the token is cached, the old\ntoken can continue to be recognized as authorized till the token\nexpires",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the shared and private exercises of the user, filtered by category, muscle\ngroup, equipment, difficulty and measurement.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Log out the current user",
+                "tags": [
+                    "client.Exercise"
+                ],
+                "summary": "Get the exercises",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return exercises of this category, strength, cardio or flexibility",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return exercises working this primary or secondary muscle group",
+                        "name": "muscle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return exercises with this primary muscle group",
+                        "name": "primary",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return exercises using this equipment",
+                        "name": "equipment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return exercises of this difficulty, beginner, intermediate or advanced",
+                        "name": "difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return exercises measured in reps, time or distance",
+                        "name": "measurement",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The preferred locales of the names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    },
                     {
                         "type": "string",
                         "description": "The JWT authorization token acquired at login",
@@ -128,7 +137,10 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.Exercise"
+                            }
                         }
                     },
                     "400": {
@@ -138,7 +150,13 @@ var doc = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized",
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if a filter value is not part of the taxonomy",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
@@ -152,34 +170,35 @@ var doc = `{
                 }
             }
         },
-        "/user/updatePasswd": {
-            "patch": {
+        "/exercises/merge": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates the password for a given user. If a user is changing there own\npassword the current password must be specified.\n\nThe privileges of the user determine what password update operations can be performed.\nA user always has the necessary privileges to update their own password.\nA admin privileged user can update the password of any user.\nA staff privileged user can update the password for any basic privilege user.\nA basic privilege user can only update there own password.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Fold a duplicate exercise into another, repointing the workout sessions,\ntemplates and goals referencing it. Only admin privileged users can perform\nthis operation.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "client.UserPassword"
+                    "client.ExerciseMerge"
                 ],
-                "summary": "Update the password for a user",
+                "summary": "Merge a duplicate exercise into another",
                 "parameters": [
                     {
-                        "description": "Parameters for updating the specified users password",
-                        "name": "PasswordUpdate",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/client.PasswordUpdate"
-                        }
+                        "type": "string",
+                        "description": "The id of the duplicate exercise",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The id of the exercise the duplicate is merged into",
+                        "name": "into",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -193,7 +212,7 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.UpdateResults"
+                            "$ref": "#/definitions/client.ExerciseMerge"
                         }
                     },
                     "400": {
@@ -215,25 +234,19 @@ var doc = `{
                         }
                     },
                     "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "405": {
-                        "description": "Method Not Allowed",
+                        "description": "Not Found, if either exercise does not exist",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
                     },
-                    "415": {
-                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                    "409": {
+                        "description": "Conflict, if the aliases of the merged exercise conflict with another exercise",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
                     },
-                    "421": {
-                        "description": "Validation Error",
+                    "422": {
+                        "description": "Unprocessable Entity, if an exercise is merged into itself",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
@@ -247,34 +260,46 @@ var doc = `{
                 }
             }
         },
-        "/users": {
-            "post": {
+        "/exercises/search": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a user for the activity server.\nThe privileges of the user invoking this method determine whether this operation\ncan be performed. A admin privileged user can create a user with any privilege level.\nA staff privileged user can create a staff or a basic privilege level user.\nA basic privilege user can not create any users.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Full text search of the name, aliases and description of the exercises with relevance\nranking, pagination and prefix and typo tolerant matching.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "client.UserCreate Identity"
+                    "client.ExerciseSearch"
                 ],
-                "summary": "Create a user for the activity server",
+                "summary": "Search the exercises",
                 "parameters": [
                     {
-                        "description": "Configuration Data of the user being create",
-                        "name": "UserCreate",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/client.UserCreate"
-                        }
+                        "type": "string",
+                        "description": "The text to search for",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The page of results, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "The number of results in a page, defaults to 20, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The preferred locales of the names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
                     },
                     {
                         "type": "string",
@@ -285,10 +310,10 @@ var doc = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.Identity"
+                            "$ref": "#/definitions/client.ExerciseSearch"
                         }
                     },
                     "400": {
@@ -303,26 +328,8 @@ var doc = `{
                             "$ref": "#/definitions/controllers.APIError"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "405": {
-                        "description": "Method Not Allowed",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict, if attempting to add a user that already exists",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "415": {
-                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                    "422": {
+                        "description": "Unprocessable Entity, if the search text, page or limit is invalid",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
@@ -336,24 +343,19 @@ var doc = `{
                 }
             }
         },
-        "/users/": {
+        "/export": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get client.UserInfo data for all known users.\nOnly admin and staff privileged users can perform this operation.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stream the contents of a collection as JSON Lines or CSV.\nThe hashed passwords of users are redacted unless explicitly requested.\nOnly admin privileged users can perform this operation.",
                 "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "client.UserInfo"
+                    "application/x-ndjson",
+                    "text/csv"
                 ],
-                "summary": "Get user information for all users",
+                "summary": "Export users, exercises or logs",
                 "parameters": [
                     {
                         "type": "string",
@@ -361,16 +363,32 @@ var doc = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The collection to export, users, exercises or logs",
+                        "name": "collection",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The format of the data, jsonl or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the hashed passwords of users",
+                        "name": "passwords",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "The exported documents",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/client.UserInfo"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -391,12 +409,6 @@ var doc = `{
                             "$ref": "#/definitions/controllers.APIError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
                     "405": {
                         "description": "Method Not Allowed",
                         "schema": {
@@ -412,45 +424,58 @@ var doc = `{
                 }
             }
         },
-        "/users/{user_id}": {
-            "get": {
+        "/import": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get the client.UserInfo data for the specified user ID.\nThe privileges (client.UserInfo.Privilege) of the user invoking this\nmethod are used to determine what requests can be satisfied.\nA admin and staff privileged user can fetch details about any user.\nA basic privilege user can only fetch details about themselves.",
+                "description": "Bulk import users or exercises from JSON Lines, a JSON array or CSV.\nEach record is validated, records that fail validation are reported\nwith their line number. Only admin privileged users can perform this operation.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "client.UserInfo"
+                    "client.ImportResult"
                 ],
-                "summary": "Get information about the specified user",
+                "summary": "Bulk import users or exercises",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID of the User to fetch details about",
-                        "name": "user_id",
-                        "in": "path",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "The JWT authorization token acquired at login",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "The collection to import into, users or exercises",
+                        "name": "collection",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The format of the data, json, jsonl or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "How to handle records matching existing entries, skip or upsert",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/client.UserInfo"
+                            "$ref": "#/definitions/client.ImportResult"
                         }
                     },
                     "400": {
@@ -466,19 +491,19 @@ var doc = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden, if the user lacks permission to perform the red operation",
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "405": {
+                        "description": "Method Not Allowed",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
                     },
-                    "405": {
-                        "description": "Method Not Allowed",
+                    "422": {
+                        "description": "Unprocessable Entity, if the data could not be read",
                         "schema": {
                             "$ref": "#/definitions/controllers.APIError"
                         }
@@ -490,14 +515,16 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/login": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a user for the given ID.\nThe privileges of the user invoking this method determine whether this operation\ncan be performed.\nA admin privileged user can delete a user with any privilege level.\nA staff privileged user can delete a basic privilege level user.\nA basic privilege user can not delete any users.",
+                "description": "Log a user into the activity server, allowing the user to\nacquire authorization to execute methods for this application.\nThe privileges associated with a users account (client.UserInfo.Privilege)\nwill dictat what methods can be invoked by the user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -505,16 +532,296 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "DeleteCount"
+                    "client.Credentials",
+                    "client.UserInfo"
                 ],
-                "summary": "Delete a user from the activity server",
+                "summary": "Login log a user into server",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID of the user to delete",
-                        "name": "user_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Login Credentials",
+                        "name": "Credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.UserInfo"
+                        },
+                        "headers": {
+                            "auth": {
+                                "type": "string",
+                                "description": "JWT Authentication Token"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log out the current user.\nBy nature of how JWT tokens work if the token is cached, the old\ntoken can continue to be recognized as authorized till the token\nexpires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Log out the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/media": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload a PNG, JPEG or GIF image, or link a video, demonstrating an exercise.\nGIFs of more than one frame are animations. A thumbnail is generated for each image.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Media"
+                ],
+                "summary": "Add an image, animation or video link to an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise",
+                        "name": "exercise",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "The PNG, JPEG or GIF image",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The http or https URL of a video, when no file is uploaded",
+                        "name": "url",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "A caption describing the media",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/client.Media"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the exercise does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the image is not supported or the link invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/media/{media_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get an uploaded image or animation of an exercise.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/gif"
+                ],
+                "tags": [
+                    "client.Media"
+                ],
+                "summary": "Get an image or animation of an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the media",
+                        "name": "media_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The image or animation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the media does not exist or is a video link",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove the media from its exercise, deleting any image and thumbnail.",
+                "tags": [
+                    "client.Media"
+                ],
+                "summary": "Remove an image, animation or video link from an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the media",
+                        "name": "media_id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -523,79 +830,5652 @@ var doc = `{
                         "in": "header",
                         "required": true
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Number of items deleted",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.DeleteCount"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized, if the user not authorized",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found, if the ID of the user to delete is not found",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "405": {
-                        "description": "Method Not Allowed",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.APIError"
-                        }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the media does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/media/{media_id}/thumbnail": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the thumbnail, at most 256 pixels wide and high, of an image or animation of an exercise.",
+                "produces": [
+                    "image/png",
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "client.Media"
+                ],
+                "summary": "Get the thumbnail of an image or animation of an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the media",
+                        "name": "media_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The thumbnail",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the media does not exist or is a video link",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all training plans ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Plan"
+                ],
+                "summary": "Get the training plans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.Plan"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Author a multi-week training plan of sessions scheduled from the templates of the author.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Plan Identity"
+                ],
+                "summary": "Author a training plan",
+                "parameters": [
+                    {
+                        "description": "The training plan",
+                        "name": "Plan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.Plan"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the plan fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/plans/{plan_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a training plan along with the sessions scheduled each week.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Plan"
+                ],
+                "summary": "Get a training plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the plan",
+                        "name": "plan_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Plan"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a training plan that is not assigned to any user.",
+                "summary": "Delete a training plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the plan",
+                        "name": "plan_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the plan is assigned to users",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/translations/{exercise_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the name and description of an exercise in each translated locale.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Translation"
+                ],
+                "summary": "Get the translations of an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise",
+                        "name": "exercise_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Translations"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the exercise does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/translations/{exercise_id}/{locale}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the name and description of an exercise in a locale.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Translation"
+                ],
+                "summary": "Translate an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise",
+                        "name": "exercise_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The locale, a BCP 47 language tag",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The name and description in the locale",
+                        "name": "Translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.Translation"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Translation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the exercise does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the locale or translation is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove the name and description of an exercise in a locale.",
+                "tags": [
+                    "client.Translation"
+                ],
+                "summary": "Remove the translation of an exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise",
+                        "name": "exercise_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The locale, a BCP 47 language tag",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the exercise has no translation into the locale",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the locale is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a user for the activity server.\nThe privileges of the user invoking this method determine whether this operation\ncan be performed. A admin privileged user can create a user with any privilege level.\nA staff privileged user can create a staff or a basic privilege level user.\nA basic privilege user can not create any users.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.UserCreate Identity"
+                ],
+                "summary": "Create a user for the activity server",
+                "parameters": [
+                    {
+                        "description": "Configuration Data of the user being create",
+                        "name": "UserCreate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.UserCreate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if attempting to add a user that already exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates the password for a given user. If a user is changing there own\npassword the current password must be specified.\n\nThe privileges of the user determine what password update operations can be performed.\nA user always has the necessary privileges to update their own password.\nA admin privileged user can update the password of any user.\nA staff privileged user can update the password for any basic privilege user.\nA basic privilege user can only update there own password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.UserPassword"
+                ],
+                "summary": "Update the password for a user",
+                "parameters": [
+                    {
+                        "description": "Parameters for updating the specified users password",
+                        "name": "PasswordUpdate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.PasswordUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateResults"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "421": {
+                        "description": "Validation Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get client.UserInfo data for all known users.\nOnly admin and staff privileged users can perform this operation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.UserInfo"
+                ],
+                "summary": "Get user information for all users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.UserInfo"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the client.UserInfo data for the specified user ID.\nThe privileges (client.UserInfo.Privilege) of the user invoking this\nmethod are used to determine what requests can be satisfied.\nA admin and staff privileged user can fetch details about any user.\nA basic privilege user can only fetch details about themselves.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.UserInfo"
+                ],
+                "summary": "Get information about the specified user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the User to fetch details about",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.UserInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the red operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a user for the given ID.\nThe privileges of the user invoking this method determine whether this operation\ncan be performed.\nA admin privileged user can delete a user with any privilege level.\nA staff privileged user can delete a basic privilege level user.\nA basic privilege user can not delete any users.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "DeleteCount"
+                ],
+                "summary": "Delete a user from the activity server",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user to delete",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of items deleted",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DeleteCount"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the ID of the user to delete is not found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create or update a scheduled session for each event of an iCalendar (RFC 5545) file.",
+                "consumes": [
+                    "text/calendar"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.ImportResult"
+                ],
+                "summary": "Import an iCalendar file of scheduled sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone of floating times, defaults to UTC",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the body is not an iCalendar file",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the sessions imported into the calendar of a user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.ScheduledEvent"
+                ],
+                "summary": "Get the scheduled sessions of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include sessions ending at or after this RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include sessions starting before this RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.ScheduledEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar/events/{event_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a session imported into the calendar of a user.",
+                "summary": "Delete a scheduled session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the scheduled session",
+                        "name": "event_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/calendar/feed": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a secret token granting access to the iCalendar feed of the logged and\nscheduled sessions of a user, revoking any previous token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.CalendarFeed"
+                ],
+                "summary": "Create the calendar feed of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/client.CalendarFeed"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke the secret token granting access to the iCalendar feed of a user.",
+                "summary": "Revoke the calendar feed of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/charts/{chart}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Render the volume lifted or distance covered per day, week or month, or the\nprogression of the estimated one rep max of an exercise, as a SVG image.",
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "client.Stats"
+                ],
+                "summary": "Get a progress chart of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The chart, volume, distance or records",
+                        "name": "chart",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this exercise, required by the records chart",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include sessions started at or after this RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include sessions started before this RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group sessions by day, week or month, defaults to week",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone periods start in, defaults to UTC",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width of the image in pixels, defaults to 640",
+                        "name": "width",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Height of the image in pixels, defaults to 320",
+                        "name": "height",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The SVG image",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the chart, period, range or size is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/exercises": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add an exercise visible only to the user alongside the shared catalog.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Exercise Identity"
+                ],
+                "summary": "Add a private exercise",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The exercise",
+                        "name": "Exercise",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.Exercise"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the name or an alias matches an exercise visible to the user",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the exercise fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/exercises/{exercise_id}/promote": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move a private exercise of a user into the shared catalog.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Exercise"
+                ],
+                "summary": "Promote a private exercise into the shared catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the private exercise",
+                        "name": "exercise_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Exercise"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the user has no such private exercise",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the name or an alias matches an exercise of the shared catalog",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/goals": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the goals of a user with their progress and the history of their status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Goal"
+                ],
+                "summary": "Get the goals of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return goals with this status, active, achieved or missed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.Goal"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set a goal for a user, ie run 50 km this month or squat 100 kg. Goals cover\na day, week or month, or from a start until a deadline.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Goal Identity"
+                ],
+                "summary": "Set a goal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The goal",
+                        "name": "Goal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.Goal"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the goal fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/goals/{goal_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a goal of a user with its progress and the history of its status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Goal"
+                ],
+                "summary": "Get a goal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the goal",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Goal"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a goal of a user along with its history.",
+                "summary": "Delete a goal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the goal",
+                        "name": "goal_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/heartrates": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the maximum and resting heart rates, in beats per minute, used to compute\nheart rate zones and training load.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.HeartRates"
+                ],
+                "summary": "Set the heart rates of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The heart rates",
+                        "name": "HeartRates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.HeartRates"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the heart rates are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/load": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the time in heart rate zones and TRIMP or session RPE load of each session\nof a user, and the daily acute:chronic workload ratio over rolling 7 and 28 day\nwindows with warnings when the ratio exceeds the threshold.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.TrainingLoad"
+                ],
+                "summary": "Get the training load of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only report days starting at or after this RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only report days starting before this RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The session load, trimp or rpe, defaults to trimp",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "The acute:chronic workload ratio above which a warning is reported, defaults to 1.5",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone days start in, defaults to UTC",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.TrainingLoad"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the method, threshold or range is invalid or the heart rates of the user are not set",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/plans": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the training plans assigned to a user along with the percentage of scheduled sessions completed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.PlanAssignment"
+                ],
+                "summary": "Get the training plans assigned to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.PlanAssignment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Assign a training plan to a basic user starting on a date.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.PlanAssignment Identity"
+                ],
+                "summary": "Assign a training plan to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The plan and start date",
+                        "name": "PlanAssignment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.PlanAssignment"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the user does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the assignment fails validation or the user is not a basic user",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/plans/{assignment_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove the assignment of a training plan to a user.",
+                "summary": "Unassign a training plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the assignment",
+                        "name": "assignment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/records": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the current personal records of a user, the estimated one rep max, the\nheaviest load for each number of reps, the fastest 5k and 10k and the longest duration.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.PersonalRecord"
+                ],
+                "summary": "Get the personal records of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the records of this exercise",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.PersonalRecord"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/records/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get every personal record set by a user, oldest first, including the record each beat.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.PersonalRecord"
+                ],
+                "summary": "Get the personal record history of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the records of this exercise",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return records of this kind, ie max-load",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.PersonalRecord"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the workout sessions scheduled for the user today, or on a date, by their training plans.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.ScheduledWorkout"
+                ],
+                "summary": "Get the scheduled workouts of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The date, YYYY-MM-DD, by default today",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.ScheduledWorkout"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the date is malformed",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the total and average sessions, duration, distance and volume lifted\nof a user, overall, per exercise and per day, week or month, and their\ncurrent and longest daily and weekly activity streaks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Stats"
+                ],
+                "summary": "Get the training statistics of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include the entries of this exercise",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include sessions started at or after this RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include sessions started before this RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group sessions by day, week or month, defaults to week",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone periods start in, defaults to UTC",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Stats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the period or range is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/streaks": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the time zone days start in and the number of rest days, or weeks,\nallowed without breaking a daily, or weekly, streak.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.StreakSettings"
+                ],
+                "summary": "Change the streak settings of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The streak settings",
+                        "name": "StreakSettings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.StreakSettings"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the time zone or rest allowances are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/templates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the workout templates of a user ordered by name.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.WorkoutTemplate"
+                ],
+                "summary": "Get the workout templates of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.WorkoutTemplate"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Define a named routine of exercises, with target sets, reps and duration, for a user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.WorkoutTemplate Identity"
+                ],
+                "summary": "Define a workout template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The workout template",
+                        "name": "WorkoutTemplate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.WorkoutTemplate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the user already has a template with the name",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the template fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/templates/{template_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a workout template of a user, including the target sets of each exercise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.WorkoutTemplate"
+                ],
+                "summary": "Get a workout template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.WorkoutTemplate"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the name, notes and exercises of a workout template of a user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.WorkoutTemplate"
+                ],
+                "summary": "Update a workout template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The workout template",
+                        "name": "WorkoutTemplate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.WorkoutTemplate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the user already has a template with the name",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the template fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a workout template of a user.",
+                "summary": "Delete a workout template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/templates/{template_id}/workouts": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log a workout session pre-populated with the exercises and targets of a template.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Identity"
+                ],
+                "summary": "Log a workout session from a template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the template",
+                        "name": "template_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The RFC3339 start time of the session",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if an exercise of the template no longer exists",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/tracks": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a workout session from a GPX, TCX or FIT file. The distance, moving time,\nelevation gain and heart rate are computed from the trackpoints of GPX and TCX\nfiles, FIT files also provide laps, power and cadence. The raw file is stored\nwith the session.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Identity"
+                ],
+                "summary": "Create a workout session from a GPX, TCX or FIT file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "The GPX, TCX or FIT file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise performed, defaults to the exercise named after the sport",
+                        "name": "exerciseId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the file can not be read or the exercise does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/weight": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the body weight, in kg or lb, used to estimate the kcal expended during workouts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.BodyWeight"
+                ],
+                "summary": "Set the body weight of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The body weight",
+                        "name": "BodyWeight",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.BodyWeight"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the weight or unit is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the workout sessions of a user, most recent first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Workout"
+                ],
+                "summary": "Get the workout sessions of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return sessions started at or after this RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return sessions started before this RFC3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/client.Workout"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log a workout session, made up of exercises and their sets, for a user.\nLoads may be specified in kg or lb and are stored in kg.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Workout Identity"
+                ],
+                "summary": "Log a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The workout session",
+                        "name": "Workout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.Workout"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the workout fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a workout session of a user, including the sets of each exercise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.Workout"
+                ],
+                "summary": "Get a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/client.Workout"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a workout session of a user along with all its exercises and sets.",
+                "summary": "Delete a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}/exercises": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Append an exercise, and optionally its sets, to a workout session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.WorkoutExercise Identity"
+                ],
+                "summary": "Add an exercise to a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The exercise performed",
+                        "name": "WorkoutExercise",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.WorkoutExercise"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the session was modified by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the exercise fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}/exercises/{entry_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove an exercise, and all its sets, from a workout session.",
+                "summary": "Remove an exercise from a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise entry within the session",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the session was modified by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/cardio": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the duration, distance, heart rate and elevation gain of an exercise.\nDistances may be specified in km or mi and are stored in meters.\nPace and speed are computed from the duration and distance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.CardioMetrics"
+                ],
+                "summary": "Record the cardio metrics of an exercise of a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise entry within the session",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The cardio metrics of the exercise",
+                        "name": "CardioMetrics",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.CardioMetrics"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the session was modified by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the metrics fail validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/sets": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Append a set of reps, load, rest time and RPE to an exercise of a workout session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.StrengthSet Identity"
+                ],
+                "summary": "Add a set to an exercise of a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise entry within the session",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The set performed",
+                        "name": "StrengthSet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.StrengthSet"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.Identity"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the session was modified by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the set fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}/exercises/{entry_id}/sets/{set_id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace the reps, load, rest time and RPE of a set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "client.StrengthSet"
+                ],
+                "summary": "Edit a set of an exercise of a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise entry within the session",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the set",
+                        "name": "set_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The new values of the set",
+                        "name": "StrengthSet",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/client.StrengthSet"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the session was modified by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "415": {
+                        "description": "UnsupportedMediaType, request occurred without a required application/json content",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity, if the set fails validation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a set from an exercise of a workout session.",
+                "summary": "Remove a set from an exercise of a workout session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the exercise entry within the session",
+                        "name": "entry_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the set",
+                        "name": "set_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict, if the session was modified by a concurrent request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user_id}/workouts/{workout_id}/track": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the raw GPX, TCX or FIT file a workout session was created from.",
+                "produces": [
+                    "application/gpx+xml",
+                    "application/vnd.garmin.tcx+xml",
+                    "application/vnd.ant.fit"
+                ],
+                "summary": "Get the track a workout session was created from",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the workout session",
+                        "name": "workout_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The JWT authorization token acquired at login",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The track file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, if the user not authorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden, if the user lacks permission to perform the requested operation",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found, if the session does not exist or was not created from a track",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.APIError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "client.Adherence": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "due": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 75
+                },
+                "scheduled": {
+                    "type": "integer",
+                    "example": 24
+                }
+            }
+        },
+        "client.BodyWeight": {
+            "type": "object",
+            "properties": {
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 72.5
+                }
+            }
+        },
+        "client.CalendarFeed": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string",
+                    "example": "/calendar/3f9c2a7d5e8b41c6a0d3e7f1b2c4d6e8f0a1b3c5.ics"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "3f9c2a7d5e8b41c6a0d3e7f1b2c4d6e8f0a1b3c5"
+                }
+            }
+        },
+        "client.CardioMetrics": {
+            "type": "object",
+            "properties": {
+                "avgCadence": {
+                    "type": "integer",
+                    "example": 81
+                },
+                "avgHeartRate": {
+                    "type": "integer",
+                    "example": 145
+                },
+                "avgPower": {
+                    "type": "integer",
+                    "example": 240
+                },
+                "distance": {
+                    "type": "number",
+                    "example": 5
+                },
+                "durationSeconds": {
+                    "type": "integer",
+                    "example": 1800
+                },
+                "elevationGain": {
+                    "type": "number",
+                    "example": 45
+                },
+                "laps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.CardioMetrics"
+                    }
+                },
+                "maxCadence": {
+                    "type": "integer",
+                    "example": 86
+                },
+                "maxHeartRate": {
+                    "type": "integer",
+                    "example": 172
+                },
+                "maxPower": {
+                    "type": "integer",
+                    "example": 290
+                },
+                "pace": {
+                    "type": "number",
+                    "example": 360
+                },
+                "speed": {
+                    "type": "number",
+                    "example": 10
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "km"
+                }
+            }
+        },
+        "client.Credentials": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "changeMe"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "client.DailyLoad": {
+            "type": "object",
+            "properties": {
+                "acute": {
+                    "type": "number",
+                    "example": 540.2
+                },
+                "chronic": {
+                    "type": "number",
+                    "example": 410.7
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2019-11-20"
+                },
+                "load": {
+                    "type": "number",
+                    "example": 112.4
+                },
+                "ratio": {
+                    "type": "number",
+                    "example": 1.32
+                },
+                "warning": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "client.Exercise": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "press-up",
+                        "push-up"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "strength",
+                        "cardio",
+                        "flexibility"
+                    ],
+                    "example": "strength"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "difficulty": {
+                    "type": "string",
+                    "enum": [
+                        "beginner",
+                        "intermediate",
+                        "advanced"
+                    ],
+                    "example": "intermediate"
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "barbell"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "intensities": {
+                    "type": "object"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-GB"
+                },
+                "measurement": {
+                    "type": "string",
+                    "enum": [
+                        "reps",
+                        "time",
+                        "distance"
+                    ],
+                    "example": "reps"
+                },
+                "media": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.Media"
+                    }
+                },
+                "met": {
+                    "type": "number",
+                    "example": 9.8
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc1"
+                },
+                "primaryMuscles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "quadriceps",
+                        "glutes"
+                    ]
+                },
+                "secondaryMuscles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hamstrings",
+                        "lower-back"
+                    ]
+                },
+                "translations": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Translations"
+                }
+            }
+        },
+        "client.ExerciseMatch": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "press-up",
+                        "push-up"
+                    ]
+                },
+                "category": {
+                    "type": "string",
+                    "enum": [
+                        "strength",
+                        "cardio",
+                        "flexibility"
+                    ],
+                    "example": "strength"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "difficulty": {
+                    "type": "string",
+                    "enum": [
+                        "beginner",
+                        "intermediate",
+                        "advanced"
+                    ],
+                    "example": "intermediate"
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "barbell"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "intensities": {
+                    "type": "object"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-GB"
+                },
+                "measurement": {
+                    "type": "string",
+                    "enum": [
+                        "reps",
+                        "time",
+                        "distance"
+                    ],
+                    "example": "reps"
+                },
+                "media": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.Media"
+                    }
+                },
+                "met": {
+                    "type": "number",
+                    "example": 9.8
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc1"
+                },
+                "primaryMuscles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "quadriceps",
+                        "glutes"
+                    ]
+                },
+                "score": {
+                    "type": "number",
+                    "example": 7.5
+                },
+                "secondaryMuscles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hamstrings",
+                        "lower-back"
+                    ]
+                },
+                "translations": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Translations"
+                }
+            }
+        },
+        "client.ExerciseMerge": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Exercise"
+                },
+                "goals": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "templates": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "workouts": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "client.ExerciseSearch": {
+            "type": "object",
+            "properties": {
+                "fuzzy": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "query": {
+                    "type": "string",
+                    "example": "squat thrust"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.ExerciseMatch"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "client.ExerciseStats": {
+            "type": "object",
+            "properties": {
+                "avgDistanceMeters": {
+                    "type": "number",
+                    "example": 3516.25
+                },
+                "avgDurationSeconds": {
+                    "type": "number",
+                    "example": 1800
+                },
+                "avgKcal": {
+                    "type": "number",
+                    "example": 401.7
+                },
+                "avgVolumeKg": {
+                    "type": "number",
+                    "example": 1270.8
+                },
+                "distanceMeters": {
+                    "type": "number",
+                    "example": 42195
+                },
+                "durationSeconds": {
+                    "type": "integer",
+                    "example": 21600
+                },
+                "est1RMKg": {
+                    "type": "number",
+                    "example": 116.67
+                },
+                "exerciseId": {
+                    "type": "string",
+                    "example": "5dab5fa871aab123354e5cb6"
+                },
+                "kcal": {
+                    "type": "number",
+                    "example": 4820.5
+                },
+                "reps": {
+                    "type": "integer",
+                    "example": 640
+                },
+                "sessions": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sets": {
+                    "type": "integer",
+                    "example": 96
+                },
+                "volumeKg": {
+                    "type": "number",
+                    "example": 15250
+                }
+            }
+        },
+        "client.Goal": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string",
+                    "example": "2019-12-01T08:00:00Z"
+                },
+                "exerciseId": {
+                    "type": "string",
+                    "example": "5db8ddabee74c3c19010b4f6"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.GoalEvent"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5de1f8d2e3a1f2b4c8d9e0a7"
+                },
+                "metric": {
+                    "type": "string",
+                    "enum": [
+                        "sessions",
+                        "duration",
+                        "distance",
+                        "volume",
+                        "max-load"
+                    ],
+                    "example": "distance"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "November miles"
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 64.8
+                },
+                "period": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ],
+                    "example": "month"
+                },
+                "progress": {
+                    "type": "number",
+                    "example": 32.4
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-11-01T07:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "achieved",
+                        "missed"
+                    ],
+                    "example": "active"
+                },
+                "target": {
+                    "type": "number",
+                    "example": 50
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/Los_Angeles"
+                },
+                "unit": {
+                    "type": "string",
+                    "enum": [
+                        "km",
+                        "mi",
+                        "kg",
+                        "lb",
+                        "s"
+                    ],
+                    "example": "km"
+                }
+            }
+        },
+        "client.GoalEvent": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2019-11-28T07:45:00Z"
+                },
+                "progress": {
+                    "type": "number",
+                    "example": 51.2
+                },
+                "status": {
+                    "type": "string",
+                    "example": "achieved"
+                }
+            }
+        },
+        "client.HeartRates": {
+            "type": "object",
+            "properties": {
+                "max": {
+                    "type": "integer",
+                    "example": 190
+                },
+                "resting": {
+                    "type": "integer",
+                    "example": 55
+                }
+            }
+        },
+        "client.ImportError": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "message": {
+                    "type": "string",
+                    "example": "invalid username specified, 'a'"
+                }
+            }
+        },
+        "client.ImportResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.ImportError"
+                    }
+                },
+                "inserted": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "client.Media": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "string",
+                    "example": "2019-11-20T18:30:00Z"
+                },
+                "caption": {
+                    "type": "string",
+                    "example": "Bottom of the squat"
+                },
+                "contentType": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 720
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5de1f8d2e3a1f2b4c8d9e0b3"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "image",
+                        "animation",
+                        "video"
+                    ],
+                    "example": "image"
+                },
+                "thumbnailUrl": {
+                    "type": "string",
+                    "example": "/media/5de1f8d2e3a1f2b4c8d9e0b3/thumbnail"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/media/5de1f8d2e3a1f2b4c8d9e0b3"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 1280
+                }
+            }
+        },
+        "client.PasswordUpdate": {
+            "type": "object",
+            "properties": {
+                "currentPassword": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "newPassword": {
+                    "type": "string"
+                }
+            }
+        },
+        "client.PeriodStats": {
+            "type": "object",
+            "properties": {
+                "avgDistanceMeters": {
+                    "type": "number",
+                    "example": 3516.25
+                },
+                "avgDurationSeconds": {
+                    "type": "number",
+                    "example": 1800
+                },
+                "avgKcal": {
+                    "type": "number",
+                    "example": 401.7
+                },
+                "avgVolumeKg": {
+                    "type": "number",
+                    "example": 1270.8
+                },
+                "distanceMeters": {
+                    "type": "number",
+                    "example": 42195
+                },
+                "durationSeconds": {
+                    "type": "integer",
+                    "example": 21600
+                },
+                "est1RMKg": {
+                    "type": "number",
+                    "example": 116.67
+                },
+                "kcal": {
+                    "type": "number",
+                    "example": 4820.5
+                },
+                "reps": {
+                    "type": "integer",
+                    "example": 640
+                },
+                "sessions": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sets": {
+                    "type": "integer",
+                    "example": 96
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-11-18T00:00:00Z"
+                },
+                "volumeKg": {
+                    "type": "number",
+                    "example": 15250
+                }
+            }
+        },
+        "client.PersonalRecord": {
+            "type": "object",
+            "properties": {
+                "achieved": {
+                    "type": "string",
+                    "example": "2019-11-20T18:30:00Z"
+                },
+                "current": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "exerciseId": {
+                    "type": "string",
+                    "example": "5dab5fa871aab123354e5cb6"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "1rm-epley",
+                        "1rm-brzycki",
+                        "max-load",
+                        "fastest-5k",
+                        "fastest-10k",
+                        "longest-duration"
+                    ],
+                    "example": "max-load"
+                },
+                "previous": {
+                    "type": "number",
+                    "example": 100
+                },
+                "reps": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "unit": {
+                    "type": "string",
+                    "enum": [
+                        "kg",
+                        "s"
+                    ],
+                    "example": "kg"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 102.5
+                },
+                "workoutId": {
+                    "type": "string",
+                    "example": "5dd5b7e8e3a1f2b4c8d9e0f1"
+                }
+            }
+        },
+        "client.Plan": {
+            "type": "object",
+            "properties": {
+                "authorId": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc5"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Three runs a week building to 5k"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5de3b7d2f4b2a3c5d9e0f1c4"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Couch to 5k"
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2019-12-01T10:00:00Z"
+                },
+                "weeks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.PlanWeek"
+                    }
+                }
+            }
+        },
+        "client.PlanAssignment": {
+            "type": "object",
+            "properties": {
+                "adherence": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Adherence"
+                },
+                "assignedBy": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc5"
+                },
+                "end": {
+                    "type": "string",
+                    "example": "2020-01-26"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5de3b7d2f4b2a3c5d9e0f1c5"
+                },
+                "planId": {
+                    "type": "string",
+                    "example": "5de3b7d2f4b2a3c5d9e0f1c4"
+                },
+                "planName": {
+                    "type": "string",
+                    "example": "Couch to 5k"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-12-02"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/Los_Angeles"
+                }
+            }
+        },
+        "client.PlanSession": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "templateId": {
+                    "type": "string",
+                    "example": "5de2a6c1e3a1f2b4c8d9e0b3"
+                }
+            }
+        },
+        "client.PlanWeek": {
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.PlanSession"
+                    }
+                }
+            }
+        },
+        "client.ScheduledEvent": {
+            "type": "object",
+            "properties": {
+                "allDay": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Warm up 2 km, tempo 4 km, cool down 2 km"
+                },
+                "end": {
+                    "type": "string",
+                    "example": "2019-12-02T15:30:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5de4c8e3a5c3b4d6e0f1a2d6"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-12-02T14:30:00Z"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Tempo run"
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "tempo-20191202@example.com"
+                }
+            }
+        },
+        "client.ScheduledWorkout": {
+            "type": "object",
+            "properties": {
+                "assignmentId": {
+                    "type": "string",
+                    "example": "5de3b7d2f4b2a3c5d9e0f1c5"
+                },
+                "completed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2019-12-02"
+                },
+                "day": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "planId": {
+                    "type": "string",
+                    "example": "5de3b7d2f4b2a3c5d9e0f1c4"
+                },
+                "planName": {
+                    "type": "string",
+                    "example": "Couch to 5k"
+                },
+                "template": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.WorkoutTemplate"
+                },
+                "week": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "workoutId": {
+                    "type": "string",
+                    "example": "5dc2ee5a567855de21f1070a"
+                }
+            }
+        },
+        "client.SessionLoad": {
+            "type": "object",
+            "properties": {
+                "durationSeconds": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "load": {
+                    "type": "number",
+                    "example": 112.4
+                },
+                "rpeLoad": {
+                    "type": "number",
+                    "example": 420
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-11-20T18:30:00Z"
+                },
+                "trimp": {
+                    "type": "number",
+                    "example": 112.4
+                },
+                "workoutId": {
+                    "type": "string",
+                    "example": "5dd5a1f4e1b7a5e5d8a2c3f1"
+                },
+                "zoneSeconds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "client.Stats": {
+            "type": "object",
+            "properties": {
+                "exerciseId": {
+                    "type": "string",
+                    "example": "5dab5fa871aab123354e5cb6"
+                },
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.ExerciseStats"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2019-09-01T00:00:00Z"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "week"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.PeriodStats"
                     }
+                },
+                "streaks": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Streaks"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/Los_Angeles"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2019-12-01T00:00:00Z"
+                },
+                "totals": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.StatsTotals"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc4"
                 }
             }
-        }
-    },
-    "definitions": {
-        "client.Credentials": {
+        },
+        "client.StatsTotals": {
             "type": "object",
             "properties": {
-                "password": {
+                "avgDistanceMeters": {
+                    "type": "number",
+                    "example": 3516.25
+                },
+                "avgDurationSeconds": {
+                    "type": "number",
+                    "example": 1800
+                },
+                "avgKcal": {
+                    "type": "number",
+                    "example": 401.7
+                },
+                "avgVolumeKg": {
+                    "type": "number",
+                    "example": 1270.8
+                },
+                "distanceMeters": {
+                    "type": "number",
+                    "example": 42195
+                },
+                "durationSeconds": {
+                    "type": "integer",
+                    "example": 21600
+                },
+                "est1RMKg": {
+                    "type": "number",
+                    "example": 116.67
+                },
+                "kcal": {
+                    "type": "number",
+                    "example": 4820.5
+                },
+                "reps": {
+                    "type": "integer",
+                    "example": 640
+                },
+                "sessions": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sets": {
+                    "type": "integer",
+                    "example": 96
+                },
+                "volumeKg": {
+                    "type": "number",
+                    "example": 15250
+                }
+            }
+        },
+        "client.Streak": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "currentStart": {
                     "type": "string",
-                    "example": "changeMe"
+                    "example": "2019-11-18"
                 },
-                "username": {
+                "longest": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "longestEnd": {
                     "type": "string",
-                    "example": "admin"
+                    "example": "2019-09-16"
+                },
+                "longestStart": {
+                    "type": "string",
+                    "example": "2019-09-02"
                 }
             }
         },
-        "client.PasswordUpdate": {
+        "client.StreakSettings": {
             "type": "object",
             "properties": {
-                "currentPassword": {
-                    "type": "string"
+                "restDays": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "restWeeks": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/Los_Angeles"
+                }
+            }
+        },
+        "client.Streaks": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Streak"
+                },
+                "lastActive": {
+                    "type": "string",
+                    "example": "2019-11-21"
+                },
+                "restDays": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "restWeeks": {
+                    "type": "integer",
+                    "example": 0
                 },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/Los_Angeles"
+                },
+                "weekly": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.Streak"
+                }
+            }
+        },
+        "client.StrengthSet": {
+            "type": "object",
+            "properties": {
                 "id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "5dc2ee5a567855de21f1070c"
                 },
-                "newPassword": {
+                "load": {
+                    "type": "number",
+                    "example": 100
+                },
+                "reps": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "restSeconds": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "rpe": {
+                    "type": "number",
+                    "example": 8.5
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                }
+            }
+        },
+        "client.TrainingLoad": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.DailyLoad"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2019-10-23T00:00:00Z"
+                },
+                "maxHeartRate": {
+                    "type": "integer",
+                    "example": 190
+                },
+                "method": {
+                    "type": "string",
+                    "example": "trimp"
+                },
+                "restingHeartRate": {
+                    "type": "integer",
+                    "example": 55
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.SessionLoad"
+                    }
+                },
+                "threshold": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/Los_Angeles"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2019-11-20T00:00:00Z"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc4"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "zoneSeconds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "client.Translation": {
+            "type": "object",
+            "properties": {
+                "description": {
                     "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Star Jump"
+                }
+            }
+        },
+        "client.Translations": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "object",
+                "properties": {
+                    "description": {
+                        "type": "string"
+                    },
+                    "name": {
+                        "type": "string",
+                        "example": "Star Jump"
+                    }
                 }
             }
         },
@@ -623,13 +6503,122 @@ var doc = `{
                     "type": "string",
                     "example": "5db8e02b0e7aa732afd7fbc4"
                 },
+                "maxHeartRate": {
+                    "type": "integer",
+                    "example": 190
+                },
                 "privilege": {
                     "type": "string",
                     "example": "admin"
                 },
+                "restingHeartRate": {
+                    "type": "integer",
+                    "example": 55
+                },
                 "username": {
                     "type": "string",
                     "example": "admin"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 72.5
+                },
+                "weightUnit": {
+                    "type": "string",
+                    "example": "kg"
+                }
+            }
+        },
+        "client.Workout": {
+            "type": "object",
+            "properties": {
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.WorkoutExercise"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5dc2ee5a567855de21f1070a"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Leg day"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2019-11-20T18:30:00Z"
+                },
+                "templateId": {
+                    "type": "string",
+                    "example": "5de2a6c1e3a1f2b4c8d9e0b3"
+                },
+                "trackId": {
+                    "type": "string",
+                    "example": "5dc2ee5a567855de21f1070d"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "5db8e02b0e7aa732afd7fbc4"
+                }
+            }
+        },
+        "client.WorkoutExercise": {
+            "type": "object",
+            "properties": {
+                "cardio": {
+                    "type": "object",
+                    "$ref": "#/definitions/client.CardioMetrics"
+                },
+                "exerciseId": {
+                    "type": "string",
+                    "example": "5dab5fa871aab123354e5cb6"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5dc2ee5a567855de21f1070b"
+                },
+                "intensity": {
+                    "type": "string",
+                    "example": "moderate"
+                },
+                "kcal": {
+                    "type": "number",
+                    "example": 312.5
+                },
+                "sets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.StrengthSet"
+                    }
+                }
+            }
+        },
+        "client.WorkoutTemplate": {
+            "type": "object",
+            "properties": {
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/client.WorkoutExercise"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5de2a6c1e3a1f2b4c8d9e0b3"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Leg day"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "Superset the lunges"
+                },
+                "updated": {
+                    "type": "string",
+                    "example": "2019-11-30T09:15:00Z"
                 }
             }
         },
@@ -0,0 +1,258 @@
+package fit
+
+import (
+	"time"
+)
+
+// epoch the start of FIT time, 1989-12-31T00:00:00Z. FIT timestamps
+// are the number of seconds since the epoch.
+var epoch = time.Date(1989, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// semicircles the number of semicircles in a degree, positions are
+// stored as semicircles
+const semicircles = (1 << 31) / 180.0
+
+// sportNames the names of the sports defined by the FIT profile
+var sportNames = map[int64]string{
+	0:  "generic",
+	1:  "running",
+	2:  "cycling",
+	3:  "transition",
+	4:  "fitness equipment",
+	5:  "swimming",
+	6:  "basketball",
+	7:  "soccer",
+	8:  "tennis",
+	9:  "american football",
+	10: "training",
+	11: "walking",
+	12: "cross country skiing",
+	13: "alpine skiing",
+	14: "snowboarding",
+	15: "rowing",
+	16: "mountaineering",
+	17: "hiking",
+	18: "multisport",
+	19: "paddling",
+	20: "flying",
+	21: "e-biking",
+	22: "motorcycling",
+	23: "boating",
+	24: "driving",
+	25: "golf",
+	26: "hang gliding",
+	27: "horseback riding",
+	28: "hunting",
+	29: "fishing",
+	30: "inline skating",
+	31: "rock climbing",
+	32: "sailing",
+	33: "ice skating",
+	34: "sky diving",
+	35: "snowshoeing",
+	36: "snowmobiling",
+	37: "stand up paddleboarding",
+	38: "surfing",
+	39: "wakeboarding",
+	40: "water skiing",
+	41: "kayaking",
+	42: "rafting",
+	43: "windsurfing",
+	44: "kitesurfing",
+	45: "tactical",
+	46: "jumpmaster",
+	47: "boxing",
+	48: "floor climbing",
+}
+
+// SportName the name of a FIT sport, ie running, or generic if the sport is not known
+func SportName(sport int) string {
+	if name, ok := sportNames[int64(sport)]; ok {
+		return name
+	}
+	return sportNames[0]
+}
+
+// Activity the sessions, laps and records of a FIT activity file
+type Activity struct {
+	Timestamp time.Time
+	Sessions  []Session
+	Laps      []Lap
+	Records   []Record
+}
+
+// Summary the totals shared by sessions and laps. Values not recorded by
+// the device are zero.
+type Summary struct {
+	Start        time.Time
+	ElapsedTime  time.Duration
+	TimerTime    time.Duration
+	DistanceM    float64
+	TotalAscentM float64
+	Calories     int
+	AvgHeartRate int
+	MaxHeartRate int
+	AvgCadence   int
+	MaxCadence   int
+	AvgPower     int
+	MaxPower     int
+}
+
+// Session a continuous period of a single sport within an activity
+type Session struct {
+	Summary
+	Sport    int
+	SubSport int
+}
+
+// SportName the name of the sport of the session
+func (s *Session) SportName() string {
+	return SportName(s.Sport)
+}
+
+// End the time the session ended
+func (s *Session) End() time.Time {
+	return s.Start.Add(s.ElapsedTime)
+}
+
+// Lap a lap of a session, either manually marked or automatically
+// recorded every distance or time interval
+type Lap struct {
+	Summary
+}
+
+// Record a single sample recorded during the activity. HasPosition and
+// HasAltitude report whether the position and altitude were recorded.
+type Record struct {
+	Time        time.Time
+	Lat         float64
+	Lon         float64
+	HasPosition bool
+	AltitudeM   float64
+	HasAltitude bool
+	HeartRate   int
+	Cadence     int
+	Power       int
+	DistanceM   float64
+	SpeedMPS    float64
+}
+
+// LapsOf the laps that started within the session
+func (a *Activity) LapsOf(s *Session) []Lap {
+	var laps []Lap
+	for _, l := range a.Laps {
+		if !l.Start.Before(s.Start) && !l.Start.After(s.End()) {
+			laps = append(laps, l)
+		}
+	}
+	return laps
+}
+
+// timestamp convert a FIT timestamp to a time
+func timestamp(v int64) time.Time {
+	return epoch.Add(time.Duration(v) * time.Second)
+}
+
+// scaled the value of the field divided by scale
+func (m *message) scaled(num byte, scale float64) float64 {
+	v, _ := m.value(num)
+	return float64(v) / scale
+}
+
+// integer the value of the field as an int
+func (m *message) integer(num byte) int {
+	v, _ := m.value(num)
+	return int(v)
+}
+
+// duration the value of a field holding milliseconds as a duration
+func (m *message) duration(num byte) time.Duration {
+	v, _ := m.value(num)
+	return time.Duration(v) * time.Millisecond
+}
+
+// summary the totals held by a session or lap message, both messages use
+// the same field numbers for the totals shared by them
+func (m *message) summary(ascent, calories, avgHR, maxHR, avgCadence, maxCadence,
+	avgPower, maxPower byte) Summary {
+	s := Summary{
+		ElapsedTime:  m.duration(7),
+		TimerTime:    m.duration(8),
+		DistanceM:    m.scaled(9, 100),
+		TotalAscentM: m.scaled(ascent, 1),
+		Calories:     m.integer(calories),
+		AvgHeartRate: m.integer(avgHR),
+		MaxHeartRate: m.integer(maxHR),
+		AvgCadence:   m.integer(avgCadence),
+		MaxCadence:   m.integer(maxCadence),
+		AvgPower:     m.integer(avgPower),
+		MaxPower:     m.integer(maxPower),
+	}
+	if v, ok := m.value(2); ok {
+		s.Start = timestamp(v)
+	} else if v, ok := m.value(fieldTimestamp); ok {
+		// Fall back to the end time less the elapsed time
+		s.Start = timestamp(v).Add(-s.ElapsedTime)
+	}
+	return s
+}
+
+// newActivity build the activity from the data messages of the file
+func newActivity(messages []message) *Activity {
+	var a Activity
+	for i := range messages {
+		m := &messages[i]
+		switch m.global {
+		case mesgActivity:
+			if v, ok := m.value(fieldTimestamp); ok {
+				a.Timestamp = timestamp(v)
+			}
+		case mesgSession:
+			a.Sessions = append(a.Sessions, Session{
+				Summary:  m.summary(22, 11, 16, 17, 18, 19, 20, 21),
+				Sport:    m.integer(5),
+				SubSport: m.integer(6),
+			})
+		case mesgLap:
+			a.Laps = append(a.Laps, Lap{
+				Summary: m.summary(21, 11, 15, 16, 17, 18, 19, 20),
+			})
+		case mesgRecord:
+			a.Records = append(a.Records, newRecord(m))
+		}
+	}
+	return &a
+}
+
+// newRecord build a record from a record message
+func newRecord(m *message) Record {
+	r := Record{
+		HeartRate: m.integer(3),
+		Cadence:   m.integer(4),
+		Power:     m.integer(7),
+		DistanceM: m.scaled(5, 100),
+		SpeedMPS:  m.scaled(6, 1000),
+	}
+	if v, ok := m.value(fieldTimestamp); ok {
+		r.Time = timestamp(v)
+	}
+	lat, latOK := m.value(0)
+	lon, lonOK := m.value(1)
+	if latOK && lonOK {
+		r.Lat = float64(lat) / semicircles
+		r.Lon = float64(lon) / semicircles
+		r.HasPosition = true
+	}
+	// Enhanced fields hold the same values with a larger range
+	if v, ok := m.value(78); ok {
+		r.AltitudeM = float64(v)/5 - 500
+		r.HasAltitude = true
+	} else if v, ok := m.value(2); ok {
+		r.AltitudeM = float64(v)/5 - 500
+		r.HasAltitude = true
+	}
+	if _, ok := m.value(73); ok {
+		r.SpeedMPS = m.scaled(73, 1000)
+	}
+	return r
+}
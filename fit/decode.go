@@ -0,0 +1,285 @@
+// Package fit decodes the activity files, in the Garmin Flexible and
+// Interoperable Data Transfer (FIT) protocol, recorded by watches and bike
+// computers. Only the messages describing an activity, its sessions, laps
+// and records, are decoded, all other messages are skipped.
+package fit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// MaxFileSize the largest FIT file, in bytes, that will be decoded
+const MaxFileSize = 16 << 20
+
+// The global message numbers of the messages decoded
+const (
+	mesgSession  = 18
+	mesgLap      = 19
+	mesgRecord   = 20
+	mesgActivity = 34
+)
+
+// fieldTimestamp the field number used by all messages for the time the message was recorded
+const fieldTimestamp = 253
+
+// Errors returned when decoding a FIT file
+var (
+	ErrHeader    = errors.New("fit: invalid file header")
+	ErrTruncated = errors.New("fit: file is truncated")
+	ErrCRC       = errors.New("fit: CRC mismatch")
+)
+
+// baseType describes how a field value is stored
+type baseType struct {
+	size    int
+	signed  bool
+	invalid uint64
+}
+
+// baseTypes the base types of the FIT protocol, indexed by base type number.
+// Base types without a size, ie strings, are never interpreted.
+var baseTypes = [...]baseType{
+	0:  {1, false, 0xFF},               // enum
+	1:  {1, true, 0x7F},                // sint8
+	2:  {1, false, 0xFF},               // uint8
+	3:  {2, true, 0x7FFF},              // sint16
+	4:  {2, false, 0xFFFF},             // uint16
+	5:  {4, true, 0x7FFFFFFF},          // sint32
+	6:  {4, false, 0xFFFFFFFF},         // uint32
+	7:  {0, false, 0},                  // string
+	8:  {0, false, 0},                  // float32
+	9:  {0, false, 0},                  // float64
+	10: {1, false, 0},                  // uint8z
+	11: {2, false, 0},                  // uint16z
+	12: {4, false, 0},                  // uint32z
+	13: {1, false, 0xFF},               // byte
+	14: {8, true, 0x7FFFFFFFFFFFFFFF},  // sint64
+	15: {8, false, 0xFFFFFFFFFFFFFFFF}, // uint64
+	16: {8, false, 0},                  // uint64z
+}
+
+// fieldDefinition the number, size and base type of a field of a definition message
+type fieldDefinition struct {
+	num      byte
+	size     int
+	baseType byte
+}
+
+// definition the layout of the data messages using a local message type
+type definition struct {
+	global    uint16
+	bigEndian bool
+	fields    []fieldDefinition
+	devSize   int
+}
+
+// message a decoded data message, only fields holding a valid integer value are kept
+type message struct {
+	global uint16
+	fields map[byte]int64
+}
+
+// value return the value of the field and whether it was present
+func (m *message) value(num byte) (int64, bool) {
+	v, ok := m.fields[num]
+	return v, ok
+}
+
+// Decode read a FIT activity file
+func Decode(r io.Reader) (*Activity, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("fit: file exceeds the maximum size of %d bytes", MaxFileSize)
+	}
+	return decode(data, true)
+}
+
+// decode the FIT file held in buf, when checkCRC is set the CRCs of the
+// header and file must match their contents
+func decode(buf []byte, checkCRC bool) (*Activity, error) {
+	messages, err := readMessages(buf, checkCRC)
+	if err != nil {
+		return nil, err
+	}
+	return newActivity(messages), nil
+}
+
+// readMessages read the data messages of the FIT file held in buf
+func readMessages(buf []byte, checkCRC bool) ([]message, error) {
+	if len(buf) < 12 {
+		return nil, ErrHeader
+	}
+	headerSize := int(buf[0])
+	if headerSize < 12 || len(buf) < headerSize || string(buf[8:12]) != ".FIT" {
+		return nil, ErrHeader
+	}
+	if checkCRC && headerSize >= 14 {
+		crc := binary.LittleEndian.Uint16(buf[12:14])
+		if crc != 0 && crc != CRC(buf[:12]) {
+			return nil, ErrCRC
+		}
+	}
+	dataSize := int(binary.LittleEndian.Uint32(buf[4:8]))
+	if dataSize < 0 || len(buf)-headerSize-2 < dataSize {
+		return nil, ErrTruncated
+	}
+	end := headerSize + dataSize
+	if checkCRC && binary.LittleEndian.Uint16(buf[end:end+2]) != CRC(buf[:end]) {
+		return nil, ErrCRC
+	}
+
+	var messages []message
+	var definitions [16]*definition
+	var lastTimestamp int64
+	data := buf[headerSize:end]
+	for pos := 0; pos < len(data); {
+		header := data[pos]
+		pos++
+		switch {
+		case header&0x80 != 0:
+			// Compressed timestamp header, a data message whose timestamp
+			// is given as an offset from the last timestamp
+			def := definitions[(header>>5)&0x03]
+			if def == nil {
+				return nil, fmt.Errorf("fit: data message without a definition at offset %d", pos)
+			}
+			offset := int64(header & 0x1F)
+			timestamp := lastTimestamp&^0x1F + offset
+			if offset < lastTimestamp&0x1F {
+				timestamp += 0x20
+			}
+			m, n, err := readData(data[pos:], def)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+			m.fields[fieldTimestamp] = timestamp
+			lastTimestamp = timestamp
+			messages = append(messages, m)
+		case header&0x40 != 0:
+			def, n, err := readDefinition(data[pos:], header&0x20 != 0)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+			definitions[header&0x0F] = def
+		default:
+			def := definitions[header&0x0F]
+			if def == nil {
+				return nil, fmt.Errorf("fit: data message without a definition at offset %d", pos)
+			}
+			m, n, err := readData(data[pos:], def)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+			if timestamp, ok := m.value(fieldTimestamp); ok {
+				lastTimestamp = timestamp
+			}
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// readDefinition read a definition message, returning the definition
+// and the number of bytes read
+func readDefinition(data []byte, developer bool) (*definition, int, error) {
+	if len(data) < 5 {
+		return nil, 0, ErrTruncated
+	}
+	def := definition{bigEndian: data[1] == 1}
+	if def.bigEndian {
+		def.global = binary.BigEndian.Uint16(data[2:4])
+	} else {
+		def.global = binary.LittleEndian.Uint16(data[2:4])
+	}
+	numFields := int(data[4])
+	pos := 5
+	if len(data) < pos+numFields*3 {
+		return nil, 0, ErrTruncated
+	}
+	for i := 0; i < numFields; i++ {
+		def.fields = append(def.fields, fieldDefinition{
+			num:      data[pos],
+			size:     int(data[pos+1]),
+			baseType: data[pos+2] & 0x1F,
+		})
+		pos += 3
+	}
+	if developer {
+		if len(data) < pos+1 {
+			return nil, 0, ErrTruncated
+		}
+		numDevFields := int(data[pos])
+		pos++
+		if len(data) < pos+numDevFields*3 {
+			return nil, 0, ErrTruncated
+		}
+		for i := 0; i < numDevFields; i++ {
+			def.devSize += int(data[pos+1])
+			pos += 3
+		}
+	}
+	return &def, pos, nil
+}
+
+// readData read a data message laid out as described by the definition,
+// returning the message and the number of bytes read
+func readData(data []byte, def *definition) (message, int, error) {
+	m := message{global: def.global, fields: make(map[byte]int64)}
+	pos := 0
+	for _, f := range def.fields {
+		if len(data) < pos+f.size {
+			return m, 0, ErrTruncated
+		}
+		raw := data[pos : pos+f.size]
+		pos += f.size
+		if int(f.baseType) >= len(baseTypes) {
+			continue
+		}
+		bt := baseTypes[f.baseType]
+		if bt.size == 0 || bt.size != f.size {
+			// Strings, floats and arrays are not used
+			continue
+		}
+		var v uint64
+		switch {
+		case bt.size == 1:
+			v = uint64(raw[0])
+		case def.bigEndian && bt.size == 2:
+			v = uint64(binary.BigEndian.Uint16(raw))
+		case def.bigEndian && bt.size == 4:
+			v = uint64(binary.BigEndian.Uint32(raw))
+		case def.bigEndian:
+			v = binary.BigEndian.Uint64(raw)
+		case bt.size == 2:
+			v = uint64(binary.LittleEndian.Uint16(raw))
+		case bt.size == 4:
+			v = uint64(binary.LittleEndian.Uint32(raw))
+		default:
+			v = binary.LittleEndian.Uint64(raw)
+		}
+		if v == bt.invalid {
+			continue
+		}
+		if bt.signed {
+			// Sign extend the value
+			shift := uint(64 - 8*bt.size)
+			m.fields[f.num] = int64(v<<shift) >> shift
+		} else {
+			m.fields[f.num] = int64(v)
+		}
+	}
+	if len(data) < pos+def.devSize {
+		return m, 0, ErrTruncated
+	}
+	return m, pos + def.devSize, nil
+}
@@ -0,0 +1,116 @@
+package fit_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/fit"
+	"github.com/stretchr/testify/assert"
+)
+
+var testStart = time.Date(2019, 11, 20, 8, 0, 0, 0, time.UTC)
+
+func decodeFile(t *testing.T, filename string) *fit.Activity {
+	data, err := ioutil.ReadFile(filename)
+	assert.NoError(t, err)
+	a, err := fit.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	return a
+}
+
+func TestCRC(t *testing.T) {
+	assert.Equal(t, uint16(0), fit.CRC(nil))
+	assert.Equal(t, uint16(0xBB3D), fit.CRC([]byte("123456789")))
+}
+
+func TestDecodeRun(t *testing.T) {
+	a := decodeFile(t, "testdata/run.fit")
+	if a == nil {
+		return
+	}
+	assert.Equal(t, testStart.Add(620*time.Second), a.Timestamp)
+
+	if assert.Equal(t, 1, len(a.Sessions)) {
+		s := a.Sessions[0]
+		assert.Equal(t, "running", s.SportName())
+		assert.Equal(t, testStart, s.Start)
+		assert.Equal(t, 620*time.Second, s.ElapsedTime)
+		assert.Equal(t, 600*time.Second, s.TimerTime)
+		assert.Equal(t, 1800.0, s.DistanceM)
+		assert.Equal(t, 12.0, s.TotalAscentM)
+		assert.Equal(t, 130, s.Calories)
+		assert.Equal(t, 140, s.AvgHeartRate)
+		assert.Equal(t, 160, s.MaxHeartRate)
+		assert.Equal(t, 81, s.AvgCadence)
+		assert.Equal(t, 290, s.MaxPower)
+		assert.Equal(t, 2, len(a.LapsOf(&s)))
+	}
+
+	// The laps are stored big endian
+	if assert.Equal(t, 2, len(a.Laps)) {
+		l := a.Laps[1]
+		assert.Equal(t, testStart.Add(300*time.Second), l.Start)
+		assert.Equal(t, 900.0, l.DistanceM)
+		assert.Equal(t, 7.0, l.TotalAscentM)
+		assert.Equal(t, 150, l.AvgHeartRate)
+		assert.Equal(t, 250, l.AvgPower)
+		assert.Equal(t, 86, l.MaxCadence)
+	}
+
+	// Every tenth record uses a compressed timestamp header
+	if assert.Equal(t, 61, len(a.Records)) {
+		for i, r := range a.Records {
+			assert.Equal(t, testStart.Add(time.Duration(10*i)*time.Second), r.Time, "record %d", i)
+		}
+		r := a.Records[60]
+		assert.True(t, r.HasPosition)
+		assert.InDelta(t, 45.0162, r.Lat, 0.00001)
+		assert.InDelta(t, -122.0, r.Lon, 0.00001)
+		assert.True(t, r.HasAltitude)
+		assert.InDelta(t, 22.0, r.AltitudeM, 0.2)
+		assert.Equal(t, 160, r.HeartRate)
+		assert.Equal(t, 260, r.Power)
+		assert.Equal(t, 1800.0, r.DistanceM)
+		assert.Equal(t, 3.0, r.SpeedMPS)
+	}
+}
+
+func TestDecodeMultisport(t *testing.T) {
+	a := decodeFile(t, "testdata/brick.fit")
+	if a == nil || !assert.Equal(t, 2, len(a.Sessions)) {
+		return
+	}
+	assert.Equal(t, "cycling", a.Sessions[0].SportName())
+	assert.Equal(t, "running", a.Sessions[1].SportName())
+	laps := a.LapsOf(&a.Sessions[1])
+	if assert.Equal(t, 1, len(laps)) {
+		assert.Equal(t, 1000.0, laps[0].DistanceM)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/run.fit")
+	assert.NoError(t, err)
+
+	_, err = fit.Decode(bytes.NewReader(data[:10]))
+	assert.Equal(t, fit.ErrHeader, err)
+	_, err = fit.Decode(bytes.NewReader(data[:len(data)-10]))
+	assert.Equal(t, fit.ErrTruncated, err)
+
+	corrupt := append([]byte{}, data...)
+	corrupt[100] ^= 0xFF
+	_, err = fit.Decode(bytes.NewReader(corrupt))
+	assert.Equal(t, fit.ErrCRC, err)
+
+	notFit := append([]byte{}, data...)
+	copy(notFit[8:12], "GPX!")
+	_, err = fit.Decode(bytes.NewReader(notFit))
+	assert.Equal(t, fit.ErrHeader, err)
+}
+
+func TestSportName(t *testing.T) {
+	assert.Equal(t, "walking", fit.SportName(11))
+	assert.Equal(t, "generic", fit.SportName(254))
+}
@@ -0,0 +1,42 @@
+//go:build go1.18
+// +build go1.18
+
+package fit
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzDecode ensures malformed files are rejected without panicking. The
+// CRC checks are disabled so that mutations reach the message decoder.
+func FuzzDecode(f *testing.F) {
+	files, err := filepath.Glob("testdata/*.fit")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, name := range files {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{14, 0x20, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T', 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, checkCRC := range []bool{true, false} {
+			a, err := decode(data, checkCRC)
+			if err != nil {
+				if a != nil {
+					t.Fatalf("activity returned with error %s", err)
+				}
+				continue
+			}
+			for i := range a.Sessions {
+				a.LapsOf(&a.Sessions[i])
+				a.Sessions[i].SportName()
+			}
+		}
+	})
+}
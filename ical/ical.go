@@ -0,0 +1,390 @@
+// Package ical encodes and decodes the events of iCalendar (RFC 5545) files,
+// as published and imported by calendar applications.
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxLineOctets the longest content line, excluding the line break, that is
+// written before the line is folded
+const MaxLineOctets = 75
+
+// The layouts of the DATE and DATE-TIME values of a calendar
+const (
+	DateLayout     = "20060102"
+	DateTimeLayout = "20060102T150405"
+	UTCLayout      = "20060102T150405Z"
+)
+
+// StatusCancelled the status of an event that has been cancelled
+const StatusCancelled = "CANCELLED"
+
+// ErrNotCalendar is returned when the data does not hold a VCALENDAR
+var ErrNotCalendar = errors.New("data is not an iCalendar file")
+
+// Event a VEVENT of a calendar. All day events start at midnight UTC of
+// their first date and end at midnight UTC following their last date.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Summary     string
+	Description string
+	Status      string
+	// Line the line the event begins on in the decoded data
+	Line int
+}
+
+// Error describes why an event of a decoded calendar was rejected
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Calendar the events of a calendar. Stamp is the time the calendar was
+// created, it is written as the DTSTAMP of each event. Errors holds the
+// events of a decoded calendar that could not be parsed.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Stamp  time.Time
+	Events []Event
+	Errors []*Error
+}
+
+// escaper escapes the special characters of a TEXT value
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// unescaper reverses the escaping of a TEXT value
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// writer writes folded content lines, the first error is retained
+type writer struct {
+	w   io.Writer
+	err error
+}
+
+// line write a content line, folding it at MaxLineOctets without splitting
+// a UTF-8 encoded character
+func (w *writer) line(name string, value string) {
+	if w.err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	line := name + ":" + value
+	width := MaxLineOctets
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards its length
+		width = MaxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+	_, w.err = w.w.Write(buf.Bytes())
+}
+
+// time write a DATE or UTC DATE-TIME property
+func (w *writer) time(name string, t time.Time, allDay bool) {
+	if allDay {
+		w.line(name+";VALUE=DATE", t.UTC().Format(DateLayout))
+		return
+	}
+	w.line(name, t.UTC().Format(UTCLayout))
+}
+
+// Encode write the calendar in iCalendar format
+func Encode(out io.Writer, c *Calendar) error {
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	w := writer{w: out}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", c.ProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if len(c.Name) > 0 {
+		w.line("X-WR-CALNAME", escaper.Replace(c.Name))
+	}
+	for _, e := range c.Events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", e.UID)
+		w.time("DTSTAMP", stamp, false)
+		w.time("DTSTART", e.Start, e.AllDay)
+		if e.End.After(e.Start) {
+			w.time("DTEND", e.End, e.AllDay)
+		}
+		w.line("SUMMARY", escaper.Replace(e.Summary))
+		if len(e.Description) > 0 {
+			w.line("DESCRIPTION", escaper.Replace(e.Description))
+		}
+		if len(e.Status) > 0 {
+			w.line("STATUS", e.Status)
+		}
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.err
+}
+
+// property a content line of a calendar
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+	line   int
+}
+
+// parseProperty split a content line into its name, parameters and value
+func parseProperty(s string, line int) (*property, error) {
+	p := property{params: map[string]string{}, line: line}
+	quoted := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';' || r == ':':
+			field := s[start:i]
+			if start == 0 {
+				p.name = strings.ToUpper(field)
+			} else if eq := strings.IndexByte(field, '='); eq > 0 {
+				p.params[strings.ToUpper(field[:eq])] = strings.Trim(field[eq+1:], `"`)
+			}
+			start = i + 1
+			if r == ':' {
+				p.value = s[i+1:]
+				return &p, nil
+			}
+		}
+	}
+	return nil, &Error{line, fmt.Sprintf("malformed content line '%s'", s)}
+}
+
+// contentLines unfold the lines of the data, returning each content line
+// along with the line it begins on
+func contentLines(r io.Reader) ([]*property, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	var numbers []int
+	n := 0
+	for scanner.Scan() {
+		n++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) > 0 && (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += text[1:]
+			continue
+		}
+		if len(strings.TrimSpace(text)) == 0 {
+			continue
+		}
+		lines = append(lines, text)
+		numbers = append(numbers, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	props := make([]*property, 0, len(lines))
+	for i, text := range lines {
+		p, err := parseProperty(text, numbers[i])
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, p)
+	}
+	return props, nil
+}
+
+// parseTime parse a DATE or DATE-TIME value. Times without a time zone are
+// floating times interpreted in loc.
+func parseTime(p *property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(value) == len(DateLayout) {
+		t, err := time.Parse(DateLayout, value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(UTCLayout, value)
+		return t, false, err
+	}
+	if tzid, ok := p.params["TZID"]; ok {
+		tz, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown time zone '%s'", tzid)
+		}
+		loc = tz
+	}
+	t, err := time.ParseInLocation(DateTimeLayout, value, loc)
+	return t, false, err
+}
+
+// durationPattern the parts of a DURATION value, ie P1DT2H30M or P2W
+var durationPattern = regexp.MustCompile(`^([+-]?)P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseDuration parse a DURATION value
+func ParseDuration(value string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if len(m[i+2]) > 0 {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// eventBuilder the properties of an event being decoded
+type eventBuilder struct {
+	event    Event
+	duration *time.Duration
+	err      *Error
+}
+
+// fail record the first error of the event
+func (b *eventBuilder) fail(line int, format string, args ...interface{}) {
+	if b.err == nil {
+		b.err = &Error{line, fmt.Sprintf(format, args...)}
+	}
+}
+
+// set apply a property to the event
+func (b *eventBuilder) set(p *property, loc *time.Location) {
+	var err error
+	switch p.name {
+	case "UID":
+		b.event.UID = strings.TrimSpace(p.value)
+	case "SUMMARY":
+		b.event.Summary = unescaper.Replace(p.value)
+	case "DESCRIPTION":
+		b.event.Description = unescaper.Replace(p.value)
+	case "STATUS":
+		b.event.Status = strings.ToUpper(strings.TrimSpace(p.value))
+	case "DTSTART":
+		if b.event.Start, b.event.AllDay, err = parseTime(p, loc); err != nil {
+			b.fail(p.line, "invalid DTSTART, %s", err)
+		}
+	case "DTEND":
+		if b.event.End, _, err = parseTime(p, loc); err != nil {
+			b.fail(p.line, "invalid DTEND, %s", err)
+		}
+	case "DURATION":
+		d, err := ParseDuration(p.value)
+		if err != nil {
+			b.fail(p.line, "%s", err)
+		}
+		b.duration = &d
+	}
+}
+
+// build validate the event, computing its end when not specified. An all
+// day event without an end lasts a day.
+func (b *eventBuilder) build() (*Event, *Error) {
+	e := &b.event
+	if b.err != nil {
+		return nil, b.err
+	}
+	if e.Start.IsZero() {
+		return nil, &Error{e.Line, "event has no DTSTART"}
+	}
+	switch {
+	case !e.End.IsZero():
+	case b.duration != nil:
+		e.End = e.Start.Add(*b.duration)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+	if e.End.Before(e.Start) {
+		return nil, &Error{e.Line, "event ends before it starts"}
+	}
+	e.Start, e.End = e.Start.UTC(), e.End.UTC()
+	return e, nil
+}
+
+// Decode read the events of an iCalendar file. Floating times, those without
+// a time zone, are interpreted in loc. Events that can not be parsed are
+// reported in the Errors of the calendar, the properties of components
+// nested within an event, such as alarms, are ignored.
+func Decode(r io.Reader, loc *time.Location) (*Calendar, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !bytes.HasPrefix(bytes.ToUpper(bytes.TrimSpace(data)), []byte("BEGIN:VCALENDAR")) {
+		return nil, ErrNotCalendar
+	}
+	props, err := contentLines(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	c := Calendar{Events: []Event{}}
+	var event *eventBuilder
+	depth := 0
+	for _, p := range props {
+		switch p.name {
+		case "BEGIN":
+			depth++
+			if depth == 2 && strings.ToUpper(p.value) == "VEVENT" {
+				event = &eventBuilder{event: Event{Line: p.line}}
+			}
+		case "END":
+			depth--
+			if depth == 1 && event != nil {
+				if e, err := event.build(); err != nil {
+					c.Errors = append(c.Errors, err)
+				} else {
+					c.Events = append(c.Events, *e)
+				}
+				event = nil
+			}
+		default:
+			switch {
+			case depth == 1 && p.name == "PRODID":
+				c.ProdID = p.value
+			case depth == 1 && p.name == "X-WR-CALNAME":
+				c.Name = unescaper.Replace(p.value)
+			case depth == 2 && event != nil:
+				event.set(p, loc)
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("calendar is truncated, %d components are not ended", depth)
+	}
+	return &c, nil
+}
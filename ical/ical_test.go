@@ -0,0 +1,141 @@
+package ical_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/ical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	f, err := os.Open("testdata/training.ics")
+	assert.NoError(t, err)
+	defer f.Close()
+	c, err := ical.Decode(f, time.UTC)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Training", c.Name)
+	if !assert.Equal(t, 4, len(c.Events)) {
+		return
+	}
+	tempo := c.Events[0]
+	assert.Equal(t, "tempo-20191202@example.com", tempo.UID)
+	assert.Equal(t, time.Date(2019, 12, 2, 14, 30, 0, 0, time.UTC), tempo.Start)
+	assert.Equal(t, time.Hour, tempo.End.Sub(tempo.Start))
+	assert.Equal(t, "Tempo run, 8 km", tempo.Summary)
+	assert.Equal(t, "Warm up 2 km\nTempo 4 km at threshold pace\nCool down 2 km that is folded onto a second line",
+		tempo.Description)
+	assert.Equal(t, 13, tempo.Line)
+
+	long := c.Events[1]
+	assert.Equal(t, 105*time.Minute, long.End.Sub(long.Start))
+
+	rest := c.Events[2]
+	assert.True(t, rest.AllDay)
+	assert.Equal(t, time.Date(2019, 12, 8, 0, 0, 0, 0, time.UTC), rest.Start)
+	assert.Equal(t, time.Date(2019, 12, 9, 0, 0, 0, 0, time.UTC), rest.End)
+
+	swim := c.Events[3]
+	assert.Equal(t, ical.StatusCancelled, swim.Status)
+	assert.Equal(t, time.Date(2019, 12, 3, 18, 0, 0, 0, time.UTC), swim.Start)
+
+	if assert.Equal(t, 1, len(c.Errors)) {
+		assert.Equal(t, 51, c.Errors[0].Line)
+	}
+}
+
+func TestDecodeFloatingTime(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:20191203T180000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	c, err := ical.Decode(strings.NewReader(data), loc)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(c.Events)) {
+		assert.Equal(t, time.Date(2019, 12, 3, 17, 0, 0, 0, time.UTC), c.Events[0].Start)
+		assert.Equal(t, c.Events[0].Start, c.Events[0].End)
+	}
+}
+
+func TestDecodeFailures(t *testing.T) {
+	_, err := ical.Decode(strings.NewReader("name,start\nrun,2019-12-03"), time.UTC)
+	assert.Equal(t, ical.ErrNotCalendar, err)
+	_, err = ical.Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20191203T180000Z\n"), time.UTC)
+	assert.Error(t, err)
+
+	c, err := ical.Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:No start\nEND:VEVENT\n"+
+		"BEGIN:VEVENT\nDTSTART:20191203T180000Z\nDTEND:20191203T170000Z\nEND:VEVENT\nEND:VCALENDAR\n"), time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(c.Events))
+	assert.Equal(t, 2, len(c.Errors))
+}
+
+func TestParseDuration(t *testing.T) {
+	testData := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"PT15M", 15 * time.Minute},
+		{"P1DT2H30M", 26*time.Hour + 30*time.Minute},
+		{"P2W", 14 * 24 * time.Hour},
+		{"-PT30S", -30 * time.Second},
+	}
+	for _, td := range testData {
+		d, err := ical.ParseDuration(td.value)
+		assert.NoError(t, err)
+		assert.Equal(t, td.expected, d, td.value)
+	}
+	for _, value := range []string{"P", "PT", "1H", "P1H"} {
+		_, err := ical.ParseDuration(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	c := ical.Calendar{
+		ProdID: "-//enpointe//activity//EN",
+		Name:   "Workouts",
+		Stamp:  time.Date(2019, 11, 20, 12, 0, 0, 0, time.UTC),
+		Events: []ical.Event{
+			{
+				UID:         "5dc2ee5a567855de21f1070a@activity",
+				Start:       time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC),
+				End:         time.Date(2019, 11, 20, 19, 15, 0, 0, time.UTC),
+				Summary:     "Squat, Running",
+				Description: strings.Repeat("Squat 5 x 5 at 100 kg; ", 5) + "\nRunning 5 km",
+			},
+			{
+				UID:     "5de3b7d2f4b2a3c5d9e0f1c5-1-1@activity",
+				Start:   time.Date(2019, 12, 2, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2019, 12, 3, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+				Summary: "Couch to 5k: Easy run",
+			},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, ical.Encode(&buf, &c))
+	data := buf.String()
+	assert.Contains(t, data, "DTSTART:20191120T183000Z\r\n")
+	assert.Contains(t, data, "DTSTART;VALUE=DATE:20191202\r\n")
+	assert.Contains(t, data, "DTSTAMP:20191120T120000Z\r\n")
+	assert.Contains(t, data, "SUMMARY:Squat\\, Running\r\n")
+	for _, line := range strings.Split(data, "\r\n") {
+		assert.True(t, len(line) <= ical.MaxLineOctets, line)
+	}
+
+	// The encoded calendar decodes to the same events
+	decoded, err := ical.Decode(&buf, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, c.Name, decoded.Name)
+	if assert.Equal(t, 2, len(decoded.Events)) {
+		for i, e := range decoded.Events {
+			e.Line = 0
+			assert.Equal(t, c.Events[i], e)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/enpointe/activity/blob"
 	"github.com/enpointe/activity/controllers"
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
@@ -51,9 +52,23 @@ func main() {
 	defer catchFatal()
 	dbURI := flag.String("dbURI", "mongodb://localhost:27017",
 		"URI used to connect to the mongo database, default is mongodb://localhost:27017")
+	prefix := flag.String("prefix", "",
+		"Prefix to prepend to the name of each collection, allows multiple environments to share a database")
 	adminPasswd := flag.String("admin", "", "Create an admin user and assign it the specified password")
+	mediaDir := flag.String("media", controllers.DefaultMediaDir,
+		"Directory the images and animations of exercises are stored in")
+	s3Endpoint := flag.String("s3Endpoint", "",
+		"URL of an S3 compatible service to store the images and animations of exercises in rather than -media, "+
+			"credentials are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	s3Bucket := flag.String("s3Bucket", "", "Bucket of the S3 compatible service holding the media of exercises")
+	s3Region := flag.String("s3Region", "us-east-1", "Region of the S3 compatible service")
 	logLevel := flag.String(
 		"level", "warn", "The logging level to use (error, warn, info, debug, trace)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] [command]\n\nOptions:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\n%s", commandUsage)
+	}
 	flag.Parse()
 
 	level, err := logrus.ParseLevel(*logLevel)
@@ -67,7 +82,19 @@ func main() {
 	}
 
 	clientOptions := options.Client().ApplyURI(*dbURI)
-	sOptions := []controllers.ServerOption{controllers.DBOptions(clientOptions)}
+	sOptions := []controllers.ServerOption{controllers.DBOptions(clientOptions),
+		controllers.CollectionPrefix(*prefix)}
+	if len(*s3Endpoint) > 0 {
+		store, err := blob.NewS3Store(blob.S3Config{Endpoint: *s3Endpoint, Region: *s3Region, Bucket: *s3Bucket,
+			AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"), SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY")})
+		if err != nil {
+			fmt.Printf("invalid S3 configuration, %s\n", err)
+			os.Exit(-1)
+		}
+		sOptions = append(sOptions, controllers.MediaStore(store))
+	} else {
+		sOptions = append(sOptions, controllers.MediaStore(&blob.FileStore{Dir: *mediaDir}))
+	}
 	if len(*adminPasswd) > 0 {
 		sOptions = append(sOptions, controllers.CreateAdminUser([]byte(*adminPasswd)))
 	}
@@ -91,11 +118,26 @@ func main() {
 	}
 	log.SetLevel(level)
 	log.Debug("Starting HTTP Server")
+	args := flag.Args()
+	if len(args) > 0 {
+		// Run the requested command rather than starting the HTTP server.
+		// Commands don't require an admin user to be configured.
+		server, err := controllers.NewServerService(true, sOptions...)
+		if err != nil {
+			fmt.Printf("%s\n\n", err.Error())
+			os.Exit(-2)
+		}
+		code := runCommand(server, args)
+		server.Shutdown()
+		os.Exit(code)
+	}
+
 	server, err := controllers.NewServerService(false, sOptions...)
 	if err != nil {
 		fmt.Printf("%s\n\n", err.Error())
 		os.Exit(-2)
 	}
+	warnPendingMigrations(server)
 	router := httprouter.New()
 	router.POST("/login", server.Login)
 	router.POST("/logout", server.Logout)
@@ -104,6 +146,64 @@ func main() {
 	router.GET("/users", server.GetUsers)
 	router.GET("/users/:id", server.GetUser)
 	router.PATCH("/users/", server.UpdateUserPassword)
+	router.GET("/exercises", server.GetExercises)
+	router.GET("/exercises/search", server.SearchExercises)
+	router.POST("/exercises/merge", server.MergeExercises)
+	router.PUT("/users/:id/weight", server.UpdateBodyWeight)
+	router.PUT("/users/:id/heartrates", server.UpdateHeartRates)
+	router.POST("/users/:id/workouts", server.CreateWorkout)
+	router.GET("/users/:id/workouts", server.GetWorkouts)
+	router.GET("/users/:id/workouts/:workout", server.GetWorkout)
+	router.DELETE("/users/:id/workouts/:workout", server.DeleteWorkout)
+	router.GET("/users/:id/workouts/:workout/track", server.GetWorkoutTrack)
+	router.POST("/users/:id/tracks", server.UploadTrack)
+	router.POST("/users/:id/workouts/:workout/exercises", server.AddWorkoutExercise)
+	router.DELETE("/users/:id/workouts/:workout/exercises/:entry", server.RemoveWorkoutExercise)
+	router.PUT("/users/:id/workouts/:workout/exercises/:entry/cardio", server.UpdateCardio)
+	router.POST("/users/:id/workouts/:workout/exercises/:entry/sets", server.AddSet)
+	router.PUT("/users/:id/workouts/:workout/exercises/:entry/sets/:set", server.UpdateSet)
+	router.DELETE("/users/:id/workouts/:workout/exercises/:entry/sets/:set", server.RemoveSet)
+	router.GET("/users/:id/stats", server.GetUserStats)
+	router.GET("/users/:id/charts/:chart", server.GetUserChart)
+	router.PUT("/users/:id/streaks", server.UpdateStreakSettings)
+	router.GET("/users/:id/load", server.GetTrainingLoad)
+	router.GET("/users/:id/records", server.GetRecords)
+	router.GET("/users/:id/records/history", server.GetRecordHistory)
+	router.POST("/users/:id/goals", server.CreateGoal)
+	router.GET("/users/:id/goals", server.GetGoals)
+	router.GET("/users/:id/goals/:goal", server.GetGoal)
+	router.DELETE("/users/:id/goals/:goal", server.DeleteGoal)
+	router.POST("/users/:id/exercises", server.CreateUserExercise)
+	router.POST("/users/:id/exercises/:exercise/promote", server.PromoteUserExercise)
+	router.POST("/users/:id/templates", server.CreateTemplate)
+	router.GET("/users/:id/templates", server.GetTemplates)
+	router.GET("/users/:id/templates/:template", server.GetTemplate)
+	router.PUT("/users/:id/templates/:template", server.UpdateTemplate)
+	router.DELETE("/users/:id/templates/:template", server.DeleteTemplate)
+	router.POST("/users/:id/templates/:template/workouts", server.InstantiateTemplate)
+	router.POST("/plans", server.CreatePlan)
+	router.GET("/plans", server.GetPlans)
+	router.GET("/plans/:plan", server.GetPlan)
+	router.DELETE("/plans/:plan", server.DeletePlan)
+	router.POST("/users/:id/plans", server.AssignPlan)
+	router.GET("/users/:id/plans", server.GetAssignments)
+	router.DELETE("/users/:id/plans/:assignment", server.UnassignPlan)
+	router.GET("/users/:id/schedule", server.GetSchedule)
+	router.POST("/users/:id/calendar", server.ImportCalendar)
+	router.GET("/users/:id/calendar/events", server.GetScheduledEvents)
+	router.DELETE("/users/:id/calendar/events/:event", server.DeleteScheduledEvent)
+	router.POST("/users/:id/calendar/feed", server.CreateCalendarFeed)
+	router.DELETE("/users/:id/calendar/feed", server.RevokeCalendarFeed)
+	router.GET("/calendar/:token", server.GetCalendarFeed)
+	router.POST("/media", server.AddMedia)
+	router.GET("/media/:media", server.GetMedia)
+	router.GET("/media/:media/thumbnail", server.GetMediaThumbnail)
+	router.DELETE("/media/:media", server.DeleteMedia)
+	router.GET("/translations/:exercise", server.GetTranslations)
+	router.PUT("/translations/:exercise/:locale", server.SetTranslation)
+	router.DELETE("/translations/:exercise/:locale", server.DeleteTranslation)
+	router.POST("/import", server.Import)
+	router.GET("/export", server.Export)
 
 	// programatically set swagger info
 	docs.SwaggerInfo.Title = "Activity API"
@@ -0,0 +1,151 @@
+// Package media validates the images and animations uploaded for exercises
+// and generates their thumbnails, using only the standard library decoders
+// for PNG, JPEG and GIF images.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// The kinds of media of an exercise, still images, animations and links to videos
+const (
+	KindImage     = "image"
+	KindAnimation = "animation"
+	KindVideo     = "video"
+)
+
+// ThumbnailSize the largest width and height, in pixels, of a thumbnail
+const ThumbnailSize = 256
+
+// MaxPixels the largest number of pixels of an image accepted, guarding
+// against small files that decode to huge images
+const MaxPixels = 40000000
+
+// thumbnailQuality the JPEG quality of the thumbnails of JPEG images
+const thumbnailQuality = 85
+
+// ErrUnsupported is returned for content that is not a PNG, JPEG or GIF image
+var ErrUnsupported = errors.New("unsupported media, must be a PNG, JPEG or GIF image")
+
+// Image an uploaded image along with its thumbnail. Animated GIFs are of
+// kind KindAnimation, their thumbnail is of the first frame.
+type Image struct {
+	Kind                 string
+	ContentType          string
+	Width                int
+	Height               int
+	Thumbnail            []byte
+	ThumbnailContentType string
+}
+
+// Process validate the uploaded image and generate its thumbnail. The type of
+// the content is determined from the data rather than trusting the client.
+func Process(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/png", "image/jpeg", "image/gif":
+	default:
+		return nil, ErrUnsupported
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image, %s", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, fmt.Errorf("image of %dx%d pixels exceeds %d pixels", config.Width, config.Height, MaxPixels)
+	}
+	result := Image{Kind: KindImage, ContentType: contentType, Width: config.Width, Height: config.Height}
+	var img image.Image
+	if contentType == "image/gif" {
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid image, %s", err)
+		}
+		if len(anim.Image) > 1 {
+			result.Kind = KindAnimation
+		}
+		img = anim.Image[0]
+	} else if img, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("invalid image, %s", err)
+	}
+
+	var thumbnail bytes.Buffer
+	if contentType == "image/jpeg" {
+		result.ThumbnailContentType = "image/jpeg"
+		err = jpeg.Encode(&thumbnail, Thumbnail(img, ThumbnailSize), &jpeg.Options{Quality: thumbnailQuality})
+	} else {
+		result.ThumbnailContentType = "image/png"
+		err = png.Encode(&thumbnail, Thumbnail(img, ThumbnailSize))
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Thumbnail = thumbnail.Bytes()
+	return &result, nil
+}
+
+// Thumbnail scale the image down to fit within size pixels, preserving its
+// aspect ratio. Each pixel of the thumbnail is the average of the pixels of
+// the image it covers. Images that already fit are returned as is.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	tw, th := size, h*size/w
+	if h > w {
+		tw, th = w*size/h, size
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+	thumb := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+(y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+(x+1)*w/tw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(img.At(sx, sy)).(color.NRGBA64)
+					// Weight the colors by their opacity so transparent pixels do not darken the result
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					bl += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+			if a > 0 {
+				thumb.SetNRGBA(x, y, color.NRGBA{uint8(r / a >> 8), uint8(g / a >> 8), uint8(bl / a >> 8),
+					uint8(a / n >> 8)})
+			}
+		}
+	}
+	return thumb
+}
+
+// VideoLink validate the link to a video of an exercise, it must be an
+// absolute http or https URL
+func VideoLink(link string) (string, error) {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid video link '%s', must be an http or https URL", link)
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,93 @@
+package media_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/enpointe/activity/media"
+	"github.com/stretchr/testify/assert"
+)
+
+// testImage a solid image of the size and color
+func testImage(w int, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestThumbnail(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	thumb := media.Thumbnail(testImage(1024, 512, red), media.ThumbnailSize)
+	assert.Equal(t, image.Rect(0, 0, 256, 128), thumb.Bounds())
+	assert.Equal(t, red, color.NRGBAModel.Convert(thumb.At(100, 100)))
+	thumb = media.Thumbnail(testImage(300, 3000, red), media.ThumbnailSize)
+	assert.Equal(t, image.Rect(0, 0, 25, 256), thumb.Bounds())
+
+	small := testImage(64, 32, red)
+	assert.Equal(t, small, media.Thumbnail(small, media.ThumbnailSize))
+
+	// Transparent pixels do not darken the average
+	img := testImage(512, 512, color.NRGBA{})
+	for y := 0; y < 512; y++ {
+		for x := 0; x < 512; x += 2 {
+			img.Set(x, y, red)
+		}
+	}
+	c := color.NRGBAModel.Convert(media.Thumbnail(img, media.ThumbnailSize).At(10, 10)).(color.NRGBA)
+	assert.Equal(t, uint8(255), c.R)
+	assert.InDelta(t, 127, int(c.A), 1)
+}
+
+func TestProcess(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, testImage(800, 600, color.White), nil))
+	img, err := media.Process(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, media.KindImage, img.Kind)
+	assert.Equal(t, "image/jpeg", img.ContentType)
+	assert.Equal(t, 800, img.Width)
+	assert.Equal(t, "image/jpeg", img.ThumbnailContentType)
+	thumb, _, err := image.Decode(bytes.NewReader(img.Thumbnail))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 256, 192), thumb.Bounds())
+
+	buf.Reset()
+	assert.NoError(t, png.Encode(&buf, testImage(10, 10, color.Black)))
+	img, err = media.Process(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", img.ThumbnailContentType)
+
+	// Animated GIFs
+	frame := image.NewPaletted(image.Rect(0, 0, 20, 20), palette.Plan9)
+	buf.Reset()
+	assert.NoError(t, gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{frame, frame}, Delay: []int{10, 10}}))
+	img, err = media.Process(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, media.KindAnimation, img.Kind)
+	assert.Equal(t, "image/gif", img.ContentType)
+
+	_, err = media.Process([]byte("<html><body>not an image</body></html>"))
+	assert.Equal(t, media.ErrUnsupported, err)
+	_, err = media.Process(buf.Bytes()[:20])
+	assert.Error(t, err)
+}
+
+func TestVideoLink(t *testing.T) {
+	link, err := media.VideoLink(" https://www.youtube.com/watch?v=IODxDxX7oi4 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.youtube.com/watch?v=IODxDxX7oi4", link)
+	for _, bad := range []string{"", "javascript:alert(1)", "/videos/squat.mp4", "ftp://example.com/squat"} {
+		_, err = media.VideoLink(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,299 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/enpointe/activity/models/db"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrationsCollection name of the collection used to record the
+// migrations that have been applied to the database
+const MigrationsCollection = "migrations"
+
+// LockCollection name of the collection used to hold the lock that
+// prevents more than one migration runner operating at the same time
+const LockCollection = "migrations_lock"
+
+// lockID the identifier of the single lock document
+const lockID = "lock"
+
+// LockExpiry the length of time after which a lock held by a runner
+// that failed to release it is considered stale and can be taken over
+const LockExpiry = 10 * time.Minute
+
+// Env the environment a migration is applied to. Prefix is the
+// collection name prefix in use for the environment, see db.CollectionPrefix.
+type Env struct {
+	Database *mongo.Database
+	Prefix   string
+}
+
+// Options returns the options used to create services for the environment
+func (e Env) Options() []db.ServiceOption {
+	return []db.ServiceOption{db.CollectionPrefix(e.Prefix)}
+}
+
+// Collection returns the named collection with the environment's prefix applied
+func (e Env) Collection(name string) *mongo.Collection {
+	return e.Database.Collection(db.CollectionName(name, e.Options()...))
+}
+
+// Migration a single ordered change to the documents stored in the database.
+// Up applies the change, Down reverts it. Both steps must be idempotent
+// so that a runner interrupted part way through can simply be rerun.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, env Env) error
+	Down        func(ctx context.Context, env Env) error
+}
+
+// Status the state of a known migration
+type Status struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// record the document stored in the migrations collection for each
+// migration that has been applied
+type record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// registered the migrations known to this version of the server
+var registered []Migration
+
+// register adds a migration to the list of migrations known to the server.
+// This is intended to be invoked from the init() of the file defining the
+// migration.
+func register(m Migration) {
+	registered = append(registered, m)
+}
+
+// Registered returns the migrations known to this version of the server
+// ordered by version
+func Registered() []Migration {
+	migrations := make([]Migration, len(registered))
+	copy(migrations, registered)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations
+}
+
+// Migrator runs migrations against a database
+type Migrator struct {
+	env        Env
+	migrations []Migration
+	records    *mongo.Collection
+	lock       *mongo.Collection
+	owner      string
+}
+
+// New create a Migrator for the specified environment. If no migrations are
+// passed in the registered migrations are used. Migration versions must be
+// positive and unique.
+func New(env Env, migrations ...Migration) (*Migrator, error) {
+	if len(migrations) == 0 {
+		migrations = Registered()
+	}
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("invalid migration version %d, versions must be positive", m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		if m.Up == nil || m.Down == nil {
+			return nil, fmt.Errorf("migration %d must define both an Up and Down step", m.Version)
+		}
+	}
+	hostname, _ := os.Hostname()
+	return &Migrator{
+		env:        env,
+		migrations: sorted,
+		records:    env.Collection(MigrationsCollection),
+		lock:       env.Collection(LockCollection),
+		owner:      fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}, nil
+}
+
+// applied returns the records of the migrations applied to the database
+func (m *Migrator) applied(ctx context.Context) (map[int]record, error) {
+	cursor, err := m.records.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	results := make(map[int]record)
+	for cursor.Next(ctx) {
+		var r record
+		if err := cursor.Decode(&r); err != nil {
+			return nil, err
+		}
+		results[r.Version] = r
+	}
+	return results, cursor.Err()
+}
+
+// acquire obtain the migration lock. The lock is held in a single document,
+// the upsert fails with a duplicate key error if another runner holds a lock
+// that has not yet expired.
+func (m *Migrator) acquire(ctx context.Context) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id": lockID,
+		"$or": bson.A{
+			bson.M{"locked": false},
+			bson.M{"acquired_at": bson.M{"$lt": now.Add(-LockExpiry)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked": true, "owner": m.owner, "acquired_at": now}}
+	_, err := m.lock.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if db.IsDuplicateKeyError(err) {
+			return fmt.Errorf("migrations are locked by another runner")
+		}
+		return fmt.Errorf("failed to acquire migration lock, %s", err)
+	}
+	log.Debugf("migration lock acquired by %s", m.owner)
+	return nil
+}
+
+// release give up the migration lock
+func (m *Migrator) release(ctx context.Context) {
+	filter := bson.M{"_id": lockID, "owner": m.owner}
+	update := bson.M{"$set": bson.M{"locked": false}}
+	if _, err := m.lock.UpdateOne(ctx, filter, update); err != nil {
+		log.Errorf("failed to release migration lock, %s", err)
+	}
+}
+
+// Up apply all pending migrations with a version less than or equal to target.
+// A target of zero applies all pending migrations. Returns the versions applied.
+func (m *Migrator) Up(ctx context.Context, target int) ([]int, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer m.release(ctx)
+
+	done, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, mg := range m.migrations {
+		if target > 0 && mg.Version > target {
+			break
+		}
+		if _, ok := done[mg.Version]; ok {
+			continue
+		}
+		log.Infof("applying migration %d, %s", mg.Version, mg.Description)
+		if err := mg.Up(ctx, m.env); err != nil {
+			return versions, fmt.Errorf("migration %d failed, %s", mg.Version, err)
+		}
+		r := record{Version: mg.Version, Description: mg.Description, AppliedAt: time.Now().UTC()}
+		_, err := m.records.ReplaceOne(ctx, bson.M{"_id": mg.Version}, r, options.Replace().SetUpsert(true))
+		if err != nil {
+			return versions, fmt.Errorf("failed to record migration %d, %s", mg.Version, err)
+		}
+		versions = append(versions, mg.Version)
+	}
+	return versions, nil
+}
+
+// Down revert all applied migrations with a version greater than target,
+// newest first. Returns the versions reverted.
+func (m *Migrator) Down(ctx context.Context, target int) ([]int, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer m.release(ctx)
+
+	done, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if mg.Version <= target {
+			break
+		}
+		if _, ok := done[mg.Version]; !ok {
+			continue
+		}
+		log.Infof("reverting migration %d, %s", mg.Version, mg.Description)
+		if err := mg.Down(ctx, m.env); err != nil {
+			return versions, fmt.Errorf("revert of migration %d failed, %s", mg.Version, err)
+		}
+		if _, err := m.records.DeleteOne(ctx, bson.M{"_id": mg.Version}); err != nil {
+			return versions, fmt.Errorf("failed to remove record of migration %d, %s", mg.Version, err)
+		}
+		versions = append(versions, mg.Version)
+	}
+	return versions, nil
+}
+
+// Status report the state of each known migration
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var results []Status
+	for _, mg := range m.migrations {
+		s := Status{Version: mg.Version, Description: mg.Description}
+		if r, ok := done[mg.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = r.AppliedAt
+		}
+		results = append(results, s)
+	}
+	return results, nil
+}
+
+// Current returns the version of the most recently applied migration,
+// zero if no migrations have been applied
+func (m *Migrator) Current(ctx context.Context) (int, error) {
+	done, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for v := range done {
+		if v > current {
+			current = v
+		}
+	}
+	return current, nil
+}
+
+// Pending returns the number of known migrations that have not been applied
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, s := range status {
+		if !s.Applied {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
@@ -0,0 +1,191 @@
+package migrate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/migrate"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const testDatabaseURL = "mongodb://localhost:27017"
+const testDatabase string = "testMigrate"
+
+// setup create a connection to the test database and drop any
+// existing content
+func setup(t *testing.T) *mongo.Database {
+	ctx := context.TODO()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(testDatabaseURL))
+	assert.NoError(t, err)
+	database := client.Database(testDatabase)
+	err = database.Drop(ctx)
+	assert.NoError(t, err)
+	return database
+}
+
+// teardown drop the test database
+func teardown(t *testing.T, database *mongo.Database) {
+	err := database.Drop(context.TODO())
+	assert.NoError(t, err)
+}
+
+// counter returns a migration that increments the value of the
+// document identified by the version in the "counters" collection
+func counter(version int) migrate.Migration {
+	filter := bson.M{"_id": version}
+	return migrate.Migration{
+		Version:     version,
+		Description: "counter",
+		Up: func(ctx context.Context, env migrate.Env) error {
+			_, err := env.Collection("counters").UpdateOne(ctx, filter,
+				bson.M{"$inc": bson.M{"value": 1}}, options.Update().SetUpsert(true))
+			return err
+		},
+		Down: func(ctx context.Context, env migrate.Env) error {
+			_, err := env.Collection("counters").DeleteOne(ctx, filter)
+			return err
+		},
+	}
+}
+
+// counters return the number of documents in the "counters" collection
+func counters(t *testing.T, database *mongo.Database) int64 {
+	cnt, err := database.Collection("counters").CountDocuments(context.TODO(), bson.D{})
+	assert.NoError(t, err)
+	return cnt
+}
+
+func TestNewValidation(t *testing.T) {
+	client, err := mongo.NewClient(options.Client().ApplyURI(testDatabaseURL))
+	assert.NoError(t, err)
+	database := client.Database(testDatabase)
+
+	_, err = migrate.New(migrate.Env{Database: database}, counter(1), counter(2))
+	assert.NoError(t, err)
+
+	_, err = migrate.New(migrate.Env{Database: database}, counter(2), counter(1), counter(2))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate")
+
+	_, err = migrate.New(migrate.Env{Database: database}, counter(0))
+	assert.Error(t, err)
+
+	m := counter(1)
+	m.Down = nil
+	_, err = migrate.New(migrate.Env{Database: database}, m)
+	assert.Error(t, err)
+}
+
+func TestRegistered(t *testing.T) {
+	migrations := migrate.Registered()
+	for i := 1; i < len(migrations); i++ {
+		assert.True(t, migrations[i-1].Version < migrations[i].Version)
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	database := setup(t)
+	defer teardown(t, database)
+	ctx := context.TODO()
+
+	migrator, err := migrate.New(migrate.Env{Database: database}, counter(3), counter(1), counter(2))
+	assert.NoError(t, err)
+
+	// Apply up to and including version 2
+	versions, err := migrator.Up(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+	assert.Equal(t, int64(2), counters(t, database))
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pending)
+
+	// Apply the rest, already applied migrations are not rerun
+	versions, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, versions)
+	versions, err = migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+	current, err := migrator.Current(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, current)
+
+	status, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(status))
+	for _, s := range status {
+		assert.True(t, s.Applied)
+	}
+
+	// Revert back to version 1, newest first
+	versions, err = migrator.Down(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, versions)
+	assert.Equal(t, int64(1), counters(t, database))
+	current, err = migrator.Current(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, current)
+}
+
+func TestLock(t *testing.T) {
+	database := setup(t)
+	defer teardown(t, database)
+	ctx := context.TODO()
+
+	// Simulate another runner holding the lock
+	_, err := database.Collection(migrate.LockCollection).InsertOne(ctx,
+		bson.M{"_id": "lock", "locked": true, "owner": "other", "acquired_at": time.Now()})
+	assert.NoError(t, err)
+
+	migrator, err := migrate.New(migrate.Env{Database: database}, counter(1))
+	assert.NoError(t, err)
+	_, err = migrator.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "locked")
+	assert.Equal(t, int64(0), counters(t, database))
+
+	// A stale lock can be taken over
+	_, err = database.Collection(migrate.LockCollection).UpdateOne(ctx, bson.M{"_id": "lock"},
+		bson.M{"$set": bson.M{"acquired_at": time.Now().Add(-2 * migrate.LockExpiry)}})
+	assert.NoError(t, err)
+	versions, err := migrator.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, versions)
+}
+
+// TestExerciseCollectionMigration ensure exercises stored in the legacy
+// testdata/exercises collection are moved to the exercises collection
+func TestExerciseCollectionMigration(t *testing.T) {
+	database := setup(t)
+	defer teardown(t, database)
+	ctx := context.TODO()
+
+	env := migrate.Env{Database: database, Prefix: "test_"}
+	legacy := database.Collection("testdata/exercises")
+	_, err := legacy.InsertOne(ctx, bson.M{"name": "Squat"})
+	assert.NoError(t, err)
+
+	migrator, err := migrate.New(env)
+	assert.NoError(t, err)
+	_, err = migrator.Up(ctx, 1)
+	assert.NoError(t, err)
+	cnt, err := env.Collection(db.ExerciseCollection).CountDocuments(ctx, bson.M{"name": "Squat"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), cnt)
+	cnt, err = legacy.CountDocuments(ctx, bson.D{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), cnt)
+
+	// Reverting moves the exercises back
+	_, err = migrator.Down(ctx, 0)
+	assert.NoError(t, err)
+	cnt, err = legacy.CountDocuments(ctx, bson.M{"name": "Squat"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), cnt)
+}
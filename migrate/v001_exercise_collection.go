@@ -0,0 +1,55 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyExerciseCollection the name of the collection exercises were
+// stored in prior to migration 1. Collection prefixes were not supported
+// at the time so the name is used as is.
+const legacyExerciseCollection = "testdata/exercises"
+
+func init() {
+	register(Migration{
+		Version:     1,
+		Description: "move exercises out of the testdata/exercises collection",
+		Up: func(ctx context.Context, env Env) error {
+			return moveDocuments(ctx, env.Database.Collection(legacyExerciseCollection),
+				env.Collection(db.ExerciseCollection))
+		},
+		Down: func(ctx context.Context, env Env) error {
+			return moveDocuments(ctx, env.Collection(db.ExerciseCollection),
+				env.Database.Collection(legacyExerciseCollection))
+		},
+	})
+}
+
+// moveDocuments move all documents from one collection to another and drop
+// the source collection. Documents are upserted by ID so an interrupted
+// move can be safely rerun.
+func moveDocuments(ctx context.Context, from *mongo.Collection, to *mongo.Collection) error {
+	cursor, err := from.Find(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		_, err := to.ReplaceOne(ctx, bson.M{"_id": doc["_id"]}, doc, options.Replace().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return from.Drop(ctx)
+}
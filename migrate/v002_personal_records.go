@@ -0,0 +1,30 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	log "github.com/sirupsen/logrus"
+)
+
+func init() {
+	register(Migration{
+		Version:     2,
+		Description: "compute the personal records of existing workout sessions",
+		Up: func(ctx context.Context, env Env) error {
+			service, err := db.NewWorkoutService(env.Database, env.Options()...)
+			if err != nil {
+				return err
+			}
+			cnt, err := service.RebuildRecords(ctx)
+			if err != nil {
+				return err
+			}
+			log.Infof("stored %d personal records", cnt)
+			return nil
+		},
+		Down: func(ctx context.Context, env Env) error {
+			return env.Collection(db.RecordsCollection).Drop(ctx)
+		},
+	})
+}
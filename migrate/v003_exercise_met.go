@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// exerciseMET the MET values of an exercise of schema/exercise.json
+type exerciseMET struct {
+	met         float64
+	intensities map[string]float64
+}
+
+// calisthenicsMET the MET values of calisthenics performed at each intensity
+var calisthenicsMET = map[string]float64{
+	db.IntensityLight: 2.8, db.IntensityModerate: 3.8, db.IntensityVigorous: 8.0,
+}
+
+// seededMETs the MET values of the exercises of schema/exercise.json, taken
+// from the Compendium of Physical Activities
+var seededMETs = map[string]exerciseMET{
+	"5dab53b371aab123354e5cab": {8.0, calisthenicsMET}, // Jumping Jack
+	"5dab544d71aab123354e5cad": {3.8, calisthenicsMET}, // Sit-Up
+	"5dab5f8871aab123354e5cb5": {3.8, calisthenicsMET}, // Lunge
+	"5dab5fa871aab123354e5cb6": {5.0, map[string]float64{ // Squat
+		db.IntensityLight: 3.5, db.IntensityModerate: 5.0, db.IntensityVigorous: 8.0}},
+	"5dab5fb871aab123354e5cb7": {8.0, nil},             // Burpee
+	"5dab5fc571aab123354e5cb8": {3.8, nil},             // Side Plank
+	"5db8dc8fee74c3c19010b4f4": {3.8, calisthenicsMET}, // Pushup
+	"5db8dd99ee74c3c19010b4f5": {3.5, map[string]float64{ // walking
+		db.IntensityLight: 2.8, db.IntensityModerate: 3.5, db.IntensityVigorous: 5.0}},
+	"5db8ddabee74c3c19010b4f6": {9.8, map[string]float64{ // running
+		db.IntensityLight: 8.3, db.IntensityModerate: 9.8, db.IntensityVigorous: 11.8}},
+	"5db8ddbfee74c3c19010b4f7": {3.5, map[string]float64{ // weightlifting
+		db.IntensityLight: 3.5, db.IntensityModerate: 5.0, db.IntensityVigorous: 6.0}},
+	"5db8ddcdee74c3c19010b4f8": {5.0, map[string]float64{ // dancing
+		db.IntensityLight: 3.0, db.IntensityModerate: 5.0, db.IntensityVigorous: 7.8}},
+}
+
+func init() {
+	register(Migration{
+		Version:     3,
+		Description: "seed the MET values of the standard exercises",
+		Up: func(ctx context.Context, env Env) error {
+			for hexid, m := range seededMETs {
+				id, _ := primitive.ObjectIDFromHex(hexid)
+				set := bson.M{"met": m.met}
+				if m.intensities != nil {
+					set["intensities"] = m.intensities
+				}
+				// Values already set, ie by an import, are left as is
+				_, err := env.Collection(db.ExerciseCollection).UpdateOne(ctx,
+					bson.M{"_id": id, "met": bson.M{"$exists": false}}, bson.M{"$set": set})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, env Env) error {
+			ids := bson.A{}
+			for hexid := range seededMETs {
+				id, _ := primitive.ObjectIDFromHex(hexid)
+				ids = append(ids, id)
+			}
+			_, err := env.Collection(db.ExerciseCollection).UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}},
+				bson.M{"$unset": bson.M{"met": "", "intensities": ""}})
+			return err
+		},
+	})
+}
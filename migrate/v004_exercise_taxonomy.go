@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// exerciseTaxonomy the taxonomy of an exercise of schema/exercise.json
+type exerciseTaxonomy struct {
+	category    string
+	primary     []string
+	secondary   []string
+	equipment   []string
+	difficulty  string
+	measurement string
+}
+
+// bodyweight the equipment of exercises that require none
+var bodyweight = []string{"bodyweight"}
+
+// seededTaxonomies the taxonomy of the exercises of schema/exercise.json
+var seededTaxonomies = map[string]exerciseTaxonomy{
+	"5dab53b371aab123354e5cab": {db.CategoryCardio, []string{"cardiovascular"}, // Jumping Jack
+		[]string{"calves", "shoulders"}, bodyweight, db.DifficultyBeginner, db.MeasureReps},
+	"5dab544d71aab123354e5cad": {db.CategoryStrength, []string{"abdominals"}, // Sit-Up
+		[]string{"hip-flexors", "obliques"}, []string{"bodyweight", "mat"}, db.DifficultyBeginner, db.MeasureReps},
+	"5dab5f8871aab123354e5cb5": {db.CategoryStrength, []string{"quadriceps", "glutes"}, // Lunge
+		[]string{"hamstrings", "calves"}, bodyweight, db.DifficultyBeginner, db.MeasureReps},
+	"5dab5fa871aab123354e5cb6": {db.CategoryStrength, []string{"quadriceps", "glutes"}, // Squat
+		[]string{"hamstrings", "adductors", "lower-back"}, []string{"barbell"}, db.DifficultyIntermediate, db.MeasureReps},
+	"5dab5fb871aab123354e5cb7": {db.CategoryCardio, []string{"cardiovascular"}, // Burpee
+		[]string{"chest", "quadriceps", "shoulders", "triceps"}, bodyweight, db.DifficultyIntermediate, db.MeasureReps},
+	"5dab5fc571aab123354e5cb8": {db.CategoryStrength, []string{"obliques"}, // Side Plank
+		[]string{"abdominals", "shoulders"}, []string{"bodyweight", "mat"}, db.DifficultyBeginner, db.MeasureTime},
+	"5db8dc8fee74c3c19010b4f4": {db.CategoryStrength, []string{"chest", "triceps"}, // Pushup
+		[]string{"shoulders", "abdominals"}, bodyweight, db.DifficultyBeginner, db.MeasureReps},
+	"5db8dd99ee74c3c19010b4f5": {db.CategoryCardio, []string{"cardiovascular"}, // walking
+		[]string{"quadriceps", "calves"}, bodyweight, db.DifficultyBeginner, db.MeasureDistance},
+	"5db8ddabee74c3c19010b4f6": {db.CategoryCardio, []string{"cardiovascular"}, // running
+		[]string{"quadriceps", "hamstrings", "glutes", "calves"}, bodyweight, db.DifficultyIntermediate, db.MeasureDistance},
+	"5db8ddbfee74c3c19010b4f7": {db.CategoryStrength, nil, nil, // weightlifting
+		[]string{"barbell", "dumbbell"}, db.DifficultyIntermediate, db.MeasureReps},
+	"5db8ddcdee74c3c19010b4f8": {db.CategoryCardio, []string{"cardiovascular"}, nil, // dancing
+		bodyweight, db.DifficultyBeginner, db.MeasureTime},
+}
+
+func init() {
+	register(Migration{
+		Version:     4,
+		Description: "seed the taxonomy of the standard exercises",
+		Up: func(ctx context.Context, env Env) error {
+			for hexid, t := range seededTaxonomies {
+				id, _ := primitive.ObjectIDFromHex(hexid)
+				set := bson.M{"category": t.category, "equipment": t.equipment,
+					"difficulty": t.difficulty, "measurement": t.measurement}
+				if t.primary != nil {
+					set["primary_muscles"] = t.primary
+				}
+				if t.secondary != nil {
+					set["secondary_muscles"] = t.secondary
+				}
+				// Taxonomies already set, ie by an import, are left as is
+				_, err := env.Collection(db.ExerciseCollection).UpdateOne(ctx,
+					bson.M{"_id": id, "category": bson.M{"$exists": false}}, bson.M{"$set": set})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, env Env) error {
+			ids := bson.A{}
+			for hexid := range seededTaxonomies {
+				id, _ := primitive.ObjectIDFromHex(hexid)
+				ids = append(ids, id)
+			}
+			_, err := env.Collection(db.ExerciseCollection).UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}},
+				bson.M{"$unset": bson.M{"category": "", "primary_muscles": "", "secondary_muscles": "",
+					"equipment": "", "difficulty": "", "measurement": ""}})
+			return err
+		},
+	})
+}
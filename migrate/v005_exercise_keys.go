@@ -0,0 +1,33 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func init() {
+	register(Migration{
+		Version:     5,
+		Description: "compute the name keys of existing exercises",
+		Up: func(ctx context.Context, env Env) error {
+			service, err := db.NewExerciseService(env.Database, log.StandardLogger(), env.Options()...)
+			if err != nil {
+				return err
+			}
+			cnt, err := service.RebuildKeys(ctx)
+			if err != nil {
+				return err
+			}
+			log.Infof("computed the name keys of %d exercises", cnt)
+			return nil
+		},
+		Down: func(ctx context.Context, env Env) error {
+			_, err := env.Collection(db.ExerciseCollection).UpdateMany(ctx, bson.M{},
+				bson.M{"$unset": bson.M{"keys": ""}})
+			return err
+		},
+	})
+}
@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The error codes reported when dropping an index that, or whose collection,
+// does not exist
+const (
+	namespaceNotFound = 26
+	indexNotFound     = 27
+)
+
+// dropIndex drop the named index of the collection, if it exists
+func dropIndex(ctx context.Context, collection *mongo.Collection, name string) error {
+	_, err := collection.Indexes().DropOne(ctx, name)
+	if ce, ok := err.(mongo.CommandError); ok && (ce.Code == namespaceNotFound || ce.Code == indexNotFound) {
+		return nil
+	}
+	return err
+}
+
+func init() {
+	register(Migration{
+		Version:     6,
+		Description: "scope the unique exercise names to the shared catalog and each owner",
+		Up: func(ctx context.Context, env Env) error {
+			// Replaced by the owner_name_unique and owner_keys_unique indexes
+			for _, name := range []string{"name_unique", "keys_unique"} {
+				if err := dropIndex(ctx, env.Collection(db.ExerciseCollection), name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, env Env) error {
+			collection := env.Collection(db.ExerciseCollection)
+			for _, name := range []string{"owner_name_unique", "owner_keys_unique"} {
+				if err := dropIndex(ctx, collection, name); err != nil {
+					return err
+				}
+			}
+			_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					Keys: bson.D{{Key: "name", Value: 1}},
+					Options: options.Index().SetName("name_unique").SetUnique(true).
+						SetCollation(&options.Collation{Locale: "en", Strength: 2}),
+				},
+				{
+					Keys:    bson.D{{Key: "keys", Value: 1}},
+					Options: options.Index().SetName("keys_unique").SetUnique(true).SetSparse(true),
+				},
+			})
+			return err
+		},
+	})
+}
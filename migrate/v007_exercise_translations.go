@@ -0,0 +1,52 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/enpointe/activity/models/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seededTranslations the British names of the exercises of schema/exercise.json
+// known by another name in the UK
+var seededTranslations = map[string]map[string]db.Translation{
+	"5dab53b371aab123354e5cab": {"en-GB": {Name: "Star Jump"}}, // Jumping Jack
+	"5db8dc8fee74c3c19010b4f4": {"en-GB": {Name: "Press-Up"}},  // Pushup
+}
+
+func init() {
+	register(Migration{
+		Version:     7,
+		Description: "seed the translations of the standard exercises",
+		Up: func(ctx context.Context, env Env) error {
+			for hexid, translations := range seededTranslations {
+				id, _ := primitive.ObjectIDFromHex(hexid)
+				for locale, t := range translations {
+					// Translations already set, ie by staff, are left as is
+					field := "translations." + locale
+					_, err := env.Collection(db.ExerciseCollection).UpdateOne(ctx,
+						bson.M{"_id": id, field: bson.M{"$exists": false}}, bson.M{"$set": bson.M{field: t}})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(ctx context.Context, env Env) error {
+			for hexid, translations := range seededTranslations {
+				id, _ := primitive.ObjectIDFromHex(hexid)
+				for locale, t := range translations {
+					field := "translations." + locale
+					_, err := env.Collection(db.ExerciseCollection).UpdateOne(ctx,
+						bson.M{"_id": id, field: t}, bson.M{"$unset": bson.M{field: ""}})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package client
+
+import "time"
+
+// ScheduledEvent a workout session scheduled in the calendar of a user,
+// imported from an iCalendar file. UID is the unique identifier of the
+// event in the calendar it was imported from. All day events start at
+// midnight UTC of their first date and end at midnight UTC following their
+// last date.
+type ScheduledEvent struct {
+	ID          string    `json:"id,omitempty" example:"5de4c8e3a5c3b4d6e0f1a2d6"`
+	UID         string    `json:"uid" example:"tempo-20191202@example.com"`
+	Title       string    `json:"title" example:"Tempo run"`
+	Description string    `json:"description,omitempty" example:"Warm up 2 km, tempo 4 km, cool down 2 km"`
+	Start       time.Time `json:"start" example:"2019-12-02T14:30:00Z"`
+	End         time.Time `json:"end" example:"2019-12-02T15:30:00Z"`
+	AllDay      bool      `json:"allDay,omitempty" example:"false"`
+}
+
+// CalendarFeed the secret token granting access to the iCalendar feed of a
+// user and the path of the feed. The token is only reported when created.
+type CalendarFeed struct {
+	Token string `json:"token" example:"3f9c2a7d5e8b41c6a0d3e7f1b2c4d6e8f0a1b3c5"`
+	Path  string `json:"path" example:"/calendar/3f9c2a7d5e8b41c6a0d3e7f1b2c4d6e8f0a1b3c5.ics"`
+}
@@ -1,12 +1,63 @@
 package client
 
-// Exercise the model exposed via the web interface
+import "time"
+
+// Exercise the model exposed via the web interface. Owner is the id of the
+// user a private exercise belongs to, exercises of the shared catalog have
+// none. Aliases are alternate names the exercise can be retrieved by. MET is
+// the metabolic equivalent of the exercise, used to estimate the energy
+// expended performing it. Intensities override the MET value when the
+// exercise is performed at a light, moderate or vigorous intensity. Category,
+// the muscle groups, equipment, difficulty and measurement place the exercise
+// in the exercise taxonomy, the measurement being how the exercise is logged,
+// as sets of reps, a duration or a distance. Media are the images, animations
+// and videos demonstrating the exercise. Translations are the name and
+// description of the exercise in other locales, Locale is the locale of the
+// name and description of an exercise retrieved for the locales of a user.
 type Exercise struct {
-	ID          string `json:"id,omitempty"`
-	Name        string `json:"name,omitempty"`
+	ID               string             `json:"id,omitempty"`
+	Owner            string             `json:"owner,omitempty" example:"5db8e02b0e7aa732afd7fbc1"`
+	Name             string             `json:"name,omitempty"`
+	Aliases          []string           `json:"aliases,omitempty" example:"press-up,push-up"`
+	Description      string             `json:"description,omitempty"`
+	MET              float64            `json:"met,omitempty" example:"9.8"`
+	Intensities      map[string]float64 `json:"intensities,omitempty"`
+	Category         string             `json:"category,omitempty" example:"strength" enums:"strength,cardio,flexibility"`
+	PrimaryMuscles   []string           `json:"primaryMuscles,omitempty" example:"quadriceps,glutes"`
+	SecondaryMuscles []string           `json:"secondaryMuscles,omitempty" example:"hamstrings,lower-back"`
+	Equipment        []string           `json:"equipment,omitempty" example:"barbell"`
+	Difficulty       string             `json:"difficulty,omitempty" example:"intermediate" enums:"beginner,intermediate,advanced"`
+	Measurement      string             `json:"measurement,omitempty" example:"reps" enums:"reps,time,distance"`
+	Media            []Media            `json:"media,omitempty"`
+	Locale           string             `json:"locale,omitempty" example:"en-GB"`
+	Translations     Translations       `json:"translations,omitempty"`
+}
+
+// Translation the name and description of an exercise in a locale, an empty
+// description falling back to the description of the default locale
+type Translation struct {
+	Name        string `json:"name" example:"Star Jump"`
 	Description string `json:"description,omitempty"`
 }
 
+// Translations the translations of an exercise, by locale
+type Translations map[string]Translation
+
+// Media an image, animation or link to a video demonstrating an exercise. URL
+// is where the media is retrieved from, images and animations are served by
+// the server along with a thumbnail, videos are links to another site.
+type Media struct {
+	ID           string    `json:"id" example:"5de1f8d2e3a1f2b4c8d9e0b3"`
+	Kind         string    `json:"kind" example:"image" enums:"image,animation,video"`
+	Caption      string    `json:"caption,omitempty" example:"Bottom of the squat"`
+	URL          string    `json:"url" example:"/media/5de1f8d2e3a1f2b4c8d9e0b3"`
+	ThumbnailURL string    `json:"thumbnailUrl,omitempty" example:"/media/5de1f8d2e3a1f2b4c8d9e0b3/thumbnail"`
+	ContentType  string    `json:"contentType,omitempty" example:"image/jpeg"`
+	Width        int       `json:"width,omitempty" example:"1280"`
+	Height       int       `json:"height,omitempty" example:"720"`
+	Added        time.Time `json:"added" example:"2019-11-20T18:30:00Z"`
+}
+
 // ExerciseService functions available to Exercise
 type ExerciseService interface {
 	Create(e *Exercise) error
@@ -16,3 +67,33 @@ type ExerciseService interface {
 	GetByName(name string) (*Exercise, error)
 	Update(e *Exercise) error
 }
+
+// ExerciseMerge the result of merging a duplicate exercise into another, the
+// merged exercise and the number of workout sessions, templates and goals
+// that referenced the duplicate
+type ExerciseMerge struct {
+	Exercise  Exercise `json:"exercise"`
+	Workouts  int      `json:"workouts" example:"12"`
+	Templates int      `json:"templates" example:"1"`
+	Goals     int      `json:"goals" example:"0"`
+}
+
+// ExerciseMatch an exercise matching a search along with its relevance score
+type ExerciseMatch struct {
+	Exercise
+	Score float64 `json:"score" example:"7.5"`
+}
+
+// ExerciseSearch a page of the exercises matching a search, most relevant
+// first. Total is the number of exercises matched. Fuzzy is set when the
+// search found no whole word match and the exercises were matched by prefix
+// or allowing for typos, scores are then not comparable to those of a whole
+// word match.
+type ExerciseSearch struct {
+	Query   string          `json:"query" example:"squat thrust"`
+	Total   int             `json:"total" example:"2"`
+	Page    int             `json:"page" example:"1"`
+	Limit   int             `json:"limit" example:"20"`
+	Fuzzy   bool            `json:"fuzzy" example:"false"`
+	Results []ExerciseMatch `json:"results"`
+}
@@ -0,0 +1,33 @@
+package client
+
+import "time"
+
+// GoalEvent a change in the status of a goal
+type GoalEvent struct {
+	Status   string    `json:"status" example:"achieved"`
+	Progress float64   `json:"progress" example:"51.2"`
+	At       time.Time `json:"at" example:"2019-11-28T07:45:00Z"`
+}
+
+// Goal a target a user has set, ie run 50 km this month or squat 100 kg.
+// Target and Progress are in Unit, km or mi for distance, kg or lb for
+// volume and max-load, s for duration and no unit for sessions. If
+// ExerciseID is set only that exercise counts towards the goal. If Period
+// is set the goal covers the day, week or month, in Timezone, that Start
+// falls in, otherwise it covers Start until Deadline.
+type Goal struct {
+	ID         string      `json:"id,omitempty" example:"5de1f8d2e3a1f2b4c8d9e0a7"`
+	Name       string      `json:"name,omitempty" example:"November miles"`
+	Metric     string      `json:"metric" example:"distance" enums:"sessions,duration,distance,volume,max-load"`
+	ExerciseID string      `json:"exerciseId,omitempty" example:"5db8ddabee74c3c19010b4f6"`
+	Target     float64     `json:"target" example:"50"`
+	Unit       string      `json:"unit,omitempty" example:"km" enums:"km,mi,kg,lb,s"`
+	Period     string      `json:"period,omitempty" example:"month" enums:"day,week,month"`
+	Timezone   string      `json:"timezone,omitempty" example:"America/Los_Angeles"`
+	Start      time.Time   `json:"start,omitempty" example:"2019-11-01T07:00:00Z"`
+	Deadline   time.Time   `json:"deadline,omitempty" example:"2019-12-01T08:00:00Z"`
+	Progress   float64     `json:"progress" example:"32.4"`
+	Percent    float64     `json:"percent" example:"64.8"`
+	Status     string      `json:"status,omitempty" example:"active" enums:"active,achieved,missed"`
+	History    []GoalEvent `json:"history,omitempty"`
+}
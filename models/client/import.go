@@ -0,0 +1,15 @@
+package client
+
+// ImportError describes why a record of imported data was rejected
+type ImportError struct {
+	Line    int    `json:"line" example:"3"`
+	Message string `json:"message" example:"invalid username specified, 'a'"`
+}
+
+// ImportResult the results of importing data into a collection
+type ImportResult struct {
+	Inserted int           `json:"inserted" example:"10"`
+	Updated  int           `json:"updated" example:"2"`
+	Skipped  int           `json:"skipped" example:"1"`
+	Errors   []ImportError `json:"errors,omitempty"`
+}
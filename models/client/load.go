@@ -0,0 +1,51 @@
+package client
+
+import "time"
+
+// SessionLoad the training load of a workout session. ZoneSeconds holds the
+// time, in seconds, spent in each of the five heart rate zones. TRIMP is the
+// training impulse computed from the heart rate and RPELoad the session RPE,
+// the mean RPE of its sets, multiplied by the duration in minutes. Load is the
+// value of the method selected.
+type SessionLoad struct {
+	WorkoutID       string    `json:"workoutId" example:"5dd5a1f4e1b7a5e5d8a2c3f1"`
+	Start           time.Time `json:"start" example:"2019-11-20T18:30:00Z"`
+	DurationSeconds int       `json:"durationSeconds" example:"3600"`
+	ZoneSeconds     []int     `json:"zoneSeconds,omitempty"`
+	TRIMP           float64   `json:"trimp" example:"112.4"`
+	RPELoad         float64   `json:"rpeLoad" example:"420"`
+	Load            float64   `json:"load" example:"112.4"`
+}
+
+// DailyLoad the training load of a local date along with the acute, the
+// total of the last 7 days, and chronic, the weekly average of the last 28
+// days, workloads ending on that date. Ratio is the acute:chronic workload
+// ratio, 0 when there is no chronic workload, and Warning is set when the
+// ratio exceeds the threshold.
+type DailyLoad struct {
+	Date    string  `json:"date" example:"2019-11-20"`
+	Load    float64 `json:"load" example:"112.4"`
+	Acute   float64 `json:"acute" example:"540.2"`
+	Chronic float64 `json:"chronic" example:"410.7"`
+	Ratio   float64 `json:"ratio" example:"1.32"`
+	Warning bool    `json:"warning,omitempty"`
+}
+
+// TrainingLoad the training load analytics of a user over a date range.
+// Method is the load used, trimp or rpe. ZoneSeconds totals the time spent
+// in each heart rate zone by the sessions within the range. Warnings lists
+// the days on which the acute:chronic workload ratio exceeded Threshold.
+type TrainingLoad struct {
+	UserID           string        `json:"userId" example:"5db8e02b0e7aa732afd7fbc4"`
+	From             time.Time     `json:"from" example:"2019-10-23T00:00:00Z"`
+	To               time.Time     `json:"to" example:"2019-11-20T00:00:00Z"`
+	Timezone         string        `json:"timezone" example:"America/Los_Angeles"`
+	Method           string        `json:"method" example:"trimp"`
+	Threshold        float64       `json:"threshold" example:"1.5"`
+	MaxHeartRate     int           `json:"maxHeartRate,omitempty" example:"190"`
+	RestingHeartRate int           `json:"restingHeartRate,omitempty" example:"55"`
+	ZoneSeconds      []int         `json:"zoneSeconds,omitempty"`
+	Sessions         []SessionLoad `json:"sessions"`
+	Days             []DailyLoad   `json:"days"`
+	Warnings         []string      `json:"warnings"`
+}
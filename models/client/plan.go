@@ -0,0 +1,67 @@
+package client
+
+import "time"
+
+// PlanSession a workout session scheduled by a training plan. Day is the
+// day of the week of the plan the session is scheduled on, 1 being the
+// first day of the week, and the session is the routine of the template.
+type PlanSession struct {
+	Day        int    `json:"day" example:"1"`
+	TemplateID string `json:"templateId" example:"5de2a6c1e3a1f2b4c8d9e0b3"`
+}
+
+// PlanWeek the workout sessions scheduled for a week of a training plan
+type PlanWeek struct {
+	Sessions []PlanSession `json:"sessions"`
+}
+
+// Plan a multi-week training plan authored by a staff member. The templates
+// of the scheduled sessions are those of the author.
+type Plan struct {
+	ID          string     `json:"id,omitempty" example:"5de3b7d2f4b2a3c5d9e0f1c4"`
+	AuthorID    string     `json:"authorId,omitempty" example:"5db8e02b0e7aa732afd7fbc5"`
+	Name        string     `json:"name" example:"Couch to 5k"`
+	Description string     `json:"description,omitempty" example:"Three runs a week building to 5k"`
+	Weeks       []PlanWeek `json:"weeks"`
+	Updated     time.Time  `json:"updated,omitempty" example:"2019-12-01T10:00:00Z"`
+}
+
+// Adherence how closely a user has followed a training plan. Due is the
+// number of scheduled sessions whose day has passed, along with those of
+// today that have been completed. Percent is the percentage of the due
+// sessions that were completed, 0 until a session is due.
+type Adherence struct {
+	Scheduled int     `json:"scheduled" example:"24"`
+	Due       int     `json:"due" example:"8"`
+	Completed int     `json:"completed" example:"6"`
+	Percent   float64 `json:"percent" example:"75"`
+}
+
+// PlanAssignment a training plan assigned to a user. The plan starts on
+// the Start date, YYYY-MM-DD, in the time zone of the assignment and runs
+// until the End date inclusive.
+type PlanAssignment struct {
+	ID         string     `json:"id,omitempty" example:"5de3b7d2f4b2a3c5d9e0f1c5"`
+	PlanID     string     `json:"planId" example:"5de3b7d2f4b2a3c5d9e0f1c4"`
+	PlanName   string     `json:"planName,omitempty" example:"Couch to 5k"`
+	Start      string     `json:"start" example:"2019-12-02"`
+	End        string     `json:"end,omitempty" example:"2020-01-26"`
+	Timezone   string     `json:"timezone,omitempty" example:"America/Los_Angeles"`
+	AssignedBy string     `json:"assignedBy,omitempty" example:"5db8e02b0e7aa732afd7fbc5"`
+	Adherence  *Adherence `json:"adherence,omitempty"`
+}
+
+// ScheduledWorkout a workout session a training plan schedules for a user
+// on a date. Completed is set, along with the id of the session, once a
+// matching session has been logged.
+type ScheduledWorkout struct {
+	AssignmentID string          `json:"assignmentId" example:"5de3b7d2f4b2a3c5d9e0f1c5"`
+	PlanID       string          `json:"planId" example:"5de3b7d2f4b2a3c5d9e0f1c4"`
+	PlanName     string          `json:"planName" example:"Couch to 5k"`
+	Week         int             `json:"week" example:"1"`
+	Day          int             `json:"day" example:"1"`
+	Date         string          `json:"date" example:"2019-12-02"`
+	Template     WorkoutTemplate `json:"template"`
+	Completed    bool            `json:"completed" example:"false"`
+	WorkoutID    string          `json:"workoutId,omitempty" example:"5dc2ee5a567855de21f1070a"`
+}
@@ -0,0 +1,19 @@
+package client
+
+import "time"
+
+// PersonalRecord a best performance of a user for an exercise. Loads are in
+// kilograms and times in seconds. Reps is only set for max load records, the
+// heaviest load lifted for that number of reps. Previous holds the record
+// that was beaten, if any.
+type PersonalRecord struct {
+	ExerciseID string    `json:"exerciseId" example:"5dab5fa871aab123354e5cb6"`
+	Kind       string    `json:"kind" example:"max-load" enums:"1rm-epley,1rm-brzycki,max-load,fastest-5k,fastest-10k,longest-duration"`
+	Reps       int       `json:"reps,omitempty" example:"5"`
+	Value      float64   `json:"value" example:"102.5"`
+	Unit       string    `json:"unit" example:"kg" enums:"kg,s"`
+	Previous   float64   `json:"previous,omitempty" example:"100"`
+	WorkoutID  string    `json:"workoutId" example:"5dd5b7e8e3a1f2b4c8d9e0f1"`
+	Achieved   time.Time `json:"achieved" example:"2019-11-20T18:30:00Z"`
+	Current    bool      `json:"current" example:"true"`
+}
@@ -0,0 +1,81 @@
+package client
+
+import "time"
+
+// StatsTotals the totals and per session averages of a set of workout sessions.
+// Durations are in seconds, distances in meters and volume, reps x load, in kilograms.
+// Kcal is the estimated energy expended and Est1RMKg the best one rep max
+// estimated, using the Epley formula, from the sets of the sessions.
+type StatsTotals struct {
+	Sessions           int     `json:"sessions" example:"12"`
+	DurationSeconds    int     `json:"durationSeconds" example:"21600"`
+	DistanceMeters     float64 `json:"distanceMeters" example:"42195"`
+	VolumeKg           float64 `json:"volumeKg" example:"15250"`
+	Sets               int     `json:"sets" example:"96"`
+	Reps               int     `json:"reps" example:"640"`
+	Kcal               float64 `json:"kcal" example:"4820.5"`
+	AvgDurationSeconds float64 `json:"avgDurationSeconds" example:"1800"`
+	AvgDistanceMeters  float64 `json:"avgDistanceMeters" example:"3516.25"`
+	AvgVolumeKg        float64 `json:"avgVolumeKg" example:"1270.8"`
+	AvgKcal            float64 `json:"avgKcal" example:"401.7"`
+	Est1RMKg           float64 `json:"est1RMKg" example:"116.67"`
+}
+
+// ExerciseStats the totals of the sessions that included an exercise
+type ExerciseStats struct {
+	ExerciseID string `json:"exerciseId" example:"5dab5fa871aab123354e5cb6"`
+	StatsTotals
+}
+
+// PeriodStats the totals of the sessions started within a day, week or month
+type PeriodStats struct {
+	Start time.Time `json:"start" example:"2019-11-18T00:00:00Z"`
+	StatsTotals
+}
+
+// Stats the training statistics of a user over a date range. Periods
+// holds the totals of each day, week or month within the range that
+// included at least one session, in chronological order. Streaks are
+// computed over all sessions of the user using their streak settings.
+// If ExerciseID is set only the entries of that exercise are counted.
+type Stats struct {
+	UserID     string          `json:"userId" example:"5db8e02b0e7aa732afd7fbc4"`
+	ExerciseID string          `json:"exerciseId,omitempty" example:"5dab5fa871aab123354e5cb6"`
+	From       time.Time       `json:"from" example:"2019-09-01T00:00:00Z"`
+	To         time.Time       `json:"to" example:"2019-12-01T00:00:00Z"`
+	Period     string          `json:"period" example:"week"`
+	Timezone   string          `json:"timezone" example:"America/Los_Angeles"`
+	Totals     StatsTotals     `json:"totals"`
+	Exercises  []ExerciseStats `json:"exercises"`
+	Periods    []PeriodStats   `json:"periods"`
+	Streaks    *Streaks        `json:"streaks,omitempty"`
+}
+
+// StreakSettings how the activity streaks of a user are computed. Days start
+// at midnight in Timezone, an IANA time zone name. RestDays is the number of
+// consecutive days without a session allowed within a daily streak and
+// RestWeeks the number of consecutive weeks allowed within a weekly streak.
+type StreakSettings struct {
+	Timezone  string `json:"timezone" example:"America/Los_Angeles"`
+	RestDays  int    `json:"restDays" example:"1"`
+	RestWeeks int    `json:"restWeeks" example:"0"`
+}
+
+// Streak a run of consecutive days, or weeks, with at least one session.
+// Lengths count the days or weeks with a session, dates are local dates in
+// the form 2006-01-02 and weeks are identified by the date of their Monday.
+type Streak struct {
+	Current      int    `json:"current" example:"4"`
+	CurrentStart string `json:"currentStart,omitempty" example:"2019-11-18"`
+	Longest      int    `json:"longest" example:"12"`
+	LongestStart string `json:"longestStart,omitempty" example:"2019-09-02"`
+	LongestEnd   string `json:"longestEnd,omitempty" example:"2019-09-16"`
+}
+
+// Streaks the daily and weekly activity streaks of a user
+type Streaks struct {
+	StreakSettings
+	LastActive string `json:"lastActive,omitempty" example:"2019-11-21"`
+	Daily      Streak `json:"daily"`
+	Weekly     Streak `json:"weekly"`
+}
@@ -0,0 +1,15 @@
+package client
+
+import "time"
+
+// WorkoutTemplate a named routine of exercises a user can log as a workout
+// session with a single request. The sets and cardio metrics of each
+// exercise are the targets of the routine, they pre-populate the session
+// created from the template so only the actuals that differ need updating.
+type WorkoutTemplate struct {
+	ID        string            `json:"id,omitempty" example:"5de2a6c1e3a1f2b4c8d9e0b3"`
+	Name      string            `json:"name" example:"Leg day"`
+	Notes     string            `json:"notes,omitempty" example:"Superset the lunges"`
+	Exercises []WorkoutExercise `json:"exercises"`
+	Updated   time.Time         `json:"updated,omitempty" example:"2019-11-30T09:15:00Z"`
+}
@@ -15,9 +15,29 @@ type UserCreate struct {
 	Privilege string `json:"privilege,omitempty" example:"admin"`
 }
 
-// UserInfo model used to return information about a given user
+// UserInfo model used to return information about a given user. The body
+// weight of the user, if known, is expressed in WeightUnit. Heart rates are
+// in beats per minute.
 type UserInfo struct {
-	ID        string `json:"id,unique" example:"5db8e02b0e7aa732afd7fbc4"`
-	Username  string `json:"username,unique" example:"admin"`
-	Privilege string `json:"privilege,omitempty" example:"admin"`
+	ID               string  `json:"id,unique" example:"5db8e02b0e7aa732afd7fbc4"`
+	Username         string  `json:"username,unique" example:"admin"`
+	Privilege        string  `json:"privilege,omitempty" example:"admin"`
+	Weight           float64 `json:"weight,omitempty" example:"72.5"`
+	WeightUnit       string  `json:"weightUnit,omitempty" example:"kg"`
+	MaxHeartRate     int     `json:"maxHeartRate,omitempty" example:"190"`
+	RestingHeartRate int     `json:"restingHeartRate,omitempty" example:"55"`
+}
+
+// BodyWeight model used to set the body weight of a user, used to estimate
+// the energy expended during workouts. Unit is kg (the default) or lb.
+type BodyWeight struct {
+	Weight float64 `json:"weight" example:"72.5"`
+	Unit   string  `json:"unit,omitempty" example:"kg"`
+}
+
+// HeartRates model used to set the maximum and resting heart rates of a
+// user, in beats per minute, used to compute heart rate zones and load
+type HeartRates struct {
+	Max     int `json:"max" example:"190"`
+	Resting int `json:"resting" example:"55"`
 }
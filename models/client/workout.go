@@ -0,0 +1,64 @@
+package client
+
+import "time"
+
+// Workout a workout session logged by a user. A session contains the
+// exercises performed, in the order they were performed. TrackID is set
+// when the session was created from an uploaded GPX or TCX file.
+type Workout struct {
+	ID         string            `json:"id,omitempty" example:"5dc2ee5a567855de21f1070a"`
+	UserID     string            `json:"userId,omitempty" example:"5db8e02b0e7aa732afd7fbc4"`
+	Start      time.Time         `json:"start" example:"2019-11-20T18:30:00Z"`
+	Notes      string            `json:"notes,omitempty" example:"Leg day"`
+	Exercises  []WorkoutExercise `json:"exercises,omitempty"`
+	TrackID    string            `json:"trackId,omitempty" example:"5dc2ee5a567855de21f1070d"`
+	TemplateID string            `json:"templateId,omitempty" example:"5de2a6c1e3a1f2b4c8d9e0b3"`
+}
+
+// WorkoutExercise an exercise performed during a workout session. Strength
+// exercises record Sets, cardio exercises such as walking or running record Cardio.
+// Intensity, light, moderate or vigorous, selects the MET value used to estimate
+// the energy expended, Kcal, which is only estimated once the body weight of the
+// user is known.
+type WorkoutExercise struct {
+	ID         string         `json:"id,omitempty" example:"5dc2ee5a567855de21f1070b"`
+	ExerciseID string         `json:"exerciseId" example:"5dab5fa871aab123354e5cb6"`
+	Intensity  string         `json:"intensity,omitempty" example:"moderate"`
+	Sets       []StrengthSet  `json:"sets,omitempty"`
+	Cardio     *CardioMetrics `json:"cardio,omitempty"`
+	Kcal       float64        `json:"kcal,omitempty" example:"312.5"`
+}
+
+// StrengthSet a single set of a strength exercise. Load is expressed in
+// Unit, either kg (the default) or lb. RPE is the rate of perceived
+// exertion on a scale of 1 to 10.
+type StrengthSet struct {
+	ID          string  `json:"id,omitempty" example:"5dc2ee5a567855de21f1070c"`
+	Reps        int     `json:"reps" example:"5"`
+	Load        float64 `json:"load,omitempty" example:"100"`
+	Unit        string  `json:"unit,omitempty" example:"kg"`
+	RestSeconds int     `json:"restSeconds,omitempty" example:"120"`
+	RPE         float64 `json:"rpe,omitempty" example:"8.5"`
+}
+
+// CardioMetrics the duration, distance and intensity of a cardio exercise.
+// Distance is expressed in Unit, either km (the default) or mi. ElevationGain
+// is expressed in meters when Unit is km and in feet when Unit is mi. Pace, in
+// seconds per km or mi, and Speed, in km/h or mph, are computed by the server
+// and ignored on input. Power is in watts and cadence in revolutions or steps
+// per minute. Laps holds the metrics of each lap, expressed in the same Unit.
+type CardioMetrics struct {
+	DurationSeconds int             `json:"durationSeconds,omitempty" example:"1800"`
+	Distance        float64         `json:"distance,omitempty" example:"5"`
+	Unit            string          `json:"unit,omitempty" example:"km"`
+	AvgHeartRate    int             `json:"avgHeartRate,omitempty" example:"145"`
+	MaxHeartRate    int             `json:"maxHeartRate,omitempty" example:"172"`
+	ElevationGain   float64         `json:"elevationGain,omitempty" example:"45"`
+	AvgPower        int             `json:"avgPower,omitempty" example:"240"`
+	MaxPower        int             `json:"maxPower,omitempty" example:"290"`
+	AvgCadence      int             `json:"avgCadence,omitempty" example:"81"`
+	MaxCadence      int             `json:"maxCadence,omitempty" example:"86"`
+	Pace            float64         `json:"pace,omitempty" example:"360"`
+	Speed           float64         `json:"speed,omitempty" example:"10"`
+	Laps            []CardioMetrics `json:"laps,omitempty"`
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/enpointe/activity/ical"
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxEventTitleLength the longest title of a scheduled event that is kept,
+// longer titles are truncated
+const MaxEventTitleLength = 200
+
+// MaxEventDescriptionLength the longest description of a scheduled event
+// that is kept, longer descriptions are truncated
+const MaxEventDescriptionLength = 4000
+
+// DefaultEventDuration the duration of the calendar event of a logged
+// session whose duration is not known
+const DefaultEventDuration = time.Hour
+
+// ScheduledEvent a workout session scheduled in the calendar of a user,
+// imported from an iCalendar file. UID identifies the event in the calendar
+// it was imported from so that importing the calendar again updates the
+// events previously imported.
+type ScheduledEvent struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UID         string             `bson:"uid" json:"uid"`
+	Title       string             `bson:"title" json:"title"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Start       time.Time          `bson:"start" json:"start"`
+	End         time.Time          `bson:"end" json:"end"`
+	AllDay      bool               `bson:"all_day,omitempty" json:"all_day,omitempty"`
+	Imported    time.Time          `bson:"imported" json:"imported"`
+}
+
+// truncate shorten the text to at most max bytes without splitting a character
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
+
+// NewScheduledEvent create the database representation of an event imported
+// into the calendar of the user. Events without a UID are assigned one.
+func NewScheduledEvent(userID primitive.ObjectID, e *ical.Event) (*ScheduledEvent, error) {
+	event := ScheduledEvent{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		UID:         strings.TrimSpace(e.UID),
+		Title:       truncate(strings.TrimSpace(e.Summary), MaxEventTitleLength),
+		Description: truncate(strings.TrimSpace(e.Description), MaxEventDescriptionLength),
+		Start:       e.Start.UTC(),
+		End:         e.End.UTC(),
+		AllDay:      e.AllDay,
+		Imported:    time.Now().UTC(),
+	}
+	if event.Start.IsZero() {
+		return nil, &ValidationError{"an event must have a start time"}
+	}
+	if event.End.Before(event.Start) {
+		return nil, &ValidationError{"an event can not end before it starts"}
+	}
+	if len(event.UID) == 0 {
+		event.UID = event.ID.Hex()
+	}
+	if len(event.Title) == 0 {
+		event.Title = "Workout"
+	}
+	return &event, nil
+}
+
+// Convert the database representation of a scheduled event to the client representation
+func (e *ScheduledEvent) Convert() client.ScheduledEvent {
+	return client.ScheduledEvent{
+		ID:          e.ID.Hex(),
+		UID:         e.UID,
+		Title:       e.Title,
+		Description: e.Description,
+		Start:       e.Start,
+		End:         e.End,
+		AllDay:      e.AllDay,
+	}
+}
+
+// event the calendar event of the scheduled event
+func (e *ScheduledEvent) event() ical.Event {
+	return ical.Event{
+		UID:         fmt.Sprintf("%s@activity", e.ID.Hex()),
+		Start:       e.Start,
+		End:         e.End,
+		AllDay:      e.AllDay,
+		Summary:     e.Title,
+		Description: e.Description,
+	}
+}
+
+// workoutEvent the calendar event of a logged workout session, names are the
+// names of the exercises. The event lasts the estimated duration of the session.
+func workoutEvent(w *Workout, names map[primitive.ObjectID]string) ical.Event {
+	titles := []string{}
+	lines := []string{}
+	if len(w.Notes) > 0 {
+		lines = append(lines, w.Notes)
+	}
+	seconds := 0
+	for i := range w.Exercises {
+		e := &w.Exercises[i]
+		seconds += entrySeconds(e)
+		name, ok := names[e.ExerciseID]
+		if !ok {
+			name = "Exercise"
+		}
+		titles = append(titles, name)
+		switch {
+		case e.Cardio != nil && e.Cardio.DistanceM > 0:
+			lines = append(lines, fmt.Sprintf("%s: %.2f km in %d min", name,
+				e.Cardio.DistanceM/MetersPerKilometer, e.Cardio.DurationSeconds/60))
+		case e.Cardio != nil:
+			lines = append(lines, fmt.Sprintf("%s: %d min", name, e.Cardio.DurationSeconds/60))
+		default:
+			reps := 0
+			for _, set := range e.Sets {
+				reps += set.Reps
+			}
+			lines = append(lines, fmt.Sprintf("%s: %d sets, %d reps", name, len(e.Sets), reps))
+		}
+	}
+	summary := strings.Join(titles, ", ")
+	if len(summary) == 0 {
+		summary = "Workout"
+	}
+	duration := time.Duration(seconds) * time.Second
+	if duration == 0 {
+		duration = DefaultEventDuration
+	}
+	return ical.Event{
+		UID:         fmt.Sprintf("%s@activity", w.ID.Hex()),
+		Start:       w.Start,
+		End:         w.Start.Add(duration),
+		Summary:     summary,
+		Description: strings.Join(lines, "\n"),
+	}
+}
+
+// sessionEvent the all day calendar event of a session scheduled by a
+// training plan assigned to the user
+func sessionEvent(a *Assignment, plan *Plan, s *ScheduledSession, t *WorkoutTemplate) ical.Event {
+	start, _ := time.Parse(DateLayout, s.Date)
+	event := ical.Event{
+		UID:         fmt.Sprintf("%s-%d-%d@activity", a.ID.Hex(), s.Week, s.Day),
+		Start:       start,
+		End:         start.AddDate(0, 0, 1),
+		AllDay:      true,
+		Summary:     plan.Name,
+		Description: fmt.Sprintf("Week %d, day %d of %s", s.Week, s.Day, plan.Name),
+	}
+	if t != nil {
+		event.Summary = fmt.Sprintf("%s: %s", plan.Name, t.Name)
+		if len(t.Notes) > 0 {
+			event.Description += "\n" + t.Notes
+		}
+	}
+	return event
+}
+
+// hashFeedToken the hash of a calendar feed token, only the hash of the
+// token is stored
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,327 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/enpointe/activity/ical"
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScheduledCollection name of the collection used to hold the workout
+// sessions imported into the calendar of users
+const ScheduledCollection = "scheduled"
+
+// MaxCalendarSize the largest iCalendar file, in bytes, that can be imported
+const MaxCalendarSize = 1 << 20
+
+// FeedDays the number of days of past sessions included in a calendar feed
+const FeedDays = 365
+
+// FeedTokenBytes the number of random bytes of a calendar feed token
+const FeedTokenBytes = 20
+
+// CalendarProdID the product identifier of the calendars published
+const CalendarProdID = "-//enpointe//activity//EN"
+
+// CalendarService holds a entry to the collection of scheduled sessions of
+// users along with the collections the calendar feed of a user is built from
+type CalendarService struct {
+	Collection *mongo.Collection
+	users      *mongo.Collection
+	logs       *mongo.Collection
+	exercises  *mongo.Collection
+	plans      *PlanService
+}
+
+// NewCalendarService create a new instance of the Calendar Service
+func NewCalendarService(database *mongo.Database, opts ...ServiceOption) (*CalendarService, error) {
+	plans, err := NewPlanService(database, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &CalendarService{
+		Collection: database.Collection(CollectionName(ScheduledCollection, opts...)),
+		users:      database.Collection(CollectionName(UsersCollection, opts...)),
+		logs:       database.Collection(CollectionName(LogsCollection, opts...)),
+		exercises:  database.Collection(CollectionName(ExerciseCollection, opts...)),
+		plans:      plans,
+	}, nil
+}
+
+// CreateFeedToken create a new secret token granting access to the calendar
+// feed of the user, replacing any previous token. Only the hash of the token
+// is stored so the token can not be retrieved later.
+func (s *CalendarService) CreateFeedToken(ctx context.Context, userID string) (string, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, FeedTokenBytes)
+	if _, err = rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	result, err := s.users.UpdateOne(ctx, bson.M{"_id": uid},
+		bson.M{"$set": bson.M{"calendar_token": hashFeedToken(token)}})
+	if err != nil {
+		err = fmt.Errorf("Failed to update user '%s', %s", userID, err)
+		return "", err
+	}
+	if result.MatchedCount == 0 {
+		return "", &NotFoundError{fmt.Sprintf("user '%s' not found", userID)}
+	}
+	return token, nil
+}
+
+// RevokeFeedToken revoke the token granting access to the calendar feed of the user
+func (s *CalendarService) RevokeFeedToken(ctx context.Context, userID string) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	result, err := s.users.UpdateOne(ctx, bson.M{"_id": uid}, bson.M{"$unset": bson.M{"calendar_token": ""}})
+	if err != nil {
+		err = fmt.Errorf("Failed to update user '%s', %s", userID, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("user '%s' not found", userID)}
+	}
+	return nil
+}
+
+// Feed the calendar of the user holding the feed token. The calendar holds
+// the sessions logged over the last FeedDays days, the sessions scheduled by
+// the training plans assigned to the user that are yet to be completed, as
+// completed sessions appear as the session logged, and the sessions
+// imported into the calendar of the user.
+func (s *CalendarService) Feed(ctx context.Context, token string) (*ical.Calendar, error) {
+	var user User
+	err := s.users.FindOne(ctx, bson.M{"calendar_token": hashFeedToken(token)}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{"calendar not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -FeedDays)
+	calendar := ical.Calendar{
+		ProdID: CalendarProdID,
+		Name:   fmt.Sprintf("Workouts of %s", user.Username),
+		Stamp:  now,
+		Events: []ical.Event{},
+	}
+
+	workouts := []Workout{}
+	cursor, err := s.logs.Find(ctx, bson.M{"user_id": user.ID, "start": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "start", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &workouts); err != nil {
+		return nil, err
+	}
+	names, err := s.exerciseNames(ctx, workouts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range workouts {
+		calendar.Events = append(calendar.Events, workoutEvent(&workouts[i], names))
+	}
+
+	assignments, plans, err := s.plans.assignmentsOf(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	for i := range assignments {
+		a := &assignments[i]
+		plan := plans[a.PlanID]
+		p, err := s.plans.progress(ctx, a, plan)
+		if err != nil {
+			return nil, err
+		}
+		for j := range p.schedule {
+			if p.matches[j] < 0 {
+				calendar.Events = append(calendar.Events,
+					sessionEvent(a, plan, &p.schedule[j], p.templates[p.schedule[j].TemplateID]))
+			}
+		}
+	}
+
+	scheduled := []ScheduledEvent{}
+	cursor, err = s.Collection.Find(ctx, bson.M{"user_id": user.ID, "end": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "start", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &scheduled); err != nil {
+		return nil, err
+	}
+	for i := range scheduled {
+		calendar.Events = append(calendar.Events, scheduled[i].event())
+	}
+	return &calendar, nil
+}
+
+// exerciseNames the names of the exercises performed during the workouts
+func (s *CalendarService) exerciseNames(ctx context.Context, workouts []Workout) (map[primitive.ObjectID]string, error) {
+	ids := []primitive.ObjectID{}
+	for i := range workouts {
+		for _, e := range workouts[i].Exercises {
+			ids = append(ids, e.ExerciseID)
+		}
+	}
+	names := map[primitive.ObjectID]string{}
+	if len(ids) == 0 {
+		return names, nil
+	}
+	cursor, err := s.exercises.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	exercises := []Exercise{}
+	if err = cursor.All(ctx, &exercises); err != nil {
+		return nil, err
+	}
+	for _, e := range exercises {
+		names[e.ID] = e.Name
+	}
+	return names, nil
+}
+
+// Import create a scheduled session for each event of the iCalendar file.
+// Floating times, those without a time zone, are interpreted in loc. An event
+// previously imported, identified by its UID, is updated and a cancelled event
+// removes the session previously imported. Events that can not be parsed are
+// reported along with the line they begin on.
+func (s *CalendarService) Import(ctx context.Context, userID string, data []byte,
+	loc *time.Location) (*client.ImportResult, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	if len(data) > MaxCalendarSize {
+		return nil, &ValidationError{fmt.Sprintf("calendar exceeds the maximum size of %d bytes", MaxCalendarSize)}
+	}
+	calendar, err := ical.Decode(bytes.NewReader(data), loc)
+	if err != nil {
+		return nil, &ValidationError{err.Error()}
+	}
+	result := client.ImportResult{}
+	for _, e := range calendar.Errors {
+		result.Errors = append(result.Errors, client.ImportError{Line: e.Line, Message: e.Message})
+	}
+	for i := range calendar.Events {
+		e := &calendar.Events[i]
+		if e.Status == ical.StatusCancelled {
+			if len(e.UID) > 0 {
+				if _, err = s.Collection.DeleteOne(ctx, bson.M{"user_id": uid, "uid": e.UID}); err != nil {
+					return nil, err
+				}
+			}
+			result.Skipped++
+			continue
+		}
+		event, err := NewScheduledEvent(uid, e)
+		if err != nil {
+			result.Errors = append(result.Errors, client.ImportError{Line: e.Line, Message: err.Error()})
+			continue
+		}
+		update := bson.M{
+			"$set": bson.M{"title": event.Title, "description": event.Description, "start": event.Start,
+				"end": event.End, "all_day": event.AllDay, "imported": event.Imported},
+			"$setOnInsert": bson.M{"_id": event.ID},
+		}
+		res, err := s.Collection.UpdateOne(ctx, bson.M{"user_id": uid, "uid": event.UID}, update,
+			options.Update().SetUpsert(true))
+		if err != nil {
+			err = fmt.Errorf("Unable to store scheduled session in database, %s", err)
+			log.Error(err)
+			return nil, err
+		}
+		if res.UpsertedCount > 0 {
+			result.Inserted++
+		} else {
+			result.Updated++
+		}
+	}
+	return &result, nil
+}
+
+// GetAll retrieve the scheduled sessions of the user that overlap the range
+// from to, in the order they start. A zero time leaves that end of the range open.
+func (s *CalendarService) GetAll(ctx context.Context, userID string, from time.Time,
+	to time.Time) ([]*client.ScheduledEvent, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{"user_id": uid}
+	if !from.IsZero() {
+		filter["end"] = bson.M{"$gte": from}
+	}
+	if !to.IsZero() {
+		filter["start"] = bson.M{"$lt": to}
+	}
+	cursor, err := s.Collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "start", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	events := []ScheduledEvent{}
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	results := []*client.ScheduledEvent{}
+	for i := range events {
+		e := events[i].Convert()
+		results = append(results, &e)
+	}
+	return results, nil
+}
+
+// Delete remove a scheduled session of the user
+func (s *CalendarService) Delete(ctx context.Context, userID string, id string) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	eid, err := objectID(id, "scheduled session")
+	if err != nil {
+		return err
+	}
+	result, err := s.Collection.DeleteOne(ctx, bson.M{"_id": eid, "user_id": uid})
+	if err != nil {
+		err = fmt.Errorf("failed to delete scheduled session %s, %s", id, err)
+		log.Error(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("scheduled session '%s' not found", id)}
+	}
+	return nil
+}
+
+// DeleteByUser remove all scheduled sessions of the user, returning the
+// number of sessions deleted
+func (s *CalendarService) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return 0, err
+	}
+	result, err := s.Collection.DeleteMany(ctx, bson.M{"user_id": uid})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
@@ -0,0 +1,111 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/ical"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewScheduledEvent(t *testing.T) {
+	uid := primitive.NewObjectID()
+	start := time.Date(2019, 12, 2, 14, 30, 0, 0, time.UTC)
+	event, err := db.NewScheduledEvent(uid, &ical.Event{Start: start, End: start.Add(time.Hour),
+		Summary: strings.Repeat("é", db.MaxEventTitleLength)})
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID.Hex(), event.UID)
+	assert.Equal(t, db.MaxEventTitleLength, len(event.Title))
+	assert.True(t, strings.HasPrefix(event.Title, "éé"))
+
+	event, err = db.NewScheduledEvent(uid, &ical.Event{UID: "run@example.com", Start: start, End: start})
+	assert.NoError(t, err)
+	assert.Equal(t, "Workout", event.Title)
+	assert.Equal(t, "run@example.com", event.Convert().UID)
+
+	_, err = db.NewScheduledEvent(uid, &ical.Event{})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewScheduledEvent(uid, &ical.Event{Start: start, End: start.Add(-time.Hour)})
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestCalendar(t *testing.T) {
+	workouts, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, workouts, ex)
+	database := workouts.Collection.Database()
+	service, err := db.NewCalendarService(database)
+	assert.NoError(t, err)
+	ctx := context.TODO()
+	assert.NoError(t, service.Collection.Drop(ctx))
+	defer service.Collection.Drop(ctx)
+
+	users := database.Collection(db.UsersCollection)
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	_, err = users.DeleteOne(ctx, bson.M{"_id": uid})
+	assert.NoError(t, err)
+	defer users.DeleteOne(ctx, bson.M{"_id": uid})
+	_, err = users.InsertOne(ctx, db.User{ID: uid, Username: "calendar"})
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile("../../ical/testdata/training.ics")
+	assert.NoError(t, err)
+	result, err := service.Import(ctx, testWorkoutUserID, data, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, len(result.Errors))
+
+	// Importing again updates the sessions previously imported
+	result, err = service.Import(ctx, testWorkoutUserID, data, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Inserted)
+	assert.Equal(t, 3, result.Updated)
+	_, err = service.Import(ctx, testWorkoutUserID, []byte("name,start"), time.UTC)
+	assert.True(t, db.IsValidation(err))
+
+	events, err := service.GetAll(ctx, testWorkoutUserID, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	if assert.Equal(t, 3, len(events)) {
+		assert.Equal(t, "Tempo run, 8 km", events[0].Title)
+		assert.True(t, events[2].AllDay)
+	}
+	events, err = service.GetAll(ctx, testWorkoutUserID, time.Date(2019, 12, 5, 0, 0, 0, 0, time.UTC), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(events))
+
+	// The feed holds the sessions logged and scheduled
+	_, err = workouts.Create(ctx, testWorkoutUserID, &client.Workout{Start: time.Now().Add(-time.Hour),
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: testSquatID,
+			Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 100, Unit: "kg"}}}}})
+	assert.NoError(t, err)
+	token, err := service.CreateFeedToken(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	_, err = service.Feed(ctx, "unknown")
+	assert.True(t, db.IsNotFound(err))
+	feed, err := service.Feed(ctx, token)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(feed.Events)) {
+		assert.Equal(t, "squat", strings.ToLower(feed.Events[0].Summary))
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, ical.Encode(&buf, feed))
+	assert.Contains(t, buf.String(), "BEGIN:VEVENT")
+
+	assert.NoError(t, service.RevokeFeedToken(ctx, testWorkoutUserID))
+	_, err = service.Feed(ctx, token)
+	assert.True(t, db.IsNotFound(err))
+
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, events[0].ID))
+	assert.True(t, db.IsNotFound(service.Delete(ctx, testWorkoutUserID, events[0].ID)))
+	cnt, err := service.DeleteByUser(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cnt)
+}
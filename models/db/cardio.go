@@ -0,0 +1,156 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/enpointe/activity/models/client"
+)
+
+// Conversion factors used to normalize distances to meters
+const (
+	MetersPerKilometer = 1000.0
+	MetersPerMile      = 1609.344
+	MetersPerFoot      = 0.3048
+)
+
+// The units a distance can be expressed in
+const (
+	UnitKilometer = "km"
+	UnitMile      = "mi"
+)
+
+// MaxHeartRate the highest heart rate, in beats per minute, accepted as valid
+const MaxHeartRate = 250
+
+// CardioMetrics the duration, distance and intensity of a cardio exercise.
+// All values are stored in SI units, Unit records the unit the distance
+// was entered in so that it can be reported back in the same unit. Laps
+// holds the metrics of each lap recorded by the device, if any.
+type CardioMetrics struct {
+	DurationSeconds int             `bson:"duration_s,omitempty" json:"duration_s,omitempty"`
+	DistanceM       float64         `bson:"distance_m,omitempty" json:"distance_m,omitempty"`
+	Unit            string          `bson:"unit,omitempty" json:"unit,omitempty"`
+	AvgHeartRate    int             `bson:"avg_hr,omitempty" json:"avg_hr,omitempty"`
+	MaxHeartRate    int             `bson:"max_hr,omitempty" json:"max_hr,omitempty"`
+	ElevationGainM  float64         `bson:"elevation_gain_m,omitempty" json:"elevation_gain_m,omitempty"`
+	AvgPower        int             `bson:"avg_power,omitempty" json:"avg_power,omitempty"`
+	MaxPower        int             `bson:"max_power,omitempty" json:"max_power,omitempty"`
+	AvgCadence      int             `bson:"avg_cadence,omitempty" json:"avg_cadence,omitempty"`
+	MaxCadence      int             `bson:"max_cadence,omitempty" json:"max_cadence,omitempty"`
+	Laps            []CardioMetrics `bson:"laps,omitempty" json:"laps,omitempty"`
+}
+
+// NewCardioMetrics transforms the web facing CardioMetrics structure to a
+// database compatible CardioMetrics structure. Distance and elevation gain
+// are converted to meters, any pace or speed passed in is ignored. Laps are
+// converted using the unit of the metrics they belong to.
+func NewCardioMetrics(c *client.CardioMetrics) (*CardioMetrics, error) {
+	if c.DurationSeconds < 0 {
+		return nil, &ValidationError{"duration can not be negative"}
+	}
+	if c.Distance < 0 {
+		return nil, &ValidationError{"distance can not be negative"}
+	}
+	if c.ElevationGain < 0 {
+		return nil, &ValidationError{"elevation gain can not be negative"}
+	}
+	for _, hr := range []int{c.AvgHeartRate, c.MaxHeartRate} {
+		if hr < 0 || hr > MaxHeartRate {
+			return nil, &ValidationError{fmt.Sprintf("heart rate must be between 0 and %d", MaxHeartRate)}
+		}
+	}
+	if c.MaxHeartRate != 0 && c.AvgHeartRate > c.MaxHeartRate {
+		return nil, &ValidationError{"average heart rate can not exceed the maximum heart rate"}
+	}
+	if c.AvgPower < 0 || c.MaxPower < 0 || c.AvgCadence < 0 || c.MaxCadence < 0 {
+		return nil, &ValidationError{"power and cadence can not be negative"}
+	}
+	metrics := CardioMetrics{
+		DurationSeconds: c.DurationSeconds,
+		AvgHeartRate:    c.AvgHeartRate,
+		MaxHeartRate:    c.MaxHeartRate,
+		AvgPower:        c.AvgPower,
+		MaxPower:        c.MaxPower,
+		AvgCadence:      c.AvgCadence,
+		MaxCadence:      c.MaxCadence,
+	}
+	switch strings.ToLower(c.Unit) {
+	case "", UnitKilometer:
+		metrics.Unit = UnitKilometer
+		metrics.DistanceM = c.Distance * MetersPerKilometer
+		metrics.ElevationGainM = c.ElevationGain
+	case UnitMile:
+		metrics.Unit = UnitMile
+		metrics.DistanceM = c.Distance * MetersPerMile
+		metrics.ElevationGainM = c.ElevationGain * MetersPerFoot
+	default:
+		return nil, &ValidationError{fmt.Sprintf("unsupported distance unit '%s', valid units are km, mi", c.Unit)}
+	}
+	for i := range c.Laps {
+		if len(c.Laps[i].Laps) > 0 {
+			return nil, &ValidationError{"a lap can not contain laps"}
+		}
+		lap := c.Laps[i]
+		lap.Unit = metrics.Unit
+		l, err := NewCardioMetrics(&lap)
+		if err != nil {
+			return nil, &ValidationError{fmt.Sprintf("lap %d, %s", i+1, err)}
+		}
+		l.Unit = ""
+		metrics.Laps = append(metrics.Laps, *l)
+	}
+	return &metrics, nil
+}
+
+// Speed the average speed in meters per second, 0 if either the
+// duration or distance is not known
+func (c *CardioMetrics) Speed() float64 {
+	if c.DurationSeconds == 0 || c.DistanceM == 0 {
+		return 0
+	}
+	return c.DistanceM / float64(c.DurationSeconds)
+}
+
+// Pace the average time in seconds taken to cover a kilometer, 0 if
+// either the duration or distance is not known
+func (c *CardioMetrics) Pace() float64 {
+	if c.DurationSeconds == 0 || c.DistanceM == 0 {
+		return 0
+	}
+	return float64(c.DurationSeconds) / c.DistanceM * MetersPerKilometer
+}
+
+// Convert transform into a client facing CardioMetrics object. Distances,
+// pace and speed are expressed in the unit the distance was entered in.
+func (c *CardioMetrics) Convert() client.CardioMetrics {
+	return c.convert(c.Unit)
+}
+
+// convert transform into a client facing CardioMetrics object expressed in the unit
+func (c *CardioMetrics) convert(unit string) client.CardioMetrics {
+	metersPerUnit, elevationPerMeter := MetersPerKilometer, 1.0
+	if unit == UnitMile {
+		metersPerUnit, elevationPerMeter = MetersPerMile, 1/MetersPerFoot
+	}
+	metrics := client.CardioMetrics{
+		DurationSeconds: c.DurationSeconds,
+		Distance:        round(c.DistanceM/metersPerUnit, 3),
+		Unit:            unit,
+		AvgHeartRate:    c.AvgHeartRate,
+		MaxHeartRate:    c.MaxHeartRate,
+		ElevationGain:   round(c.ElevationGainM*elevationPerMeter, 1),
+		AvgPower:        c.AvgPower,
+		MaxPower:        c.MaxPower,
+		AvgCadence:      c.AvgCadence,
+		MaxCadence:      c.MaxCadence,
+	}
+	if speed := c.Speed(); speed > 0 {
+		metrics.Speed = round(speed*3600/metersPerUnit, 2)
+		metrics.Pace = round(metersPerUnit/speed, 1)
+	}
+	for i := range c.Laps {
+		metrics.Laps = append(metrics.Laps, c.Laps[i].convert(unit))
+	}
+	return metrics
+}
@@ -0,0 +1,90 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCardioMetrics(t *testing.T) {
+	// 5km in 30 minutes
+	metrics, err := db.NewCardioMetrics(&client.CardioMetrics{
+		DurationSeconds: 1800,
+		Distance:        5,
+		AvgHeartRate:    145,
+		MaxHeartRate:    172,
+		ElevationGain:   45,
+		Pace:            1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, db.UnitKilometer, metrics.Unit)
+	assert.Equal(t, 5000.0, metrics.DistanceM)
+	assert.Equal(t, 45.0, metrics.ElevationGainM)
+	assert.InDelta(t, 2.778, metrics.Speed(), 0.001)
+	assert.Equal(t, 360.0, metrics.Pace())
+	cMetrics := metrics.Convert()
+	assert.Equal(t, 5.0, cMetrics.Distance)
+	assert.Equal(t, 360.0, cMetrics.Pace)
+	assert.Equal(t, 10.0, cMetrics.Speed)
+
+	// 3 miles in 24 minutes with 100ft of climbing
+	metrics, err = db.NewCardioMetrics(&client.CardioMetrics{
+		DurationSeconds: 1440,
+		Distance:        3,
+		Unit:            "MI",
+		ElevationGain:   100,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 4828.032, metrics.DistanceM, 0.0001)
+	assert.InDelta(t, 30.48, metrics.ElevationGainM, 0.0001)
+	cMetrics = metrics.Convert()
+	assert.Equal(t, db.UnitMile, cMetrics.Unit)
+	assert.Equal(t, 3.0, cMetrics.Distance)
+	assert.Equal(t, 100.0, cMetrics.ElevationGain)
+	assert.Equal(t, 480.0, cMetrics.Pace)
+	assert.Equal(t, 7.5, cMetrics.Speed)
+
+	// Pace and speed are only reported when both duration and distance are known
+	metrics, err = db.NewCardioMetrics(&client.CardioMetrics{DurationSeconds: 600})
+	assert.NoError(t, err)
+	assert.Zero(t, metrics.Convert().Pace)
+	assert.Zero(t, metrics.Convert().Speed)
+
+	// Laps are expressed in the unit of the metrics they belong to
+	metrics, err = db.NewCardioMetrics(&client.CardioMetrics{
+		Distance: 2,
+		Unit:     "mi",
+		AvgPower: 240,
+		Laps: []client.CardioMetrics{
+			client.CardioMetrics{DurationSeconds: 480, Distance: 1, Unit: "km"},
+			client.CardioMetrics{DurationSeconds: 420, Distance: 1},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(metrics.Laps)) {
+		assert.InDelta(t, db.MetersPerMile, metrics.Laps[0].DistanceM, 0.001)
+		cMetrics = metrics.Convert()
+		assert.Equal(t, 240, cMetrics.AvgPower)
+		assert.Equal(t, db.UnitMile, cMetrics.Laps[1].Unit)
+		assert.Equal(t, 420.0, cMetrics.Laps[1].Pace)
+	}
+
+	invalid := []client.CardioMetrics{
+		client.CardioMetrics{DurationSeconds: -1},
+		client.CardioMetrics{Distance: -1},
+		client.CardioMetrics{ElevationGain: -1},
+		client.CardioMetrics{AvgHeartRate: 300},
+		client.CardioMetrics{AvgHeartRate: 150, MaxHeartRate: 140},
+		client.CardioMetrics{Distance: 1, Unit: "yd"},
+		client.CardioMetrics{AvgPower: -1},
+		client.CardioMetrics{Laps: []client.CardioMetrics{client.CardioMetrics{MaxHeartRate: 300}}},
+		client.CardioMetrics{Laps: []client.CardioMetrics{
+			client.CardioMetrics{Laps: []client.CardioMetrics{client.CardioMetrics{}}}}},
+	}
+	for _, c := range invalid {
+		_, err := db.NewCardioMetrics(&c)
+		assert.Truef(t, db.IsValidation(err), "expected validation error for %+v, got %v", c, err)
+	}
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/enpointe/activity/chart"
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The progress charts of a user
+const (
+	ChartVolume   = "volume"
+	ChartRecords  = "records"
+	ChartDistance = "distance"
+)
+
+// MaxChartPoints the most days, weeks or months a chart can span
+const MaxChartPoints = 400
+
+// ChartQuery the progress chart of a user to render. The chart plots the
+// statistics of each day, week or month of the range of the StatsQuery, the
+// volume lifted, the distance covered or the best one rep max estimated so
+// far. The records chart requires an exercise. A zero Width or Height uses
+// the default size of the chart.
+type ChartQuery struct {
+	StatsQuery
+	Chart  string
+	Width  int
+	Height int
+}
+
+// normalize apply the defaults for any unspecified fields of the query and
+// validate the result
+func (q *ChartQuery) normalize() error {
+	if q.Chart != ChartVolume && q.Chart != ChartRecords && q.Chart != ChartDistance {
+		return &ValidationError{fmt.Sprintf("invalid chart '%s', must be one of %s, %s or %s",
+			q.Chart, ChartVolume, ChartRecords, ChartDistance)}
+	}
+	if q.Chart == ChartRecords && len(q.ExerciseID) == 0 {
+		return &ValidationError{"the records chart requires an exercise"}
+	}
+	c := chart.Chart{Kind: chart.KindBar, Width: q.Width, Height: q.Height}
+	if err := c.Validate(); err != nil {
+		return &ValidationError{err.Error()}
+	}
+	if err := q.StatsQuery.normalize(); err != nil {
+		return err
+	}
+	if len(q.periods()) > MaxChartPoints {
+		return &ValidationError{fmt.Sprintf("a chart can span at most %d periods", MaxChartPoints)}
+	}
+	return nil
+}
+
+// periods the start of each day, week or month within the range of the query
+func (q *ChartQuery) periods() []time.Time {
+	periods := []time.Time{}
+	for t := q.periodStart(q.From).In(q.Location); t.Before(q.To); {
+		periods = append(periods, t.UTC())
+		if len(periods) > MaxChartPoints {
+			break
+		}
+		switch q.Period {
+		case PeriodDay:
+			t = t.AddDate(0, 0, 1)
+		case PeriodWeek:
+			t = t.AddDate(0, 0, 7)
+		default:
+			t = t.AddDate(0, 1, 0)
+		}
+	}
+	return periods
+}
+
+// label the label of the period starting at t, the year is included when
+// the range of the query spans more than one year
+func (q *ChartQuery) label(t time.Time) string {
+	t = t.In(q.Location)
+	switch {
+	case q.Period == PeriodMonth:
+		return t.Format("Jan 2006")
+	case q.From.In(q.Location).Year() != q.To.In(q.Location).Year():
+		return t.Format("2006-01-02")
+	default:
+		return t.Format("Jan 2")
+	}
+}
+
+// buildChart the chart of the statistics of the user, name is the name of the
+// exercise the statistics are restricted to, if any. Volume and distance are
+// plotted for every period of the range, records only from the first period
+// with an estimate as they can not decrease.
+func buildChart(stats *client.Stats, q *ChartQuery, name string) *chart.Chart {
+	totals := map[time.Time]client.StatsTotals{}
+	for _, p := range stats.Periods {
+		totals[p.Start.UTC()] = p.StatsTotals
+	}
+	c := chart.Chart{Width: q.Width, Height: q.Height, Points: []chart.Point{}}
+	per := "per " + q.Period
+	switch q.Chart {
+	case ChartVolume:
+		c.Title, c.Unit, c.Kind = "Volume "+per, UnitKilogram, chart.KindBar
+	case ChartDistance:
+		c.Title, c.Unit, c.Kind = "Distance "+per, UnitKilometer, chart.KindLine
+	case ChartRecords:
+		c.Title, c.Unit, c.Kind = "Estimated one rep max", UnitKilogram, chart.KindLine
+	}
+	if len(name) > 0 {
+		c.Title = fmt.Sprintf("%s, %s", name, c.Title)
+	}
+	best := 0.0
+	for _, start := range q.periods() {
+		t := totals[start]
+		var value float64
+		switch q.Chart {
+		case ChartVolume:
+			value = t.VolumeKg
+		case ChartDistance:
+			value = round(t.DistanceMeters/MetersPerKilometer, 2)
+		case ChartRecords:
+			best = math.Max(best, t.Est1RMKg)
+			if best == 0 {
+				continue
+			}
+			value = best
+		}
+		c.Points = append(c.Points, chart.Point{Label: q.label(start), Value: value})
+	}
+	return &c
+}
+
+// ComputeChart compute the progress chart of the workout sessions in memory,
+// name is the name of the exercise of the query, if any
+func ComputeChart(workouts []Workout, q ChartQuery, name string) (*chart.Chart, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	stats, err := ComputeStats(workouts, q.StatsQuery)
+	if err != nil {
+		return nil, err
+	}
+	return buildChart(stats, &q, name), nil
+}
+
+// Chart compute the progress chart of the user from the statistics of their
+// workout sessions
+func (s *WorkoutService) Chart(ctx context.Context, q ChartQuery) (*chart.Chart, error) {
+	uid, err := objectID(q.UserID, "user")
+	if err != nil {
+		return nil, err
+	}
+	if err = q.normalize(); err != nil {
+		return nil, err
+	}
+	name := ""
+	if !q.exercise.IsZero() {
+		var exercise Exercise
+		err = s.exercises.FindOne(ctx, bson.M{"_id": q.exercise}).Decode(&exercise)
+		if err == mongo.ErrNoDocuments {
+			return nil, &NotFoundError{fmt.Sprintf("exercise '%s' not found", q.ExerciseID)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		name = exercise.Name
+	}
+	stats, err := s.stats(ctx, uid, q.StatsQuery)
+	if err != nil {
+		return nil, err
+	}
+	return buildChart(stats, &q, name), nil
+}
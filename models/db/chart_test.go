@@ -0,0 +1,78 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/chart"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestComputeChart(t *testing.T) {
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	workouts := []db.Workout{}
+	for _, w := range statsWorkouts() {
+		workout, err := db.NewWorkout(uid, &w)
+		assert.NoError(t, err)
+		workouts = append(workouts, *workout)
+	}
+	query := db.ChartQuery{
+		StatsQuery: db.StatsQuery{
+			UserID:     testWorkoutUserID,
+			ExerciseID: testSquatID,
+			From:       time.Date(2019, 11, 11, 0, 0, 0, 0, time.UTC),
+			To:         time.Date(2019, 12, 2, 0, 0, 0, 0, time.UTC),
+		},
+		Chart: db.ChartVolume,
+	}
+	c, err := db.ComputeChart(workouts, query, "Squat")
+	assert.NoError(t, err)
+	assert.Equal(t, "Squat, Volume per week", c.Title)
+	assert.Equal(t, chart.KindBar, c.Kind)
+	// Weeks without a session are plotted as zero
+	assert.Equal(t, []chart.Point{
+		{Label: "Nov 11", Value: 0},
+		{Label: "Nov 18", Value: 1600},
+		{Label: "Nov 25", Value: 300},
+	}, c.Points)
+
+	// The best estimate so far, the 3 x 100 kg set does not beat 5 x 100 kg
+	query.Chart = db.ChartRecords
+	c, err = db.ComputeChart(workouts, query, "Squat")
+	assert.NoError(t, err)
+	assert.Equal(t, chart.KindLine, c.Kind)
+	assert.Equal(t, []chart.Point{
+		{Label: "Nov 18", Value: db.Epley(100, 5)},
+		{Label: "Nov 25", Value: db.Epley(100, 5)},
+	}, c.Points)
+
+	query.Chart = db.ChartDistance
+	query.ExerciseID = ""
+	query.Period = db.PeriodMonth
+	c, err = db.ComputeChart(workouts, query, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Distance per month", c.Title)
+	assert.Equal(t, []chart.Point{
+		{Label: "Nov 2019", Value: 15},
+		{Label: "Dec 2019", Value: 0},
+	}, c.Points)
+
+	// The records chart requires an exercise
+	query.Chart = db.ChartRecords
+	_, err = db.ComputeChart(workouts, query, "")
+	assert.True(t, db.IsValidation(err))
+	query.Chart = "pace"
+	_, err = db.ComputeChart(workouts, query, "")
+	assert.True(t, db.IsValidation(err))
+	query.Chart = db.ChartVolume
+	query.Width = chart.MaxSize + 1
+	_, err = db.ComputeChart(workouts, query, "")
+	assert.True(t, db.IsValidation(err))
+	query.Width = 0
+	query.Period = db.PeriodDay
+	query.From = query.To.AddDate(-2, 0, 0)
+	_, err = db.ComputeChart(workouts, query, "")
+	assert.True(t, db.IsValidation(err))
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The intensities an exercise can be performed at, each may override the
+// MET value of the exercise
+const (
+	IntensityLight    = "light"
+	IntensityModerate = "moderate"
+	IntensityVigorous = "vigorous"
+)
+
+// MaxMET the highest MET value accepted for an exercise, the most strenuous
+// activities of the Compendium of Physical Activities are below this
+const MaxMET = 25
+
+// SecondsPerRep the time a single rep is estimated to take, used to estimate
+// the duration of strength sets which only record reps and rest time
+const SecondsPerRep = 4
+
+// validIntensity is the intensity one of those an exercise can be performed at
+func validIntensity(intensity string) bool {
+	switch intensity {
+	case IntensityLight, IntensityModerate, IntensityVigorous:
+		return true
+	}
+	return false
+}
+
+// validMET ensure a MET value is within the range accepted
+func validMET(met float64) error {
+	if met < 0 || met > MaxMET {
+		return &ValidationError{fmt.Sprintf("met must be between 0 and %d", MaxMET)}
+	}
+	return nil
+}
+
+// METFor the MET value of the exercise performed at the intensity. The value
+// for the intensity overrides that of the exercise, 0 if neither is known.
+func (e *Exercise) METFor(intensity string) float64 {
+	if met, ok := e.Intensities[intensity]; ok && met > 0 {
+		return met
+	}
+	return e.MET
+}
+
+// entrySeconds the duration of an exercise entry. The duration of cardio is
+// recorded, that of strength sets is estimated from their reps and rest time.
+func entrySeconds(e *WorkoutExercise) int {
+	if e.Cardio != nil && e.Cardio.DurationSeconds > 0 {
+		return e.Cardio.DurationSeconds
+	}
+	seconds := 0
+	for _, set := range e.Sets {
+		seconds += set.Reps*SecondsPerRep + set.RestSeconds
+	}
+	return seconds
+}
+
+// EstimateKcal the energy, in kilocalories, expended performing an activity
+// of the MET value for the number of seconds by a person of the body weight
+func EstimateKcal(met float64, weightKg float64, seconds int) float64 {
+	return round(met*weightKg*float64(seconds)/3600, 1)
+}
+
+// estimateEnergy set the estimated energy expended for each exercise entry of
+// the workout from the MET value of the exercise and the body weight of the
+// user. No estimate is made if the body weight of the user is not known.
+func (s *WorkoutService) estimateEnergy(ctx context.Context, workout *Workout) error {
+	var user User
+	err := s.users.FindOne(ctx, bson.M{"_id": workout.UserID}).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+	ids := []primitive.ObjectID{}
+	for _, e := range workout.Exercises {
+		ids = append(ids, e.ExerciseID)
+	}
+	cursor, err := s.exercises.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	exercises := []Exercise{}
+	if err = cursor.All(ctx, &exercises); err != nil {
+		return err
+	}
+	mets := map[primitive.ObjectID]*Exercise{}
+	for i := range exercises {
+		mets[exercises[i].ID] = &exercises[i]
+	}
+	for i := range workout.Exercises {
+		e := &workout.Exercises[i]
+		e.Kcal = 0
+		if ex, ok := mets[e.ExerciseID]; ok {
+			e.Kcal = EstimateKcal(ex.METFor(e.Intensity), user.WeightKg, entrySeconds(e))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewExerciseMET(t *testing.T) {
+	e, err := db.NewExercise(&client.Exercise{Name: "rowing", MET: 7,
+		Intensities: map[string]float64{db.IntensityVigorous: 12}})
+	assert.NoError(t, err)
+	assert.Equal(t, 7.0, e.METFor(""))
+	assert.Equal(t, 7.0, e.METFor(db.IntensityLight))
+	assert.Equal(t, 12.0, e.METFor(db.IntensityVigorous))
+	assert.Equal(t, 7.0, e.Convert().MET)
+
+	_, err = db.NewExercise(&client.Exercise{Name: "rowing", MET: db.MaxMET + 1})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewExercise(&client.Exercise{Name: "rowing", MET: -1})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewExercise(&client.Exercise{Name: "rowing",
+		Intensities: map[string]float64{"extreme": 14}})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewExercise(&client.Exercise{Name: "rowing",
+		Intensities: map[string]float64{db.IntensityLight: 30}})
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestEstimateKcal(t *testing.T) {
+	assert.Equal(t, 490.0, db.EstimateKcal(9.8, 75, 40*60))
+	assert.Equal(t, 0.0, db.EstimateKcal(9.8, 0, 40*60))
+	assert.Equal(t, 0.0, db.EstimateKcal(0, 75, 40*60))
+	assert.Equal(t, 2.1, db.EstimateKcal(5, 50, 30))
+}
+
+func TestNewWorkoutExerciseIntensity(t *testing.T) {
+	e, err := db.NewWorkoutExercise(&client.WorkoutExercise{ExerciseID: testRunningID, Intensity: "Vigorous"})
+	assert.NoError(t, err)
+	assert.Equal(t, db.IntensityVigorous, e.Intensity)
+
+	_, err = db.NewWorkoutExercise(&client.WorkoutExercise{ExerciseID: testRunningID, Intensity: "extreme"})
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestWorkoutEnergy(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	users := service.Collection.Database().Collection(db.UsersCollection)
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	_, err := users.DeleteOne(ctx, bson.M{"_id": uid})
+	assert.NoError(t, err)
+	defer users.DeleteOne(ctx, bson.M{"_id": uid})
+
+	w := client.Workout{
+		Start: time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC),
+		Exercises: []client.WorkoutExercise{
+			client.WorkoutExercise{
+				ExerciseID: testRunningID,
+				Intensity:  db.IntensityVigorous,
+				Cardio:     &client.CardioMetrics{DurationSeconds: 30 * 60},
+			},
+			client.WorkoutExercise{
+				ExerciseID: testSquatID,
+				Sets:       []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 225, Unit: "lb", RestSeconds: 180}},
+			},
+		},
+	}
+
+	// No estimate is made without the body weight of the user
+	id, err := service.Create(ctx, testWorkoutUserID, &w)
+	assert.NoError(t, err)
+	workout, err := service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, workout.Exercises[0].Kcal)
+
+	_, err = users.InsertOne(ctx, db.User{ID: uid, Username: "energy", WeightKg: 80, WeightUnit: db.UnitKilogram})
+	assert.NoError(t, err)
+	id, err = service.Create(ctx, testWorkoutUserID, &w)
+	assert.NoError(t, err)
+	workout, err = service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, db.EstimateKcal(11.8, 80, 30*60), workout.Exercises[0].Kcal)
+	assert.Equal(t, db.EstimateKcal(5, 80, 5*db.SecondsPerRep+180), workout.Exercises[1].Kcal)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// duplicateKeyCode the MongoDB server error code reported when a write
+// violates a unique index
+const duplicateKeyCode = 11000
+
+// ConflictError is returned when a create or update request would result
+// in a duplicate of an entry that must be unique, such as a username or
+// an exercise name
+type ConflictError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// IsConflict reports whether the error is a ConflictError
+func IsConflict(err error) bool {
+	_, ok := err.(*ConflictError)
+	return ok
+}
+
+// NotFoundError is returned when the entry a request refers to does not exist
+type NotFoundError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *NotFoundError) Error() string {
+	return e.Message
+}
+
+// IsNotFound reports whether the error is a NotFoundError
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
+
+// ValidationError is returned when the data of a request fails validation
+type ValidationError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// IsValidation reports whether the error is a ValidationError
+func IsValidation(err error) bool {
+	_, ok := err.(*ValidationError)
+	return ok
+}
+
+// IsDuplicateKeyError reports whether the error returned from a
+// write operation was caused by a unique index violation
+func IsDuplicateKeyError(err error) bool {
+	switch e := err.(type) {
+	case mongo.WriteException:
+		for _, we := range e.WriteErrors {
+			if we.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	case mongo.BulkWriteException:
+		for _, we := range e.WriteErrors {
+			if we.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	case mongo.CommandError:
+		return e.Code == duplicateKeyCode
+	}
+	return false
+}
@@ -1,37 +1,205 @@
 package db
 
 import (
+	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Exercise represents information for a type of exercise
+// Exercise represents information for a type of exercise along with its
+// place in the exercise taxonomy, its category, the muscle groups it works,
+// the equipment it requires, its difficulty and how it is measured.
+// Aliases are alternate names the exercise is known by. Keys holds the
+// normalized keys of the name and aliases, see NameKey, and is unique
+// across the exercises visible to a user. Owner is the user a private
+// exercise belongs to, exercises of the shared catalog have no owner. Media
+// are the images, animations and videos demonstrating the exercise. The name
+// and description are those of the DefaultLocale, Translations holds them in
+// other locales.
 type Exercise struct {
-	ID          primitive.ObjectID `bson:"_id,unique,omitempty" json:"_id,omitempty"`
-	Name        string             `bson:"name,omitempty" json:"name,omitempty"`
-	Description string             `bson:"description,omitempty" json:"description"`
+	ID               primitive.ObjectID     `bson:"_id,unique,omitempty" json:"_id,omitempty"`
+	Owner            primitive.ObjectID     `bson:"owner,omitempty" json:"owner,omitempty"`
+	Name             string                 `bson:"name,omitempty" json:"name,omitempty"`
+	Aliases          []string               `bson:"aliases,omitempty" json:"aliases,omitempty"`
+	Keys             []string               `bson:"keys,omitempty" json:"-"`
+	Description      string                 `bson:"description,omitempty" json:"description"`
+	MET              float64                `bson:"met,omitempty" json:"met,omitempty"`
+	Intensities      map[string]float64     `bson:"intensities,omitempty" json:"intensities,omitempty"`
+	Category         string                 `bson:"category,omitempty" json:"category,omitempty"`
+	PrimaryMuscles   []string               `bson:"primary_muscles,omitempty" json:"primary_muscles,omitempty"`
+	SecondaryMuscles []string               `bson:"secondary_muscles,omitempty" json:"secondary_muscles,omitempty"`
+	Equipment        []string               `bson:"equipment,omitempty" json:"equipment,omitempty"`
+	Difficulty       string                 `bson:"difficulty,omitempty" json:"difficulty,omitempty"`
+	Measurement      string                 `bson:"measurement,omitempty" json:"measurement,omitempty"`
+	Media            []Media                `bson:"media,omitempty" json:"media,omitempty"`
+	Translations     map[string]Translation `bson:"translations,omitempty" json:"translations,omitempty"`
 }
 
 // NewExercise transforms the web facing Exercise structure
 // to a database compatible Exercise structure. The ID field is
 // automatically set to a primitive.NewObjectID() any passed
-// in value is ignored
-func NewExercise(e *client.Exercise) *Exercise {
+// in value is ignored. The name of the exercise must be specified.
+// The aliases, MET values, taxonomy and translations of the exercise, if any,
+// are validated.
+func NewExercise(e *client.Exercise) (*Exercise, error) {
 	exercise := Exercise{
 		ID:          primitive.NewObjectID(),
 		Name:        strings.TrimSpace(e.Name),
 		Description: strings.TrimSpace(e.Description),
+		MET:         e.MET,
 	}
-	return &exercise
+	if len(exercise.Name) == 0 {
+		return nil, &ValidationError{"exercise name must be specified"}
+	}
+	if len(NameKey(exercise.Name)) == 0 {
+		return nil, &ValidationError{fmt.Sprintf("exercise name '%s' must contain a letter or digit", exercise.Name)}
+	}
+	if err := exercise.setAliases(e.Aliases); err != nil {
+		return nil, err
+	}
+	if err := validMET(e.MET); err != nil {
+		return nil, err
+	}
+	for intensity, met := range e.Intensities {
+		if !validIntensity(intensity) {
+			return nil, &ValidationError{fmt.Sprintf("invalid intensity '%s', must be one of %s, %s or %s",
+				intensity, IntensityLight, IntensityModerate, IntensityVigorous)}
+		}
+		if err := validMET(met); err != nil {
+			return nil, err
+		}
+		if exercise.Intensities == nil {
+			exercise.Intensities = map[string]float64{}
+		}
+		exercise.Intensities[intensity] = met
+	}
+	if err := exercise.setTaxonomy(e); err != nil {
+		return nil, err
+	}
+	if err := exercise.setTranslations(e.Translations); err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+// visibleTo the condition on the owner of the exercises visible to the user,
+// the shared catalog along with their own private exercises. Without a user
+// only the shared catalog is visible.
+func visibleTo(userID primitive.ObjectID) interface{} {
+	if userID.IsZero() {
+		return nil
+	}
+	return bson.M{"$in": bson.A{nil, userID}}
+}
+
+// NameKey the key exercise names are compared by, the letters and digits of
+// the name in lower case. Names differing only in case, spacing or
+// punctuation, such as "Push-Up", "push up" and "Pushup", share a key.
+func NameKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// setAliases validate and set the aliases of the exercise along with its
+// keys. Aliases sharing a key with the name or another alias are dropped.
+func (e *Exercise) setAliases(aliases []string) error {
+	e.Aliases = nil
+	for _, a := range aliases {
+		a = strings.TrimSpace(a)
+		if len(NameKey(a)) == 0 {
+			return &ValidationError{fmt.Sprintf("alias '%s' must contain a letter or digit", a)}
+		}
+		if oneOf(NameKey(a), e.keys()) {
+			continue
+		}
+		e.Aliases = append(e.Aliases, a)
+	}
+	e.Keys = e.keys()
+	return nil
+}
+
+// keys the keys of the name and aliases of the exercise, name first
+func (e *Exercise) keys() []string {
+	keys := []string{NameKey(e.Name)}
+	for _, a := range e.Aliases {
+		if k := NameKey(a); !oneOf(k, keys) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// setTaxonomy validate and set the taxonomy of the exercise. A muscle group
+// can not be both a primary and a secondary muscle group of the exercise.
+func (e *Exercise) setTaxonomy(c *client.Exercise) error {
+	var err error
+	if e.Category, err = taxonomyValue("category", c.Category, Categories); err != nil {
+		return err
+	}
+	if e.Difficulty, err = taxonomyValue("difficulty", c.Difficulty, Difficulties); err != nil {
+		return err
+	}
+	if e.Measurement, err = taxonomyValue("measurement", c.Measurement, Measurements); err != nil {
+		return err
+	}
+	if e.PrimaryMuscles, err = taxonomyList("muscle group", c.PrimaryMuscles, MuscleGroups); err != nil {
+		return err
+	}
+	if e.SecondaryMuscles, err = taxonomyList("muscle group", c.SecondaryMuscles, MuscleGroups); err != nil {
+		return err
+	}
+	for _, m := range e.SecondaryMuscles {
+		if oneOf(m, e.PrimaryMuscles) {
+			return &ValidationError{fmt.Sprintf("muscle group '%s' can not be both primary and secondary", m)}
+		}
+	}
+	if e.Equipment, err = taxonomyList("equipment", c.Equipment, Equipment); err != nil {
+		return err
+	}
+	return nil
 }
 
 // Convert transform into a client facing Exercise object
 func (e *Exercise) Convert() client.Exercise {
+	var owner string
+	if !e.Owner.IsZero() {
+		owner = e.Owner.Hex()
+	}
+	var media []client.Media
+	for i := range e.Media {
+		media = append(media, e.Media[i].Convert())
+	}
+	var translations map[string]client.Translation
+	for locale, t := range e.Translations {
+		if translations == nil {
+			translations = map[string]client.Translation{}
+		}
+		translations[locale] = client.Translation{Name: t.Name, Description: t.Description}
+	}
 	return client.Exercise{
-		ID:          e.ID.Hex(),
-		Name:        e.Name,
-		Description: e.Description,
+		ID:               e.ID.Hex(),
+		Owner:            owner,
+		Name:             e.Name,
+		Aliases:          e.Aliases,
+		Description:      e.Description,
+		MET:              e.MET,
+		Intensities:      e.Intensities,
+		Category:         e.Category,
+		PrimaryMuscles:   e.PrimaryMuscles,
+		SecondaryMuscles: e.SecondaryMuscles,
+		Equipment:        e.Equipment,
+		Difficulty:       e.Difficulty,
+		Measurement:      e.Measurement,
+		Media:            media,
+		Translations:     translations,
 	}
 }
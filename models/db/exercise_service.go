@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"strings"
 
+	"github.com/enpointe/activity/blob"
 	"github.com/enpointe/activity/models/client"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,39 +16,100 @@ import (
 )
 
 // ExerciseCollection name of the collection used to hold exercise information
-const ExerciseCollection = "testdata/exercises"
+const ExerciseCollection = "exercises"
 
 // ExerciseService holds a entry to the Exercise Collection in the database
 type ExerciseService struct {
 	Collection *mongo.Collection
 	log        *log.Logger
+	templates  *mongo.Collection
+	workouts   *WorkoutService
 }
 
 // NewExerciseService create a new instance of the Exercise Service
-func NewExerciseService(database *mongo.Database, logger *log.Logger) (*ExerciseService, error) {
-	collection := database.Collection(ExerciseCollection)
+func NewExerciseService(database *mongo.Database, logger *log.Logger,
+	opts ...ServiceOption) (*ExerciseService, error) {
+	collection := database.Collection(CollectionName(ExerciseCollection, opts...))
+	workouts, err := NewWorkoutService(database, opts...)
+	if err != nil {
+		return nil, err
+	}
 	return &ExerciseService{
-		Collection: collection, log: logger}, nil
+		Collection: collection, log: logger,
+		templates: database.Collection(CollectionName(TemplatesCollection, opts...)),
+		workouts:  workouts}, nil
+}
+
+// byName the query matching the exercises visible to the owner of the exercise
+// whose name or aliases share a key with the name or aliases of the exercise.
+// Exercises stored before keys were introduced are matched by name, ignoring case.
+func byName(e *Exercise) bson.M {
+	return bson.M{"$or": bson.A{bson.M{"keys": bson.M{"$in": e.keys()}}, bson.M{"name": e.Name}},
+		"owner": visibleTo(e.Owner)}
+}
+
+// checkName ensure no other exercise has a name or alias sharing a key with
+// the name or aliases of the exercise. The names of private exercises must
+// be unique among the shared catalog and the other exercises of their owner,
+// the names of shared exercises among the shared catalog.
+func (s *ExerciseService) checkName(ctx context.Context, e *Exercise) error {
+	filter := byName(e)
+	filter["_id"] = bson.M{"$ne": e.ID}
+	var existing Exercise
+	err := s.Collection.FindOne(ctx, filter, options.FindOne().SetCollation(caseInsensitive)).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = &ConflictError{fmt.Sprintf("A entry matching the exercise name '%s' already exists, '%s'",
+		e.Name, existing.Name)}
+	log.Debug(err)
+	return err
 }
 
-// Create adds a new exercise to the database
+// Create adds a new exercise to the shared catalog
 func (s *ExerciseService) Create(ctx context.Context, ex *client.Exercise) error {
-	if len(strings.TrimSpace(ex.Name)) == 0 {
-		err := fmt.Errorf("exercise name must be specified")
+	exercise, err := NewExercise(ex)
+	if err != nil {
 		return err
 	}
-	exercise := NewExercise(ex)
+	return s.insert(ctx, exercise)
+}
 
-	// Check to make sure a exercise with the specified exercise name doesn't already exist
-	cursor := s.Collection.FindOne(ctx, bson.M{"name": exercise.Name})
-	if err := cursor.Err(); err == nil {
-		// A match for that user already exists
-		err = fmt.Errorf("A entry matching the exercise name '%s' already exists", exercise.Name)
-		log.Debug(err)
+// CreatePrivate adds a new exercise visible only to the user, returning its id
+func (s *ExerciseService) CreatePrivate(ctx context.Context, userID string, ex *client.Exercise) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	exercise, err := NewExercise(ex)
+	if err != nil {
+		return "", err
+	}
+	exercise.Owner = uid
+	if err = s.insert(ctx, exercise); err != nil {
+		return "", err
+	}
+	return exercise.ID.Hex(), nil
+}
+
+// insert store the new exercise, ensuring its name is unique
+func (s *ExerciseService) insert(ctx context.Context, exercise *Exercise) error {
+	// Check to make sure a exercise with the specified exercise name doesn't already exist.
+	// Exercise names and aliases are compared by their keys, ignoring case, spacing
+	// and punctuation
+	if err := s.checkName(ctx, exercise); err != nil {
 		return err
 	}
 
-	_, err := s.Collection.InsertOne(ctx, &exercise)
+	_, err := s.Collection.InsertOne(ctx, exercise)
+	if IsDuplicateKeyError(err) {
+		err = &ConflictError{fmt.Sprintf("A entry matching the exercise name '%s' already exists", exercise.Name)}
+		log.Debug(err)
+		return err
+	}
 	if err != nil {
 		log.Errorf("Insert of %s failed, %s", exercise.Name, err)
 	}
@@ -80,17 +141,72 @@ func (s *ExerciseService) DeleteAll(ctx context.Context) error {
 	return s.Collection.Drop(context.TODO())
 }
 
-// Update update an existing exercise. Only the name and/or description
-// field can be updated
+// DeleteByOwner remove the private exercises of the user, along with the images
+// and thumbnails of their media from the blob store, returning the number of
+// exercises deleted
+func (s *ExerciseService) DeleteByOwner(ctx context.Context, store blob.Store, userID string) (int, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return 0, err
+	}
+	cursor, err := s.Collection.Find(ctx, bson.M{"owner": uid, "media": bson.M{"$ne": nil}},
+		options.Find().SetProjection(bson.M{"media": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var owned []Exercise
+	if err = cursor.All(ctx, &owned); err != nil {
+		return 0, err
+	}
+	result, err := s.Collection.DeleteMany(ctx, bson.M{"owner": uid})
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range owned {
+		for i := range e.Media {
+			deleteBlobs(ctx, store, &e.Media[i])
+		}
+	}
+	return int(result.DeletedCount), nil
+}
+
+// Update update an existing exercise. Only the name, aliases, description,
+// MET values and taxonomy can be updated, the owner of the exercise is unchanged.
+// The translations of the exercise are managed by SetTranslation.
 func (s *ExerciseService) Update(ctx context.Context, e *client.Exercise) error {
 	idPrimitive, err := primitive.ObjectIDFromHex(e.ID)
 	if err != nil {
 		err = fmt.Errorf("invalid id %s, %s", e.ID, err)
 		return err
 	}
+	exercise, err := NewExercise(e)
+	if err != nil {
+		return err
+	}
+	var current Exercise
+	err = s.Collection.FindOne(ctx, bson.M{"_id": idPrimitive}).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		return fmt.Errorf("failed to update exercise %s, no match found", e.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update exercise %s, %s", e.ID, err)
+	}
+	exercise.ID, exercise.Owner = idPrimitive, current.Owner
+	if err = s.checkName(ctx, exercise); err != nil {
+		return err
+	}
 	filter := bson.M{"_id": idPrimitive}
-	update := bson.M{"$set": bson.M{"name": e.Name, "description": e.Description}}
+	update := bson.M{"$set": bson.M{"name": exercise.Name, "description": exercise.Description,
+		"aliases": exercise.Aliases, "keys": exercise.Keys,
+		"met": exercise.MET, "intensities": exercise.Intensities,
+		"category": exercise.Category, "primary_muscles": exercise.PrimaryMuscles,
+		"secondary_muscles": exercise.SecondaryMuscles, "equipment": exercise.Equipment,
+		"difficulty": exercise.Difficulty, "measurement": exercise.Measurement}}
 	updateResult, err := s.Collection.UpdateOne(ctx, filter, update)
+	if IsDuplicateKeyError(err) {
+		err = &ConflictError{fmt.Sprintf("A entry matching the exercise name '%s' already exists", exercise.Name)}
+		return err
+	}
 	if err != nil {
 		err = fmt.Errorf("failed to update exercise %s, %s", e.ID, err)
 		return err
@@ -132,9 +248,11 @@ func (s *ExerciseService) GetByID(ctx context.Context, hexid string) (*client.Ex
 	return &cExercise, nil
 }
 
-// GetByName retrieve the details of an exercise
+// GetByName retrieve the details of an exercise of the shared catalog by its
+// name or one of its aliases. Names are compared by their keys, see NameKey,
+// so "push up" retrieves the exercise named "Pushup".
 func (s *ExerciseService) GetByName(ctx context.Context, name string) (*client.Exercise, error) {
-	exercise, err := s.getOne(ctx, bson.D{primitive.E{Key: "name", Value: name}})
+	exercise, err := s.getOne(ctx, byName(&Exercise{Name: name}))
 	if err != nil {
 		return nil, err
 	}
@@ -142,10 +260,23 @@ func (s *ExerciseService) GetByName(ctx context.Context, name string) (*client.E
 	return &cExercise, nil
 }
 
-// GetAll retrieve a list of all known exercises
+// GetAll retrieve a list of the exercises of the shared catalog
 func (s *ExerciseService) GetAll(ctx context.Context) ([]*client.Exercise, error) {
-	var results []*client.Exercise
-	cursor, err := s.Collection.Find(ctx, bson.D{{}})
+	return s.Find(ctx, &ExerciseFilter{})
+}
+
+// Find retrieve the exercises with the attributes of the filter, ordered by
+// name. The private exercises of the user of the filter are listed along with
+// the shared catalog. The exercises are localized for the locales of the
+// filter, see Exercise.Localize.
+func (s *ExerciseService) Find(ctx context.Context, filter *ExerciseFilter) ([]*client.Exercise, error) {
+	query, err := filter.query()
+	if err != nil {
+		return nil, err
+	}
+	results := []*client.Exercise{}
+	cursor, err := s.Collection.Find(ctx, query,
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetCollation(caseInsensitive))
 	if err != nil {
 		return nil, err
 	}
@@ -159,15 +290,11 @@ func (s *ExerciseService) GetAll(ctx context.Context) ([]*client.Exercise, error
 		var elem Exercise
 		err := cursor.Decode(&elem)
 		if err != nil {
-			log.Errorf("failed to decode exercise %s", elem)
+			log.Errorf("failed to decode exercise %v", elem)
 			return nil, err
 		}
 
-		exercise := client.Exercise{
-			ID:          elem.ID.Hex(),
-			Name:        elem.Name,
-			Description: elem.Description,
-		}
+		exercise := elem.Localize(filter.Locales)
 		results = append(results, &exercise)
 	}
 
@@ -177,9 +304,70 @@ func (s *ExerciseService) GetAll(ctx context.Context) ([]*client.Exercise, error
 	return results, nil
 }
 
+// RebuildKeys compute the keys of the exercises stored without them,
+// returning the number of exercises updated. Exercises whose name shares a
+// key with another exercise are left without keys and reported, they should
+// be merged, see Merge.
+func (s *ExerciseService) RebuildKeys(ctx context.Context) (int, error) {
+	cursor, err := s.Collection.Find(ctx, bson.M{"keys": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return 0, err
+	}
+	exercises := []Exercise{}
+	if err = cursor.All(ctx, &exercises); err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, e := range exercises {
+		_, err = s.Collection.UpdateOne(ctx, bson.M{"_id": e.ID}, bson.M{"$set": bson.M{"keys": e.keys()}})
+		if IsDuplicateKeyError(err) {
+			log.Warnf("exercise '%s' (%s) duplicates another exercise and should be merged", e.Name, e.ID.Hex())
+			continue
+		}
+		if err != nil {
+			return cnt, err
+		}
+		cnt++
+	}
+	return cnt, nil
+}
+
+// Promote move the private exercise of the user into the shared catalog,
+// making it visible to every user. The name and aliases of the exercise
+// must not conflict with those of the shared catalog.
+func (s *ExerciseService) Promote(ctx context.Context, userID string, hexid string) (*client.Exercise, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	exercise, err := s.getExercise(ctx, hexid)
+	if err != nil {
+		return nil, err
+	}
+	if exercise.Owner != uid {
+		return nil, &NotFoundError{fmt.Sprintf("private exercise '%s' not found", hexid)}
+	}
+	exercise.Owner = primitive.NilObjectID
+	if err = s.checkName(ctx, exercise); err != nil {
+		return nil, err
+	}
+	_, err = s.Collection.UpdateOne(ctx, bson.M{"_id": exercise.ID}, bson.M{"$unset": bson.M{"owner": ""}})
+	if IsDuplicateKeyError(err) {
+		return nil, &ConflictError{fmt.Sprintf("A entry matching the exercise name '%s' already exists",
+			exercise.Name)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	cExercise := exercise.Convert()
+	return &cExercise, nil
+}
+
 // LoadFromFile load json data from a file directly into a database.
 // If the ID field of the exercise data is not set, ie ObjectID.IsZero(),
-// a new ObjectID will be created for the exercise.
+// a new ObjectID will be created for the exercise. The keys of the name
+// and aliases of each exercise are computed.
 func (s *ExerciseService) LoadFromFile(ctx context.Context, filename string) error {
 	// Load values from JSON file to model
 	byteValues, err := ioutil.ReadFile(filename)
@@ -204,6 +392,7 @@ func (s *ExerciseService) LoadFromFile(ctx context.Context, filename string) err
 			// Not set
 			e.ID = primitive.NewObjectID()
 		}
+		e.Keys = e.keys()
 		exercisesToAdd = append(exercisesToAdd, e)
 	}
 	// Insert exercise into DB
@@ -177,6 +177,14 @@ func TestUpdateExercise(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, e.Description, update.Description)
 
+	// The name and description are stored trimmed, as they are validated
+	e.Name, e.Description = "  Running  ", " the action of a runner. "
+	assert.NoError(t, service.Update(ctx, e))
+	update, err = service.GetByID(ctx, e.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Running", update.Name)
+	assert.Equal(t, "the action of a runner.", update.Description)
+
 	// Attempt update with bad ID
 	e.ID = "Jumping Jack"
 	err = service.Update(ctx, e)
@@ -222,3 +230,103 @@ func TestLoadExFromFile(t *testing.T) {
 	err = service.LoadFromFile(ctx, "testdata/invalid.json")
 	assert.Error(t, err)
 }
+
+func TestNameKey(t *testing.T) {
+	for _, name := range []string{"Push-Up", "push up", "Pushup", " PUSH_UP "} {
+		assert.Equal(t, "pushup", db.NameKey(name), name)
+	}
+	assert.Equal(t, "", db.NameKey("--"))
+
+	e, err := db.NewExercise(&client.Exercise{Name: "Pushup", Aliases: []string{" Press-Up ", "push up", "press up"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Press-Up"}, e.Aliases)
+	assert.Equal(t, []string{"pushup", "pressup"}, e.Keys)
+	_, err = db.NewExercise(&client.Exercise{Name: "Pushup", Aliases: []string{"-"}})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewExercise(&client.Exercise{Name: "!"})
+	assert.True(t, db.IsValidation(err))
+}
+
+// TestExerciseAliases ensure names differing only in case, spacing or punctuation
+// and aliases are treated as the same exercise
+func TestExerciseAliases(t *testing.T) {
+	service := SetupExercise(t, true, true)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+	assert.NoError(t, db.EnsureIndexes(ctx, service.Collection.Database()))
+
+	for _, name := range []string{"pushup", "Push-Up", "sit up"} {
+		err := service.Create(ctx, &client.Exercise{Name: name})
+		assert.True(t, db.IsConflict(err), name)
+	}
+	err := service.Create(ctx, &client.Exercise{Name: "Plank", Aliases: []string{"Front Plank", "Jumping-Jack"}})
+	assert.True(t, db.IsConflict(err))
+	assert.NoError(t, service.Create(ctx, &client.Exercise{Name: "Plank", Aliases: []string{"Front Plank"}}))
+
+	for _, name := range []string{"push up", "Pushup", "front-plank"} {
+		_, err := service.GetByName(ctx, name)
+		assert.NoError(t, err, name)
+	}
+	e, err := service.GetByName(ctx, "Front Plank")
+	assert.NoError(t, err)
+	assert.Equal(t, "Plank", e.Name)
+
+	// Renaming an exercise to an alias of another conflicts
+	running, err := service.GetByName(ctx, "running")
+	assert.NoError(t, err)
+	running.Aliases = []string{"front plank"}
+	assert.True(t, db.IsConflict(service.Update(ctx, running)))
+	running.Aliases = []string{"jog"}
+	assert.NoError(t, service.Update(ctx, running))
+	e, err = service.GetByName(ctx, "Jog")
+	assert.NoError(t, err)
+	assert.Equal(t, running.ID, e.ID)
+}
+
+func TestPrivateExercises(t *testing.T) {
+	service := SetupExercise(t, true, true)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+	assert.NoError(t, db.EnsureIndexes(ctx, service.Collection.Database()))
+	owner, other := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+
+	// Private names are unique among the shared catalog and the exercises of the owner
+	_, err := service.CreatePrivate(ctx, owner, &client.Exercise{Name: "push up"})
+	assert.True(t, db.IsConflict(err))
+	id, err := service.CreatePrivate(ctx, owner, &client.Exercise{Name: "Sled Push"})
+	assert.NoError(t, err)
+	_, err = service.CreatePrivate(ctx, owner, &client.Exercise{Name: "sled-push"})
+	assert.True(t, db.IsConflict(err))
+	otherID, err := service.CreatePrivate(ctx, other, &client.Exercise{Name: "Sled Push"})
+	assert.NoError(t, err)
+
+	// Only the shared catalog and the exercises of the user are listed
+	shared, err := service.GetAll(ctx)
+	assert.NoError(t, err)
+	visible, err := service.Find(ctx, &db.ExerciseFilter{UserID: owner})
+	assert.NoError(t, err)
+	assert.Equal(t, len(shared)+1, len(visible))
+	result, err := service.Search(ctx, db.SearchQuery{UserID: owner, Text: "sled"})
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, result.Total) {
+		assert.Equal(t, id, result.Results[0].ID)
+		assert.Equal(t, owner, result.Results[0].Owner)
+	}
+	result, err = service.Search(ctx, db.SearchQuery{Text: "sled"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Total)
+	_, err = service.GetByName(ctx, "Sled Push")
+	assert.Error(t, err)
+
+	// Promotion moves the exercise into the shared catalog
+	_, err = service.Promote(ctx, other, id)
+	assert.True(t, db.IsNotFound(err))
+	promoted, err := service.Promote(ctx, owner, id)
+	assert.NoError(t, err)
+	assert.Empty(t, promoted.Owner)
+	e, err := service.GetByName(ctx, "Sled Push")
+	assert.NoError(t, err)
+	assert.Equal(t, id, e.ID)
+	_, err = service.Promote(ctx, other, otherID)
+	assert.True(t, db.IsConflict(err))
+}
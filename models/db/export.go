@@ -0,0 +1,201 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportFormat the format data is exported in
+type ExportFormat string
+
+const (
+	// ExportJSONLines one MongoDB Extended JSON document per line,
+	// compatible with mongoexport and Import
+	ExportJSONLines ExportFormat = "jsonl"
+	// ExportCSV comma separated values with a header line naming
+	// the field held in each column
+	ExportCSV ExportFormat = "csv"
+)
+
+// ParseExportFormat convert a format name or file extension to an ExportFormat.
+// The names json, jsonl and ndjson all map to ExportJSONLines.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "json", "jsonl", "ndjson":
+		return ExportJSONLines, nil
+	case "csv":
+		return ExportCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported export format '%s', valid formats are jsonl, csv", name)
+	}
+}
+
+// ContentType the media type of data in the export format
+func (f ExportFormat) ContentType() string {
+	if f == ExportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// ExportOptions the options controlling an export
+type ExportOptions struct {
+	Format ExportFormat
+	// Fields the CSV columns, if not set the fields of the first document are used
+	Fields []string
+	// Exclude the fields omitted from the export
+	Exclude []string
+}
+
+// Export write every document of the collection to w in the requested format.
+// Documents are written as they are read so that large collections can be
+// streamed. Nested documents and arrays are written to CSV columns as Extended JSON.
+func Export(ctx context.Context, collection *mongo.Collection, w io.Writer, opts ExportOptions) error {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if len(opts.Exclude) > 0 {
+		projection := bson.M{}
+		for _, field := range opts.Exclude {
+			projection[field] = 0
+		}
+		findOptions.SetProjection(projection)
+	}
+	cursor, err := collection.Find(ctx, bson.D{}, findOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var fields []string
+	for _, f := range opts.Fields {
+		if !contains(opts.Exclude, f) {
+			fields = append(fields, f)
+		}
+	}
+
+	buffered := bufio.NewWriter(w)
+	csvWriter := csv.NewWriter(buffered)
+	cnt := 0
+	for cursor.Next(ctx) {
+		switch opts.Format {
+		case ExportJSONLines:
+			line, err := bson.MarshalExtJSON(cursor.Current, false, false)
+			if err != nil {
+				return err
+			}
+			buffered.Write(line)
+			buffered.WriteByte('\n')
+		case ExportCSV:
+			var doc bson.D
+			if err := bson.Unmarshal(cursor.Current, &doc); err != nil {
+				return err
+			}
+			if cnt == 0 {
+				if len(fields) == 0 {
+					for _, e := range doc {
+						fields = append(fields, e.Key)
+					}
+				}
+				if err := csvWriter.Write(fields); err != nil {
+					return err
+				}
+			}
+			if err := csvWriter.Write(csvRow(doc, fields)); err != nil {
+				return err
+			}
+			csvWriter.Flush()
+		default:
+			return fmt.Errorf("unsupported export format '%s'", opts.Format)
+		}
+		cnt++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if opts.Format == ExportCSV && cnt == 0 && len(fields) > 0 {
+		csvWriter.Write(fields)
+		csvWriter.Flush()
+	}
+	log.Debugf("exported %d documents from %s", cnt, collection.Name())
+	return buffered.Flush()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// csvRow convert the document into a CSV row with a column for each field
+func csvRow(doc bson.D, fields []string) []string {
+	row := make([]string, len(fields))
+	values := doc.Map()
+	for i, field := range fields {
+		row[i] = csvValue(values[field])
+	}
+	return row
+}
+
+// csvValue convert a document value into the string used in a CSV column
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+	case bson.D, bson.A, bson.M:
+		b, err := bson.MarshalExtJSON(bson.M{"v": v}, false, false)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		// Strip the {"v": ...} wrapper required to marshal a value
+		s := string(b)
+		return strings.TrimSuffix(strings.TrimPrefix(s, `{"v":`), "}")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Export write all users to w in the requested format. The hashed
+// passwords of the users are only included if explicitly requested, the
+// hashed calendar feed tokens never are.
+func (s *UserService) Export(ctx context.Context, w io.Writer, format ExportFormat,
+	includePasswords bool) error {
+	opts := ExportOptions{
+		Format: format,
+		Fields: []string{"_id", "user_id", "password", "privilege", "weight_kg", "weight_unit", "max_hr",
+			"resting_hr"},
+		Exclude: []string{"calendar_token"},
+	}
+	if !includePasswords {
+		opts.Exclude = append(opts.Exclude, "password")
+	}
+	return Export(ctx, s.Collection, w, opts)
+}
+
+// Export write all exercises to w in the requested format
+func (s *ExerciseService) Export(ctx context.Context, w io.Writer, format ExportFormat) error {
+	opts := ExportOptions{
+		Format: format,
+		Fields: []string{"_id", "owner", "name", "aliases", "description", "met", "intensities", "category",
+			"primary_muscles", "secondary_muscles", "equipment", "difficulty", "measurement", "media", "translations"},
+	}
+	return Export(ctx, s.Collection, w, opts)
+}
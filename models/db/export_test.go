@@ -0,0 +1,141 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	format, err := db.ParseExportFormat(".jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ExportJSONLines, format)
+	format, err = db.ParseExportFormat("csv")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ExportCSV, format)
+	_, err = db.ParseExportFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestExportUsers(t *testing.T) {
+	userService := SetupUser(t, true, true)
+	defer TeardownUser(t, userService)
+	ctx := context.TODO()
+
+	// Passwords are redacted by default
+	var buf bytes.Buffer
+	err := userService.Export(ctx, &buf, db.ExportJSONLines, false)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.NotContains(t, buf.String(), "password")
+	assert.Contains(t, lines[0], `{"$oid":"5db8e02b0e7aa732afd7fbc1"}`)
+
+	buf.Reset()
+	err = userService.Export(ctx, &buf, db.ExportCSV, true)
+	assert.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Equal(t, "_id,user_id,password,privilege,weight_kg,weight_unit,max_hr,resting_hr", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "5db8e02b0e7aa732afd7fbc1,customer1,$2a$"))
+
+	// An export including passwords can be imported back
+	err = userService.DeleteAll(ctx)
+	assert.NoError(t, err)
+	result, err := userService.Import(ctx, &buf, db.ImportCSV, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+	assert.Empty(t, result.Errors)
+}
+
+func TestExportUsersProfile(t *testing.T) {
+	userService := SetupUser(t, true, true)
+	defer TeardownUser(t, userService)
+	ctx := context.TODO()
+	assert.NoError(t, userService.SetBodyWeight(ctx, testAdminID, &client.BodyWeight{Weight: 176, Unit: "lb"}))
+	assert.NoError(t, userService.SetHeartRates(ctx, testAdminID, &client.HeartRates{Max: 185, Resting: 55}))
+	id, _ := primitive.ObjectIDFromHex(testAdminID)
+	_, err := userService.Collection.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"calendar_token": "hashed token"}})
+	assert.NoError(t, err)
+
+	// The calendar token is never exported
+	var buf bytes.Buffer
+	assert.NoError(t, userService.Export(ctx, &buf, db.ExportJSONLines, true))
+	assert.NotContains(t, buf.String(), "calendar_token")
+	assert.Contains(t, buf.String(), `"max_hr"`)
+
+	// The body weight and heart rates survive an export and import
+	buf.Reset()
+	assert.NoError(t, userService.Export(ctx, &buf, db.ExportCSV, true))
+	assert.NoError(t, userService.DeleteAll(ctx))
+	result, err := userService.Import(ctx, &buf, db.ImportCSV, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	info, err := userService.GetByID(ctx, testAdminID)
+	assert.NoError(t, err)
+	assert.Equal(t, 176.0, info.Weight)
+	assert.Equal(t, "lb", info.WeightUnit)
+	assert.Equal(t, 185, info.MaxHeartRate)
+	assert.Equal(t, 55, info.RestingHeartRate)
+
+	// Importing a user over an existing user replaces the imported fields,
+	// clearing those left empty, and keeps the fields not imported
+	_, err = userService.Collection.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"calendar_token": "hashed token"}})
+	assert.NoError(t, err)
+	result, err = userService.Import(ctx, strings.NewReader("user_id,password,privilege,weight_kg,max_hr,resting_hr\n"+
+		testAdminUsername+",changedPassword,admin,,180,50\n"), db.ImportCSV, db.ImportUpsert)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 1, result.Updated)
+	var user db.User
+	assert.NoError(t, userService.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user))
+	assert.Equal(t, "hashed token", user.CalendarToken)
+	assert.Equal(t, 180, user.MaxHR)
+	assert.Equal(t, 50, user.RestingHR)
+	assert.Equal(t, 0.0, user.WeightKg)
+	assert.Equal(t, "", user.WeightUnit)
+
+	// A staff user can be demoted to basic, the zero privilege
+	result, err = userService.Import(ctx, strings.NewReader("user_id,password,privilege\n"+
+		testStaffUsername+",changedPassword,basic\n"), db.ImportCSV, db.ImportUpsert)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	staff, err := userService.GetByUsername(ctx, testStaffUsername)
+	assert.NoError(t, err)
+	assert.Equal(t, "basic", staff.Privilege)
+
+	// The heart rates are validated as when set via the API
+	result, err = userService.Import(ctx, strings.NewReader("user_id,password,max_hr,resting_hr\n"+
+		"runner,aPassword,185,190\n"), db.ImportCSV, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(result.Errors)) {
+		assert.Contains(t, result.Errors[0].Message, "resting heart rate")
+	}
+}
+
+func TestExportExercises(t *testing.T) {
+	service := SetupExercise(t, true, true)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+
+	var buf bytes.Buffer
+	err := service.Export(ctx, &buf, db.ExportJSONLines)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 11, len(lines))
+
+	err = service.DeleteAll(ctx)
+	assert.NoError(t, err)
+	result, err := service.Import(ctx, &buf, db.ImportJSON, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Equal(t, 11, result.Inserted)
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The metrics progress towards a goal can be measured by
+const (
+	MetricSessions = "sessions"
+	MetricDuration = "duration"
+	MetricDistance = "distance"
+	MetricVolume   = "volume"
+	MetricMaxLoad  = "max-load"
+)
+
+// The status of a goal
+const (
+	GoalActive   = "active"
+	GoalAchieved = "achieved"
+	GoalMissed   = "missed"
+)
+
+// UnitSecond the unit of duration goals
+const UnitSecond = "s"
+
+// GoalEvent a change in the status of a goal, progress is in SI units
+type GoalEvent struct {
+	Status   string    `bson:"status" json:"status"`
+	Progress float64   `bson:"progress" json:"progress"`
+	At       time.Time `bson:"at" json:"at"`
+}
+
+// Goal a target set by a user. Target and Progress are held in SI units,
+// meters, seconds and kilograms. Unit is the unit the target was set in.
+// Sessions started at or after Start and before Deadline count towards
+// the goal.
+type Goal struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name       string             `bson:"name,omitempty" json:"name,omitempty"`
+	Metric     string             `bson:"metric" json:"metric"`
+	ExerciseID primitive.ObjectID `bson:"exercise_id,omitempty" json:"exercise_id,omitempty"`
+	Target     float64            `bson:"target" json:"target"`
+	Unit       string             `bson:"unit,omitempty" json:"unit,omitempty"`
+	Period     string             `bson:"period,omitempty" json:"period,omitempty"`
+	Timezone   string             `bson:"timezone" json:"timezone"`
+	Start      time.Time          `bson:"start" json:"start"`
+	Deadline   time.Time          `bson:"deadline" json:"deadline"`
+	Progress   float64            `bson:"progress" json:"progress"`
+	Status     string             `bson:"status" json:"status"`
+	History    []GoalEvent        `bson:"history" json:"history"`
+}
+
+// goalUnits the units a target may be specified in for each metric, the
+// first is the default
+var goalUnits = map[string][]string{
+	MetricSessions: []string{""},
+	MetricDuration: []string{UnitSecond},
+	MetricDistance: []string{UnitKilometer, UnitMile},
+	MetricVolume:   []string{UnitKilogram, UnitPound},
+	MetricMaxLoad:  []string{UnitKilogram, UnitPound},
+}
+
+// unitScale the number of SI units in one unit
+func unitScale(unit string) float64 {
+	switch unit {
+	case UnitKilometer:
+		return MetersPerKilometer
+	case UnitMile:
+		return MetersPerMile
+	case UnitPound:
+		return KilogramsPerPound
+	}
+	return 1
+}
+
+// NewGoal create the database representation of a goal of the user
+func NewGoal(userID primitive.ObjectID, g *client.Goal) (*Goal, error) {
+	goal := Goal{
+		ID:       primitive.NewObjectID(),
+		UserID:   userID,
+		Name:     strings.TrimSpace(g.Name),
+		Metric:   g.Metric,
+		Unit:     strings.ToLower(g.Unit),
+		Period:   g.Period,
+		Timezone: g.Timezone,
+		Start:    g.Start.UTC(),
+		Deadline: g.Deadline.UTC(),
+		History:  []GoalEvent{},
+	}
+	units, ok := goalUnits[goal.Metric]
+	if !ok {
+		return nil, &ValidationError{fmt.Sprintf("invalid metric '%s', must be one of %s, %s, %s, %s or %s",
+			g.Metric, MetricSessions, MetricDuration, MetricDistance, MetricVolume, MetricMaxLoad)}
+	}
+	if len(goal.Unit) == 0 {
+		goal.Unit = units[0]
+	}
+	valid := false
+	for _, u := range units {
+		valid = valid || u == goal.Unit
+	}
+	if !valid {
+		return nil, &ValidationError{fmt.Sprintf("invalid unit '%s' for metric %s", g.Unit, goal.Metric)}
+	}
+	if g.Target <= 0 {
+		return nil, &ValidationError{"target must be greater than 0"}
+	}
+	goal.Target = g.Target * unitScale(goal.Unit)
+	if len(g.ExerciseID) > 0 {
+		id, err := primitive.ObjectIDFromHex(g.ExerciseID)
+		if err != nil {
+			return nil, &ValidationError{fmt.Sprintf("invalid exercise id '%s'", g.ExerciseID)}
+		}
+		goal.ExerciseID = id
+	}
+
+	if len(goal.Timezone) == 0 {
+		goal.Timezone = time.UTC.String()
+	}
+	loc, err := time.LoadLocation(goal.Timezone)
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("unknown timezone '%s'", g.Timezone)}
+	}
+	if goal.Start.IsZero() {
+		goal.Start = time.Now().UTC()
+	}
+	if len(goal.Period) > 0 {
+		q := StatsQuery{Period: goal.Period, Location: loc}
+		if err := q.normalize(); err != nil {
+			return nil, err
+		}
+		start := q.periodStart(goal.Start).In(loc)
+		goal.Start = start.UTC()
+		switch goal.Period {
+		case PeriodDay:
+			goal.Deadline = start.AddDate(0, 0, 1).UTC()
+		case PeriodWeek:
+			goal.Deadline = start.AddDate(0, 0, 7).UTC()
+		case PeriodMonth:
+			goal.Deadline = start.AddDate(0, 1, 0).UTC()
+		}
+	}
+	if goal.Deadline.IsZero() {
+		return nil, &ValidationError{"a deadline or period must be specified"}
+	}
+	if !goal.Start.Before(goal.Deadline) {
+		return nil, &ValidationError{"deadline must be after start"}
+	}
+	return &goal, nil
+}
+
+// Convert the database representation of a goal to the client representation
+func (g *Goal) Convert() client.Goal {
+	scale := unitScale(g.Unit)
+	goal := client.Goal{
+		ID:       g.ID.Hex(),
+		Name:     g.Name,
+		Metric:   g.Metric,
+		Target:   round(g.Target/scale, 3),
+		Unit:     g.Unit,
+		Period:   g.Period,
+		Timezone: g.Timezone,
+		Start:    g.Start,
+		Deadline: g.Deadline,
+		Progress: round(g.Progress/scale, 3),
+		Percent:  round(g.Progress/g.Target*100, 1),
+		Status:   g.Status,
+		History:  []client.GoalEvent{},
+	}
+	if !g.ExerciseID.IsZero() {
+		goal.ExerciseID = g.ExerciseID.Hex()
+	}
+	for _, e := range g.History {
+		goal.History = append(goal.History,
+			client.GoalEvent{Status: e.Status, Progress: round(e.Progress/scale, 3), At: e.At})
+	}
+	return goal
+}
+
+// GoalProgress the progress, in SI units, made towards the goal by the
+// workout sessions. Only sessions started within the period of the goal,
+// and entries of the exercise of the goal, if any, count.
+func GoalProgress(g *Goal, workouts []Workout) float64 {
+	progress := 0.0
+	for i := range workouts {
+		w := &workouts[i]
+		if w.Start.Before(g.Start) || !w.Start.Before(g.Deadline) {
+			continue
+		}
+		included := g.ExerciseID.IsZero()
+		for j := range w.Exercises {
+			e := &w.Exercises[j]
+			if !g.ExerciseID.IsZero() && e.ExerciseID != g.ExerciseID {
+				continue
+			}
+			included = true
+			totals := entryTotals(e)
+			switch g.Metric {
+			case MetricDuration:
+				progress += float64(totals.DurationSeconds)
+			case MetricDistance:
+				progress += totals.DistanceM
+			case MetricVolume:
+				progress += totals.VolumeKg
+			case MetricMaxLoad:
+				for _, set := range e.Sets {
+					if set.Reps > 0 && set.LoadKg > progress {
+						progress = set.LoadKg
+					}
+				}
+			}
+		}
+		if included && g.Metric == MetricSessions {
+			progress++
+		}
+	}
+	return round(progress, 3)
+}
+
+// evaluate update the progress and status of the goal as of the time now. A
+// goal is achieved once the progress reaches the target and missed if the
+// deadline passes before it does. Each change of status is recorded in the
+// history of the goal. Returns true if the goal changed.
+func (g *Goal) evaluate(progress float64, now time.Time) bool {
+	status := GoalActive
+	if progress >= g.Target {
+		status = GoalAchieved
+	} else if !now.Before(g.Deadline) {
+		status = GoalMissed
+	}
+	changed := progress != g.Progress || status != g.Status
+	g.Progress = progress
+	if status != g.Status {
+		g.Status = status
+		g.History = append(g.History, GoalEvent{Status: status, Progress: progress, At: now.UTC()})
+	}
+	return changed
+}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GoalsCollection name of the collection used to hold the goals of users
+const GoalsCollection = "goals"
+
+// GoalService holds a entry to the collection of goals in the database
+type GoalService struct {
+	Collection *mongo.Collection
+	logs       *mongo.Collection
+	exercises  *mongo.Collection
+}
+
+// NewGoalService create a new instance of the Goal Service
+func NewGoalService(database *mongo.Database, opts ...ServiceOption) (*GoalService, error) {
+	return &GoalService{
+		Collection: database.Collection(CollectionName(GoalsCollection, opts...)),
+		logs:       database.Collection(CollectionName(LogsCollection, opts...)),
+		exercises:  database.Collection(CollectionName(ExerciseCollection, opts...)),
+	}, nil
+}
+
+// progress compute the progress towards the goal from the workout sessions of the user
+func (s *GoalService) progress(ctx context.Context, g *Goal) (float64, error) {
+	filter := bson.M{"user_id": g.UserID, "start": bson.M{"$gte": g.Start, "$lt": g.Deadline}}
+	if !g.ExerciseID.IsZero() {
+		filter["exercises.exercise_id"] = g.ExerciseID
+	}
+	cursor, err := s.logs.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	workouts := []Workout{}
+	if err = cursor.All(ctx, &workouts); err != nil {
+		return 0, err
+	}
+	return GoalProgress(g, workouts), nil
+}
+
+// refresh recompute the progress and status of the goal, storing the goal if it changed
+func (s *GoalService) refresh(ctx context.Context, g *Goal) error {
+	progress, err := s.progress(ctx, g)
+	if err != nil {
+		return err
+	}
+	if !g.evaluate(progress, time.Now()) {
+		return nil
+	}
+	_, err = s.Collection.ReplaceOne(ctx, bson.M{"_id": g.ID}, g)
+	return err
+}
+
+// find retrieve the goals matching the filter, refreshing any active goal
+// whose deadline has passed
+func (s *GoalService) find(ctx context.Context, filter bson.M) ([]*Goal, error) {
+	cursor, err := s.Collection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "deadline", Value: -1}, {Key: "_id", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	now := time.Now()
+	results := []*Goal{}
+	for cursor.Next(ctx) {
+		var elem Goal
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode goal %s", err)
+			return nil, err
+		}
+		if elem.Status == GoalActive && !now.Before(elem.Deadline) {
+			if err := s.refresh(ctx, &elem); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, &elem)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Create add a new goal for the user, the progress towards the goal is
+// computed from the sessions already logged
+func (s *GoalService) Create(ctx context.Context, userID string, g *client.Goal) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	goal, err := NewGoal(uid, g)
+	if err != nil {
+		return "", err
+	}
+	if !goal.ExerciseID.IsZero() {
+		cnt, err := s.exercises.CountDocuments(ctx, bson.M{"_id": goal.ExerciseID, "owner": visibleTo(uid)})
+		if err != nil {
+			return "", err
+		}
+		if cnt == 0 {
+			return "", &ValidationError{fmt.Sprintf("exercise '%s' does not exist", g.ExerciseID)}
+		}
+	}
+	progress, err := s.progress(ctx, goal)
+	if err != nil {
+		return "", err
+	}
+	goal.evaluate(progress, time.Now())
+	if _, err = s.Collection.InsertOne(ctx, goal); err != nil {
+		err = fmt.Errorf("Unable to store goal in database, %s", err)
+		log.Error(err)
+		return "", err
+	}
+	return goal.ID.Hex(), nil
+}
+
+// GetAll retrieve the goals of the user, those with the latest deadline
+// first. If a status is specified only goals with that status are returned.
+func (s *GoalService) GetAll(ctx context.Context, userID string, status string) ([]*client.Goal, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	goals, err := s.find(ctx, bson.M{"user_id": uid})
+	if err != nil {
+		return nil, err
+	}
+	results := []*client.Goal{}
+	for _, g := range goals {
+		if len(status) > 0 && g.Status != status {
+			continue
+		}
+		goal := g.Convert()
+		results = append(results, &goal)
+	}
+	return results, nil
+}
+
+// GetByID retrieve the goal of the user with the specified id
+func (s *GoalService) GetByID(ctx context.Context, userID string, id string) (*client.Goal, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	gid, err := objectID(id, "goal")
+	if err != nil {
+		return nil, err
+	}
+	goals, err := s.find(ctx, bson.M{"_id": gid, "user_id": uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(goals) == 0 {
+		return nil, &NotFoundError{fmt.Sprintf("goal '%s' not found", id)}
+	}
+	goal := goals[0].Convert()
+	return &goal, nil
+}
+
+// Delete remove the goal of the user with the specified id
+func (s *GoalService) Delete(ctx context.Context, userID string, id string) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	gid, err := objectID(id, "goal")
+	if err != nil {
+		return err
+	}
+	result, err := s.Collection.DeleteOne(ctx, bson.M{"_id": gid, "user_id": uid})
+	if err != nil {
+		err = fmt.Errorf("failed to delete goal %s, %s", id, err)
+		log.Error(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("goal '%s' not found", id)}
+	}
+	return nil
+}
+
+// deleteByUser remove all goals of the user
+func (s *GoalService) deleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := s.Collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+// workoutChanged refresh the goals of the user covering the start of a
+// workout session that was logged, modified or deleted
+func (s *GoalService) workoutChanged(ctx context.Context, userID primitive.ObjectID, start time.Time) error {
+	cursor, err := s.Collection.Find(ctx, bson.M{
+		"user_id":  userID,
+		"start":    bson.M{"$lte": start},
+		"deadline": bson.M{"$gt": start},
+	})
+	if err != nil {
+		return err
+	}
+	goals := []Goal{}
+	if err = cursor.All(ctx, &goals); err != nil {
+		return err
+	}
+	for i := range goals {
+		if err = s.refresh(ctx, &goals[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
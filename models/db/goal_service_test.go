@@ -0,0 +1,82 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// SetupGoal Setup the database for testing by clearing the goals
+// collection along with the workout logs
+func SetupGoal(t *testing.T) (*db.GoalService, *db.WorkoutService, *db.ExerciseService) {
+	workouts, ex := SetupWorkout(t)
+	service, err := db.NewGoalService(workouts.Collection.Database())
+	assert.NoError(t, err)
+	err = service.Collection.Drop(context.TODO())
+	assert.NoError(t, err)
+	return service, workouts, ex
+}
+
+func TestGoals(t *testing.T) {
+	service, workouts, ex := SetupGoal(t)
+	defer TeardownWorkout(t, workouts, ex)
+	defer service.Collection.Drop(context.TODO())
+	ctx := context.TODO()
+
+	now := time.Now().UTC()
+	id, err := service.Create(ctx, testWorkoutUserID, &client.Goal{Name: "Run 10k this week",
+		Metric: db.MetricDistance, ExerciseID: testRunningID, Target: 10, Period: db.PeriodWeek})
+	assert.NoError(t, err)
+	goal, err := service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, db.GoalActive, goal.Status)
+	assert.Equal(t, 0.0, goal.Progress)
+
+	// Logging sessions updates the progress until the goal is achieved
+	run := client.Workout{Start: now, Exercises: []client.WorkoutExercise{client.WorkoutExercise{
+		ExerciseID: testRunningID, Cardio: &client.CardioMetrics{DurationSeconds: 1800, Distance: 6, Unit: "km"}}}}
+	first, err := workouts.Create(ctx, testWorkoutUserID, &run)
+	assert.NoError(t, err)
+	goal, err = service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, goal.Progress)
+	assert.Equal(t, 60.0, goal.Percent)
+	_, err = workouts.Create(ctx, testWorkoutUserID, &run)
+	assert.NoError(t, err)
+	goal, err = service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, db.GoalAchieved, goal.Status)
+	if assert.Equal(t, 2, len(goal.History)) {
+		assert.Equal(t, db.GoalActive, goal.History[0].Status)
+		assert.Equal(t, 12.0, goal.History[1].Progress)
+	}
+
+	// Deleting a session can return the goal to active
+	assert.NoError(t, workouts.Delete(ctx, testWorkoutUserID, first))
+	goals, err := service.GetAll(ctx, testWorkoutUserID, db.GoalActive)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(goals)) {
+		assert.Equal(t, 3, len(goals[0].History))
+	}
+
+	// A goal whose deadline has passed without being achieved is missed
+	_, err = service.Create(ctx, testWorkoutUserID, &client.Goal{Metric: db.MetricSessions, Target: 5,
+		Start: now.AddDate(0, 0, -10), Deadline: now.AddDate(0, 0, -3)})
+	assert.NoError(t, err)
+	goals, err = service.GetAll(ctx, testWorkoutUserID, db.GoalMissed)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(goals))
+
+	_, err = service.Create(ctx, testWorkoutUserID, &client.Goal{Metric: db.MetricSessions, Target: 5,
+		Period: db.PeriodWeek, ExerciseID: "5db8e02b0e7aa732afd7fbc1"})
+	assert.True(t, db.IsValidation(err))
+
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, id))
+	_, err = service.GetByID(ctx, testWorkoutUserID, id)
+	assert.True(t, db.IsNotFound(err))
+	assert.True(t, db.IsNotFound(service.Delete(ctx, testWorkoutUserID, id)))
+}
@@ -0,0 +1,92 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewGoal(t *testing.T) {
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	start := time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC)
+
+	// A monthly goal covers the calendar month in the time zone of the goal
+	g, err := db.NewGoal(uid, &client.Goal{Metric: db.MetricDistance, Target: 50, Period: db.PeriodMonth,
+		Timezone: "America/Los_Angeles", Start: start})
+	assert.NoError(t, err)
+	assert.Equal(t, 50000.0, g.Target)
+	assert.Equal(t, db.UnitKilometer, g.Unit)
+	assert.Equal(t, time.Date(2019, 11, 1, 7, 0, 0, 0, time.UTC), g.Start)
+	assert.Equal(t, time.Date(2019, 12, 1, 8, 0, 0, 0, time.UTC), g.Deadline)
+
+	g, err = db.NewGoal(uid, &client.Goal{Metric: db.MetricMaxLoad, Target: 225, Unit: "LB",
+		ExerciseID: testSquatID, Start: start, Deadline: start.AddDate(0, 3, 0)})
+	assert.NoError(t, err)
+	assert.Equal(t, db.UnitPound, g.Unit)
+	assert.InDelta(t, 102.06, g.Target, 0.01)
+	assert.Equal(t, 225.0, g.Convert().Target)
+
+	testData := []struct {
+		name string
+		goal client.Goal
+	}{
+		{"metric", client.Goal{Metric: "calories", Target: 1, Period: db.PeriodWeek}},
+		{"unit", client.Goal{Metric: db.MetricDistance, Unit: "kg", Target: 1, Period: db.PeriodWeek}},
+		{"target", client.Goal{Metric: db.MetricSessions, Period: db.PeriodWeek}},
+		{"period", client.Goal{Metric: db.MetricSessions, Target: 3, Period: "year"}},
+		{"deadline", client.Goal{Metric: db.MetricSessions, Target: 3}},
+		{"before", client.Goal{Metric: db.MetricSessions, Target: 3, Start: start, Deadline: start}},
+		{"exercise", client.Goal{Metric: db.MetricSessions, Target: 3, Period: db.PeriodWeek, ExerciseID: "squat"}},
+		{"timezone", client.Goal{Metric: db.MetricSessions, Target: 3, Period: db.PeriodWeek, Timezone: "Mars"}},
+	}
+	for _, d := range testData {
+		t.Run(d.name, func(t *testing.T) {
+			_, err := db.NewGoal(uid, &d.goal)
+			assert.True(t, db.IsValidation(err))
+		})
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	workouts := []db.Workout{}
+	for _, w := range statsWorkouts() {
+		workout, err := db.NewWorkout(uid, &w)
+		assert.NoError(t, err)
+		workouts = append(workouts, *workout)
+	}
+	month := client.Goal{Period: db.PeriodMonth, Start: time.Date(2019, 11, 5, 0, 0, 0, 0, time.UTC), Target: 1}
+	testData := []struct {
+		metric   string
+		exercise string
+		expected float64
+	}{
+		{db.MetricSessions, "", 3},
+		{db.MetricSessions, testSquatID, 2},
+		{db.MetricDuration, "", 4800},
+		{db.MetricDistance, testRunningID, 15000},
+		{db.MetricVolume, testSquatID, 1900},
+		{db.MetricMaxLoad, testSquatID, 100},
+		{db.MetricMaxLoad, testRunningID, 0},
+	}
+	for _, d := range testData {
+		t.Run(d.metric+d.exercise, func(t *testing.T) {
+			goal := month
+			goal.Metric = d.metric
+			goal.ExerciseID = d.exercise
+			g, err := db.NewGoal(uid, &goal)
+			assert.NoError(t, err)
+			assert.Equal(t, d.expected, db.GoalProgress(g, workouts))
+		})
+	}
+
+	// Sessions outside of the period of the goal do not count
+	g, err := db.NewGoal(uid, &client.Goal{Metric: db.MetricSessions, Target: 1, Period: db.PeriodWeek,
+		Start: time.Date(2019, 11, 25, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, db.GoalProgress(g, workouts))
+}
@@ -0,0 +1,803 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/perm"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ImportFormat the format of the data being imported
+type ImportFormat string
+
+const (
+	// ImportJSON JSON data, either a JSON array or JSON Lines (one document
+	// per line) as produced by mongoexport. Both plain JSON and MongoDB
+	// Extended JSON, ie {"$oid": "..."}, are accepted.
+	ImportJSON ImportFormat = "json"
+	// ImportCSV comma separated values. The first line is a header
+	// naming the field held in each column.
+	ImportCSV ImportFormat = "csv"
+)
+
+// ParseImportFormat convert a format name or file extension to an ImportFormat.
+// The names json, jsonl and ndjson all map to ImportJSON.
+func ParseImportFormat(name string) (ImportFormat, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "json", "jsonl", "ndjson":
+		return ImportJSON, nil
+	case "csv":
+		return ImportCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported import format '%s', valid formats are json, jsonl, csv", name)
+	}
+}
+
+// ImportMode determines how a record matching an existing entry is handled
+type ImportMode string
+
+const (
+	// ImportSkipExisting records that match an existing entry are skipped
+	ImportSkipExisting ImportMode = "skip"
+	// ImportUpsert records that match an existing entry replace the entry
+	ImportUpsert ImportMode = "upsert"
+)
+
+// ParseImportMode convert a mode name to an ImportMode
+func ParseImportMode(name string) (ImportMode, error) {
+	switch ImportMode(strings.ToLower(name)) {
+	case ImportSkipExisting:
+		return ImportSkipExisting, nil
+	case ImportUpsert:
+		return ImportUpsert, nil
+	default:
+		return "", fmt.Errorf("unsupported import mode '%s', valid modes are skip, upsert", name)
+	}
+}
+
+// importRecord a single record read from the data being imported.
+// err is set if the record could not be parsed.
+type importRecord struct {
+	line int
+	doc  bson.M
+	err  error
+}
+
+// importable a database entry created from an import record
+type importable interface {
+	// setID set the ID of the entry
+	setID(id primitive.ObjectID)
+	// key the filter used to find an existing entry matching this entry
+	key() bson.M
+	// importedFields the fields of the entry held by an import record, an
+	// upsert clears those the record leaves empty
+	importedFields() []string
+}
+
+// readRecords read the records from data in the specified format.
+// An error is only returned if the data as a whole can't be read,
+// errors for individual records are returned with the record.
+func readRecords(r io.Reader, format ImportFormat) ([]importRecord, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ImportJSON:
+		trimmed := bytes.TrimSpace(data)
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			return readJSONArray(data)
+		}
+		return readJSONLines(data)
+	case ImportCSV:
+		return readCSV(data)
+	default:
+		return nil, fmt.Errorf("unsupported import format '%s'", format)
+	}
+}
+
+// parseDocument parse a single JSON document, plain or Extended JSON
+func parseDocument(raw []byte) (bson.M, error) {
+	var doc bson.M
+	err := bson.UnmarshalExtJSON(raw, false, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON document, %s", err)
+	}
+	return doc, nil
+}
+
+// readJSONLines read a document from each non blank line
+func readJSONLines(data []byte) ([]importRecord, error) {
+	var records []importRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		doc, err := parseDocument(text)
+		records = append(records, importRecord{line: line, doc: doc, err: err})
+	}
+	return records, scanner.Err()
+}
+
+// readJSONArray read each document of a JSON array
+func readJSONArray(data []byte) ([]importRecord, error) {
+	var records []importRecord
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid JSON array, %s", err)
+	}
+	// The offset of the end of the previous document, or of the opening
+	// bracket, the raw bytes of each document are found after it
+	offset := bytes.IndexByte(data, '[') + 1
+	for decoder.More() {
+		line := lineAt(data, offset)
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON array at line %d, %s", line, err)
+		}
+		if i := bytes.Index(data[offset:], raw); i >= 0 {
+			offset += i + len(raw)
+		}
+		doc, err := parseDocument(raw)
+		records = append(records, importRecord{line: line, doc: doc, err: err})
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("invalid JSON array, %s", err)
+	}
+	return records, nil
+}
+
+// lineAt returns the line number of the next value at or after offset
+func lineAt(data []byte, offset int) int {
+	i := offset
+	for i < len(data) && strings.IndexByte(" \t\r\n,", data[i]) >= 0 {
+		i++
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
+
+// csvLines the text of a row of a CSV file and the line it starts on
+type csvLines struct {
+	line int
+	text []byte
+}
+
+// csvRows split CSV data into its rows, skipping blank lines. A row continues
+// onto the next line while one of its quoted fields is open, the quotes of
+// the row being unbalanced.
+func csvRows(data []byte) []csvLines {
+	var rows []csvLines
+	var row csvLines
+	quotes := 0
+	for i, text := range bytes.SplitAfter(data, []byte("\n")) {
+		if row.text == nil {
+			if len(bytes.TrimSpace(text)) == 0 {
+				continue
+			}
+			row.line = i + 1
+		}
+		row.text = append(row.text, text...)
+		quotes += bytes.Count(text, []byte(`"`))
+		if quotes%2 == 0 {
+			rows = append(rows, row)
+			row, quotes = csvLines{}, 0
+		}
+	}
+	if row.text != nil {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// readCSV read a document from each row, the first row names the fields
+func readCSV(data []byte) ([]importRecord, error) {
+	parse := func(row csvLines) ([]string, error) {
+		reader := csv.NewReader(bytes.NewReader(row.text))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		fields, err := reader.Read()
+		if pe, ok := err.(*csv.ParseError); ok {
+			err = pe.Err
+		}
+		return fields, err
+	}
+	rows := csvRows(data)
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("unable to read CSV header, %s", io.EOF)
+	}
+	header, err := parse(rows[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to read CSV header, %s", err)
+	}
+	var records []importRecord
+	for _, r := range rows[1:] {
+		row, err := parse(r)
+		if err != nil {
+			records = append(records, importRecord{line: r.line, err: err})
+			continue
+		}
+		if len(row) != len(header) {
+			err = fmt.Errorf("expected %d fields, found %d", len(header), len(row))
+			records = append(records, importRecord{line: r.line, err: err})
+			continue
+		}
+		doc := bson.M{}
+		for i, name := range header {
+			if len(row[i]) > 0 {
+				doc[strings.TrimSpace(name)] = row[i]
+			}
+		}
+		records = append(records, importRecord{line: r.line, doc: doc})
+	}
+	return records, nil
+}
+
+// stringField returns the string value of a field, empty if not set
+func stringField(doc bson.M, name string) (string, error) {
+	switch v := doc[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("field %s must be a string", name)
+	}
+}
+
+// floatField returns the number held in the field of the document, 0 if not
+// set. CSV values are parsed from their text.
+func floatField(doc bson.M, name string) (float64, error) {
+	switch v := doc[name].(type) {
+	case nil:
+		return 0, nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("field %s must be a number", name)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("field %s must be a number", name)
+	}
+}
+
+// stringsField returns the strings held in the array field of the document,
+// nil if not set. CSV values hold either the JSON array as written by the
+// export or a comma separated list.
+func stringsField(doc bson.M, name string) ([]string, error) {
+	var values bson.A
+	switch v := doc[name].(type) {
+	case nil:
+		return nil, nil
+	case bson.A:
+		values = v
+	case string:
+		if !strings.HasPrefix(strings.TrimSpace(v), "[") {
+			return strings.Split(v, ","), nil
+		}
+		var wrapper struct {
+			Values bson.A `bson:"values"`
+		}
+		if err := bson.UnmarshalExtJSON([]byte(`{"values":`+v+`}`), false, &wrapper); err != nil {
+			return nil, fmt.Errorf("field %s must be an array of strings, %s", name, err)
+		}
+		values = wrapper.Values
+	default:
+		return nil, fmt.Errorf("field %s must be an array of strings", name)
+	}
+	results := make([]string, 0, len(values))
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be an array of strings", name)
+		}
+		results = append(results, str)
+	}
+	return results, nil
+}
+
+// intensitiesField returns the MET value for each intensity held in the
+// intensities field of the document. CSV values hold the JSON document as
+// written by the export.
+func intensitiesField(doc bson.M) (map[string]float64, error) {
+	var values bson.M
+	switch v := doc["intensities"].(type) {
+	case nil:
+		return nil, nil
+	case bson.M:
+		values = v
+	case bson.D:
+		values = v.Map()
+	case string:
+		if err := bson.UnmarshalExtJSON([]byte(v), false, &values); err != nil {
+			return nil, fmt.Errorf("field intensities must be a document, %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("field intensities must be a document")
+	}
+	intensities := map[string]float64{}
+	for intensity := range values {
+		met, err := floatField(values, intensity)
+		if err != nil {
+			return nil, err
+		}
+		intensities[intensity] = met
+	}
+	return intensities, nil
+}
+
+// translationsField returns the translations of the document, a document of
+// the name and description of each locale. CSV files hold the document as
+// extended JSON.
+func translationsField(doc bson.M) (map[string]client.Translation, error) {
+	var values bson.M
+	switch v := doc["translations"].(type) {
+	case nil:
+		return nil, nil
+	case bson.M:
+		values = v
+	case bson.D:
+		values = v.Map()
+	case string:
+		if len(v) == 0 {
+			return nil, nil
+		}
+		if err := bson.UnmarshalExtJSON([]byte(v), false, &values); err != nil {
+			return nil, fmt.Errorf("field translations must be a document, %s", err)
+		}
+	default:
+		return nil, fmt.Errorf("field translations must be a document")
+	}
+	translations := map[string]client.Translation{}
+	for locale, value := range values {
+		var t bson.M
+		switch v := value.(type) {
+		case bson.M:
+			t = v
+		case bson.D:
+			t = v.Map()
+		default:
+			return nil, fmt.Errorf("translation %s must be a document", locale)
+		}
+		name, err := stringField(t, "name")
+		if err != nil {
+			return nil, err
+		}
+		description, err := stringField(t, "description")
+		if err != nil {
+			return nil, err
+		}
+		translations[locale] = client.Translation{Name: name, Description: description}
+	}
+	return translations, nil
+}
+
+// mediaField returns the media of the exercise document, as written by the
+// export. CSV files hold the array as extended JSON. The blobs of images and
+// animations are not imported, only the references to them held by the
+// exercise, so they must already be in the blob store of the server.
+func mediaField(doc bson.M) ([]Media, error) {
+	value := doc["media"]
+	if v, ok := value.(string); ok {
+		var wrapper bson.M
+		if err := bson.UnmarshalExtJSON([]byte(`{"v":`+v+`}`), false, &wrapper); err != nil {
+			return nil, fmt.Errorf("field media must be an array, %s", err)
+		}
+		value = wrapper["v"]
+	}
+	if value == nil {
+		return nil, nil
+	}
+	data, err := bson.Marshal(bson.M{"media": value})
+	if err != nil {
+		return nil, fmt.Errorf("field media must be an array, %s", err)
+	}
+	var holder struct {
+		Media []Media `bson:"media"`
+	}
+	if err = bson.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("field media must be an array of media, %s", err)
+	}
+	if len(holder.Media) > MaxMedia {
+		return nil, fmt.Errorf("an exercise can have at most %d media", MaxMedia)
+	}
+	for i := range holder.Media {
+		if err = holder.Media[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return holder.Media, nil
+}
+
+// idField returns the ID of the document, primitive.NilObjectID if not set
+func idField(doc bson.M) (primitive.ObjectID, error) {
+	return objectIDField(doc, "_id")
+}
+
+// objectIDField returns the ObjectID value of the field of the document,
+// primitive.NilObjectID if not set
+func objectIDField(doc bson.M, field string) (primitive.ObjectID, error) {
+	switch v := doc[field].(type) {
+	case nil:
+		return primitive.NilObjectID, nil
+	case primitive.ObjectID:
+		return v, nil
+	case string:
+		if len(v) == 0 {
+			return primitive.NilObjectID, nil
+		}
+		id, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return id, fmt.Errorf("invalid %s '%s'", field, v)
+		}
+		return id, nil
+	default:
+		return primitive.NilObjectID, fmt.Errorf("invalid %s %v", field, v)
+	}
+}
+
+// privilegeField returns the privilege of the document, either the
+// name of the privilege or the stored numeric value, basic if not set
+func privilegeField(doc bson.M) (perm.Privilege, error) {
+	var value int64
+	switch v := doc["privilege"].(type) {
+	case nil:
+		return perm.Basic, nil
+	case int32:
+		value = int64(v)
+	case int64:
+		value = v
+	case float64:
+		value = int64(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 8)
+		if err != nil {
+			p := perm.Convert(v)
+			if p.String() != strings.ToLower(v) {
+				return perm.Basic, fmt.Errorf("invalid privilege '%s'", v)
+			}
+			return p, nil
+		}
+		value = n
+	default:
+		return perm.Basic, fmt.Errorf("invalid privilege %v", v)
+	}
+	p := perm.Privilege(value)
+	if int64(p) != value || p.String() == "unknown" {
+		return perm.Basic, fmt.Errorf("invalid privilege %d", value)
+	}
+	return p, nil
+}
+
+// exerciseFromRecord create an exercise from the imported document
+func exerciseFromRecord(doc bson.M) (*Exercise, error) {
+	id, err := idField(doc)
+	if err != nil {
+		return nil, err
+	}
+	name, err := stringField(doc, "name")
+	if err != nil {
+		return nil, err
+	}
+	description, err := stringField(doc, "description")
+	if err != nil {
+		return nil, err
+	}
+	met, err := floatField(doc, "met")
+	if err != nil {
+		return nil, err
+	}
+	intensities, err := intensitiesField(doc)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := translationsField(doc)
+	if err != nil {
+		return nil, err
+	}
+	ex := client.Exercise{Name: name, Description: description, MET: met, Intensities: intensities,
+		Translations: translations}
+	for field, value := range map[string]*string{"category": &ex.Category, "difficulty": &ex.Difficulty,
+		"measurement": &ex.Measurement} {
+		if *value, err = stringField(doc, field); err != nil {
+			return nil, err
+		}
+	}
+	for field, values := range map[string]*[]string{"aliases": &ex.Aliases, "primary_muscles": &ex.PrimaryMuscles,
+		"secondary_muscles": &ex.SecondaryMuscles, "equipment": &ex.Equipment} {
+		if *values, err = stringsField(doc, field); err != nil {
+			return nil, err
+		}
+	}
+	exercise, err := NewExercise(&ex)
+	if err != nil {
+		return nil, err
+	}
+	if !id.IsZero() {
+		exercise.ID = id
+	}
+	if exercise.Owner, err = objectIDField(doc, "owner"); err != nil {
+		return nil, err
+	}
+	if exercise.Media, err = mediaField(doc); err != nil {
+		return nil, err
+	}
+	return exercise, nil
+}
+
+// userFromRecord create a user from the imported document. The document
+// may use the client field names (username) or the database field names
+// (user_id). If the password is a bcrypt hash, as found in a database
+// export, it is stored as is. Otherwise it is treated as a plain
+// text password and hashed. The body weight and heart rates of the user,
+// if present, are validated as when set via the API.
+func userFromRecord(doc bson.M) (*User, error) {
+	id, err := idField(doc)
+	if err != nil {
+		return nil, err
+	}
+	username, err := stringField(doc, "username")
+	if err != nil {
+		return nil, err
+	}
+	if len(username) == 0 {
+		if username, err = stringField(doc, "user_id"); err != nil {
+			return nil, err
+		}
+	}
+	password, err := stringField(doc, "password")
+	if err != nil {
+		return nil, err
+	}
+	privilege, err := privilegeField(doc)
+	if err != nil {
+		return nil, err
+	}
+	var user *User
+	if _, err := bcrypt.Cost([]byte(password)); err == nil {
+		if err := validateUsername(username); err != nil {
+			return nil, err
+		}
+		user = &User{
+			ID:        primitive.NewObjectID(),
+			Username:  username,
+			Password:  password,
+			Privilege: privilege,
+		}
+	} else {
+		user, err = NewUser(&client.UserCreate{
+			Username:  username,
+			Password:  password,
+			Privilege: privilege.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !id.IsZero() {
+		user.ID = id
+	}
+	if err = profileFields(doc, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// profileFields set the body weight and heart rates of the user from the
+// fields of the imported document, as held in the database
+func profileFields(doc bson.M, user *User) error {
+	kg, err := floatField(doc, "weight_kg")
+	if err != nil {
+		return err
+	}
+	unit, err := stringField(doc, "weight_unit")
+	if err != nil {
+		return err
+	}
+	if kg != 0 {
+		if _, unit, err = NewBodyWeight(&client.BodyWeight{Weight: kg / unitScale(unit), Unit: unit}); err != nil {
+			return err
+		}
+		user.WeightKg, user.WeightUnit = kg, unit
+	}
+	max, err := floatField(doc, "max_hr")
+	if err != nil {
+		return err
+	}
+	resting, err := floatField(doc, "resting_hr")
+	if err != nil {
+		return err
+	}
+	if max != 0 || resting != 0 {
+		rates := client.HeartRates{Max: int(max), Resting: int(resting)}
+		if err = ValidateHeartRates(&rates); err != nil {
+			return err
+		}
+		user.MaxHR, user.RestingHR = rates.Max, rates.Resting
+	}
+	return nil
+}
+
+// updateFields the update of an existing entry by the imported entry. The
+// imported fields of the entry are set, those left empty are unset, the
+// fields that are not imported are left as is.
+func updateFields(entry importable) (bson.M, error) {
+	data, err := bson.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var set bson.M
+	if err = bson.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	delete(set, "_id")
+	unset := bson.M{}
+	for _, field := range entry.importedFields() {
+		if _, ok := set[field]; !ok {
+			unset[field] = ""
+		}
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
+
+// importRecords store the records into the collection. Each record is converted
+// to a database entry via convert. Records that match an existing entry are
+// either skipped or update the existing entry depending on mode. An update
+// replaces the imported fields of the existing entry, fields that are not
+// imported, such as the calendar token of a user, are left as is.
+func importRecords(ctx context.Context, collection *mongo.Collection, records []importRecord,
+	mode ImportMode, findOptions *options.FindOneOptions,
+	convert func(doc bson.M) (importable, error)) *client.ImportResult {
+	result := &client.ImportResult{}
+	addError := func(line int, err error) {
+		result.Errors = append(result.Errors, client.ImportError{Line: line, Message: err.Error()})
+	}
+	for _, r := range records {
+		if r.err != nil {
+			addError(r.line, r.err)
+			continue
+		}
+		entry, err := convert(r.doc)
+		if err != nil {
+			addError(r.line, err)
+			continue
+		}
+
+		// Look for an existing entry
+		var existing struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		err = collection.FindOne(ctx, entry.key(), findOptions).Decode(&existing)
+		if err != nil && err != mongo.ErrNoDocuments {
+			addError(r.line, err)
+			continue
+		}
+		if err == nil {
+			if mode != ImportUpsert {
+				result.Skipped++
+				continue
+			}
+			entry.setID(existing.ID)
+			update, err := updateFields(entry)
+			if err == nil {
+				_, err = collection.UpdateOne(ctx, bson.M{"_id": existing.ID}, update)
+			}
+			if err != nil {
+				addError(r.line, err)
+				continue
+			}
+			result.Updated++
+			continue
+		}
+
+		_, err = collection.InsertOne(ctx, entry)
+		if IsDuplicateKeyError(err) {
+			addError(r.line, fmt.Errorf("entry conflicts with an existing entry"))
+			continue
+		}
+		if err != nil {
+			addError(r.line, err)
+			continue
+		}
+		result.Inserted++
+	}
+	log.Debugf("import into %s, %d inserted, %d updated, %d skipped, %d errors", collection.Name(),
+		result.Inserted, result.Updated, result.Skipped, len(result.Errors))
+	return result
+}
+
+func (e *Exercise) setID(id primitive.ObjectID) {
+	e.ID = id
+}
+
+func (e *Exercise) importedFields() []string {
+	return []string{"owner", "name", "aliases", "keys", "description", "met", "intensities", "category",
+		"primary_muscles", "secondary_muscles", "equipment", "difficulty", "measurement", "media",
+		"translations"}
+}
+
+// key matches the exercises of the same owner, a private exercise replaces
+// neither a shared exercise nor those of other users
+func (e *Exercise) key() bson.M {
+	key := byName(e)
+	if !e.Owner.IsZero() {
+		key["owner"] = e.Owner
+	}
+	return key
+}
+
+func (u *User) setID(id primitive.ObjectID) {
+	u.ID = id
+}
+
+func (u *User) importedFields() []string {
+	return []string{"user_id", "password", "privilege", "weight_kg", "weight_unit", "max_hr", "resting_hr"}
+}
+
+func (u *User) key() bson.M {
+	return bson.M{"user_id": u.Username}
+}
+
+// Import the exercises contained in r. Each record is validated via NewExercise.
+// Records matching the name or an alias of an existing exercise of the same
+// owner are handled according to mode.
+// An error is returned only if the data as a whole can not be read, the errors
+// for individual records are reported in the results.
+func (s *ExerciseService) Import(ctx context.Context, r io.Reader, format ImportFormat,
+	mode ImportMode) (*client.ImportResult, error) {
+	records, err := readRecords(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return importRecords(ctx, s.Collection, records, mode,
+		options.FindOne().SetCollation(caseInsensitive),
+		func(doc bson.M) (importable, error) {
+			return exerciseFromRecord(doc)
+		}), nil
+}
+
+// Import the users contained in r. Each record is validated via NewUser.
+// Records matching the username of an existing user are handled according to mode.
+// An error is returned only if the data as a whole can not be read, the errors
+// for individual records are reported in the results.
+func (s *UserService) Import(ctx context.Context, r io.Reader, format ImportFormat,
+	mode ImportMode) (*client.ImportResult, error) {
+	records, err := readRecords(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return importRecords(ctx, s.Collection, records, mode, options.FindOne(),
+		func(doc bson.M) (importable, error) {
+			return userFromRecord(doc)
+		}), nil
+}
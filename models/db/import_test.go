@@ -0,0 +1,126 @@
+package db_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportFormat(t *testing.T) {
+	for _, name := range []string{"json", "jsonl", ".ndjson", "JSON"} {
+		format, err := db.ParseImportFormat(name)
+		assert.NoError(t, err)
+		assert.Equal(t, db.ImportJSON, format)
+	}
+	format, err := db.ParseImportFormat(".csv")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ImportCSV, format)
+	_, err = db.ParseImportFormat("xml")
+	assert.Error(t, err)
+
+	mode, err := db.ParseImportMode("upsert")
+	assert.NoError(t, err)
+	assert.Equal(t, db.ImportUpsert, mode)
+	_, err = db.ParseImportMode("replace")
+	assert.Error(t, err)
+}
+
+func TestImportExercisesJSONLines(t *testing.T) {
+	service := SetupExercise(t, true, false)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+
+	f, err := os.Open("testdata/import_exercises.jsonl")
+	assert.NoError(t, err)
+	defer f.Close()
+	result, err := service.Import(ctx, f, db.ImportJSON, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result.Inserted)
+	if assert.Equal(t, 2, len(result.Errors)) {
+		assert.Equal(t, 4, result.Errors[0].Line)
+		assert.Equal(t, 5, result.Errors[1].Line)
+		assert.Contains(t, result.Errors[1].Message, "name must be specified")
+	}
+
+	// The ID of the exported exercise is retained
+	e, err := service.GetByID(ctx, "5dab53b371aab123354e5cab")
+	assert.NoError(t, err)
+	assert.Equal(t, "Jumping Jack", e.Name)
+}
+
+func TestImportExercisesJSONArray(t *testing.T) {
+	service := SetupExercise(t, true, true)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+
+	// Existing exercises are skipped, names are matched ignoring case
+	data := `[
+		{"name": "jumping jack", "description": "updated"},
+		{"name": "Box Jump"}
+	]`
+	result, err := service.Import(ctx, strings.NewReader(data), db.ImportJSON, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Inserted)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Empty(t, result.Errors)
+
+	// Upsert replaces the existing exercise but keeps its ID, the fields the
+	// record leaves empty are cleared
+	result, err = service.Import(ctx, strings.NewReader(data), db.ImportJSON, db.ImportUpsert)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Updated)
+	e, err := service.GetByID(ctx, "5dab53b371aab123354e5cab")
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", e.Description)
+	assert.Empty(t, e.Category)
+	assert.Empty(t, e.Equipment)
+
+	// A malformed array can't be imported at all
+	_, err = service.Import(ctx, strings.NewReader(`[{"name": "x"}`), db.ImportJSON, db.ImportUpsert)
+	assert.Error(t, err)
+}
+
+func TestImportUsersCSV(t *testing.T) {
+	userService := SetupUser(t, true, true)
+	defer TeardownUser(t, userService)
+	ctx := context.TODO()
+
+	f, err := os.Open("testdata/import_users.csv")
+	assert.NoError(t, err)
+	defer f.Close()
+	result, err := userService.Import(ctx, f, db.ImportCSV, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Inserted)
+	if assert.Equal(t, 2, len(result.Errors)) {
+		assert.Equal(t, 3, result.Errors[0].Line)
+		assert.Contains(t, result.Errors[0].Message, "invalid username")
+		assert.Equal(t, 5, result.Errors[1].Line)
+		assert.Contains(t, result.Errors[1].Message, "invalid privilege")
+	}
+
+	// The plain text password is hashed on import
+	user, err := userService.GetByUsername(ctx, "staff3")
+	assert.NoError(t, err)
+	assert.Equal(t, "staff", user.Privilege)
+}
+
+func TestImportUsersExport(t *testing.T) {
+	userService := SetupUser(t, true, false)
+	defer TeardownUser(t, userService)
+	ctx := context.TODO()
+
+	// Users exported via mongoexport retain their hashed password
+	f, err := os.Open(testUserFilename)
+	assert.NoError(t, err)
+	defer f.Close()
+	result, err := userService.Import(ctx, f, db.ImportJSON, db.ImportSkipExisting)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Inserted)
+	user, err := userService.GetByID(ctx, testAdminID)
+	assert.NoError(t, err)
+	assert.Equal(t, testAdminUsername, user.Username)
+}
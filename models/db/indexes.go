@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LogsCollection name of the collection used to hold the workout logs of users
+const LogsCollection = "logs"
+
+// caseInsensitive collation used for indexes and queries where the case
+// of a string value should be ignored when comparing values
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+// collectionIndexes the indexes required for a given collection
+type collectionIndexes struct {
+	collection string
+	indexes    []mongo.IndexModel
+}
+
+// requiredIndexes returns the indexes that must exist for each of the
+// collections used by the activity server
+func requiredIndexes() []collectionIndexes {
+	return []collectionIndexes{
+		{
+			collection: UsersCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}},
+					Options: options.Index().SetName("user_id_unique").SetUnique(true),
+				},
+				{
+					Keys:    bson.D{{Key: "calendar_token", Value: 1}},
+					Options: options.Index().SetName("calendar_token_unique").SetUnique(true).SetSparse(true),
+				},
+			},
+		},
+		{
+			collection: ExerciseCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys: bson.D{{Key: "owner", Value: 1}, {Key: "name", Value: 1}},
+					Options: options.Index().SetName("owner_name_unique").SetUnique(true).
+						SetCollation(caseInsensitive),
+				},
+				{
+					Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "keys", Value: 1}},
+					Options: options.Index().SetName("owner_keys_unique").SetUnique(true).SetSparse(true),
+				},
+				{
+					Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+					Options: options.Index().SetName("name_description_text").
+						SetWeights(bson.M{"name": NameWeight, "description": DescriptionWeight}),
+				},
+			},
+		},
+		{
+			collection: LogsCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "start", Value: -1}},
+					Options: options.Index().SetName("user_id_start"),
+				},
+				{
+					Keys:    bson.D{{Key: "exercises.exercise_id", Value: 1}},
+					Options: options.Index().SetName("exercise_id"),
+				},
+			},
+		},
+		{
+			collection: TracksCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "workout_id", Value: 1}},
+					Options: options.Index().SetName("workout_id"),
+				},
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}},
+					Options: options.Index().SetName("user_id"),
+				},
+			},
+		},
+		{
+			collection: RecordsCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "exercise_id", Value: 1},
+						{Key: "achieved", Value: 1}},
+					Options: options.Index().SetName("user_id_exercise_id_achieved"),
+				},
+				{
+					Keys:    bson.D{{Key: "workout_id", Value: 1}},
+					Options: options.Index().SetName("workout_id"),
+				},
+			},
+		},
+		{
+			collection: GoalsCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "deadline", Value: -1}},
+					Options: options.Index().SetName("user_id_deadline"),
+				},
+			},
+		},
+		{
+			collection: TemplatesCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+					Options: options.Index().SetName("user_id_name_unique").SetUnique(true).
+						SetCollation(caseInsensitive),
+				},
+			},
+		},
+		{
+			collection: PlansCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "weeks.sessions.template_id", Value: 1}},
+					Options: options.Index().SetName("template_id"),
+				},
+			},
+		},
+		{
+			collection: AssignmentsCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "start", Value: -1}},
+					Options: options.Index().SetName("user_id_start"),
+				},
+				{
+					Keys:    bson.D{{Key: "plan_id", Value: 1}},
+					Options: options.Index().SetName("plan_id"),
+				},
+			},
+		},
+		{
+			collection: ScheduledCollection,
+			indexes: []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "uid", Value: 1}},
+					Options: options.Index().SetName("user_id_uid_unique").SetUnique(true),
+				},
+				{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "start", Value: 1}},
+					Options: options.Index().SetName("user_id_start"),
+				},
+			},
+		},
+	}
+}
+
+// EnsureIndexes creates the indexes required by the collections of the
+// database. Creating an index that already exists is a no-op so this can
+// safely be invoked every time the server is started. Creation of a unique
+// index will fail if the collection already contains duplicate entries.
+func EnsureIndexes(ctx context.Context, database *mongo.Database, opts ...ServiceOption) error {
+	for _, ci := range requiredIndexes() {
+		name := CollectionName(ci.collection, opts...)
+		names, err := database.Collection(name).Indexes().CreateMany(ctx, ci.indexes)
+		if err != nil {
+			err = fmt.Errorf("failed to create indexes for collection %s, %s", name, err)
+			log.Error(err)
+			return err
+		}
+		log.Debugf("collection %s indexes %v ensured", name, names)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestEnsureIndexes ensure the unique indexes reject duplicate entries
+// even when the pre insert checks of the services are bypassed
+func TestEnsureIndexes(t *testing.T) {
+	ctx := context.TODO()
+	mClient, err := mongo.Connect(ctx, options.Client().ApplyURI(testDatabaseURL))
+	assert.NoError(t, err)
+	database := mClient.Database(testDatabase)
+	defer database.Drop(ctx)
+
+	err = database.Drop(ctx)
+	assert.NoError(t, err)
+	err = db.EnsureIndexes(ctx, database)
+	assert.NoError(t, err)
+
+	// Creating the indexes a second time is not an error
+	err = db.EnsureIndexes(ctx, database)
+	assert.NoError(t, err)
+
+	// Insert a duplicate username directly into the collection
+	user, err := db.NewUser(&client.UserCreate{Username: "customer1", Password: "password"})
+	assert.NoError(t, err)
+	users := database.Collection(db.UsersCollection)
+	_, err = users.InsertOne(ctx, user)
+	assert.NoError(t, err)
+	user, err = db.NewUser(&client.UserCreate{Username: "customer1", Password: "password"})
+	assert.NoError(t, err)
+	_, err = users.InsertOne(ctx, user)
+	assert.Error(t, err)
+
+	// Exercise names are unique regardless of case
+	service, err := db.NewExerciseService(database, log.StandardLogger())
+	assert.NoError(t, err)
+	err = service.Create(ctx, &client.Exercise{Name: "Pushup"})
+	assert.NoError(t, err)
+	err = service.Create(ctx, &client.Exercise{Name: "PUSHUP"})
+	assert.Error(t, err)
+	assert.True(t, db.IsConflict(err))
+}
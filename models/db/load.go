@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The methods the training load of a session can be computed with
+const (
+	LoadTRIMP = "trimp"
+	LoadRPE   = "rpe"
+)
+
+// The rolling windows, in days, of the acute and chronic workloads
+const (
+	AcuteDays   = 7
+	ChronicDays = 28
+)
+
+// DefaultACWRThreshold the acute:chronic workload ratio above which the
+// risk of injury is considered elevated
+const DefaultACWRThreshold = 1.5
+
+// DefaultLoadRange the range of days reported when no start of the range
+// is specified
+const DefaultLoadRange = ChronicDays * 24 * time.Hour
+
+// MaxLoadRange the longest range of days that can be reported
+const MaxLoadRange = 366 * 24 * time.Hour
+
+// HRZones the number of heart rate zones
+const HRZones = 5
+
+// zoneFloors the fraction of the heart rate reserve at which each zone
+// above the first starts
+var zoneFloors = [HRZones - 1]float64{0.6, 0.7, 0.8, 0.9}
+
+// LoadQuery the days the training load of a user is reported for. Days are
+// local dates in Location, those with a start at or after From and before
+// To are included.
+type LoadQuery struct {
+	UserID    string
+	From      time.Time
+	To        time.Time
+	Method    string
+	Threshold float64
+	Location  *time.Location
+}
+
+// normalize apply the defaults for any unspecified fields of the query and
+// validate the result
+func (q *LoadQuery) normalize() error {
+	if len(q.Method) == 0 {
+		q.Method = LoadTRIMP
+	}
+	if q.Method != LoadTRIMP && q.Method != LoadRPE {
+		return &ValidationError{fmt.Sprintf("invalid method '%s', must be one of %s or %s",
+			q.Method, LoadTRIMP, LoadRPE)}
+	}
+	if q.Threshold == 0 {
+		q.Threshold = DefaultACWRThreshold
+	}
+	if q.Threshold < 0 {
+		return &ValidationError{"threshold must be positive"}
+	}
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+	if q.To.IsZero() {
+		q.To = time.Now().UTC()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultLoadRange)
+	}
+	if !q.From.Before(q.To) {
+		return &ValidationError{"from must be before to"}
+	}
+	if q.To.Sub(q.From) > MaxLoadRange {
+		return &ValidationError{"the range can not exceed 366 days"}
+	}
+	return nil
+}
+
+// dayStart the midnight beginning the local date the time falls on
+func (q *LoadQuery) dayStart(t time.Time) time.Time {
+	year, month, day := t.In(q.Location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, q.Location)
+}
+
+// HRZone the heart rate zone, 1 to 5, of the heart rate based on the
+// fraction of the heart rate reserve, the difference between the maximum and
+// resting heart rates, it represents
+func HRZone(hr int, max int, resting int) int {
+	reserve := float64(hr-resting) / float64(max-resting)
+	zone := 1
+	for _, floor := range zoneFloors {
+		if reserve >= floor {
+			zone++
+		}
+	}
+	return zone
+}
+
+// TRIMP the Banister training impulse of exercising for the number of
+// seconds at the average heart rate
+func TRIMP(seconds int, hr int, max int, resting int) float64 {
+	reserve := math.Max(0, math.Min(1, float64(hr-resting)/float64(max-resting)))
+	return float64(seconds) / 60 * reserve * 0.64 * math.Exp(1.92*reserve)
+}
+
+// hrSegment a period of a session for which the average heart rate is known
+type hrSegment struct {
+	seconds int
+	hr      int
+}
+
+// hrSegments the periods of a cardio entry with a recorded heart rate. Laps
+// are used when each of them records a heart rate, as they describe the
+// session more closely than its average.
+func hrSegments(c *CardioMetrics) []hrSegment {
+	segments := []hrSegment{}
+	for _, lap := range c.Laps {
+		if lap.AvgHeartRate == 0 || lap.DurationSeconds == 0 {
+			segments = nil
+			break
+		}
+		segments = append(segments, hrSegment{lap.DurationSeconds, lap.AvgHeartRate})
+	}
+	if len(segments) == 0 && c.AvgHeartRate > 0 && c.DurationSeconds > 0 {
+		segments = []hrSegment{{c.DurationSeconds, c.AvgHeartRate}}
+	}
+	return segments
+}
+
+// ComputeSessionLoad the time in zone and training load of a workout session.
+// The heart rate zones and TRIMP are only computed when the maximum and
+// resting heart rates of the user are known.
+func ComputeSessionLoad(w *Workout, method string, max int, resting int) client.SessionLoad {
+	load := client.SessionLoad{WorkoutID: w.ID.Hex(), Start: w.Start}
+	var zones []int
+	if max > resting && resting > 0 {
+		zones = make([]int, HRZones)
+	}
+	rpe, rated := 0.0, 0
+	for i := range w.Exercises {
+		e := &w.Exercises[i]
+		load.DurationSeconds += entrySeconds(e)
+		for _, set := range e.Sets {
+			if set.RPE > 0 {
+				rpe += set.RPE
+				rated++
+			}
+		}
+		if e.Cardio == nil || zones == nil {
+			continue
+		}
+		for _, s := range hrSegments(e.Cardio) {
+			zones[HRZone(s.hr, max, resting)-1] += s.seconds
+			load.TRIMP += TRIMP(s.seconds, s.hr, max, resting)
+		}
+	}
+	if rated > 0 {
+		load.RPELoad = round(rpe/float64(rated)*float64(load.DurationSeconds)/60, 1)
+	}
+	load.TRIMP = round(load.TRIMP, 1)
+	load.ZoneSeconds = zones
+	load.Load = load.TRIMP
+	if method == LoadRPE {
+		load.Load = load.RPELoad
+	}
+	return load
+}
+
+// ComputeLoad compute the training load of the workout sessions of a user,
+// whose maximum and resting heart rates are specified, for each day of the
+// query and report the sessions started on those days in chronological
+// order. The sessions of the 27 days preceding the first day are required to
+// compute the chronic workload of the first days.
+func ComputeLoad(workouts []Workout, max int, resting int, q LoadQuery) (*client.TrainingLoad, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	if q.Method == LoadTRIMP && (max == 0 || resting == 0) {
+		return nil, &ValidationError{"the maximum and resting heart rates of the user must be set to compute the TRIMP"}
+	}
+	result := client.TrainingLoad{
+		UserID:           q.UserID,
+		From:             q.From,
+		To:               q.To,
+		Timezone:         q.Location.String(),
+		Method:           q.Method,
+		Threshold:        q.Threshold,
+		MaxHeartRate:     max,
+		RestingHeartRate: resting,
+		Sessions:         []client.SessionLoad{},
+		Days:             []client.DailyLoad{},
+		Warnings:         []string{},
+	}
+	first := q.dayStart(q.From)
+	if first.Before(q.From) {
+		first = first.AddDate(0, 0, 1)
+	}
+	earliest := first.AddDate(0, 0, 1-ChronicDays)
+	daily := map[string]float64{}
+	for i := range workouts {
+		w := &workouts[i]
+		if w.Start.Before(earliest) || !w.Start.Before(q.To) {
+			continue
+		}
+		session := ComputeSessionLoad(w, q.Method, max, resting)
+		daily[w.Start.In(q.Location).Format(DateLayout)] += session.Load
+		if w.Start.Before(first) {
+			continue
+		}
+		result.Sessions = append(result.Sessions, session)
+		if session.ZoneSeconds != nil {
+			if result.ZoneSeconds == nil {
+				result.ZoneSeconds = make([]int, HRZones)
+			}
+			for z, seconds := range session.ZoneSeconds {
+				result.ZoneSeconds[z] += seconds
+			}
+		}
+	}
+	sort.Slice(result.Sessions, func(i, j int) bool {
+		return result.Sessions[i].Start.Before(result.Sessions[j].Start)
+	})
+	for day := first; day.Before(q.To); day = day.AddDate(0, 0, 1) {
+		date := day.Format(DateLayout)
+		d := client.DailyLoad{Date: date, Load: round(daily[date], 1)}
+		for i := 0; i < ChronicDays; i++ {
+			load := daily[day.AddDate(0, 0, -i).Format(DateLayout)]
+			if i < AcuteDays {
+				d.Acute += load
+			}
+			d.Chronic += load
+		}
+		d.Acute = round(d.Acute, 1)
+		d.Chronic = round(d.Chronic*AcuteDays/ChronicDays, 1)
+		if d.Chronic > 0 {
+			d.Ratio = round(d.Acute/d.Chronic, 2)
+		}
+		if d.Ratio > q.Threshold {
+			d.Warning = true
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"%s: acute:chronic workload ratio %.2f exceeds %.2f", date, d.Ratio, q.Threshold))
+		}
+		result.Days = append(result.Days, d)
+	}
+	return &result, nil
+}
+
+// TrainingLoad compute the heart rate zones, session loads and acute:chronic
+// workload ratio of the user for each day of the query
+func (s *WorkoutService) TrainingLoad(ctx context.Context, q LoadQuery) (*client.TrainingLoad, error) {
+	uid, err := objectID(q.UserID, "user")
+	if err != nil {
+		return nil, err
+	}
+	if err = q.normalize(); err != nil {
+		return nil, err
+	}
+	var user User
+	err = s.users.FindOne(ctx, bson.M{"_id": uid}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{fmt.Sprintf("user '%s' not found", q.UserID)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := s.Collection.Find(ctx, bson.M{
+		"user_id": uid,
+		"start":   bson.M{"$gte": q.dayStart(q.From).AddDate(0, 0, -ChronicDays), "$lt": q.To},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	workouts := []Workout{}
+	for cursor.Next(ctx) {
+		var elem Workout
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode workout %s", err)
+			return nil, err
+		}
+		workouts = append(workouts, elem)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return ComputeLoad(workouts, user.MaxHR, user.RestingHR, q)
+}
@@ -0,0 +1,125 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHRZone(t *testing.T) {
+	// Heart rate reserve of 140 bpm
+	assert.Equal(t, 1, db.HRZone(100, 190, 50))
+	assert.Equal(t, 2, db.HRZone(134, 190, 50))
+	assert.Equal(t, 3, db.HRZone(150, 190, 50))
+	assert.Equal(t, 4, db.HRZone(162, 190, 50))
+	assert.Equal(t, 5, db.HRZone(180, 190, 50))
+	assert.Equal(t, 5, db.HRZone(200, 190, 50))
+}
+
+func TestTRIMP(t *testing.T) {
+	// 60 minutes at 50% of the heart rate reserve
+	assert.InDelta(t, 50.1, db.TRIMP(3600, 120, 190, 50), 0.1)
+	assert.Equal(t, 0.0, db.TRIMP(3600, 40, 190, 50))
+	assert.Equal(t, db.TRIMP(600, 190, 190, 50), db.TRIMP(600, 200, 190, 50))
+}
+
+func TestComputeSessionLoad(t *testing.T) {
+	w := db.Workout{
+		ID:    primitive.NewObjectID(),
+		Start: time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC),
+		Exercises: []db.WorkoutExercise{
+			db.WorkoutExercise{Cardio: &db.CardioMetrics{DurationSeconds: 1200, AvgHeartRate: 150,
+				Laps: []db.CardioMetrics{
+					db.CardioMetrics{DurationSeconds: 600, AvgHeartRate: 134},
+					db.CardioMetrics{DurationSeconds: 600, AvgHeartRate: 180},
+				}}},
+			db.WorkoutExercise{Sets: []db.StrengthSet{
+				db.StrengthSet{Reps: 5, RestSeconds: 280, RPE: 7},
+				db.StrengthSet{Reps: 5, RestSeconds: 280, RPE: 9},
+			}},
+		},
+	}
+	load := db.ComputeSessionLoad(&w, db.LoadTRIMP, 190, 50)
+	assert.Equal(t, 1800, load.DurationSeconds)
+	assert.Equal(t, []int{0, 600, 0, 0, 600}, load.ZoneSeconds)
+	expected := db.TRIMP(600, 134, 190, 50) + db.TRIMP(600, 180, 190, 50)
+	assert.InDelta(t, expected, load.TRIMP, 0.05)
+	assert.Equal(t, 240.0, load.RPELoad)
+	assert.Equal(t, load.TRIMP, load.Load)
+
+	// The average is used when a lap lacks a heart rate
+	w.Exercises[0].Cardio.Laps[1].AvgHeartRate = 0
+	load = db.ComputeSessionLoad(&w, db.LoadRPE, 190, 50)
+	assert.Equal(t, []int{0, 0, 1200, 0, 0}, load.ZoneSeconds)
+	assert.Equal(t, 240.0, load.Load)
+
+	// Zones require the heart rates of the user
+	load = db.ComputeSessionLoad(&w, db.LoadRPE, 0, 0)
+	assert.Nil(t, load.ZoneSeconds)
+	assert.Equal(t, 0.0, load.TRIMP)
+}
+
+// rpeWorkout a session of the duration, in minutes, at the RPE
+func rpeWorkout(start time.Time, minutes int, rpe float64) db.Workout {
+	return db.Workout{
+		ID:    primitive.NewObjectID(),
+		Start: start,
+		Exercises: []db.WorkoutExercise{db.WorkoutExercise{Sets: []db.StrengthSet{
+			db.StrengthSet{RestSeconds: minutes * 60, RPE: rpe},
+		}}},
+	}
+}
+
+func TestComputeLoad(t *testing.T) {
+	first := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	workouts := []db.Workout{}
+	// A steady 100 per day for four weeks followed by a week at 300
+	for i := 0; i < 35; i++ {
+		load := 100.0
+		if i >= 28 {
+			load = 300
+		}
+		workouts = append(workouts, rpeWorkout(first.AddDate(0, 0, i).Add(8*time.Hour), 20, load/20))
+	}
+	q := db.LoadQuery{Method: db.LoadRPE, From: first.AddDate(0, 0, 27), To: first.AddDate(0, 0, 35)}
+	load, err := db.ComputeLoad(workouts, 0, 0, q)
+	assert.NoError(t, err)
+	assert.Equal(t, db.DefaultACWRThreshold, load.Threshold)
+	assert.Equal(t, 8, len(load.Sessions))
+	assert.Nil(t, load.ZoneSeconds)
+	if assert.Equal(t, 8, len(load.Days)) {
+		day := load.Days[0]
+		assert.Equal(t, "2019-11-28", day.Date)
+		assert.Equal(t, 700.0, day.Acute)
+		assert.Equal(t, 700.0, day.Chronic)
+		assert.Equal(t, 1.0, day.Ratio)
+		assert.False(t, day.Warning)
+
+		day = load.Days[7]
+		assert.Equal(t, 2100.0, day.Acute)
+		assert.Equal(t, 1050.0, day.Chronic)
+		assert.Equal(t, 2.0, day.Ratio)
+		assert.True(t, day.Warning)
+	}
+	assert.Equal(t, 5, len(load.Warnings))
+
+	q.Threshold = 2.5
+	load, err = db.ComputeLoad(workouts, 0, 0, q)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(load.Warnings))
+
+	// The TRIMP requires the heart rates of the user
+	q.Method = db.LoadTRIMP
+	_, err = db.ComputeLoad(workouts, 0, 0, q)
+	assert.True(t, db.IsValidation(err))
+	q.Method = "banister"
+	_, err = db.ComputeLoad(workouts, 190, 50, q)
+	assert.True(t, db.IsValidation(err))
+	q.Method = db.LoadRPE
+	q.Threshold = -1
+	_, err = db.ComputeLoad(workouts, 190, 50, q)
+	assert.True(t, db.IsValidation(err))
+}
@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DefaultLocale the locale of the name and description of an exercise, used
+// when no translation matches the locales of a user
+const DefaultLocale = "en"
+
+// The longest locale accepted and the most locales of an Accept-Language
+// header considered
+const (
+	MaxLocaleLength = 35
+	MaxLocales      = 10
+)
+
+// Translation the name and description of an exercise in a locale
+type Translation struct {
+	Name        string `bson:"name" json:"name"`
+	Description string `bson:"description,omitempty" json:"description,omitempty"`
+}
+
+// isAlpha whether the subtag is made up of ASCII letters only
+func isAlpha(subtag string) bool {
+	for _, r := range subtag {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphaNum whether the subtag is made up of ASCII letters and digits only
+func isAlphaNum(subtag string) bool {
+	for _, r := range subtag {
+		if !isAlpha(string(r)) && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeLocale validate a BCP 47 language tag, such as "en-GB" or
+// "zh-Hant-TW", returning it in its canonical case. The language is lower
+// case, a script title case and a region upper case. Underscores are
+// accepted in place of hyphens, so "en_gb" is "en-GB".
+func NormalizeLocale(tag string) (string, error) {
+	tag = strings.Replace(strings.TrimSpace(tag), "_", "-", -1)
+	if len(tag) > MaxLocaleLength {
+		return "", &ValidationError{fmt.Sprintf("locale exceeds %d characters", MaxLocaleLength)}
+	}
+	subtags := strings.Split(tag, "-")
+	if len(subtags[0]) < 2 || len(subtags[0]) > 3 || !isAlpha(subtags[0]) {
+		return "", &ValidationError{fmt.Sprintf("invalid locale '%s'", tag)}
+	}
+	subtags[0] = strings.ToLower(subtags[0])
+	for i, s := range subtags[1:] {
+		if len(s) == 0 || len(s) > 8 || !isAlphaNum(s) {
+			return "", &ValidationError{fmt.Sprintf("invalid locale '%s'", tag)}
+		}
+		switch {
+		case len(s) == 4 && isAlpha(s):
+			s = strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+		case len(s) == 2 && isAlpha(s):
+			s = strings.ToUpper(s)
+		default:
+			s = strings.ToLower(s)
+		}
+		subtags[i+1] = s
+	}
+	return strings.Join(subtags, "-"), nil
+}
+
+// ParseAcceptLanguage the locales of an Accept-Language header, most
+// preferred first. Locales of equal quality keep their order in the header.
+// Wildcards, invalid locales and those with a quality of 0 are ignored.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+	ranges := []weighted{}
+	for _, r := range strings.Split(header, ",") {
+		params := strings.Split(r, ";")
+		locale, err := NormalizeLocale(params[0])
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if quality, err = strconv.ParseFloat(p[2:], 64); err != nil {
+					quality = 0
+				}
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, weighted{locale, quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+	locales := []string{}
+	for _, r := range ranges {
+		if !oneOf(r.locale, locales) && len(locales) < MaxLocales {
+			locales = append(locales, r.locale)
+		}
+	}
+	return locales
+}
+
+// newTranslation validate the translation of the name and description of an
+// exercise into the locale, returning the normalized locale and translation.
+// The default locale is not translated, it is the name and description of the
+// exercise.
+func newTranslation(locale string, t client.Translation) (string, *Translation, error) {
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return "", nil, err
+	}
+	if locale == DefaultLocale {
+		return "", nil, &ValidationError{fmt.Sprintf(
+			"the name and description of the exercise are those of the default locale '%s'", DefaultLocale)}
+	}
+	translation := Translation{Name: strings.TrimSpace(t.Name), Description: strings.TrimSpace(t.Description)}
+	if len(NameKey(translation.Name)) == 0 {
+		return "", nil, &ValidationError{fmt.Sprintf("the %s name must contain a letter or digit", locale)}
+	}
+	return locale, &translation, nil
+}
+
+// setTranslations validate and set the translations of the exercise
+func (e *Exercise) setTranslations(translations map[string]client.Translation) error {
+	e.Translations = nil
+	for l, t := range translations {
+		locale, translation, err := newTranslation(l, t)
+		if err != nil {
+			return err
+		}
+		if e.Translations == nil {
+			e.Translations = map[string]Translation{}
+		}
+		e.Translations[locale] = *translation
+	}
+	return nil
+}
+
+// translation the translation of the exercise for the first of the locales
+// with one. A locale without a translation falls back to its parent locales,
+// "en-GB-oxendict" to "en-GB" then "en". No translation is returned when the
+// default locale is preferred or none of the locales are translated.
+func (e *Exercise) translation(locales []string) (string, *Translation) {
+	for _, locale := range locales {
+		for {
+			if locale == DefaultLocale {
+				return "", nil
+			}
+			if t, ok := e.Translations[locale]; ok {
+				return locale, &t
+			}
+			i := strings.LastIndex(locale, "-")
+			if i < 0 {
+				break
+			}
+			locale = locale[:i]
+		}
+	}
+	return "", nil
+}
+
+// Localize transform into a client facing Exercise object with the name and
+// description of the first of the locales translated, see translation. The
+// exercise has the name and description of the default locale otherwise. The
+// translations themselves are not included.
+func (e *Exercise) Localize(locales []string) client.Exercise {
+	c := e.Convert()
+	c.Translations = nil
+	c.Locale = DefaultLocale
+	if locale, t := e.translation(locales); t != nil {
+		c.Locale, c.Name = locale, t.Name
+		if len(t.Description) > 0 {
+			c.Description = t.Description
+		}
+	}
+	return c
+}
+
+// GetTranslations retrieve the translations of the name and description of
+// the exercise, by locale
+func (s *ExerciseService) GetTranslations(ctx context.Context, exerciseID string) (client.Translations, error) {
+	exercise, err := s.getExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	translations := client.Translations{}
+	for locale, t := range exercise.Translations {
+		translations[locale] = client.Translation{Name: t.Name, Description: t.Description}
+	}
+	return translations, nil
+}
+
+// SetTranslation add, or replace, the translation of the name and description
+// of the exercise into the locale
+func (s *ExerciseService) SetTranslation(ctx context.Context, exerciseID string, locale string,
+	t *client.Translation) (*client.Translation, error) {
+	id, err := objectID(exerciseID, "exercise")
+	if err != nil {
+		return nil, err
+	}
+	locale, translation, err := newTranslation(locale, *t)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.Collection.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"translations." + locale: translation}})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, &NotFoundError{fmt.Sprintf("exercise '%s' not found", exerciseID)}
+	}
+	return &client.Translation{Name: translation.Name, Description: translation.Description}, nil
+}
+
+// DeleteTranslation remove the translation of the exercise into the locale
+func (s *ExerciseService) DeleteTranslation(ctx context.Context, exerciseID string, locale string) error {
+	id, err := objectID(exerciseID, "exercise")
+	if err != nil {
+		return err
+	}
+	if locale, err = NormalizeLocale(locale); err != nil {
+		return err
+	}
+	field := "translations." + locale
+	err = s.Collection.FindOneAndUpdate(ctx, bson.M{"_id": id, field: bson.M{"$exists": true}},
+		bson.M{"$unset": bson.M{field: ""}}).Err()
+	if err == mongo.ErrNoDocuments {
+		return &NotFoundError{fmt.Sprintf("exercise '%s' has no %s translation", exerciseID, locale)}
+	}
+	return err
+}
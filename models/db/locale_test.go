@@ -0,0 +1,133 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	for tag, expected := range map[string]string{
+		"en":         "en",
+		"EN-gb":      "en-GB",
+		"en_us":      "en-US",
+		"zh-hant-tw": "zh-Hant-TW",
+		"es-419":     "es-419",
+		" de-CH ":    "de-CH",
+	} {
+		locale, err := db.NormalizeLocale(tag)
+		assert.NoError(t, err, tag)
+		assert.Equal(t, expected, locale, tag)
+	}
+	for _, tag := range []string{"", "e", "english", "en-", "en--gb", "en-toolongsubtag", "1n", "*"} {
+		_, err := db.NormalizeLocale(tag)
+		assert.True(t, db.IsValidation(err), tag)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for header, expected := range map[string][]string{
+		"":                                   {},
+		"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5": {"fr-CH", "fr", "en"},
+		"de;q=0.5, en-gb":                    {"en-GB", "de"},
+		"es;q=0, pt-BR;q=0.7, it;q=0.7":      {"pt-BR", "it"},
+		"fr;q=x, nl, not a locale, nl-NL":    {"nl", "nl-NL"},
+	} {
+		assert.Equal(t, expected, db.ParseAcceptLanguage(header), header)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	exercise, err := db.NewExercise(&client.Exercise{Name: "Jumping Jack", Description: "A jumping exercise",
+		Translations: map[string]client.Translation{
+			"en_gb": {Name: "Star Jump"},
+			"de":    {Name: "Hampelmann", Description: "Eine Sprungübung"},
+		}})
+	assert.NoError(t, err)
+	for _, d := range []struct {
+		locales     []string
+		locale      string
+		name        string
+		description string
+	}{
+		{nil, "en", "Jumping Jack", "A jumping exercise"},
+		{[]string{"en-GB"}, "en-GB", "Star Jump", "A jumping exercise"},
+		{[]string{"de-AT", "en-GB"}, "de", "Hampelmann", "Eine Sprungübung"},
+		{[]string{"en-US", "de"}, "en", "Jumping Jack", "A jumping exercise"},
+		{[]string{"fr", "en-GB"}, "en-GB", "Star Jump", "A jumping exercise"},
+		{[]string{"fr"}, "en", "Jumping Jack", "A jumping exercise"},
+	} {
+		c := exercise.Localize(d.locales)
+		assert.Equal(t, d.locale, c.Locale, "%v", d.locales)
+		assert.Equal(t, d.name, c.Name, "%v", d.locales)
+		assert.Equal(t, d.description, c.Description, "%v", d.locales)
+		assert.Nil(t, c.Translations)
+	}
+	assert.Equal(t, 2, len(exercise.Convert().Translations))
+
+	for _, translations := range []map[string]client.Translation{
+		{"en": {Name: "Jumping Jack"}},
+		{"fr": {Name: " "}},
+		{"not a locale": {Name: "Jumping Jack"}},
+	} {
+		_, err = db.NewExercise(&client.Exercise{Name: "Jumping Jack", Translations: translations})
+		assert.True(t, db.IsValidation(err), "%v", translations)
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	service := SetupExercise(t, true, false)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+	assert.NoError(t, service.Create(ctx, &client.Exercise{Name: "Pushup", Description: "Push the floor away"}))
+	assert.NoError(t, service.Create(ctx, &client.Exercise{Name: "Squat"}))
+	pushup, err := service.GetByName(ctx, "Pushup")
+	assert.NoError(t, err)
+
+	translation, err := service.SetTranslation(ctx, pushup.ID, "en_gb", &client.Translation{Name: " Press-Up "})
+	assert.NoError(t, err)
+	assert.Equal(t, "Press-Up", translation.Name)
+	_, err = service.SetTranslation(ctx, pushup.ID, "de", &client.Translation{Name: "Liegestütz"})
+	assert.NoError(t, err)
+	_, err = service.SetTranslation(ctx, pushup.ID, "en", &client.Translation{Name: "Push-Up"})
+	assert.True(t, db.IsValidation(err))
+	_, err = service.SetTranslation(ctx, primitive.NewObjectID().Hex(), "fr", &client.Translation{Name: "Pompe"})
+	assert.True(t, db.IsNotFound(err))
+	translations, err := service.GetTranslations(ctx, pushup.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, client.Translations{"en-GB": {Name: "Press-Up"}, "de": {Name: "Liegestütz"}},
+		translations)
+
+	// The exercises are listed and searched in the locale of the user
+	exercises, err := service.Find(ctx, &db.ExerciseFilter{Locales: []string{"en-GB"}})
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(exercises)) {
+		assert.Equal(t, "Press-Up", exercises[0].Name)
+		assert.Equal(t, "en-GB", exercises[0].Locale)
+		assert.Equal(t, "Push the floor away", exercises[0].Description)
+		assert.Equal(t, "Squat", exercises[1].Name)
+		assert.Equal(t, "en", exercises[1].Locale)
+	}
+	result, err := service.Search(ctx, db.SearchQuery{Text: "liegestutz", Locales: []string{"de"}})
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(result.Results)) {
+		assert.Equal(t, "Liegestütz", result.Results[0].Name)
+	}
+
+	// Updating the exercise leaves its translations as is
+	pushup.Name = "Push-up"
+	assert.NoError(t, service.Update(ctx, pushup))
+	translations, err = service.GetTranslations(ctx, pushup.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(translations))
+
+	assert.NoError(t, service.DeleteTranslation(ctx, pushup.ID, "en-gb"))
+	assert.True(t, db.IsNotFound(service.DeleteTranslation(ctx, pushup.ID, "en-GB")))
+	exercises, err = service.Find(ctx, &db.ExerciseFilter{Locales: []string{"en-GB"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Push-up", exercises[0].Name)
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enpointe/activity/blob"
+	"github.com/enpointe/activity/media"
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MediaPath the path the server serves the images and animations of exercises from
+const MediaPath = "/media/"
+
+// The limits on the media of an exercise
+const (
+	MaxMedia         = 20
+	MaxMediaSize     = 10 << 20
+	MaxCaptionLength = 200
+)
+
+// Media an image, animation or video demonstrating an exercise. Images and
+// animations, and their thumbnails, are held in a blob store under Key and
+// ThumbnailKey. Videos are links to another site.
+type Media struct {
+	ID            primitive.ObjectID `bson:"_id" json:"_id"`
+	Kind          string             `bson:"kind" json:"kind"`
+	Caption       string             `bson:"caption,omitempty" json:"caption,omitempty"`
+	ContentType   string             `bson:"content_type,omitempty" json:"content_type,omitempty"`
+	Key           string             `bson:"key,omitempty" json:"key,omitempty"`
+	ThumbnailType string             `bson:"thumbnail_type,omitempty" json:"thumbnail_type,omitempty"`
+	ThumbnailKey  string             `bson:"thumbnail_key,omitempty" json:"thumbnail_key,omitempty"`
+	Link          string             `bson:"link,omitempty" json:"link,omitempty"`
+	Width         int                `bson:"width,omitempty" json:"width,omitempty"`
+	Height        int                `bson:"height,omitempty" json:"height,omitempty"`
+	Size          int                `bson:"size,omitempty" json:"size,omitempty"`
+	Added         time.Time          `bson:"added" json:"added"`
+}
+
+// Convert transform into a client facing Media object
+func (m *Media) Convert() client.Media {
+	c := client.Media{
+		ID:          m.ID.Hex(),
+		Kind:        m.Kind,
+		Caption:     m.Caption,
+		ContentType: m.ContentType,
+		Width:       m.Width,
+		Height:      m.Height,
+		Added:       m.Added,
+	}
+	if m.Kind == media.KindVideo {
+		c.URL = m.Link
+	} else {
+		c.URL = MediaPath + m.ID.Hex()
+		c.ThumbnailURL = MediaPath + m.ID.Hex() + "/thumbnail"
+	}
+	return c
+}
+
+// validate ensure the media, ie as imported, is complete. Images and
+// animations must reference their blobs, videos their link.
+func (m *Media) validate() error {
+	if m.ID.IsZero() {
+		return &ValidationError{"media must have an id"}
+	}
+	switch m.Kind {
+	case media.KindImage, media.KindAnimation:
+		if err := blob.ValidKey(m.Key); err != nil {
+			return &ValidationError{fmt.Sprintf("media '%s' has an invalid key, %s", m.ID.Hex(), err)}
+		}
+		if err := blob.ValidKey(m.ThumbnailKey); err != nil {
+			return &ValidationError{fmt.Sprintf("media '%s' has an invalid thumbnail key, %s", m.ID.Hex(), err)}
+		}
+	case media.KindVideo:
+		if _, err := media.VideoLink(m.Link); err != nil {
+			return &ValidationError{fmt.Sprintf("media '%s' has an invalid link, %s", m.ID.Hex(), err)}
+		}
+	default:
+		return &ValidationError{fmt.Sprintf("media '%s' has an invalid kind '%s'", m.ID.Hex(), m.Kind)}
+	}
+	return nil
+}
+
+// extensions the file extension of the blobs of each content type
+var extensions = map[string]string{"image/png": ".png", "image/jpeg": ".jpg", "image/gif": ".gif"}
+
+// newMedia validate the caption of new media of the exercise
+func newMedia(e *Exercise, kind string, caption string) (*Media, error) {
+	if len(e.Media) >= MaxMedia {
+		return nil, &ValidationError{fmt.Sprintf("an exercise can have at most %d media", MaxMedia)}
+	}
+	if len(caption) > MaxCaptionLength {
+		return nil, &ValidationError{fmt.Sprintf("caption exceeds %d characters", MaxCaptionLength)}
+	}
+	return &Media{ID: primitive.NewObjectID(), Kind: kind, Caption: caption,
+		Added: time.Now().UTC().Truncate(time.Millisecond)}, nil
+}
+
+// pushMedia add the media to the exercise
+func (s *ExerciseService) pushMedia(ctx context.Context, e *Exercise, m *Media) error {
+	result, err := s.Collection.UpdateOne(ctx, bson.M{"_id": e.ID}, bson.M{"$push": bson.M{"media": m}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("exercise '%s' not found", e.ID.Hex())}
+	}
+	return nil
+}
+
+// AddMedia add an uploaded image or animation to the exercise. The image must
+// be a PNG, JPEG or GIF, GIFs of more than one frame being animations. The
+// image and its generated thumbnail are stored in the blob store.
+func (s *ExerciseService) AddMedia(ctx context.Context, store blob.Store, exerciseID string, data []byte,
+	caption string) (*client.Media, error) {
+	exercise, err := s.getExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxMediaSize {
+		return nil, &ValidationError{fmt.Sprintf("media exceeds %d bytes", MaxMediaSize)}
+	}
+	img, err := media.Process(data)
+	if err != nil {
+		return nil, &ValidationError{err.Error()}
+	}
+	m, err := newMedia(exercise, img.Kind, caption)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "exercises/" + exercise.ID.Hex() + "/" + m.ID.Hex()
+	m.ContentType, m.Width, m.Height, m.Size = img.ContentType, img.Width, img.Height, len(data)
+	m.Key = prefix + extensions[img.ContentType]
+	m.ThumbnailType = img.ThumbnailContentType
+	m.ThumbnailKey = prefix + "-thumbnail" + extensions[img.ThumbnailContentType]
+	if err = store.Put(ctx, m.Key, data, m.ContentType); err != nil {
+		return nil, err
+	}
+	if err = store.Put(ctx, m.ThumbnailKey, img.Thumbnail, m.ThumbnailType); err == nil {
+		err = s.pushMedia(ctx, exercise, m)
+	}
+	if err != nil {
+		deleteBlobs(ctx, store, m)
+		return nil, err
+	}
+	c := m.Convert()
+	return &c, nil
+}
+
+// AddVideoLink add a link to a video demonstrating the exercise
+func (s *ExerciseService) AddVideoLink(ctx context.Context, exerciseID string, link string,
+	caption string) (*client.Media, error) {
+	exercise, err := s.getExercise(ctx, exerciseID)
+	if err != nil {
+		return nil, err
+	}
+	if link, err = media.VideoLink(link); err != nil {
+		return nil, &ValidationError{err.Error()}
+	}
+	m, err := newMedia(exercise, media.KindVideo, caption)
+	if err != nil {
+		return nil, err
+	}
+	m.Link = link
+	if err = s.pushMedia(ctx, exercise, m); err != nil {
+		return nil, err
+	}
+	c := m.Convert()
+	return &c, nil
+}
+
+// FindMedia retrieve the media of an exercise visible to the user, see Find
+func (s *ExerciseService) FindMedia(ctx context.Context, userID string, mediaID string) (*Media, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	mid, err := objectID(mediaID, "media")
+	if err != nil {
+		return nil, err
+	}
+	var exercise Exercise
+	err = s.Collection.FindOne(ctx, bson.M{"media._id": mid, "owner": visibleTo(uid)},
+		options.FindOne().SetProjection(bson.M{"media.$": 1})).Decode(&exercise)
+	if err == mongo.ErrNoDocuments || (err == nil && len(exercise.Media) == 0) {
+		return nil, &NotFoundError{fmt.Sprintf("media '%s' not found", mediaID)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exercise.Media[0], nil
+}
+
+// DeleteMedia remove the media from its exercise, along with its image and
+// thumbnail from the blob store
+func (s *ExerciseService) DeleteMedia(ctx context.Context, store blob.Store, mediaID string) error {
+	mid, err := objectID(mediaID, "media")
+	if err != nil {
+		return err
+	}
+	var exercise Exercise
+	err = s.Collection.FindOneAndUpdate(ctx, bson.M{"media._id": mid},
+		bson.M{"$pull": bson.M{"media": bson.M{"_id": mid}}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"media": bson.M{"$elemMatch": bson.M{"_id": mid}}})).
+		Decode(&exercise)
+	if err == mongo.ErrNoDocuments || (err == nil && len(exercise.Media) == 0) {
+		return &NotFoundError{fmt.Sprintf("media '%s' not found", mediaID)}
+	}
+	if err != nil {
+		return err
+	}
+	deleteBlobs(ctx, store, &exercise.Media[0])
+	return nil
+}
+
+// deleteBlobs remove the image and thumbnail of the media from the blob store.
+// Failures are logged rather than reported, leaving at worst an unreferenced blob.
+func deleteBlobs(ctx context.Context, store blob.Store, m *Media) {
+	for _, key := range []string{m.Key, m.ThumbnailKey} {
+		if len(key) == 0 {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			log.Warnf("failed to delete blob %s, %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package db_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/enpointe/activity/blob"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMediaConvert(t *testing.T) {
+	id := primitive.NewObjectID()
+	upload := db.Media{ID: id, Kind: "image", ContentType: "image/png", Key: "exercises/a/b.png",
+		ThumbnailKey: "exercises/a/b-thumbnail.png", Width: 10, Height: 20}
+	c := upload.Convert()
+	assert.Equal(t, "/media/"+id.Hex(), c.URL)
+	assert.Equal(t, "/media/"+id.Hex()+"/thumbnail", c.ThumbnailURL)
+	assert.Equal(t, 10, c.Width)
+
+	video := db.Media{ID: id, Kind: "video", Link: "https://example.com/squat"}
+	c = video.Convert()
+	assert.Equal(t, "https://example.com/squat", c.URL)
+	assert.Empty(t, c.ThumbnailURL)
+}
+
+func TestExerciseMedia(t *testing.T) {
+	service := SetupExercise(t, true, false)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+	dir, err := ioutil.TempDir("", "media")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := &blob.FileStore{Dir: dir}
+
+	owner, other := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	id, err := service.CreatePrivate(ctx, owner, &client.Exercise{Name: "Sled Push"})
+	assert.NoError(t, err)
+	var data bytes.Buffer
+	assert.NoError(t, png.Encode(&data, image.NewGray(image.Rect(0, 0, 600, 400))))
+
+	_, err = service.AddMedia(ctx, store, id, []byte("GIF89a"), "")
+	assert.True(t, db.IsValidation(err))
+	_, err = service.AddMedia(ctx, store, primitive.NewObjectID().Hex(), data.Bytes(), "")
+	assert.True(t, db.IsNotFound(err))
+	media, err := service.AddMedia(ctx, store, id, data.Bytes(), "start position")
+	assert.NoError(t, err)
+	assert.Equal(t, "image", media.Kind)
+	assert.Equal(t, 600, media.Width)
+	_, err = service.AddVideoLink(ctx, id, "not a link", "")
+	assert.True(t, db.IsValidation(err))
+	video, err := service.AddVideoLink(ctx, id, "https://example.com/sled", "")
+	assert.NoError(t, err)
+
+	exercise, err := service.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Media{*media, *video}, exercise.Media)
+
+	// The media of a private exercise are only found for its owner
+	found, err := service.FindMedia(ctx, owner, media.ID)
+	assert.NoError(t, err)
+	blobs, err := ioutil.ReadDir(dir + "/exercises/" + id)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(blobs))
+	reader, err := store.Get(ctx, found.ThumbnailKey)
+	if assert.NoError(t, err) {
+		thumbnail, err := png.Decode(reader)
+		reader.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 256, 170), thumbnail.Bounds())
+	}
+	_, err = service.FindMedia(ctx, other, media.ID)
+	assert.True(t, db.IsNotFound(err))
+
+	assert.NoError(t, service.DeleteMedia(ctx, store, media.ID))
+	assert.True(t, db.IsNotFound(service.DeleteMedia(ctx, store, media.ID)))
+	_, err = store.Get(ctx, found.Key)
+	assert.Equal(t, blob.ErrNotFound, err)
+	exercise, err = service.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, []client.Media{*video}, exercise.Media)
+}
+
+func TestMediaExportImport(t *testing.T) {
+	service := SetupExercise(t, true, false)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+	dir, err := ioutil.TempDir("", "media")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store := &blob.FileStore{Dir: dir}
+
+	assert.NoError(t, service.Create(ctx, &client.Exercise{Name: "Squat"}))
+	squat, err := service.GetByName(ctx, "Squat")
+	assert.NoError(t, err)
+	var data bytes.Buffer
+	assert.NoError(t, png.Encode(&data, image.NewGray(image.Rect(0, 0, 300, 300))))
+	img, err := service.AddMedia(ctx, store, squat.ID, data.Bytes(), "bottom position")
+	assert.NoError(t, err)
+	video, err := service.AddVideoLink(ctx, squat.ID, "https://example.com/squat", "")
+	assert.NoError(t, err)
+
+	formats := []struct {
+		export db.ExportFormat
+		imprt  db.ImportFormat
+	}{{db.ExportJSONLines, db.ImportJSON}, {db.ExportCSV, db.ImportCSV}}
+	for _, format := range formats {
+		var buf bytes.Buffer
+		assert.NoError(t, service.Export(ctx, &buf, format.export))
+		exported := buf.String()
+
+		// Importing over the exercise keeps its media
+		result, err := service.Import(ctx, strings.NewReader(exported), format.imprt, db.ImportUpsert)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Updated)
+		assert.Empty(t, result.Errors)
+		exercise, err := service.GetByID(ctx, squat.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []client.Media{*img, *video}, exercise.Media)
+
+		// The media are restored along with the exercise, still referencing their blobs
+		assert.NoError(t, service.DeleteAll(ctx))
+		result, err = service.Import(ctx, strings.NewReader(exported), format.imprt, db.ImportUpsert)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Inserted, format)
+		assert.Empty(t, result.Errors)
+		exercise, err = service.GetByID(ctx, squat.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []client.Media{*img, *video}, exercise.Media, format)
+		found, err := service.FindMedia(ctx, primitive.NewObjectID().Hex(), img.ID)
+		if assert.NoError(t, err) {
+			reader, err := store.Get(ctx, found.Key)
+			assert.NoError(t, err)
+			reader.Close()
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// getExercise retrieve the exercise with the id, reporting a NotFoundError if
+// there is none
+func (s *ExerciseService) getExercise(ctx context.Context, hexid string) (*Exercise, error) {
+	id, err := objectID(hexid, "exercise")
+	if err != nil {
+		return nil, err
+	}
+	var exercise Exercise
+	err = s.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&exercise)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{fmt.Sprintf("exercise '%s' not found", hexid)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exercise, nil
+}
+
+// repoint change the exercise of the entries of the documents of the
+// collection, returning the number of documents modified
+func repoint(ctx context.Context, collection *mongo.Collection, from primitive.ObjectID,
+	into primitive.ObjectID) (int, error) {
+	result, err := collection.UpdateMany(ctx, bson.M{"exercises.exercise_id": from},
+		bson.M{"$set": bson.M{"exercises.$[e].exercise_id": into}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"e.exercise_id": from}},
+		}))
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+// Merge fold the duplicate exercise from into the exercise into. The workout
+// sessions, templates and goals referencing the duplicate are repointed to
+// the exercise it is merged into, and the personal records of the users that
+// logged the duplicate are rebuilt. The name and aliases of the duplicate
+// become aliases of the merged exercise. Its description and MET values are
+// kept when the merged exercise has none. Its media are added to the merged
+// exercise, as are its translations into locales the merged exercise lacks.
+// The duplicate is then deleted. A private exercise can be merged into the
+// shared catalog but a shared exercise can not be merged into a private one.
+func (s *ExerciseService) Merge(ctx context.Context, from string, into string) (*client.ExerciseMerge, error) {
+	duplicate, err := s.getExercise(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	exercise, err := s.getExercise(ctx, into)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate.ID == exercise.ID {
+		return nil, &ValidationError{"an exercise can not be merged into itself"}
+	}
+	if !exercise.Owner.IsZero() && exercise.Owner != duplicate.Owner {
+		return nil, &ValidationError{"an exercise can only be merged into a shared exercise or one of its owner"}
+	}
+	if err = exercise.setAliases(append(append(exercise.Aliases, duplicate.Name), duplicate.Aliases...)); err != nil {
+		return nil, err
+	}
+	if len(exercise.Description) == 0 {
+		exercise.Description = duplicate.Description
+	}
+	if exercise.MET == 0 {
+		exercise.MET = duplicate.MET
+	}
+	if len(exercise.Intensities) == 0 {
+		exercise.Intensities = duplicate.Intensities
+	}
+	exercise.Media = append(exercise.Media, duplicate.Media...)
+	for locale, t := range duplicate.Translations {
+		if _, ok := exercise.Translations[locale]; !ok {
+			if exercise.Translations == nil {
+				exercise.Translations = map[string]Translation{}
+			}
+			exercise.Translations[locale] = t
+		}
+	}
+
+	result := client.ExerciseMerge{}
+	err = s.workouts.transaction(ctx, func(ctx context.Context) error {
+		users, err := s.workouts.Collection.Distinct(ctx, "user_id", bson.M{"exercises.exercise_id": duplicate.ID})
+		if err != nil {
+			return err
+		}
+		if result.Workouts, err = repoint(ctx, s.workouts.Collection, duplicate.ID, exercise.ID); err != nil {
+			return err
+		}
+		if result.Templates, err = repoint(ctx, s.templates, duplicate.ID, exercise.ID); err != nil {
+			return err
+		}
+		goals, err := s.workouts.goals.Collection.UpdateMany(ctx, bson.M{"exercise_id": duplicate.ID},
+			bson.M{"$set": bson.M{"exercise_id": exercise.ID}})
+		if err != nil {
+			return err
+		}
+		result.Goals = int(goals.ModifiedCount)
+
+		// The sessions of the duplicate now count towards the goals and records
+		// of the merged exercise
+		cursor, err := s.workouts.goals.Collection.Find(ctx, bson.M{"exercise_id": exercise.ID})
+		if err != nil {
+			return err
+		}
+		affected := []Goal{}
+		if err = cursor.All(ctx, &affected); err != nil {
+			return err
+		}
+		for i := range affected {
+			if err = s.workouts.goals.refresh(ctx, &affected[i]); err != nil {
+				return err
+			}
+		}
+		if _, err = s.workouts.records.DeleteMany(ctx, bson.M{"exercise_id": duplicate.ID}); err != nil {
+			return err
+		}
+		for _, user := range users {
+			if uid, ok := user.(primitive.ObjectID); ok {
+				if err = s.workouts.updateRecords(ctx, uid, exercise.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		// The duplicate is removed first, releasing its keys for the merged exercise
+		if _, err = s.Collection.DeleteOne(ctx, bson.M{"_id": duplicate.ID}); err != nil {
+			return err
+		}
+		set := bson.M{"aliases": exercise.Aliases, "keys": exercise.Keys, "description": exercise.Description,
+			"met": exercise.MET, "intensities": exercise.Intensities}
+		if len(exercise.Media) > 0 {
+			set["media"] = exercise.Media
+		}
+		if len(exercise.Translations) > 0 {
+			set["translations"] = exercise.Translations
+		}
+		_, err = s.Collection.UpdateOne(ctx, bson.M{"_id": exercise.ID}, bson.M{"$set": set})
+		if IsDuplicateKeyError(err) {
+			return &ConflictError{fmt.Sprintf("the aliases of '%s' conflict with another exercise", exercise.Name)}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Exercise = exercise.Convert()
+	return &result, nil
+}
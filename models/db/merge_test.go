@@ -0,0 +1,63 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMergeExercises(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+	goals, err := db.NewGoalService(service.Collection.Database())
+	assert.NoError(t, err)
+	defer goals.Collection.Drop(ctx)
+
+	// A duplicate of the squat, logged and targeted by a goal
+	assert.NoError(t, ex.Create(ctx, &client.Exercise{Name: "Back Squat", Aliases: []string{"BS"},
+		Description: "A squat with the bar on the upper back"}))
+	duplicate, err := ex.GetByName(ctx, "back squat")
+	assert.NoError(t, err)
+	workouts := statsWorkouts()
+	workouts[0].Exercises[1].ExerciseID = duplicate.ID
+	for i := range workouts {
+		_, err = service.Create(ctx, testWorkoutUserID, &workouts[i])
+		assert.NoError(t, err)
+	}
+	_, err = goals.Create(ctx, testWorkoutUserID, &client.Goal{Metric: "sessions", ExerciseID: duplicate.ID,
+		Target: 10, Period: "month"})
+	assert.NoError(t, err)
+
+	result, err := ex.Merge(ctx, duplicate.ID, testSquatID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Workouts)
+	assert.Equal(t, 1, result.Goals)
+	assert.Equal(t, []string{"Back Squat", "BS"}, result.Exercise.Aliases)
+
+	_, err = ex.GetByID(ctx, duplicate.ID)
+	assert.Error(t, err)
+	merged, err := ex.GetByName(ctx, "back-squat")
+	assert.NoError(t, err)
+	assert.Equal(t, testSquatID, merged.ID)
+	records, err := service.GetRecords(ctx, testWorkoutUserID, duplicate.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+	records, err = service.GetRecords(ctx, testWorkoutUserID, testSquatID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, records)
+	list, err := goals.GetAll(ctx, testWorkoutUserID, "")
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(list)) {
+		assert.Equal(t, testSquatID, list[0].ExerciseID)
+	}
+
+	_, err = ex.Merge(ctx, testSquatID, testSquatID)
+	assert.True(t, db.IsValidation(err))
+	_, err = ex.Merge(ctx, primitive.NewObjectID().Hex(), testSquatID)
+	assert.True(t, db.IsNotFound(err))
+}
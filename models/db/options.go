@@ -0,0 +1,33 @@
+package db
+
+// ServiceOption options that can be passed to the services of this package
+type ServiceOption func(*serviceOptions)
+
+// serviceOptions the settings configurable via ServiceOption
+type serviceOptions struct {
+	prefix string
+}
+
+// CollectionPrefix specifies a prefix prepended to the name of each collection
+// accessed by a service. Using a different prefix for each environment
+// allows multiple environments to share a single database.
+func CollectionPrefix(prefix string) ServiceOption {
+	return func(o *serviceOptions) {
+		o.prefix = prefix
+	}
+}
+
+// newServiceOptions apply the passed in options to the default settings
+func newServiceOptions(opts []ServiceOption) *serviceOptions {
+	o := &serviceOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// CollectionName returns the name of the collection with the
+// configured collection prefix applied
+func CollectionName(collection string, opts ...ServiceOption) string {
+	return newServiceOptions(opts).prefix + collection
+}
@@ -0,0 +1,14 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionName(t *testing.T) {
+	assert.Equal(t, db.UsersCollection, db.CollectionName(db.UsersCollection))
+	assert.Equal(t, "dev_exercises", db.CollectionName(db.ExerciseCollection, db.CollectionPrefix("dev_")))
+	assert.Equal(t, db.LogsCollection, db.CollectionName(db.LogsCollection, db.CollectionPrefix("")))
+}
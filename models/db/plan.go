@@ -0,0 +1,273 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxPlanWeeks the longest training plan that can be authored
+const MaxPlanWeeks = 52
+
+// DaysPerWeek the number of days in each week of a training plan
+const DaysPerWeek = 7
+
+// PlanSession a workout session scheduled on a day, 1 to 7, of a week of a plan
+type PlanSession struct {
+	Day        int                `bson:"day" json:"day"`
+	TemplateID primitive.ObjectID `bson:"template_id" json:"template_id"`
+}
+
+// PlanWeek the workout sessions scheduled for a week of a plan
+type PlanWeek struct {
+	Sessions []PlanSession `bson:"sessions" json:"sessions"`
+}
+
+// Plan a multi-week training plan authored by a staff member
+type Plan struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	AuthorID    primitive.ObjectID `bson:"author_id" json:"author_id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Weeks       []PlanWeek         `bson:"weeks" json:"weeks"`
+	Updated     time.Time          `bson:"updated" json:"updated"`
+}
+
+// Assignment a training plan assigned to a user. Start is the midnight, in
+// the time zone of the assignment, of the first day of the plan and End the
+// midnight following the last day.
+type Assignment struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	PlanID     primitive.ObjectID `bson:"plan_id" json:"plan_id"`
+	AssignedBy primitive.ObjectID `bson:"assigned_by" json:"assigned_by"`
+	Timezone   string             `bson:"timezone" json:"timezone"`
+	Start      time.Time          `bson:"start" json:"start"`
+	End        time.Time          `bson:"end" json:"end"`
+}
+
+// ScheduledSession a workout session of a plan scheduled on a local date
+type ScheduledSession struct {
+	Week       int
+	Day        int
+	Date       string
+	TemplateID primitive.ObjectID
+}
+
+// NewPlan transforms the web facing Plan structure to a database compatible
+// Plan structure authored by the specified user
+func NewPlan(authorID primitive.ObjectID, p *client.Plan) (*Plan, error) {
+	plan := Plan{
+		ID:          primitive.NewObjectID(),
+		AuthorID:    authorID,
+		Name:        strings.TrimSpace(p.Name),
+		Description: strings.TrimSpace(p.Description),
+		Weeks:       []PlanWeek{},
+		Updated:     time.Now().UTC(),
+	}
+	if len(plan.Name) == 0 {
+		return nil, &ValidationError{"a plan name must be specified"}
+	}
+	if len(plan.Name) > MaxTemplateNameLength {
+		return nil, &ValidationError{"plan name is too long"}
+	}
+	if len(p.Weeks) == 0 || len(p.Weeks) > MaxPlanWeeks {
+		return nil, &ValidationError{fmt.Sprintf("a plan must have between 1 and %d weeks", MaxPlanWeeks)}
+	}
+	sessions := 0
+	for _, w := range p.Weeks {
+		week := PlanWeek{Sessions: []PlanSession{}}
+		for _, s := range w.Sessions {
+			if s.Day < 1 || s.Day > DaysPerWeek {
+				return nil, &ValidationError{fmt.Sprintf("day must be between 1 and %d", DaysPerWeek)}
+			}
+			id, err := primitive.ObjectIDFromHex(s.TemplateID)
+			if err != nil {
+				return nil, &ValidationError{fmt.Sprintf("invalid template id '%s'", s.TemplateID)}
+			}
+			week.Sessions = append(week.Sessions, PlanSession{Day: s.Day, TemplateID: id})
+		}
+		sort.SliceStable(week.Sessions, func(i, j int) bool { return week.Sessions[i].Day < week.Sessions[j].Day })
+		sessions += len(week.Sessions)
+		plan.Weeks = append(plan.Weeks, week)
+	}
+	if sessions == 0 {
+		return nil, &ValidationError{"a plan must schedule at least one session"}
+	}
+	return &plan, nil
+}
+
+// templates the ids of the templates used by the plan
+func (p *Plan) templates() []primitive.ObjectID {
+	seen := map[primitive.ObjectID]bool{}
+	ids := []primitive.ObjectID{}
+	for _, w := range p.Weeks {
+		for _, s := range w.Sessions {
+			if !seen[s.TemplateID] {
+				seen[s.TemplateID] = true
+				ids = append(ids, s.TemplateID)
+			}
+		}
+	}
+	return ids
+}
+
+// Convert the database representation of a plan to the client representation
+func (p *Plan) Convert() client.Plan {
+	plan := client.Plan{
+		ID:          p.ID.Hex(),
+		AuthorID:    p.AuthorID.Hex(),
+		Name:        p.Name,
+		Description: p.Description,
+		Weeks:       []client.PlanWeek{},
+		Updated:     p.Updated,
+	}
+	for _, w := range p.Weeks {
+		week := client.PlanWeek{Sessions: []client.PlanSession{}}
+		for _, s := range w.Sessions {
+			week.Sessions = append(week.Sessions, client.PlanSession{Day: s.Day, TemplateID: s.TemplateID.Hex()})
+		}
+		plan.Weeks = append(plan.Weeks, week)
+	}
+	return plan
+}
+
+// Schedule the sessions of the plan on the local dates they fall on when
+// the plan starts on the date of start, start being the midnight beginning
+// the first day in the time zone of the schedule
+func (p *Plan) Schedule(start time.Time) []ScheduledSession {
+	schedule := []ScheduledSession{}
+	for i, w := range p.Weeks {
+		for _, s := range w.Sessions {
+			date := start.AddDate(0, 0, i*DaysPerWeek+s.Day-1)
+			schedule = append(schedule, ScheduledSession{Week: i + 1, Day: s.Day,
+				Date: date.Format(DateLayout), TemplateID: s.TemplateID})
+		}
+	}
+	return schedule
+}
+
+// NewAssignment create the database representation of the assignment of the
+// plan to a user, starting on the local date of the assignment
+func NewAssignment(userID primitive.ObjectID, assignedBy primitive.ObjectID, plan *Plan,
+	a *client.PlanAssignment) (*Assignment, error) {
+	assignment := Assignment{
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		PlanID:     plan.ID,
+		AssignedBy: assignedBy,
+		Timezone:   a.Timezone,
+	}
+	if len(assignment.Timezone) == 0 {
+		assignment.Timezone = time.UTC.String()
+	}
+	loc, err := time.LoadLocation(assignment.Timezone)
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("unknown timezone '%s'", a.Timezone)}
+	}
+	start, err := time.ParseInLocation(DateLayout, a.Start, loc)
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("start '%s' must be a date of the form %s", a.Start, DateLayout)}
+	}
+	assignment.Start = start.UTC()
+	assignment.End = start.AddDate(0, 0, len(plan.Weeks)*DaysPerWeek).UTC()
+	return &assignment, nil
+}
+
+// location the time zone of the assignment
+func (a *Assignment) location() *time.Location {
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Convert the database representation of an assignment to the client
+// representation, plan is the plan assigned
+func (a *Assignment) Convert(plan *Plan) client.PlanAssignment {
+	loc := a.location()
+	return client.PlanAssignment{
+		ID:         a.ID.Hex(),
+		PlanID:     a.PlanID.Hex(),
+		PlanName:   plan.Name,
+		Start:      a.Start.In(loc).Format(DateLayout),
+		End:        a.End.In(loc).AddDate(0, 0, -1).Format(DateLayout),
+		Timezone:   a.Timezone,
+		AssignedBy: a.AssignedBy.Hex(),
+	}
+}
+
+// covers does the workout include every exercise of the template
+func covers(w *Workout, t *WorkoutTemplate) bool {
+	for _, te := range t.Exercises {
+		found := false
+		for _, we := range w.Exercises {
+			found = found || we.ExerciseID == te.ExerciseID
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchSessions match the logged workout sessions to the scheduled sessions,
+// returning for each scheduled session the index of the workout matched to
+// it, or -1. A workout matches a session scheduled on its local date when it
+// was logged from the template of the session or includes every exercise of
+// the template. Workouts logged from the template are matched first, each
+// workout is matched to at most one session.
+func MatchSessions(schedule []ScheduledSession, workouts []Workout,
+	templates map[primitive.ObjectID]*WorkoutTemplate, loc *time.Location) []int {
+	matches := make([]int, len(schedule))
+	used := make([]bool, len(workouts))
+	dates := make([]string, len(workouts))
+	for i := range workouts {
+		dates[i] = workouts[i].Start.In(loc).Format(DateLayout)
+	}
+	match := func(i int, accept func(w *Workout, s *ScheduledSession) bool) {
+		for j := range workouts {
+			if !used[j] && dates[j] == schedule[i].Date && accept(&workouts[j], &schedule[i]) {
+				used[j] = true
+				matches[i] = j
+				return
+			}
+		}
+	}
+	for i := range schedule {
+		matches[i] = -1
+		match(i, func(w *Workout, s *ScheduledSession) bool { return w.TemplateID == s.TemplateID })
+	}
+	for i := range schedule {
+		t, ok := templates[schedule[i].TemplateID]
+		if matches[i] >= 0 || !ok {
+			continue
+		}
+		match(i, func(w *Workout, s *ScheduledSession) bool { return covers(w, t) })
+	}
+	return matches
+}
+
+// ComputeAdherence how closely the schedule was followed as of the local date
+// today, matches are the workouts matched to each scheduled session
+func ComputeAdherence(schedule []ScheduledSession, matches []int, today string) client.Adherence {
+	adherence := client.Adherence{Scheduled: len(schedule)}
+	for i, s := range schedule {
+		completed := matches[i] >= 0
+		if s.Date < today || (s.Date == today && completed) {
+			adherence.Due++
+			if completed {
+				adherence.Completed++
+			}
+		}
+	}
+	if adherence.Due > 0 {
+		adherence.Percent = round(float64(adherence.Completed)/float64(adherence.Due)*100, 1)
+	}
+	return adherence
+}
@@ -0,0 +1,363 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PlansCollection name of the collection used to hold the training plans
+const PlansCollection = "plans"
+
+// AssignmentsCollection name of the collection used to hold the training
+// plans assigned to users
+const AssignmentsCollection = "assignments"
+
+// PlanService holds a entry to the collections of training plans and their
+// assignment to users in the database
+type PlanService struct {
+	Collection  *mongo.Collection
+	assignments *mongo.Collection
+	templates   *mongo.Collection
+	logs        *mongo.Collection
+}
+
+// NewPlanService create a new instance of the Plan Service
+func NewPlanService(database *mongo.Database, opts ...ServiceOption) (*PlanService, error) {
+	return &PlanService{
+		Collection:  database.Collection(CollectionName(PlansCollection, opts...)),
+		assignments: database.Collection(CollectionName(AssignmentsCollection, opts...)),
+		templates:   database.Collection(CollectionName(TemplatesCollection, opts...)),
+		logs:        database.Collection(CollectionName(LogsCollection, opts...)),
+	}, nil
+}
+
+// planProgress the sessions scheduled by an assignment along with the
+// workouts logged during the assignment and the workout matched to each
+// scheduled session
+type planProgress struct {
+	schedule  []ScheduledSession
+	matches   []int
+	workouts  []Workout
+	templates map[primitive.ObjectID]*WorkoutTemplate
+}
+
+// Create add a new training plan authored by the user to the database. The
+// templates of the scheduled sessions must be templates of the author.
+func (s *PlanService) Create(ctx context.Context, authorID string, p *client.Plan) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(authorID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", authorID)}
+	}
+	plan, err := NewPlan(uid, p)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range plan.templates() {
+		cnt, err := s.templates.CountDocuments(ctx, bson.M{"_id": id, "user_id": uid})
+		if err != nil {
+			return "", err
+		}
+		if cnt == 0 {
+			return "", &ValidationError{fmt.Sprintf("template '%s' does not exist", id.Hex())}
+		}
+	}
+	if _, err = s.Collection.InsertOne(ctx, plan); err != nil {
+		err = fmt.Errorf("Unable to store plan in database, %s", err)
+		log.Error(err)
+		return "", err
+	}
+	return plan.ID.Hex(), nil
+}
+
+// find retrieve the training plan with the specified id
+func (s *PlanService) find(ctx context.Context, id primitive.ObjectID) (*Plan, error) {
+	var plan Plan
+	err := s.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&plan)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{fmt.Sprintf("plan '%s' not found", id.Hex())}
+	}
+	if err != nil {
+		log.Errorf("failed to retrieve plan %s, %s", id.Hex(), err)
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetByID retrieve the training plan with the specified id
+func (s *PlanService) GetByID(ctx context.Context, id string) (*client.Plan, error) {
+	pid, err := objectID(id, "plan")
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.find(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	cPlan := plan.Convert()
+	return &cPlan, nil
+}
+
+// GetAll retrieve all training plans ordered by name
+func (s *PlanService) GetAll(ctx context.Context) ([]*client.Plan, error) {
+	cursor, err := s.Collection.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetCollation(caseInsensitive))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []*client.Plan{}
+	for cursor.Next(ctx) {
+		var elem Plan
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode plan %s", err)
+			return nil, err
+		}
+		plan := elem.Convert()
+		results = append(results, &plan)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Delete remove the training plan with the specified id. A plan that is
+// assigned to users can not be deleted.
+func (s *PlanService) Delete(ctx context.Context, id string) error {
+	pid, err := objectID(id, "plan")
+	if err != nil {
+		return err
+	}
+	cnt, err := s.assignments.CountDocuments(ctx, bson.M{"plan_id": pid})
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return &ConflictError{fmt.Sprintf("plan '%s' is assigned to %d users", id, cnt)}
+	}
+	result, err := s.Collection.DeleteOne(ctx, bson.M{"_id": pid})
+	if err != nil {
+		err = fmt.Errorf("failed to delete plan %s, %s", id, err)
+		log.Error(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("plan '%s' not found", id)}
+	}
+	return nil
+}
+
+// Assign the training plan to the user starting on the date of the
+// assignment, returning the id of the assignment
+func (s *PlanService) Assign(ctx context.Context, userID string, assignedBy string,
+	a *client.PlanAssignment) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	by, err := primitive.ObjectIDFromHex(assignedBy)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", assignedBy)}
+	}
+	pid, err := primitive.ObjectIDFromHex(a.PlanID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid plan id '%s'", a.PlanID)}
+	}
+	plan, err := s.find(ctx, pid)
+	if IsNotFound(err) {
+		return "", &ValidationError{fmt.Sprintf("plan '%s' does not exist", a.PlanID)}
+	}
+	if err != nil {
+		return "", err
+	}
+	assignment, err := NewAssignment(uid, by, plan, a)
+	if err != nil {
+		return "", err
+	}
+	if _, err = s.assignments.InsertOne(ctx, assignment); err != nil {
+		err = fmt.Errorf("Unable to store assignment in database, %s", err)
+		log.Error(err)
+		return "", err
+	}
+	return assignment.ID.Hex(), nil
+}
+
+// progress match the workouts the user logged during the assignment to the
+// sessions scheduled by the plan
+func (s *PlanService) progress(ctx context.Context, a *Assignment, plan *Plan) (*planProgress, error) {
+	p := planProgress{
+		schedule:  plan.Schedule(a.Start.In(a.location())),
+		workouts:  []Workout{},
+		templates: map[primitive.ObjectID]*WorkoutTemplate{},
+	}
+	cursor, err := s.logs.Find(ctx, bson.M{"user_id": a.UserID, "start": bson.M{"$gte": a.Start, "$lt": a.End}},
+		options.Find().SetSort(bson.D{{Key: "start", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &p.workouts); err != nil {
+		return nil, err
+	}
+	cursor, err = s.templates.Find(ctx, bson.M{"_id": bson.M{"$in": plan.templates()}})
+	if err != nil {
+		return nil, err
+	}
+	templates := []WorkoutTemplate{}
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		p.templates[templates[i].ID] = &templates[i]
+	}
+	p.matches = MatchSessions(p.schedule, p.workouts, p.templates, a.location())
+	return &p, nil
+}
+
+// assignmentsOf retrieve the assignments of the user along with the plans
+// assigned, the most recently started first
+func (s *PlanService) assignmentsOf(ctx context.Context, userID string) ([]Assignment, map[primitive.ObjectID]*Plan, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, nil, err
+	}
+	cursor, err := s.assignments.Find(ctx, bson.M{"user_id": uid},
+		options.Find().SetSort(bson.D{{Key: "start", Value: -1}}))
+	if err != nil {
+		return nil, nil, err
+	}
+	assignments := []Assignment{}
+	if err = cursor.All(ctx, &assignments); err != nil {
+		return nil, nil, err
+	}
+	plans := map[primitive.ObjectID]*Plan{}
+	for _, a := range assignments {
+		if _, ok := plans[a.PlanID]; ok {
+			continue
+		}
+		if plans[a.PlanID], err = s.find(ctx, a.PlanID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return assignments, plans, nil
+}
+
+// GetAssignments retrieve the training plans assigned to the user, the most
+// recently started first, along with how closely the user has followed them
+func (s *PlanService) GetAssignments(ctx context.Context, userID string) ([]*client.PlanAssignment, error) {
+	assignments, plans, err := s.assignmentsOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	results := []*client.PlanAssignment{}
+	for i := range assignments {
+		a := &assignments[i]
+		plan := plans[a.PlanID]
+		p, err := s.progress(ctx, a, plan)
+		if err != nil {
+			return nil, err
+		}
+		adherence := ComputeAdherence(p.schedule, p.matches, now.In(a.location()).Format(DateLayout))
+		assignment := a.Convert(plan)
+		assignment.Adherence = &adherence
+		results = append(results, &assignment)
+	}
+	return results, nil
+}
+
+// Schedule retrieve the workout sessions the plans assigned to the user
+// schedule on the local date, YYYY-MM-DD. If no date is specified the
+// current date, in the time zone of each assignment, is used.
+func (s *PlanService) Schedule(ctx context.Context, userID string, date string) ([]*client.ScheduledWorkout, error) {
+	if len(date) > 0 {
+		if _, err := time.Parse(DateLayout, date); err != nil {
+			return nil, &ValidationError{fmt.Sprintf("date '%s' must be of the form %s", date, DateLayout)}
+		}
+	}
+	assignments, plans, err := s.assignmentsOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	results := []*client.ScheduledWorkout{}
+	for i := range assignments {
+		a := &assignments[i]
+		day := date
+		if len(day) == 0 {
+			day = now.In(a.location()).Format(DateLayout)
+		}
+		plan := plans[a.PlanID]
+		p, err := s.progress(ctx, a, plan)
+		if err != nil {
+			return nil, err
+		}
+		for j, session := range p.schedule {
+			if session.Date != day {
+				continue
+			}
+			scheduled := client.ScheduledWorkout{
+				AssignmentID: a.ID.Hex(),
+				PlanID:       plan.ID.Hex(),
+				PlanName:     plan.Name,
+				Week:         session.Week,
+				Day:          session.Day,
+				Date:         session.Date,
+				Template:     client.WorkoutTemplate{ID: session.TemplateID.Hex(), Exercises: []client.WorkoutExercise{}},
+			}
+			if t, ok := p.templates[session.TemplateID]; ok {
+				scheduled.Template = t.Convert()
+			}
+			if k := p.matches[j]; k >= 0 {
+				scheduled.Completed = true
+				scheduled.WorkoutID = p.workouts[k].ID.Hex()
+			}
+			results = append(results, &scheduled)
+		}
+	}
+	return results, nil
+}
+
+// Unassign remove the assignment of a training plan to the user
+func (s *PlanService) Unassign(ctx context.Context, userID string, id string) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	aid, err := objectID(id, "assignment")
+	if err != nil {
+		return err
+	}
+	result, err := s.assignments.DeleteOne(ctx, bson.M{"_id": aid, "user_id": uid})
+	if err != nil {
+		err = fmt.Errorf("failed to delete assignment %s, %s", id, err)
+		log.Error(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("assignment '%s' not found", id)}
+	}
+	return nil
+}
+
+// DeleteByUser remove all training plan assignments of the user, returning
+// the number of assignments deleted
+func (s *PlanService) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return 0, err
+	}
+	result, err := s.assignments.DeleteMany(ctx, bson.M{"user_id": uid})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
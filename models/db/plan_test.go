@@ -0,0 +1,160 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testPlanTemplateID = "5de2a6c1e3a1f2b4c8d9e0b3"
+
+func TestNewPlan(t *testing.T) {
+	author := primitive.NewObjectID()
+	plan, err := db.NewPlan(author, &client.Plan{Name: " Couch to 5k ", Weeks: []client.PlanWeek{
+		client.PlanWeek{Sessions: []client.PlanSession{
+			client.PlanSession{Day: 5, TemplateID: testPlanTemplateID},
+			client.PlanSession{Day: 1, TemplateID: testPlanTemplateID},
+		}},
+		client.PlanWeek{},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Couch to 5k", plan.Name)
+	if assert.Equal(t, 2, len(plan.Weeks)) && assert.Equal(t, 2, len(plan.Weeks[0].Sessions)) {
+		assert.Equal(t, 1, plan.Weeks[0].Sessions[0].Day)
+	}
+
+	session := client.PlanSession{Day: 1, TemplateID: testPlanTemplateID}
+	tests := []client.Plan{
+		client.Plan{Weeks: []client.PlanWeek{client.PlanWeek{Sessions: []client.PlanSession{session}}}},
+		client.Plan{Name: "No weeks"},
+		client.Plan{Name: "No sessions", Weeks: []client.PlanWeek{client.PlanWeek{}}},
+		client.Plan{Name: "Bad day", Weeks: []client.PlanWeek{client.PlanWeek{Sessions: []client.PlanSession{
+			client.PlanSession{Day: 8, TemplateID: testPlanTemplateID}}}}},
+		client.Plan{Name: "Bad template", Weeks: []client.PlanWeek{client.PlanWeek{Sessions: []client.PlanSession{
+			client.PlanSession{Day: 1, TemplateID: "bad"}}}}},
+		client.Plan{Name: "Too long", Weeks: make([]client.PlanWeek, db.MaxPlanWeeks+1)},
+	}
+	for _, test := range tests {
+		_, err := db.NewPlan(author, &test)
+		assert.True(t, db.IsValidation(err), "plan '%s' expected validation error", test.Name)
+	}
+}
+
+func TestPlanAdherence(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Los_Angeles")
+	squat, _ := primitive.ObjectIDFromHex(testSquatID)
+	running, _ := primitive.ObjectIDFromHex(testRunningID)
+	legs := db.WorkoutTemplate{ID: primitive.NewObjectID(),
+		Exercises: []db.WorkoutExercise{db.WorkoutExercise{ExerciseID: squat}}}
+	run := db.WorkoutTemplate{ID: primitive.NewObjectID(),
+		Exercises: []db.WorkoutExercise{db.WorkoutExercise{ExerciseID: running}}}
+	templates := map[primitive.ObjectID]*db.WorkoutTemplate{legs.ID: &legs, run.ID: &run}
+	plan := db.Plan{Weeks: []db.PlanWeek{
+		db.PlanWeek{Sessions: []db.PlanSession{
+			db.PlanSession{Day: 1, TemplateID: legs.ID},
+			db.PlanSession{Day: 1, TemplateID: run.ID},
+			db.PlanSession{Day: 3, TemplateID: legs.ID},
+		}},
+		db.PlanWeek{Sessions: []db.PlanSession{db.PlanSession{Day: 2, TemplateID: run.ID}}},
+	}}
+	schedule := plan.Schedule(time.Date(2019, 12, 30, 0, 0, 0, 0, loc))
+	if assert.Equal(t, 4, len(schedule)) {
+		assert.Equal(t, "2019-12-30", schedule[0].Date)
+		assert.Equal(t, "2020-01-01", schedule[2].Date)
+		assert.Equal(t, 2, schedule[3].Week)
+		assert.Equal(t, "2020-01-07", schedule[3].Date)
+	}
+
+	workouts := []db.Workout{
+		// A run on the evening of the first day, the next day in UTC, matched by exercise
+		db.Workout{Start: time.Date(2019, 12, 31, 3, 0, 0, 0, time.UTC),
+			Exercises: []db.WorkoutExercise{db.WorkoutExercise{ExerciseID: running}}},
+		// Logged from the legs template on the first day
+		db.Workout{Start: time.Date(2019, 12, 30, 17, 0, 0, 0, time.UTC), TemplateID: legs.ID},
+		// Legs logged a day late do not match
+		db.Workout{Start: time.Date(2020, 1, 2, 17, 0, 0, 0, time.UTC),
+			Exercises: []db.WorkoutExercise{db.WorkoutExercise{ExerciseID: squat}}},
+	}
+	matches := db.MatchSessions(schedule, workouts, templates, loc)
+	assert.Equal(t, []int{1, 0, -1, -1}, matches)
+
+	adherence := db.ComputeAdherence(schedule, matches, "2019-12-30")
+	assert.Equal(t, client.Adherence{Scheduled: 4, Due: 2, Completed: 2, Percent: 100}, adherence)
+	adherence = db.ComputeAdherence(schedule, matches, "2020-01-07")
+	assert.Equal(t, client.Adherence{Scheduled: 4, Due: 3, Completed: 2, Percent: 66.7}, adherence)
+	adherence = db.ComputeAdherence(schedule, matches, "2019-12-01")
+	assert.Equal(t, client.Adherence{Scheduled: 4}, adherence)
+}
+
+func TestPlans(t *testing.T) {
+	workouts, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, workouts, ex)
+	database := workouts.Collection.Database()
+	service, err := db.NewPlanService(database)
+	assert.NoError(t, err)
+	templates, err := db.NewTemplateService(database)
+	assert.NoError(t, err)
+	ctx := context.TODO()
+	for _, name := range []string{db.PlansCollection, db.AssignmentsCollection, db.TemplatesCollection} {
+		assert.NoError(t, database.Collection(name).Drop(ctx))
+		defer database.Collection(name).Drop(ctx)
+	}
+
+	author := primitive.NewObjectID().Hex()
+	templateID, err := templates.Create(ctx, author, &client.WorkoutTemplate{Name: "Legs",
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: testSquatID}}})
+	assert.NoError(t, err)
+	plan := client.Plan{Name: "Strength", Weeks: []client.PlanWeek{client.PlanWeek{Sessions: []client.PlanSession{
+		client.PlanSession{Day: 1, TemplateID: templateID}, client.PlanSession{Day: 4, TemplateID: templateID}}}}}
+
+	// Only the templates of the author can be scheduled
+	_, err = service.Create(ctx, primitive.NewObjectID().Hex(), &plan)
+	assert.True(t, db.IsValidation(err))
+	planID, err := service.Create(ctx, author, &plan)
+	assert.NoError(t, err)
+
+	today := time.Now().UTC()
+	assignmentID, err := service.Assign(ctx, testWorkoutUserID, author,
+		&client.PlanAssignment{PlanID: planID, Start: today.Format(db.DateLayout)})
+	assert.NoError(t, err)
+	_, err = service.Assign(ctx, testWorkoutUserID, author, &client.PlanAssignment{PlanID: planID, Start: "today"})
+	assert.True(t, db.IsValidation(err))
+
+	schedule, err := service.Schedule(ctx, testWorkoutUserID, "")
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(schedule)) {
+		assert.Equal(t, "Legs", schedule[0].Template.Name)
+		assert.False(t, schedule[0].Completed)
+	}
+
+	// A session including the exercises of the template completes the scheduled session
+	workoutID, err := workouts.Create(ctx, testWorkoutUserID, &client.Workout{Start: today,
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: testSquatID}}})
+	assert.NoError(t, err)
+	schedule, err = service.Schedule(ctx, testWorkoutUserID, today.Format(db.DateLayout))
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(schedule)) {
+		assert.True(t, schedule[0].Completed)
+		assert.Equal(t, workoutID, schedule[0].WorkoutID)
+	}
+
+	assignments, err := service.GetAssignments(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(assignments)) {
+		assert.Equal(t, "Strength", assignments[0].PlanName)
+		assert.Equal(t, client.Adherence{Scheduled: 2, Due: 1, Completed: 1, Percent: 100}, *assignments[0].Adherence)
+	}
+
+	// Plans that are assigned, and templates they use, can not be deleted
+	assert.True(t, db.IsConflict(service.Delete(ctx, planID)))
+	assert.True(t, db.IsConflict(templates.Delete(ctx, author, templateID)))
+	assert.NoError(t, service.Unassign(ctx, testWorkoutUserID, assignmentID))
+	assert.NoError(t, service.Delete(ctx, planID))
+	_, err = service.GetByID(ctx, planID)
+	assert.True(t, db.IsNotFound(err))
+}
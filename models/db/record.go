@@ -0,0 +1,363 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RecordsCollection name of the collection used to hold the personal records of users
+const RecordsCollection = "records"
+
+// The kinds of personal records detected
+const (
+	RecordEpley           = "1rm-epley"
+	RecordBrzycki         = "1rm-brzycki"
+	RecordMaxLoad         = "max-load"
+	RecordFastest5K       = "fastest-5k"
+	RecordFastest10K      = "fastest-10k"
+	RecordLongestDuration = "longest-duration"
+)
+
+// MaxEstimateReps the most reps of a set used to estimate a one rep max,
+// estimates from sets with more reps are unreliable
+const MaxEstimateReps = 10
+
+// illegalOperation the error code returned by a standalone server for
+// commands, such as transactions, that require a replica set
+const illegalOperation = 20
+
+// PersonalRecord an improvement on the best performance of a user for an
+// exercise. Each improvement is stored, the most recent for a kind of
+// record is the current record.
+type PersonalRecord struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ExerciseID primitive.ObjectID `bson:"exercise_id" json:"exercise_id"`
+	Kind       string             `bson:"kind" json:"kind"`
+	Reps       int                `bson:"reps,omitempty" json:"reps,omitempty"`
+	Value      float64            `bson:"value" json:"value"`
+	Previous   float64            `bson:"previous,omitempty" json:"previous,omitempty"`
+	WorkoutID  primitive.ObjectID `bson:"workout_id" json:"workout_id"`
+	Achieved   time.Time          `bson:"achieved" json:"achieved"`
+	Current    bool               `bson:"current" json:"current"`
+}
+
+// Convert the database representation of a personal record to the client representation
+func (r *PersonalRecord) Convert() client.PersonalRecord {
+	unit := UnitKilogram
+	if r.Kind == RecordFastest5K || r.Kind == RecordFastest10K || r.Kind == RecordLongestDuration {
+		unit = "s"
+	}
+	return client.PersonalRecord{
+		ExerciseID: r.ExerciseID.Hex(),
+		Kind:       r.Kind,
+		Reps:       r.Reps,
+		Value:      r.Value,
+		Unit:       unit,
+		Previous:   r.Previous,
+		WorkoutID:  r.WorkoutID.Hex(),
+		Achieved:   r.Achieved,
+		Current:    r.Current,
+	}
+}
+
+// Epley estimate the one rep max of a set using the Epley formula
+func Epley(loadKg float64, reps int) float64 {
+	if reps == 1 {
+		return loadKg
+	}
+	return round(loadKg*(1+float64(reps)/30), 2)
+}
+
+// Brzycki estimate the one rep max of a set using the Brzycki formula
+func Brzycki(loadKg float64, reps int) float64 {
+	return round(loadKg*36/(37-float64(reps)), 2)
+}
+
+// recordKey identifies a kind of record, max load records are kept for each number of reps
+type recordKey struct {
+	kind string
+	reps int
+}
+
+// lowerIsBetter whether a smaller value beats a record of this kind
+func (k recordKey) lowerIsBetter() bool {
+	return k.kind == RecordFastest5K || k.kind == RecordFastest10K
+}
+
+// better whether a value beats the current value of a record
+func (k recordKey) better(value float64, current float64) bool {
+	if k.lowerIsBetter() {
+		return value < current
+	}
+	return value > current
+}
+
+// candidates the best value of each kind of record achieved by an exercise entry
+func candidates(e *WorkoutExercise, best map[recordKey]float64) {
+	offer := func(k recordKey, value float64) {
+		if v, ok := best[k]; !ok || k.better(value, v) {
+			best[k] = value
+		}
+	}
+	for _, set := range e.Sets {
+		if set.Reps <= 0 || set.LoadKg <= 0 {
+			continue
+		}
+		offer(recordKey{kind: RecordMaxLoad, reps: set.Reps}, set.LoadKg)
+		if set.Reps <= MaxEstimateReps {
+			offer(recordKey{kind: RecordEpley}, Epley(set.LoadKg, set.Reps))
+			offer(recordKey{kind: RecordBrzycki}, Brzycki(set.LoadKg, set.Reps))
+		}
+	}
+	if e.Cardio == nil {
+		return
+	}
+	if e.Cardio.DurationSeconds > 0 {
+		offer(recordKey{kind: RecordLongestDuration}, float64(e.Cardio.DurationSeconds))
+	}
+	// The session, or any lap, covering the distance at its average pace
+	segments := append([]CardioMetrics{*e.Cardio}, e.Cardio.Laps...)
+	for _, c := range segments {
+		if c.DurationSeconds <= 0 {
+			continue
+		}
+		if c.DistanceM >= 5000 {
+			offer(recordKey{kind: RecordFastest5K}, round(float64(c.DurationSeconds)*5000/c.DistanceM, 1))
+		}
+		if c.DistanceM >= 10000 {
+			offer(recordKey{kind: RecordFastest10K}, round(float64(c.DurationSeconds)*10000/c.DistanceM, 1))
+		}
+	}
+}
+
+// DetectRecords the personal records of the user for an exercise achieved by
+// the workout sessions. Sessions are considered in the order they were
+// started, each session that beats the best value so far of a kind of record
+// sets a new record. The records are returned in the order achieved.
+func DetectRecords(userID primitive.ObjectID, exerciseID primitive.ObjectID,
+	workouts []Workout) []PersonalRecord {
+	sorted := make([]*Workout, len(workouts))
+	for i := range workouts {
+		sorted[i] = &workouts[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	records := []PersonalRecord{}
+	current := map[recordKey]int{}
+	for _, w := range sorted {
+		best := map[recordKey]float64{}
+		for i := range w.Exercises {
+			if w.Exercises[i].ExerciseID == exerciseID {
+				candidates(&w.Exercises[i], best)
+			}
+		}
+		keys := make([]recordKey, 0, len(best))
+		for k := range best {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].kind != keys[j].kind {
+				return keys[i].kind < keys[j].kind
+			}
+			return keys[i].reps < keys[j].reps
+		})
+		for _, k := range keys {
+			r := PersonalRecord{
+				UserID:     userID,
+				ExerciseID: exerciseID,
+				Kind:       k.kind,
+				Reps:       k.reps,
+				Value:      best[k],
+				WorkoutID:  w.ID,
+				Achieved:   w.Start,
+				Current:    true,
+			}
+			if i, ok := current[k]; ok {
+				if !k.better(r.Value, records[i].Value) {
+					continue
+				}
+				records[i].Current = false
+				r.Previous = records[i].Value
+			}
+			current[k] = len(records)
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// transaction run fn within a transaction. Transactions are only supported by
+// replica sets, when connected to a standalone server fn is run without one.
+func (s *WorkoutService) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := s.Collection.Database().Client().UseSession(ctx, func(sc mongo.SessionContext) error {
+		_, err := sc.WithTransaction(sc, func(tc mongo.SessionContext) (interface{}, error) {
+			return nil, fn(tc)
+		})
+		return err
+	})
+	if ce, ok := err.(mongo.CommandError); ok && ce.Code == illegalOperation {
+		log.Debugf("transactions not supported, %s", err)
+		return fn(ctx)
+	}
+	return err
+}
+
+// affectedExercises the exercises whose records may change when a workout
+// session is modified, those of the entries of the session and any that
+// hold a record achieved by the session
+func (s *WorkoutService) affectedExercises(ctx context.Context, workoutID primitive.ObjectID,
+	entries []WorkoutExercise) ([]primitive.ObjectID, error) {
+	ids, err := s.records.Distinct(ctx, "exercise_id", bson.M{"workout_id": workoutID})
+	if err != nil {
+		return nil, err
+	}
+	seen := map[primitive.ObjectID]bool{}
+	results := []primitive.ObjectID{}
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok && !seen[oid] {
+			seen[oid] = true
+			results = append(results, oid)
+		}
+	}
+	for _, e := range entries {
+		if !seen[e.ExerciseID] {
+			seen[e.ExerciseID] = true
+			results = append(results, e.ExerciseID)
+		}
+	}
+	return results, nil
+}
+
+// updateRecords recompute the personal records of the user for the exercises
+// from all of the workout sessions of the user that include them
+func (s *WorkoutService) updateRecords(ctx context.Context, userID primitive.ObjectID,
+	exerciseIDs ...primitive.ObjectID) error {
+	for _, eid := range exerciseIDs {
+		cursor, err := s.Collection.Find(ctx, bson.M{"user_id": userID, "exercises.exercise_id": eid},
+			options.Find().SetSort(bson.D{{Key: "start", Value: 1}, {Key: "_id", Value: 1}}))
+		if err != nil {
+			return err
+		}
+		workouts := []Workout{}
+		err = cursor.All(ctx, &workouts)
+		if err != nil {
+			return err
+		}
+		if _, err = s.records.DeleteMany(ctx, bson.M{"user_id": userID, "exercise_id": eid}); err != nil {
+			return err
+		}
+		records := DetectRecords(userID, eid, workouts)
+		if len(records) == 0 {
+			continue
+		}
+		docs := make([]interface{}, len(records))
+		for i := range records {
+			records[i].ID = primitive.NewObjectID()
+			docs[i] = &records[i]
+		}
+		if _, err = s.records.InsertMany(ctx, docs); err != nil {
+			err = fmt.Errorf("Unable to store personal records in database, %s", err)
+			log.Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildRecords recompute the personal records of every user from their
+// workout sessions, returning the number of records stored
+func (s *WorkoutService) RebuildRecords(ctx context.Context) (int, error) {
+	if _, err := s.records.DeleteMany(ctx, bson.M{}); err != nil {
+		return 0, err
+	}
+	cursor, err := s.Collection.Aggregate(ctx, bson.A{
+		bson.M{"$unwind": "$exercises"},
+		bson.M{"$group": bson.M{"_id": bson.M{"user": "$user_id", "exercise": "$exercises.exercise_id"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var pairs []struct {
+		ID struct {
+			User     primitive.ObjectID `bson:"user"`
+			Exercise primitive.ObjectID `bson:"exercise"`
+		} `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &pairs); err != nil {
+		return 0, err
+	}
+	for _, p := range pairs {
+		if err = s.updateRecords(ctx, p.ID.User, p.ID.Exercise); err != nil {
+			return 0, err
+		}
+	}
+	cnt, err := s.records.CountDocuments(ctx, bson.M{})
+	return int(cnt), err
+}
+
+// findRecords retrieve the personal records of the user matching the filter
+func (s *WorkoutService) findRecords(ctx context.Context, userID string, exerciseID string,
+	filter bson.M, sortBy bson.D) ([]client.PersonalRecord, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	filter["user_id"] = uid
+	if len(exerciseID) > 0 {
+		eid, err := objectID(exerciseID, "exercise")
+		if err != nil {
+			return nil, err
+		}
+		filter["exercise_id"] = eid
+	}
+	cursor, err := s.records.Find(ctx, filter, options.Find().SetSort(sortBy))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []client.PersonalRecord{}
+	for cursor.Next(ctx) {
+		var elem PersonalRecord
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode personal record %s", err)
+			return nil, err
+		}
+		results = append(results, elem.Convert())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetRecords retrieve the current personal records of the user. If an
+// exercise is specified only the records for that exercise are returned.
+func (s *WorkoutService) GetRecords(ctx context.Context, userID string,
+	exerciseID string) ([]client.PersonalRecord, error) {
+	return s.findRecords(ctx, userID, exerciseID, bson.M{"current": true},
+		bson.D{{Key: "exercise_id", Value: 1}, {Key: "kind", Value: 1}, {Key: "reps", Value: 1}})
+}
+
+// GetRecordHistory retrieve every personal record set by the user, in the
+// order they were achieved. The records can be restricted to an exercise
+// and a kind of record.
+func (s *WorkoutService) GetRecordHistory(ctx context.Context, userID string, exerciseID string,
+	kind string) ([]client.PersonalRecord, error) {
+	filter := bson.M{}
+	if len(kind) > 0 {
+		filter["kind"] = kind
+	}
+	return s.findRecords(ctx, userID, exerciseID, filter,
+		bson.D{{Key: "achieved", Value: 1}, {Key: "kind", Value: 1}, {Key: "reps", Value: 1}})
+}
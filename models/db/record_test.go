@@ -0,0 +1,150 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOneRepMax(t *testing.T) {
+	assert.Equal(t, 100.0, db.Epley(100, 1))
+	assert.Equal(t, 116.67, db.Epley(100, 5))
+	assert.Equal(t, 100.0, db.Brzycki(100, 1))
+	assert.Equal(t, 112.5, db.Brzycki(100, 5))
+}
+
+// recordWorkout helper function that builds a workout session for the record tests
+func recordWorkout(t *testing.T, start time.Time, exercises ...client.WorkoutExercise) db.Workout {
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	w, err := db.NewWorkout(uid, &client.Workout{Start: start, Exercises: exercises})
+	assert.NoError(t, err)
+	return *w
+}
+
+func TestDetectRecords(t *testing.T) {
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	squat, _ := primitive.ObjectIDFromHex(testSquatID)
+	running, _ := primitive.ObjectIDFromHex(testRunningID)
+	day := time.Date(2019, 11, 18, 18, 0, 0, 0, time.UTC)
+	sets := func(reps int, load float64) client.WorkoutExercise {
+		return client.WorkoutExercise{ExerciseID: testSquatID,
+			Sets: []client.StrengthSet{client.StrengthSet{Reps: reps, Load: load, Unit: db.UnitKilogram}}}
+	}
+	run := func(seconds int, km float64) client.WorkoutExercise {
+		return client.WorkoutExercise{ExerciseID: testRunningID,
+			Cardio: &client.CardioMetrics{DurationSeconds: seconds, Distance: km, Unit: db.UnitKilometer}}
+	}
+
+	// Given out of order, the later heavier session sets a new record
+	workouts := []db.Workout{
+		recordWorkout(t, day.AddDate(0, 0, 7), sets(5, 105), run(1500, 5)),
+		recordWorkout(t, day, sets(5, 100), sets(3, 110)),
+		recordWorkout(t, day.AddDate(0, 0, 3), sets(5, 95)),
+	}
+	records := db.DetectRecords(uid, squat, workouts)
+	current := map[string]db.PersonalRecord{}
+	history := 0
+	for _, r := range records {
+		assert.Equal(t, squat, r.ExerciseID)
+		if r.Kind == db.RecordMaxLoad && r.Reps == 5 {
+			history++
+		}
+		if r.Current {
+			current[fmt.Sprintf("%s%d", r.Kind, r.Reps)] = r
+		}
+	}
+	assert.Equal(t, 2, history)
+	assert.Equal(t, 105.0, current["max-load5"].Value)
+	assert.Equal(t, 100.0, current["max-load5"].Previous)
+	assert.Equal(t, workouts[0].ID, current["max-load5"].WorkoutID)
+	assert.Equal(t, 110.0, current["max-load3"].Value)
+	assert.Equal(t, 122.5, current[db.RecordEpley+"0"].Value)
+	assert.Equal(t, day.AddDate(0, 0, 7), current[db.RecordEpley+"0"].Achieved)
+	assert.Equal(t, 118.13, current[db.RecordBrzycki+"0"].Value)
+
+	// The fastest 5k uses the average pace of a longer run
+	workouts = append(workouts, recordWorkout(t, day.AddDate(0, 0, 10), run(2700, 10)))
+	records = db.DetectRecords(uid, running, workouts)
+	for _, r := range records {
+		if r.Current && r.Kind == db.RecordFastest5K {
+			assert.Equal(t, 1350.0, r.Value)
+			assert.Equal(t, 1500.0, r.Previous)
+		}
+		if r.Current && r.Kind == db.RecordLongestDuration {
+			assert.Equal(t, 2700.0, r.Value)
+		}
+		if r.Kind == db.RecordFastest10K {
+			assert.Equal(t, 2700.0, r.Value)
+		}
+	}
+	assert.Equal(t, 5, len(records))
+}
+
+func TestWorkoutRecords(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	first, err := service.Create(ctx, testWorkoutUserID, &client.Workout{
+		Start: time.Date(2019, 11, 18, 18, 0, 0, 0, time.UTC),
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: testSquatID,
+			Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 100, Unit: db.UnitKilogram}}}},
+	})
+	assert.NoError(t, err)
+	second, err := service.Create(ctx, testWorkoutUserID, &client.Workout{
+		Start: time.Date(2019, 11, 20, 18, 0, 0, 0, time.UTC),
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: testSquatID,
+			Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 110, Unit: db.UnitKilogram}}}},
+	})
+	assert.NoError(t, err)
+
+	records, err := service.GetRecords(ctx, testWorkoutUserID, testSquatID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(records))
+	for _, r := range records {
+		assert.Equal(t, second, r.WorkoutID)
+	}
+	history, err := service.GetRecordHistory(ctx, testWorkoutUserID, testSquatID, db.RecordMaxLoad)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(history)) {
+		assert.Equal(t, 100.0, history[0].Value)
+		assert.Equal(t, 110.0, history[1].Value)
+		assert.Equal(t, 100.0, history[1].Previous)
+	}
+
+	// Editing the record setting set lowers the record
+	workout, err := service.GetByID(ctx, testWorkoutUserID, second)
+	assert.NoError(t, err)
+	entry := workout.Exercises[0]
+	err = service.UpdateSet(ctx, testWorkoutUserID, second, entry.ID, entry.Sets[0].ID,
+		&client.StrengthSet{Reps: 5, Load: 90, Unit: db.UnitKilogram})
+	assert.NoError(t, err)
+	history, err = service.GetRecordHistory(ctx, testWorkoutUserID, testSquatID, db.RecordMaxLoad)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(history)) {
+		assert.Equal(t, first, history[0].WorkoutID)
+	}
+
+	// Deleting the only session holding records removes them
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, first))
+	records, err = service.GetRecords(ctx, testWorkoutUserID, "")
+	assert.NoError(t, err)
+	if assert.Equal(t, 3, len(records)) {
+		assert.Equal(t, second, records[0].WorkoutID)
+	}
+
+	// Removing the exercise from the session removes its records
+	assert.NoError(t, service.RemoveExercise(ctx, testWorkoutUserID, second, entry.ID))
+	records, err = service.GetRecords(ctx, testWorkoutUserID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(records))
+
+	_, err = service.GetRecords(ctx, testWorkoutUserID, "unknown")
+	assert.True(t, db.IsNotFound(err))
+}
@@ -0,0 +1,404 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The default and largest number of search results returned in a page
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// MaxSearchLength the longest search text accepted
+const MaxSearchLength = 200
+
+// The weights of the fields of an exercise when ranking search results, the
+// same weights are used by the text index
+const (
+	NameWeight        = 10
+	DescriptionWeight = 1
+)
+
+// The relevance of a word of an exercise matching a search term
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.8
+	fuzzyMatch  = 0.6
+)
+
+// minPrefixLength the shortest search term matched as the prefix of a word
+const minPrefixLength = 3
+
+// stopWords common words ignored by the in memory search, as by the text index
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"the": true, "to": true, "with": true,
+}
+
+// SearchQuery a full text search of the exercises. Page, starting at 1, and
+// Limit select the page of results returned. The shared catalog is searched
+// along with the private exercises of UserID, if set. The results are
+// localized for Locales, most preferred first.
+type SearchQuery struct {
+	UserID  string
+	Locales []string
+	Text    string
+	Page    int
+	Limit   int
+
+	user primitive.ObjectID
+}
+
+// normalize apply the defaults for any unspecified fields of the query and
+// validate the result
+func (q *SearchQuery) normalize() error {
+	q.Text = strings.TrimSpace(q.Text)
+	if len(q.Text) == 0 {
+		return &ValidationError{"search text must be specified"}
+	}
+	if len(q.Text) > MaxSearchLength {
+		return &ValidationError{fmt.Sprintf("search text exceeds %d characters", MaxSearchLength)}
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	if q.Page < 1 {
+		return &ValidationError{"page must be 1 or more"}
+	}
+	if q.Limit < 1 || q.Limit > MaxSearchLimit {
+		return &ValidationError{fmt.Sprintf("limit must be between 1 and %d", MaxSearchLimit)}
+	}
+	if len(q.UserID) > 0 {
+		var err error
+		if q.user, err = primitive.ObjectIDFromHex(q.UserID); err != nil {
+			return &ValidationError{fmt.Sprintf("invalid user id '%s'", q.UserID)}
+		}
+	}
+	return nil
+}
+
+// page the results of the page of the query
+func (q *SearchQuery) page(matches []client.ExerciseMatch) []client.ExerciseMatch {
+	start := (q.Page - 1) * q.Limit
+	if start >= len(matches) {
+		return []client.ExerciseMatch{}
+	}
+	end := start + q.Limit
+	if end > len(matches) {
+		end = len(matches)
+	}
+	return matches[start:end]
+}
+
+// tokenize split the text into lower case words, dropping stop words and
+// the plural suffix of words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := []string{}
+	for _, w := range words {
+		if stopWords[w] {
+			continue
+		}
+		if len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") {
+			w = w[:len(w)-1]
+		}
+		tokens = append(tokens, w)
+	}
+	return tokens
+}
+
+// maxEdits the number of typos tolerated in a search term of its length
+func maxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance the Levenshtein distance between a and b, any distance
+// greater than max is reported as max + 1
+func editDistance(a string, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		lowest := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			lowest = minInt(lowest, curr[j])
+		}
+		if lowest > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// termMatch how well a word matches a search term, an exact match, the term
+// being a prefix of the word or the word being within the typos tolerated
+func termMatch(term string, word string) float64 {
+	switch {
+	case term == word:
+		return exactMatch
+	case len(term) >= minPrefixLength && strings.HasPrefix(word, term):
+		return prefixMatch
+	case editDistance(term, word, maxEdits(term)) <= maxEdits(term):
+		return fuzzyMatch
+	}
+	return 0
+}
+
+// fieldMatch the best match of the search term among the words of a field
+func fieldMatch(term string, words []string) float64 {
+	best := 0.0
+	for _, w := range words {
+		best = math.Max(best, termMatch(term, w))
+		if best == exactMatch {
+			break
+		}
+	}
+	return best
+}
+
+// SearchExercises rank the exercises matching the search in memory, most
+// relevant first. Each term of the search matches the words of the name, along
+// with the aliases, and description of an exercise, and of their translation
+// into the locales of the search, exactly, as a prefix or within a number of
+// typos that grows with the length of the term. The relevance of an exercise
+// is the sum over the terms of the weighted quality of the matches in each
+// field, scaled by the rarity of the term, multiplied by the fraction of the
+// terms matched. This is used by Search when neither the text index nor the
+// aliases and translations match whole words, and with backends that do not
+// support text search.
+func SearchExercises(exercises []Exercise, q SearchQuery) (*client.ExerciseSearch, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	result := client.ExerciseSearch{Query: q.Text, Page: q.Page, Limit: q.Limit, Fuzzy: true}
+	terms := tokenize(q.Text)
+	type fields struct{ name, description []string }
+	docs := make([]fields, len(exercises))
+	for i := range exercises {
+		name := strings.Join(append([]string{exercises[i].Name}, exercises[i].Aliases...), " ")
+		description := exercises[i].Description
+		if _, t := exercises[i].translation(q.Locales); t != nil {
+			name, description = t.Name+" "+name, t.Description+" "+description
+		}
+		docs[i] = fields{tokenize(name), tokenize(description)}
+	}
+	// The quality of the match of each term in each field of each exercise
+	names := make([][]float64, len(terms))
+	descriptions := make([][]float64, len(terms))
+	rarity := make([]float64, len(terms))
+	for t, term := range terms {
+		names[t] = make([]float64, len(docs))
+		descriptions[t] = make([]float64, len(docs))
+		matched := 0
+		for i, d := range docs {
+			names[t][i] = fieldMatch(term, d.name)
+			descriptions[t][i] = fieldMatch(term, d.description)
+			if names[t][i] > 0 || descriptions[t][i] > 0 {
+				matched++
+			}
+		}
+		rarity[t] = math.Log(1 + float64(len(docs))/float64(matched+1))
+	}
+	matches := []client.ExerciseMatch{}
+	for i := range exercises {
+		score := 0.0
+		covered := 0
+		for t := range terms {
+			s := NameWeight*names[t][i] + DescriptionWeight*descriptions[t][i]
+			if s > 0 {
+				covered++
+				score += s * rarity[t]
+			}
+		}
+		if covered == 0 {
+			continue
+		}
+		score *= float64(covered) / float64(len(terms))
+		matches = append(matches, client.ExerciseMatch{Exercise: exercises[i].Localize(q.Locales),
+			Score: round(score, 3)})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	result.Total = len(matches)
+	result.Results = q.page(matches)
+	return &result, nil
+}
+
+// scoredExercise an exercise matched by the text index along with its score
+type scoredExercise struct {
+	Exercise `bson:",inline"`
+	Score    float64 `bson:"score"`
+}
+
+// Search the exercises for the text, most relevant first. The text index is
+// searched first, matching whole words of the name and description, ignoring
+// their suffixes, ranked by the text score. The text index does not cover the
+// aliases and translations of exercises, those matching the words of the text
+// are merged into the results, see wordMatches. When neither finds a match,
+// such as when the text contains a typo or partial words, or the backend does
+// not support text search, the exercises are searched in memory, see
+// SearchExercises.
+func (s *ExerciseService) Search(ctx context.Context, q SearchQuery) (*client.ExerciseSearch, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	matches, err := s.textSearch(ctx, q)
+	if _, unsupported := err.(mongo.CommandError); unsupported {
+		log.Warnf("text search failed, searching in memory, %s", err)
+	} else if err != nil {
+		return nil, err
+	} else {
+		// Only the exercises with aliases or translations can add a match
+		exercises := []Exercise{}
+		cursor, err := s.Collection.Find(ctx, bson.M{"owner": visibleTo(q.user),
+			"$or": bson.A{bson.M{"aliases": bson.M{"$exists": true}},
+				bson.M{"translations": bson.M{"$exists": true}}}})
+		if err != nil {
+			return nil, err
+		}
+		if err = cursor.All(ctx, &exercises); err != nil {
+			return nil, err
+		}
+		if matches = mergeMatches(matches, wordMatches(exercises, q)); len(matches) > 0 {
+			return &client.ExerciseSearch{Query: q.Text, Total: len(matches), Page: q.Page, Limit: q.Limit,
+				Results: q.page(matches)}, nil
+		}
+	}
+	exercises := []Exercise{}
+	cursor, err := s.Collection.Find(ctx, bson.M{"owner": visibleTo(q.user)})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &exercises); err != nil {
+		return nil, err
+	}
+	return SearchExercises(exercises, q)
+}
+
+// textSearch search the exercises using the text index, returning every
+// match ranked by the text score
+func (s *ExerciseService) textSearch(ctx context.Context, q SearchQuery) ([]client.ExerciseMatch, error) {
+	filter := bson.M{"$text": bson.M{"$search": q.Text}, "owner": visibleTo(q.user)}
+	score := bson.M{"$meta": "textScore"}
+	cursor, err := s.Collection.Find(ctx, filter, options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	scored := []scoredExercise{}
+	if err = cursor.All(ctx, &scored); err != nil {
+		return nil, err
+	}
+	matches := []client.ExerciseMatch{}
+	for i := range scored {
+		matches = append(matches,
+			client.ExerciseMatch{Exercise: scored[i].Localize(q.Locales), Score: round(scored[i].Score, 3)})
+	}
+	return matches, nil
+}
+
+// wordMatches the exercises whose aliases, or translation into the locales of
+// the search, contain the words of the search, the fields not covered by the
+// text index. Like the text index, only whole words are matched and each word
+// scores the weight of the field, so the scores are comparable to the text
+// score.
+func wordMatches(exercises []Exercise, q SearchQuery) []client.ExerciseMatch {
+	terms := tokenize(q.Text)
+	matches := []client.ExerciseMatch{}
+	for i := range exercises {
+		name, description := strings.Join(exercises[i].Aliases, " "), ""
+		if _, t := exercises[i].translation(q.Locales); t != nil {
+			name, description = t.Name+" "+name, t.Description
+		}
+		names, descriptions := tokenize(name), tokenize(description)
+		score := 0.0
+		for _, term := range terms {
+			if fieldMatch(term, names) == exactMatch {
+				score += NameWeight
+			}
+			if fieldMatch(term, descriptions) == exactMatch {
+				score += DescriptionWeight
+			}
+		}
+		if score > 0 {
+			matches = append(matches, client.ExerciseMatch{Exercise: exercises[i].Localize(q.Locales),
+				Score: round(score, 3)})
+		}
+	}
+	return matches
+}
+
+// mergeMatches combine the matches of the text index with those of
+// wordMatches, an exercise matched by both keeping its best score, most
+// relevant first
+func mergeMatches(matches []client.ExerciseMatch, words []client.ExerciseMatch) []client.ExerciseMatch {
+	index := map[string]int{}
+	for i, m := range matches {
+		index[m.ID] = i
+	}
+	for _, w := range words {
+		if i, ok := index[w.ID]; !ok {
+			matches = append(matches, w)
+		} else if w.Score > matches[i].Score {
+			matches[i].Score = w.Score
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	return matches
+}
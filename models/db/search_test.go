@@ -0,0 +1,118 @@
+package db_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// matchNames the names of the exercises matched by a search, in order
+func matchNames(result *client.ExerciseSearch) []string {
+	names := []string{}
+	for _, m := range result.Results {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestSearchExercises(t *testing.T) {
+	data, err := ioutil.ReadFile(testExerciseFilename)
+	assert.NoError(t, err)
+	exercises := []db.Exercise{}
+	assert.NoError(t, json.Unmarshal(data, &exercises))
+
+	// The description of the burpee mentions squat thrusts
+	result, err := db.SearchExercises(exercises, db.SearchQuery{Text: "squat thrust"})
+	assert.NoError(t, err)
+	assert.True(t, result.Fuzzy)
+	assert.Contains(t, matchNames(result), "Burpee")
+	assert.Contains(t, matchNames(result), "Squat")
+	assert.Equal(t, result.Total, len(result.Results))
+
+	// Prefixes and typos
+	for text, expected := range map[string]string{
+		"burp":            "Burpee",
+		"pusup":           "Pushup",
+		"jumpng jack":     "Jumping Jack",
+		"the side planks": "Side Plank",
+	} {
+		result, err = db.SearchExercises(exercises, db.SearchQuery{Text: text})
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, result.Results, text) {
+			assert.Equal(t, expected, result.Results[0].Name, text)
+		}
+	}
+	result, err = db.SearchExercises(exercises, db.SearchQuery{Text: "xylophone"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Total)
+	assert.Empty(t, result.Results)
+
+	// Pages of results
+	all, err := db.SearchExercises(exercises, db.SearchQuery{Text: "exercise"})
+	assert.NoError(t, err)
+	assert.True(t, all.Total > 2)
+	result, err = db.SearchExercises(exercises, db.SearchQuery{Text: "exercise", Page: 2, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, all.Total, result.Total)
+	assert.Equal(t, matchNames(all)[2:4], matchNames(result))
+	result, err = db.SearchExercises(exercises, db.SearchQuery{Text: "exercise", Page: 100})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Results)
+
+	for _, q := range []db.SearchQuery{{Text: " "}, {Text: "squat", Limit: db.MaxSearchLimit + 1},
+		{Text: "squat", Page: -1}} {
+		_, err = db.SearchExercises(exercises, q)
+		assert.True(t, db.IsValidation(err), "%v", q)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	service := SetupExercise(t, true, true)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+	assert.NoError(t, db.EnsureIndexes(ctx, service.Collection.Database()))
+
+	// Whole words are found by the text index, the name ranks above the description
+	result, err := service.Search(ctx, db.SearchQuery{Text: "squat"})
+	assert.NoError(t, err)
+	assert.False(t, result.Fuzzy)
+	names := matchNames(result)
+	if assert.Contains(t, names, "Burpee") {
+		assert.Equal(t, "Squat", names[0])
+	}
+
+	// Typos fall back to the in memory search
+	result, err = service.Search(ctx, db.SearchQuery{Text: "burpe"})
+	assert.NoError(t, err)
+	assert.True(t, result.Fuzzy)
+	assert.Equal(t, []string{"Burpee"}, matchNames(result))
+
+	// Aliases and translations are matched along with the text index, the
+	// results agreeing with those of the in memory search
+	assert.NoError(t, service.Create(ctx, &client.Exercise{Name: "Wall Sit", Aliases: []string{"Wall Squat"}}))
+	lunge, err := service.GetByName(ctx, "Lunge")
+	assert.NoError(t, err)
+	_, err = service.SetTranslation(ctx, lunge.ID, "fr", &client.Translation{Name: "Fente squat"})
+	assert.NoError(t, err)
+	q := db.SearchQuery{Text: "squat", Locales: []string{"fr"}}
+	result, err = service.Search(ctx, q)
+	assert.NoError(t, err)
+	assert.False(t, result.Fuzzy)
+	names = matchNames(result)
+	assert.Contains(t, names, "Squat")
+	assert.Contains(t, names, "Wall Sit")
+	assert.Contains(t, names, "Fente squat")
+	exercises := []db.Exercise{}
+	cursor, err := service.Collection.Find(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.NoError(t, cursor.All(ctx, &exercises))
+	inMemory, err := db.SearchExercises(exercises, q)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, matchNames(inMemory), names)
+}
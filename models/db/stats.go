@@ -0,0 +1,463 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Periods the statistics of a user can be grouped by
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DefaultStatsRange the range of sessions the statistics are computed over
+// when no start of the range is specified
+const DefaultStatsRange = 90 * 24 * time.Hour
+
+// StatsQuery the sessions the statistics of a user are computed over. Sessions
+// started at or after From and before To are included. Days, weeks and months
+// start at midnight in Location, weeks start on Monday. If ExerciseID is set
+// only the entries of that exercise, and the sessions including it, are counted.
+type StatsQuery struct {
+	UserID     string
+	ExerciseID string
+	From       time.Time
+	To         time.Time
+	Period     string
+	Location   *time.Location
+	exercise   primitive.ObjectID
+}
+
+// normalize apply the defaults for any unspecified fields of the query and
+// validate the result
+func (q *StatsQuery) normalize() error {
+	if len(q.Period) == 0 {
+		q.Period = PeriodWeek
+	}
+	if q.Period != PeriodDay && q.Period != PeriodWeek && q.Period != PeriodMonth {
+		return &ValidationError{fmt.Sprintf("invalid period '%s', must be one of %s, %s or %s",
+			q.Period, PeriodDay, PeriodWeek, PeriodMonth)}
+	}
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+	if q.To.IsZero() {
+		q.To = time.Now().UTC()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultStatsRange)
+	}
+	if !q.From.Before(q.To) {
+		return &ValidationError{"from must be before to"}
+	}
+	if len(q.ExerciseID) > 0 {
+		var err error
+		if q.exercise, err = objectID(q.ExerciseID, "exercise"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includes whether the entry is counted by the query
+func (q *StatsQuery) includes(e *WorkoutExercise) bool {
+	return q.exercise.IsZero() || e.ExerciseID == q.exercise
+}
+
+// periodStart the start of the day, week or month the time falls within
+func (q *StatsQuery) periodStart(t time.Time) time.Time {
+	t = t.In(q.Location)
+	year, month, day := t.Date()
+	switch q.Period {
+	case PeriodMonth:
+		day = 1
+	case PeriodWeek:
+		day -= (int(t.Weekday()) + 6) % 7
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, q.Location).UTC()
+}
+
+// statsGroup the totals of a group of sessions as computed by the aggregation
+type statsGroup struct {
+	Sessions        int     `bson:"sessions"`
+	DurationSeconds int     `bson:"duration"`
+	DistanceM       float64 `bson:"distance"`
+	VolumeKg        float64 `bson:"volume"`
+	Sets            int     `bson:"sets"`
+	Reps            int     `bson:"reps"`
+	Kcal            float64 `bson:"kcal"`
+	Est1RMKg        float64 `bson:"e1rm"`
+}
+
+// add include the totals of a session, or an exercise within it, in the group
+func (g *statsGroup) add(o statsGroup) {
+	g.DurationSeconds += o.DurationSeconds
+	g.DistanceM += o.DistanceM
+	g.VolumeKg += o.VolumeKg
+	g.Sets += o.Sets
+	g.Reps += o.Reps
+	g.Kcal += o.Kcal
+	g.Est1RMKg = math.Max(g.Est1RMKg, o.Est1RMKg)
+}
+
+// convert the group into the totals and per session averages reported to the client
+func (g *statsGroup) convert() client.StatsTotals {
+	t := client.StatsTotals{
+		Sessions:        g.Sessions,
+		DurationSeconds: g.DurationSeconds,
+		DistanceMeters:  round(g.DistanceM, 2),
+		VolumeKg:        round(g.VolumeKg, 2),
+		Sets:            g.Sets,
+		Reps:            g.Reps,
+		Kcal:            round(g.Kcal, 1),
+		Est1RMKg:        round(g.Est1RMKg, 2),
+	}
+	if g.Sessions > 0 {
+		n := float64(g.Sessions)
+		t.AvgDurationSeconds = round(float64(g.DurationSeconds)/n, 2)
+		t.AvgDistanceMeters = round(g.DistanceM/n, 2)
+		t.AvgVolumeKg = round(g.VolumeKg/n, 2)
+		t.AvgKcal = round(g.Kcal/n, 1)
+	}
+	return t
+}
+
+// entryTotals the totals of a single exercise entry of a session along with
+// the best one rep max estimated from its sets
+func entryTotals(e *WorkoutExercise) statsGroup {
+	g := statsGroup{Kcal: e.Kcal}
+	if e.Cardio != nil {
+		g.DurationSeconds = e.Cardio.DurationSeconds
+		g.DistanceM = e.Cardio.DistanceM
+	}
+	for _, set := range e.Sets {
+		g.Sets++
+		g.Reps += set.Reps
+		g.VolumeKg += float64(set.Reps) * set.LoadKg
+		if set.Reps > 0 && set.Reps <= MaxEstimateReps && set.LoadKg > 0 {
+			g.Est1RMKg = math.Max(g.Est1RMKg, Epley(set.LoadKg, set.Reps))
+		}
+	}
+	return g
+}
+
+type exerciseGroup struct {
+	ExerciseID primitive.ObjectID `bson:"_id"`
+	statsGroup `bson:",inline"`
+}
+
+type periodGroup struct {
+	Start      time.Time `bson:"_id"`
+	statsGroup `bson:",inline"`
+}
+
+// statsFacets the result of the statistics aggregation pipeline
+type statsFacets struct {
+	Totals    []statsGroup    `bson:"totals"`
+	Exercises []exerciseGroup `bson:"exercises"`
+	Periods   []periodGroup   `bson:"periods"`
+}
+
+// result build the statistics reported to the client. Exercises are ordered
+// by the number of sessions they were included in, periods chronologically.
+func (f *statsFacets) result(q *StatsQuery) *client.Stats {
+	stats := client.Stats{
+		UserID:     q.UserID,
+		ExerciseID: q.ExerciseID,
+		From:       q.From,
+		To:         q.To,
+		Period:     q.Period,
+		Timezone:   q.Location.String(),
+		Exercises:  []client.ExerciseStats{},
+		Periods:    []client.PeriodStats{},
+	}
+	if len(f.Totals) > 0 {
+		stats.Totals = f.Totals[0].convert()
+	}
+	sort.Slice(f.Exercises, func(i, j int) bool {
+		a, b := f.Exercises[i], f.Exercises[j]
+		if a.Sessions != b.Sessions {
+			return a.Sessions > b.Sessions
+		}
+		return a.ExerciseID.Hex() < b.ExerciseID.Hex()
+	})
+	for _, e := range f.Exercises {
+		stats.Exercises = append(stats.Exercises,
+			client.ExerciseStats{ExerciseID: e.ExerciseID.Hex(), StatsTotals: e.convert()})
+	}
+	sort.Slice(f.Periods, func(i, j int) bool { return f.Periods[i].Start.Before(f.Periods[j].Start) })
+	for _, p := range f.Periods {
+		stats.Periods = append(stats.Periods,
+			client.PeriodStats{Start: p.Start.UTC(), StatsTotals: p.convert()})
+	}
+	return &stats
+}
+
+// ComputeStats compute the statistics of the workout sessions in memory. This
+// produces the same result as the aggregation used by WorkoutService.Stats and
+// is used with backends that do not support the aggregation pipeline.
+func ComputeStats(workouts []Workout, q StatsQuery) (*client.Stats, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+	var totals statsGroup
+	exercises := map[primitive.ObjectID]*exerciseGroup{}
+	periods := map[time.Time]*periodGroup{}
+	for i := range workouts {
+		w := &workouts[i]
+		if w.Start.Before(q.From) || !w.Start.Before(q.To) {
+			continue
+		}
+		var session statsGroup
+		included := map[primitive.ObjectID]statsGroup{}
+		for j := range w.Exercises {
+			if !q.includes(&w.Exercises[j]) {
+				continue
+			}
+			entry := entryTotals(&w.Exercises[j])
+			session.add(entry)
+			g := included[w.Exercises[j].ExerciseID]
+			g.add(entry)
+			included[w.Exercises[j].ExerciseID] = g
+		}
+		// A session without exercises counts, as in the aggregation, unless
+		// the statistics are of a single exercise
+		if len(included) == 0 && !q.exercise.IsZero() {
+			continue
+		}
+		for id, g := range included {
+			e, ok := exercises[id]
+			if !ok {
+				e = &exerciseGroup{ExerciseID: id}
+				exercises[id] = e
+			}
+			e.Sessions++
+			e.add(g)
+		}
+		start := q.periodStart(w.Start)
+		p, ok := periods[start]
+		if !ok {
+			p = &periodGroup{Start: start}
+			periods[start] = p
+		}
+		p.Sessions++
+		p.add(session)
+		totals.Sessions++
+		totals.add(session)
+	}
+
+	var facets statsFacets
+	if totals.Sessions > 0 {
+		facets.Totals = []statsGroup{totals}
+	}
+	for _, e := range exercises {
+		facets.Exercises = append(facets.Exercises, *e)
+	}
+	for _, p := range periods {
+		facets.Periods = append(facets.Periods, *p)
+	}
+	return facets.result(&q), nil
+}
+
+// periodExpr the aggregation expression for the start of the day, week or
+// month a session started in
+func (q *StatsQuery) periodExpr() bson.M {
+	date := func(op string) bson.M {
+		return bson.M{op: bson.M{"date": "$start", "timezone": q.Location.String()}}
+	}
+	parts := bson.M{"timezone": q.Location.String()}
+	switch q.Period {
+	case PeriodWeek:
+		parts["isoWeekYear"] = date("$isoWeekYear")
+		parts["isoWeek"] = date("$isoWeek")
+		parts["isoDayOfWeek"] = 1
+	case PeriodMonth:
+		parts["year"] = date("$year")
+		parts["month"] = date("$month")
+		parts["day"] = 1
+	default:
+		parts["year"] = date("$year")
+		parts["month"] = date("$month")
+		parts["day"] = date("$dayOfMonth")
+	}
+	return bson.M{"$dateFromParts": parts}
+}
+
+// statsPipeline the aggregation pipeline computing the statistics of the user
+func (q *StatsQuery) statsPipeline(uid primitive.ObjectID) bson.A {
+	sums := func(id interface{}, sessions interface{}) bson.M {
+		return bson.M{
+			"_id":      id,
+			"sessions": bson.M{"$sum": sessions},
+			"duration": bson.M{"$sum": "$duration"},
+			"distance": bson.M{"$sum": "$distance"},
+			"volume":   bson.M{"$sum": "$volume"},
+			"sets":     bson.M{"$sum": "$sets"},
+			"reps":     bson.M{"$sum": "$reps"},
+			"kcal":     bson.M{"$sum": "$kcal"},
+			"e1rm":     bson.M{"$max": "$e1rm"},
+		}
+	}
+	sets := bson.M{"$ifNull": bson.A{"$$e.sets", bson.A{}}}
+	// The Epley estimate of the one rep max of a set, zero when the set can not be used
+	estimate := bson.M{"$cond": bson.A{
+		bson.M{"$and": bson.A{
+			bson.M{"$gt": bson.A{"$$s.reps", 0}},
+			bson.M{"$lte": bson.A{"$$s.reps", MaxEstimateReps}},
+			bson.M{"$gt": bson.A{"$$s.load_kg", 0}},
+		}},
+		bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$$s.reps", 1}}, "$$s.load_kg",
+			bson.M{"$multiply": bson.A{"$$s.load_kg", bson.M{"$add": bson.A{1, bson.M{"$divide": bson.A{"$$s.reps", 30}}}}}}}},
+		0.0,
+	}}
+	entry := bson.M{
+		"exercise_id": "$$e.exercise_id",
+		"duration":    bson.M{"$ifNull": bson.A{"$$e.cardio.duration_s", 0}},
+		"distance":    bson.M{"$ifNull": bson.A{"$$e.cardio.distance_m", 0.0}},
+		"kcal":        bson.M{"$ifNull": bson.A{"$$e.kcal", 0.0}},
+		"sets":        bson.M{"$size": sets},
+		"reps":        bson.M{"$sum": bson.M{"$map": bson.M{"input": sets, "as": "s", "in": "$$s.reps"}}},
+		"volume": bson.M{"$sum": bson.M{"$map": bson.M{"input": sets, "as": "s",
+			"in": bson.M{"$multiply": bson.A{"$$s.reps", "$$s.load_kg"}}}}},
+		"e1rm": bson.M{"$max": bson.A{0.0,
+			bson.M{"$max": bson.M{"$map": bson.M{"input": sets, "as": "s", "in": estimate}}}}},
+	}
+	match := bson.M{"user_id": uid, "start": bson.M{"$gte": q.From, "$lt": q.To}}
+	entries := interface{}(bson.M{"$ifNull": bson.A{"$exercises", bson.A{}}})
+	if !q.exercise.IsZero() {
+		match["exercises.exercise_id"] = q.exercise
+		entries = bson.M{"$filter": bson.M{"input": entries, "as": "e",
+			"cond": bson.M{"$eq": bson.A{"$$e.exercise_id", q.exercise}}}}
+	}
+	return bson.A{
+		bson.M{"$match": match},
+		bson.M{"$project": bson.M{
+			"start":     1,
+			"exercises": bson.M{"$map": bson.M{"input": entries, "as": "e", "in": entry}},
+		}},
+		bson.M{"$addFields": bson.M{
+			"period":   q.periodExpr(),
+			"duration": bson.M{"$sum": "$exercises.duration"},
+			"distance": bson.M{"$sum": "$exercises.distance"},
+			"volume":   bson.M{"$sum": "$exercises.volume"},
+			"sets":     bson.M{"$sum": "$exercises.sets"},
+			"reps":     bson.M{"$sum": "$exercises.reps"},
+			"kcal":     bson.M{"$sum": "$exercises.kcal"},
+			"e1rm":     bson.M{"$ifNull": bson.A{bson.M{"$max": "$exercises.e1rm"}, 0.0}},
+		}},
+		bson.M{"$facet": bson.M{
+			"totals":  bson.A{bson.M{"$group": sums(nil, 1)}},
+			"periods": bson.A{bson.M{"$group": sums("$period", 1)}},
+			"exercises": bson.A{
+				bson.M{"$unwind": "$exercises"},
+				bson.M{"$project": bson.M{
+					"exercise_id": "$exercises.exercise_id",
+					"duration":    "$exercises.duration",
+					"distance":    "$exercises.distance",
+					"volume":      "$exercises.volume",
+					"sets":        "$exercises.sets",
+					"reps":        "$exercises.reps",
+					"kcal":        "$exercises.kcal",
+					"e1rm":        "$exercises.e1rm",
+				}},
+				bson.M{"$group": sums(bson.M{"exercise": "$exercise_id", "workout": "$_id"}, 0)},
+				bson.M{"$group": sums("$_id.exercise", 1)},
+			},
+		}},
+	}
+}
+
+// Stats compute the totals and averages of the workout sessions of the user,
+// overall, per exercise and per day, week or month, along with the activity
+// streaks of the user. If the server does not support the aggregation pipeline
+// the statistics are computed in memory.
+func (s *WorkoutService) Stats(ctx context.Context, q StatsQuery) (*client.Stats, error) {
+	uid, err := objectID(q.UserID, "user")
+	if err != nil {
+		return nil, err
+	}
+	if err = q.normalize(); err != nil {
+		return nil, err
+	}
+	stats, err := s.stats(ctx, uid, q)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Streaks, err = s.GetStreaks(ctx, q.UserID); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// stats compute the statistics of the user for the normalized query
+func (s *WorkoutService) stats(ctx context.Context, uid primitive.ObjectID, q StatsQuery) (*client.Stats, error) {
+	stats, err := s.aggregateStats(ctx, uid, q)
+	if _, unsupported := err.(mongo.CommandError); unsupported {
+		log.Warnf("statistics aggregation failed, computing in memory, %s", err)
+		stats, err = s.computeStats(ctx, uid, q)
+	}
+	return stats, err
+}
+
+// aggregateStats compute the statistics of the user with the aggregation pipeline
+func (s *WorkoutService) aggregateStats(ctx context.Context, uid primitive.ObjectID,
+	q StatsQuery) (*client.Stats, error) {
+	cursor, err := s.Collection.Aggregate(ctx, q.statsPipeline(uid))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var facets statsFacets
+	if cursor.Next(ctx) {
+		if err = cursor.Decode(&facets); err != nil {
+			log.Errorf("failed to decode statistics %s", err)
+			return nil, err
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+	return facets.result(&q), nil
+}
+
+// computeStats compute the statistics of the user from the sessions within the query range
+func (s *WorkoutService) computeStats(ctx context.Context, uid primitive.ObjectID,
+	q StatsQuery) (*client.Stats, error) {
+	filter := bson.M{
+		"user_id": uid,
+		"start":   bson.M{"$gte": q.From, "$lt": q.To},
+	}
+	if !q.exercise.IsZero() {
+		filter["exercises.exercise_id"] = q.exercise
+	}
+	cursor, err := s.Collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	workouts := []Workout{}
+	for cursor.Next(ctx) {
+		var elem Workout
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode workout %s", err)
+			return nil, err
+		}
+		workouts = append(workouts, elem)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return ComputeStats(workouts, q)
+}
@@ -0,0 +1,164 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// statsWorkouts the sessions used by the statistics tests, two sessions in
+// the week of Monday November 18th 2019 and one in the following week
+func statsWorkouts() []client.Workout {
+	return []client.Workout{
+		client.Workout{
+			Start: time.Date(2019, 11, 18, 18, 0, 0, 0, time.UTC),
+			Exercises: []client.WorkoutExercise{
+				client.WorkoutExercise{ExerciseID: testSquatID, Sets: []client.StrengthSet{
+					client.StrengthSet{Reps: 5, Load: 100, Unit: db.UnitKilogram},
+					client.StrengthSet{Reps: 5, Load: 100, Unit: db.UnitKilogram},
+				}},
+				client.WorkoutExercise{ExerciseID: testSquatID, Sets: []client.StrengthSet{
+					client.StrengthSet{Reps: 10, Load: 60, Unit: db.UnitKilogram},
+				}},
+			},
+		},
+		client.Workout{
+			Start: time.Date(2019, 11, 20, 7, 0, 0, 0, time.UTC),
+			Exercises: []client.WorkoutExercise{
+				client.WorkoutExercise{ExerciseID: testRunningID,
+					Cardio: &client.CardioMetrics{DurationSeconds: 1800, Distance: 5, Unit: db.UnitKilometer}},
+			},
+		},
+		// Sunday evening in Los Angeles, Monday in UTC
+		client.Workout{
+			Start: time.Date(2019, 11, 25, 3, 0, 0, 0, time.UTC),
+			Exercises: []client.WorkoutExercise{
+				client.WorkoutExercise{ExerciseID: testRunningID,
+					Cardio: &client.CardioMetrics{DurationSeconds: 3000, Distance: 10, Unit: db.UnitKilometer}},
+				client.WorkoutExercise{ExerciseID: testSquatID, Sets: []client.StrengthSet{
+					client.StrengthSet{Reps: 3, Load: 220.462, Unit: db.UnitPound},
+				}},
+			},
+		},
+	}
+}
+
+// emptyWorkout a session logged without exercises, counted by the statistics
+// along with the other sessions
+var emptyWorkout = client.Workout{Start: time.Date(2019, 11, 22, 12, 0, 0, 0, time.UTC)}
+
+func TestComputeStats(t *testing.T) {
+	uid, _ := primitive.ObjectIDFromHex(testWorkoutUserID)
+	workouts := []db.Workout{}
+	for _, w := range append(statsWorkouts(), emptyWorkout) {
+		workout, err := db.NewWorkout(uid, &w)
+		assert.NoError(t, err)
+		workouts = append(workouts, *workout)
+	}
+	query := db.StatsQuery{
+		UserID: testWorkoutUserID,
+		From:   time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+	stats, err := db.ComputeStats(workouts, query)
+	assert.NoError(t, err)
+	assert.Equal(t, db.PeriodWeek, stats.Period)
+	assert.Equal(t, "UTC", stats.Timezone)
+	assert.Equal(t, 4, stats.Totals.Sessions)
+	assert.Equal(t, 4800, stats.Totals.DurationSeconds)
+	assert.Equal(t, 15000.0, stats.Totals.DistanceMeters)
+	assert.Equal(t, 1900.0, stats.Totals.VolumeKg)
+	assert.Equal(t, 4, stats.Totals.Sets)
+	assert.Equal(t, 23, stats.Totals.Reps)
+	assert.Equal(t, 1200.0, stats.Totals.AvgDurationSeconds)
+
+	// Squats were included in two sessions, twice in the first
+	if assert.Equal(t, 2, len(stats.Exercises)) {
+		assert.Equal(t, testSquatID, stats.Exercises[0].ExerciseID)
+		assert.Equal(t, 2, stats.Exercises[0].Sessions)
+		assert.Equal(t, 1900.0, stats.Exercises[0].VolumeKg)
+		assert.Equal(t, 950.0, stats.Exercises[0].AvgVolumeKg)
+		assert.Equal(t, db.Epley(100, 5), stats.Exercises[0].Est1RMKg)
+		assert.Equal(t, testRunningID, stats.Exercises[1].ExerciseID)
+		assert.Equal(t, 7500.0, stats.Exercises[1].AvgDistanceMeters)
+	}
+	if assert.Equal(t, 2, len(stats.Periods)) {
+		assert.Equal(t, time.Date(2019, 11, 18, 0, 0, 0, 0, time.UTC), stats.Periods[0].Start)
+		assert.Equal(t, 3, stats.Periods[0].Sessions)
+		assert.Equal(t, time.Date(2019, 11, 25, 0, 0, 0, 0, time.UTC), stats.Periods[1].Start)
+	}
+
+	// Weeks start on Monday in the time zone of the query
+	query.Location, err = time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	stats, err = db.ComputeStats(workouts, query)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(stats.Periods)) {
+		assert.Equal(t, time.Date(2019, 11, 18, 8, 0, 0, 0, time.UTC), stats.Periods[0].Start)
+		assert.Equal(t, 4, stats.Periods[0].Sessions)
+	}
+
+	query.Period = db.PeriodDay
+	stats, err = db.ComputeStats(workouts, query)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(stats.Periods))
+
+	// Sessions outside of the range are excluded
+	query.From = time.Date(2019, 11, 19, 0, 0, 0, 0, time.UTC)
+	stats, err = db.ComputeStats(workouts, query)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.Totals.Sessions)
+
+	query.Period = "year"
+	_, err = db.ComputeStats(workouts, query)
+	assert.True(t, db.IsValidation(err))
+	query.Period = db.PeriodMonth
+	query.To = query.From
+	_, err = db.ComputeStats(workouts, query)
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestWorkoutStats(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	for _, w := range append(statsWorkouts(), emptyWorkout) {
+		_, err := service.Create(ctx, testWorkoutUserID, &w)
+		assert.NoError(t, err)
+	}
+	workouts := []db.Workout{}
+	cursor, err := service.Collection.Find(ctx, map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.NoError(t, cursor.All(ctx, &workouts))
+
+	la, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	for _, period := range []string{db.PeriodDay, db.PeriodWeek, db.PeriodMonth} {
+		for _, loc := range []*time.Location{time.UTC, la} {
+			query := db.StatsQuery{
+				UserID:   testWorkoutUserID,
+				From:     time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC),
+				Period:   period,
+				Location: loc,
+			}
+			// The aggregation and in memory statistics must match
+			stats, err := service.Stats(ctx, query)
+			assert.NoError(t, err)
+			expected, err := db.ComputeStats(workouts, query)
+			assert.NoError(t, err)
+			assert.NotNil(t, stats.Streaks)
+			expected.Streaks = stats.Streaks
+			assert.Equal(t, expected, stats, "%s %s", period, loc)
+		}
+	}
+
+	_, err = service.Stats(ctx, db.StatsQuery{UserID: "unknown"})
+	assert.True(t, db.IsNotFound(err))
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StreaksCollection name of the collection used to hold the days each user was active
+const StreaksCollection = "streaks"
+
+// DateLayout the layout of the local dates used by streaks
+const DateLayout = "2006-01-02"
+
+// The largest rest allowances of a streak
+const (
+	MaxRestDays  = 6
+	MaxRestWeeks = 4
+)
+
+// StreakSettings how the activity streaks of a user are computed
+type StreakSettings struct {
+	Timezone  string `bson:"timezone" json:"timezone"`
+	RestDays  int    `bson:"rest_days" json:"rest_days"`
+	RestWeeks int    `bson:"rest_weeks" json:"rest_weeks"`
+}
+
+// NewStreakSettings create the database representation of the streak settings of a user
+func NewStreakSettings(s *client.StreakSettings) (*StreakSettings, error) {
+	settings := StreakSettings{Timezone: s.Timezone, RestDays: s.RestDays, RestWeeks: s.RestWeeks}
+	if len(settings.Timezone) == 0 {
+		settings.Timezone = time.UTC.String()
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		return nil, &ValidationError{fmt.Sprintf("unknown timezone '%s'", s.Timezone)}
+	}
+	if settings.RestDays < 0 || settings.RestDays > MaxRestDays {
+		return nil, &ValidationError{fmt.Sprintf("rest days must be between 0 and %d", MaxRestDays)}
+	}
+	if settings.RestWeeks < 0 || settings.RestWeeks > MaxRestWeeks {
+		return nil, &ValidationError{fmt.Sprintf("rest weeks must be between 0 and %d", MaxRestWeeks)}
+	}
+	return &settings, nil
+}
+
+// Convert the database representation of streak settings to the client representation
+func (s *StreakSettings) Convert() client.StreakSettings {
+	return client.StreakSettings{Timezone: s.Timezone, RestDays: s.RestDays, RestWeeks: s.RestWeeks}
+}
+
+// location the time zone days start in
+func (s *StreakSettings) location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Activity the local dates on which a user logged at least one workout
+// session. The dates are maintained as sessions are logged and deleted
+// so streaks can be computed without reading the logs of the user.
+type Activity struct {
+	UserID         primitive.ObjectID `bson:"_id" json:"_id"`
+	StreakSettings `bson:",inline"`
+	Days           []string `bson:"days" json:"days"`
+}
+
+// dayNumber the number of days since the unix epoch of a local date
+func dayNumber(date string) (int, bool) {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return 0, false
+	}
+	return int(t.Unix() / 86400), true
+}
+
+// weekNumber the number of the week, starting Monday, a day falls in. The
+// unix epoch was a Thursday.
+func weekNumber(day int) int {
+	return floorDiv(day+3, 7)
+}
+
+// floorDiv integer division rounding towards negative infinity
+func floorDiv(a int, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+// dayDate the local date of a day number
+func dayDate(day int) string {
+	return time.Unix(int64(day)*86400, 0).UTC().Format(DateLayout)
+}
+
+// weekDate the local date of the Monday of a week number
+func weekDate(week int) string {
+	return dayDate(week*7 - 3)
+}
+
+// streak the current and longest runs of the sorted, distinct units. Gaps of
+// up to rest inactive units do not break a run. The current run is the last
+// run if no more than rest units have passed since, not including now.
+func streak(units []int, now int, rest int, date func(int) string) client.Streak {
+	var s client.Streak
+	start := 0
+	for i := range units {
+		if i > 0 && units[i]-units[i-1]-1 > rest {
+			start = i
+		}
+		if n := i - start + 1; n > s.Longest {
+			s.Longest = n
+			s.LongestStart = date(units[start])
+			s.LongestEnd = date(units[i])
+		}
+	}
+	if len(units) > 0 && now-units[len(units)-1]-1 <= rest {
+		s.Current = len(units) - start
+		s.CurrentStart = date(units[start])
+	}
+	return s
+}
+
+// ComputeStreaks compute the daily and weekly streaks from the local dates
+// on which the user was active, as of the time now
+func ComputeStreaks(days []string, now time.Time, settings StreakSettings) client.Streaks {
+	result := client.Streaks{StreakSettings: settings.Convert()}
+	seen := map[int]bool{}
+	daily := []int{}
+	for _, d := range days {
+		if n, ok := dayNumber(d); ok && !seen[n] {
+			seen[n] = true
+			daily = append(daily, n)
+		}
+	}
+	sort.Ints(daily)
+	weekly := []int{}
+	for _, d := range daily {
+		if w := weekNumber(d); len(weekly) == 0 || weekly[len(weekly)-1] != w {
+			weekly = append(weekly, w)
+		}
+	}
+	today, _ := dayNumber(now.In(settings.location()).Format(DateLayout))
+	result.Daily = streak(daily, today, settings.RestDays, dayDate)
+	result.Weekly = streak(weekly, weekNumber(today), settings.RestWeeks, weekDate)
+	if len(daily) > 0 {
+		result.LastActive = dayDate(daily[len(daily)-1])
+	}
+	return result
+}
+
+// rebuildActivity recompute the days the user was active from their
+// workout sessions and store them with the settings
+func (s *WorkoutService) rebuildActivity(ctx context.Context, userID primitive.ObjectID,
+	settings StreakSettings) (*Activity, error) {
+	cursor, err := s.Collection.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetProjection(bson.M{"start": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	loc := settings.location()
+	seen := map[string]bool{}
+	activity := Activity{UserID: userID, StreakSettings: settings, Days: []string{}}
+	for cursor.Next(ctx) {
+		var elem Workout
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode workout %s", err)
+			return nil, err
+		}
+		if day := elem.Start.In(loc).Format(DateLayout); !seen[day] {
+			seen[day] = true
+			activity.Days = append(activity.Days, day)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(activity.Days)
+	_, err = s.streaks.ReplaceOne(ctx, bson.M{"_id": userID}, &activity, options.Replace().SetUpsert(true))
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// activity retrieve the days the user was active. If they have not been
+// recorded yet they are computed from the workout sessions of the user.
+func (s *WorkoutService) activity(ctx context.Context, userID primitive.ObjectID) (*Activity, bool, error) {
+	var activity Activity
+	err := s.streaks.FindOne(ctx, bson.M{"_id": userID}).Decode(&activity)
+	if err == mongo.ErrNoDocuments {
+		a, err := s.rebuildActivity(ctx, userID, StreakSettings{Timezone: time.UTC.String()})
+		return a, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &activity, false, nil
+}
+
+// sessionAdded record the day of a newly logged session as active
+func (s *WorkoutService) sessionAdded(ctx context.Context, userID primitive.ObjectID, start time.Time) error {
+	activity, rebuilt, err := s.activity(ctx, userID)
+	if err != nil || rebuilt {
+		return err
+	}
+	day := start.In(activity.location()).Format(DateLayout)
+	_, err = s.streaks.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$addToSet": bson.M{"days": day}})
+	return err
+}
+
+// sessionRemoved remove the day of a deleted session from the active days
+// if no other session was logged on that day
+func (s *WorkoutService) sessionRemoved(ctx context.Context, userID primitive.ObjectID, start time.Time) error {
+	activity, rebuilt, err := s.activity(ctx, userID)
+	if err != nil || rebuilt {
+		return err
+	}
+	local := start.In(activity.location())
+	year, month, day := local.Date()
+	from := time.Date(year, month, day, 0, 0, 0, 0, local.Location())
+	cnt, err := s.Collection.CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"start":   bson.M{"$gte": from, "$lt": from.AddDate(0, 0, 1)},
+	})
+	if err != nil || cnt > 0 {
+		return err
+	}
+	_, err = s.streaks.UpdateOne(ctx, bson.M{"_id": userID},
+		bson.M{"$pull": bson.M{"days": local.Format(DateLayout)}})
+	return err
+}
+
+// SetStreakSettings change how the activity streaks of the user are computed.
+// The days the user was active are recomputed in the new time zone.
+func (s *WorkoutService) SetStreakSettings(ctx context.Context, userID string,
+	settings *client.StreakSettings) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	updated, err := NewStreakSettings(settings)
+	if err != nil {
+		return err
+	}
+	_, err = s.rebuildActivity(ctx, uid, *updated)
+	return err
+}
+
+// GetStreaks compute the current and longest daily and weekly activity streaks of the user
+func (s *WorkoutService) GetStreaks(ctx context.Context, userID string) (*client.Streaks, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	activity, _, err := s.activity(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	streaks := ComputeStreaks(activity.Days, time.Now(), activity.StreakSettings)
+	return &streaks, nil
+}
@@ -0,0 +1,104 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStreaks(t *testing.T) {
+	days := []string{
+		"2019-10-28", "2019-10-29", "2019-10-30", "2019-10-31", // Mon - Thu
+		"2019-11-04", "2019-11-05", // Mon, Tue
+		"2019-11-18", "2019-11-20", "2019-11-21", "2019-11-21", // Mon, Wed, Thu twice
+	}
+	now := time.Date(2019, 11, 22, 12, 0, 0, 0, time.UTC)
+	utc := db.StreakSettings{Timezone: "UTC"}
+
+	streaks := db.ComputeStreaks(days, now, utc)
+	assert.Equal(t, "2019-11-21", streaks.LastActive)
+	assert.Equal(t, client.Streak{Current: 2, CurrentStart: "2019-11-20",
+		Longest: 4, LongestStart: "2019-10-28", LongestEnd: "2019-10-31"}, streaks.Daily)
+	assert.Equal(t, client.Streak{Current: 1, CurrentStart: "2019-11-18",
+		Longest: 2, LongestStart: "2019-10-28", LongestEnd: "2019-11-04"}, streaks.Weekly)
+
+	// A rest day does not break the streak
+	utc.RestDays = 1
+	streaks = db.ComputeStreaks(days, now, utc)
+	assert.Equal(t, 3, streaks.Daily.Current)
+	assert.Equal(t, "2019-11-18", streaks.Daily.CurrentStart)
+
+	// Nor does a rest week
+	utc.RestWeeks = 1
+	streaks = db.ComputeStreaks(days, now, utc)
+	assert.Equal(t, 3, streaks.Weekly.Current)
+	assert.Equal(t, 3, streaks.Weekly.Longest)
+
+	// The current streak ends once more days than allowed have passed
+	streaks = db.ComputeStreaks(days, now.AddDate(0, 0, 2), utc)
+	assert.Equal(t, 0, streaks.Daily.Current)
+	assert.Equal(t, "", streaks.Daily.CurrentStart)
+
+	// Today is determined in the time zone of the settings
+	tokyo := db.StreakSettings{Timezone: "Asia/Tokyo"}
+	streaks = db.ComputeStreaks(days, time.Date(2019, 11, 22, 16, 0, 0, 0, time.UTC), tokyo)
+	assert.Equal(t, 0, streaks.Daily.Current)
+
+	streaks = db.ComputeStreaks(nil, now, utc)
+	assert.Equal(t, client.Streak{}, streaks.Daily)
+	assert.Equal(t, "", streaks.LastActive)
+}
+
+func TestNewStreakSettings(t *testing.T) {
+	settings, err := db.NewStreakSettings(&client.StreakSettings{})
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", settings.Timezone)
+	_, err = db.NewStreakSettings(&client.StreakSettings{Timezone: "Nowhere/Special"})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewStreakSettings(&client.StreakSettings{RestDays: db.MaxRestDays + 1})
+	assert.True(t, db.IsValidation(err))
+	_, err = db.NewStreakSettings(&client.StreakSettings{RestWeeks: -1})
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestWorkoutStreaks(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	// Sessions yesterday and today, late evening in Los Angeles
+	la, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	now := time.Now().In(la)
+	evening := time.Date(now.Year(), now.Month(), now.Day(), 0, 30, 0, 0, la)
+	ids := []string{}
+	for _, start := range []time.Time{evening.AddDate(0, 0, -1), evening} {
+		id, err := service.Create(ctx, testWorkoutUserID, &client.Workout{Start: start})
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
+	err = service.SetStreakSettings(ctx, testWorkoutUserID, &client.StreakSettings{Timezone: la.String()})
+	assert.NoError(t, err)
+	streaks, err := service.GetStreaks(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, streaks.Daily.Current)
+	assert.Equal(t, evening.Format(db.DateLayout), streaks.LastActive)
+
+	// A second session on the same day does not change the streak until both are deleted
+	id, err := service.Create(ctx, testWorkoutUserID, &client.Workout{Start: evening.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, id))
+	streaks, err = service.GetStreaks(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, streaks.Daily.Current)
+
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, ids[1]))
+	streaks, err = service.GetStreaks(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, streaks.Daily.Current)
+	assert.Equal(t, evening.AddDate(0, 0, -1).Format(db.DateLayout), streaks.LastActive)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The categories of exercises
+const (
+	CategoryStrength    = "strength"
+	CategoryCardio      = "cardio"
+	CategoryFlexibility = "flexibility"
+)
+
+// The difficulty levels of exercises
+const (
+	DifficultyBeginner     = "beginner"
+	DifficultyIntermediate = "intermediate"
+	DifficultyAdvanced     = "advanced"
+)
+
+// The measurements an exercise is logged with, sets of reps, a duration or a distance
+const (
+	MeasureReps     = "reps"
+	MeasureTime     = "time"
+	MeasureDistance = "distance"
+)
+
+// Categories the categories of exercises
+var Categories = []string{CategoryStrength, CategoryCardio, CategoryFlexibility}
+
+// Difficulties the difficulty levels of exercises, easiest first
+var Difficulties = []string{DifficultyBeginner, DifficultyIntermediate, DifficultyAdvanced}
+
+// Measurements the measurements exercises are logged with
+var Measurements = []string{MeasureReps, MeasureTime, MeasureDistance}
+
+// MuscleGroups the muscle groups exercises work
+var MuscleGroups = []string{
+	"abdominals", "obliques", "lower-back", "chest", "upper-back", "lats", "shoulders", "biceps",
+	"triceps", "forearms", "glutes", "hip-flexors", "adductors", "abductors", "quadriceps",
+	"hamstrings", "calves", "cardiovascular",
+}
+
+// Equipment the equipment exercises are performed with, bodyweight exercises
+// require none
+var Equipment = []string{
+	"bodyweight", "barbell", "dumbbell", "kettlebell", "machine", "cable", "band", "bench",
+	"pull-up-bar", "medicine-ball", "mat", "treadmill", "bike", "rower",
+}
+
+// oneOf whether the value is one of the valid values
+func oneOf(value string, valid []string) bool {
+	for _, v := range valid {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// taxonomyValue normalize an attribute of an exercise, ensuring it is empty
+// or one of the valid values
+func taxonomyValue(name string, value string, valid []string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) > 0 && !oneOf(value, valid) {
+		return "", &ValidationError{fmt.Sprintf("invalid %s '%s', must be one of %s",
+			name, value, strings.Join(valid, ", "))}
+	}
+	return value, nil
+}
+
+// taxonomyList normalize a list of attributes of an exercise, ensuring each
+// is one of the valid values. Duplicates are removed.
+func taxonomyList(name string, values []string, valid []string) ([]string, error) {
+	var results []string
+	for _, v := range values {
+		v, err := taxonomyValue(name, v, valid)
+		if err != nil {
+			return nil, err
+		}
+		if len(v) > 0 && !oneOf(v, results) {
+			results = append(results, v)
+		}
+	}
+	return results, nil
+}
+
+// ExerciseFilter the attributes the exercises retrieved must have. Muscle
+// matches either a primary or secondary muscle group of an exercise, Primary
+// only its primary muscle groups. Empty attributes match any exercise. The
+// exercises of the shared catalog are retrieved along with the private
+// exercises of UserID, if set. Locales are the locales the exercises are
+// localized for, most preferred first.
+type ExerciseFilter struct {
+	UserID      string
+	Locales     []string
+	Category    string
+	Muscle      string
+	Primary     string
+	Equipment   string
+	Difficulty  string
+	Measurement string
+}
+
+// query validate the filter returning the matching database query
+func (f *ExerciseFilter) query() (bson.M, error) {
+	var uid primitive.ObjectID
+	if len(f.UserID) > 0 {
+		var err error
+		if uid, err = primitive.ObjectIDFromHex(f.UserID); err != nil {
+			return nil, &ValidationError{fmt.Sprintf("invalid user id '%s'", f.UserID)}
+		}
+	}
+	query := bson.M{"owner": visibleTo(uid)}
+	attributes := []struct {
+		name  string
+		field string
+		value string
+		valid []string
+	}{
+		{"category", "category", f.Category, Categories},
+		{"muscle group", "primary_muscles", f.Primary, MuscleGroups},
+		{"equipment", "equipment", f.Equipment, Equipment},
+		{"difficulty", "difficulty", f.Difficulty, Difficulties},
+		{"measurement", "measurement", f.Measurement, Measurements},
+	}
+	for _, a := range attributes {
+		value, err := taxonomyValue(a.name, a.value, a.valid)
+		if err != nil {
+			return nil, err
+		}
+		if len(value) > 0 {
+			query[a.field] = value
+		}
+	}
+	muscle, err := taxonomyValue("muscle group", f.Muscle, MuscleGroups)
+	if err != nil {
+		return nil, err
+	}
+	if len(muscle) > 0 {
+		query["$or"] = bson.A{bson.M{"primary_muscles": muscle}, bson.M{"secondary_muscles": muscle}}
+	}
+	return query, nil
+}
@@ -0,0 +1,77 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExerciseTaxonomy(t *testing.T) {
+	e, err := db.NewExercise(&client.Exercise{
+		Name:             "Deadlift",
+		Category:         " Strength",
+		PrimaryMuscles:   []string{"hamstrings", "Glutes", "hamstrings"},
+		SecondaryMuscles: []string{"lower-back", "forearms"},
+		Equipment:        []string{"barbell"},
+		Difficulty:       db.DifficultyAdvanced,
+		Measurement:      db.MeasureReps,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, db.CategoryStrength, e.Category)
+	assert.Equal(t, []string{"hamstrings", "glutes"}, e.PrimaryMuscles)
+	c := e.Convert()
+	assert.Equal(t, []string{"lower-back", "forearms"}, c.SecondaryMuscles)
+	assert.Equal(t, []string{"barbell"}, c.Equipment)
+	assert.Equal(t, db.DifficultyAdvanced, c.Difficulty)
+	assert.Equal(t, db.MeasureReps, c.Measurement)
+
+	// The taxonomy is optional
+	e, err = db.NewExercise(&client.Exercise{Name: "Deadlift"})
+	assert.NoError(t, err)
+	assert.Empty(t, e.Category)
+	assert.Nil(t, e.PrimaryMuscles)
+
+	invalid := []client.Exercise{
+		{Name: "Deadlift", Category: "balance"},
+		{Name: "Deadlift", Difficulty: "expert"},
+		{Name: "Deadlift", Measurement: "weight"},
+		{Name: "Deadlift", PrimaryMuscles: []string{"legs"}},
+		{Name: "Deadlift", Equipment: []string{"trap-bar"}},
+		{Name: "Deadlift", PrimaryMuscles: []string{"glutes"}, SecondaryMuscles: []string{"glutes"}},
+	}
+	for i := range invalid {
+		_, err = db.NewExercise(&invalid[i])
+		assert.True(t, db.IsValidation(err), "%v", invalid[i])
+	}
+}
+
+func TestFindExercises(t *testing.T) {
+	service := SetupExercise(t, true, true)
+	defer TeardownExercise(t, service)
+	ctx := context.TODO()
+
+	names := func(filter db.ExerciseFilter) []string {
+		exercises, err := service.Find(ctx, &filter)
+		assert.NoError(t, err)
+		results := []string{}
+		for _, e := range exercises {
+			results = append(results, e.Name)
+		}
+		return results
+	}
+	assert.Equal(t, 11, len(names(db.ExerciseFilter{})))
+	assert.Equal(t, []string{"Lunge", "Squat"}, names(db.ExerciseFilter{Primary: "quadriceps"}))
+	assert.Equal(t, []string{"Burpee", "Lunge", "running", "Squat", "walking"},
+		names(db.ExerciseFilter{Muscle: "quadriceps"}))
+	assert.Equal(t, []string{"Side Plank", "Sit-Up"}, names(db.ExerciseFilter{Equipment: "mat"}))
+	assert.Equal(t, []string{"running", "walking"},
+		names(db.ExerciseFilter{Category: db.CategoryCardio, Measurement: db.MeasureDistance}))
+	assert.Equal(t, []string{"running"},
+		names(db.ExerciseFilter{Measurement: db.MeasureDistance, Difficulty: db.DifficultyIntermediate}))
+
+	_, err := service.Find(ctx, &db.ExerciseFilter{Category: "balance"})
+	assert.True(t, db.IsValidation(err))
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxTemplateNameLength the longest name a workout template can be given
+const MaxTemplateNameLength = 100
+
+// WorkoutTemplate a named routine of exercises, with their target sets and
+// cardio metrics, that a user can log as a workout session
+type WorkoutTemplate struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name      string             `bson:"name" json:"name"`
+	Notes     string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Exercises []WorkoutExercise  `bson:"exercises" json:"exercises"`
+	Updated   time.Time          `bson:"updated" json:"updated"`
+}
+
+// NewWorkoutTemplate transforms the web facing WorkoutTemplate structure to
+// a database compatible WorkoutTemplate structure for the specified user.
+// The exercises of the template are validated as for a workout session.
+func NewWorkoutTemplate(userID primitive.ObjectID, t *client.WorkoutTemplate) (*WorkoutTemplate, error) {
+	template := WorkoutTemplate{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(t.Name),
+		Notes:     strings.TrimSpace(t.Notes),
+		Exercises: []WorkoutExercise{},
+		Updated:   time.Now().UTC(),
+	}
+	if len(template.Name) == 0 {
+		return nil, &ValidationError{"a template name must be specified"}
+	}
+	if len(template.Name) > MaxTemplateNameLength {
+		return nil, &ValidationError{"template name is too long"}
+	}
+	if len(t.Exercises) == 0 {
+		return nil, &ValidationError{"a template must include at least one exercise"}
+	}
+	for i := range t.Exercises {
+		e, err := NewWorkoutExercise(&t.Exercises[i])
+		if err != nil {
+			return nil, err
+		}
+		template.Exercises = append(template.Exercises, *e)
+	}
+	return &template, nil
+}
+
+// Convert the database representation of a template to the client representation
+func (t *WorkoutTemplate) Convert() client.WorkoutTemplate {
+	template := client.WorkoutTemplate{
+		ID:        t.ID.Hex(),
+		Name:      t.Name,
+		Notes:     t.Notes,
+		Exercises: []client.WorkoutExercise{},
+		Updated:   t.Updated,
+	}
+	for i := range t.Exercises {
+		template.Exercises = append(template.Exercises, t.Exercises[i].Convert())
+	}
+	return template
+}
+
+// workout the workout session logged from the template, its exercises are
+// pre-populated with the targets of the template
+func (t *WorkoutTemplate) workout(start time.Time) client.Workout {
+	template := t.Convert()
+	return client.Workout{Start: start, Notes: template.Notes, Exercises: template.Exercises,
+		TemplateID: template.ID}
+}
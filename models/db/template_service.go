@@ -0,0 +1,229 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TemplatesCollection name of the collection used to hold the workout templates of users
+const TemplatesCollection = "templates"
+
+// TemplateService holds a entry to the collection of workout templates in the database
+type TemplateService struct {
+	Collection *mongo.Collection
+	plans      *mongo.Collection
+	workouts   *WorkoutService
+}
+
+// NewTemplateService create a new instance of the Template Service
+func NewTemplateService(database *mongo.Database, opts ...ServiceOption) (*TemplateService, error) {
+	workouts, err := NewWorkoutService(database, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateService{
+		Collection: database.Collection(CollectionName(TemplatesCollection, opts...)),
+		plans:      database.Collection(CollectionName(PlansCollection, opts...)),
+		workouts:   workouts,
+	}, nil
+}
+
+// duplicateName the error reported when the user already has a template with the name
+func duplicateName(name string) error {
+	return &ConflictError{fmt.Sprintf("A template named '%s' already exists", name)}
+}
+
+// checkName ensure the user has no other template with the same name, names are
+// compared ignoring case
+func (s *TemplateService) checkName(ctx context.Context, t *WorkoutTemplate) error {
+	cnt, err := s.Collection.CountDocuments(ctx,
+		bson.M{"user_id": t.UserID, "name": t.Name, "_id": bson.M{"$ne": t.ID}},
+		options.Count().SetCollation(caseInsensitive))
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return duplicateName(t.Name)
+	}
+	return nil
+}
+
+// Create add a new workout template for the user to the database
+func (s *TemplateService) Create(ctx context.Context, userID string, t *client.WorkoutTemplate) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	template, err := NewWorkoutTemplate(uid, t)
+	if err != nil {
+		return "", err
+	}
+	if err = s.workouts.checkExercises(ctx, template.UserID, template.Exercises...); err != nil {
+		return "", err
+	}
+	if err = s.checkName(ctx, template); err != nil {
+		return "", err
+	}
+	_, err = s.Collection.InsertOne(ctx, template)
+	if IsDuplicateKeyError(err) {
+		return "", duplicateName(template.Name)
+	}
+	if err != nil {
+		err = fmt.Errorf("Unable to store template in database, %s", err)
+		log.Error(err)
+		return "", err
+	}
+	return template.ID.Hex(), nil
+}
+
+// find retrieve the workout template of the user with the specified id
+func (s *TemplateService) find(ctx context.Context, userID string, id string) (*WorkoutTemplate, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	tid, err := objectID(id, "template")
+	if err != nil {
+		return nil, err
+	}
+	var template WorkoutTemplate
+	err = s.Collection.FindOne(ctx, bson.M{"_id": tid, "user_id": uid}).Decode(&template)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{fmt.Sprintf("template '%s' not found", id)}
+	}
+	if err != nil {
+		log.Errorf("failed to retrieve template %s, %s", id, err)
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetByID retrieve the workout template of the user with the specified id
+func (s *TemplateService) GetByID(ctx context.Context, userID string, id string) (*client.WorkoutTemplate, error) {
+	template, err := s.find(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	cTemplate := template.Convert()
+	return &cTemplate, nil
+}
+
+// GetAll retrieve the workout templates of the user ordered by name
+func (s *TemplateService) GetAll(ctx context.Context, userID string) ([]*client.WorkoutTemplate, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := s.Collection.Find(ctx, bson.M{"user_id": uid},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetCollation(caseInsensitive))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []*client.WorkoutTemplate{}
+	for cursor.Next(ctx) {
+		var elem WorkoutTemplate
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode template %s", err)
+			return nil, err
+		}
+		template := elem.Convert()
+		results = append(results, &template)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Update replace the name, notes and exercises of an existing workout template
+func (s *TemplateService) Update(ctx context.Context, userID string, id string, t *client.WorkoutTemplate) error {
+	existing, err := s.find(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	template, err := NewWorkoutTemplate(existing.UserID, t)
+	if err != nil {
+		return err
+	}
+	template.ID = existing.ID
+	if err = s.workouts.checkExercises(ctx, template.UserID, template.Exercises...); err != nil {
+		return err
+	}
+	if err = s.checkName(ctx, template); err != nil {
+		return err
+	}
+	_, err = s.Collection.ReplaceOne(ctx, bson.M{"_id": template.ID}, template)
+	if IsDuplicateKeyError(err) {
+		return duplicateName(template.Name)
+	}
+	return err
+}
+
+// Delete remove the workout template of the user with the specified id. A
+// template that sessions of a training plan are scheduled from can not be
+// deleted.
+func (s *TemplateService) Delete(ctx context.Context, userID string, id string) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	tid, err := objectID(id, "template")
+	if err != nil {
+		return err
+	}
+	cnt, err := s.plans.CountDocuments(ctx, bson.M{"weeks.sessions.template_id": tid})
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return &ConflictError{fmt.Sprintf("template '%s' is used by %d plans", id, cnt)}
+	}
+	result, err := s.Collection.DeleteOne(ctx, bson.M{"_id": tid, "user_id": uid})
+	if err != nil {
+		err = fmt.Errorf("failed to delete template %s, %s", id, err)
+		log.Error(err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("template '%s' not found", id)}
+	}
+	return nil
+}
+
+// DeleteByUser remove all workout templates of the user, returning the number
+// of templates deleted
+func (s *TemplateService) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return 0, err
+	}
+	result, err := s.Collection.DeleteMany(ctx, bson.M{"user_id": uid})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
+
+// Instantiate log a new workout session for the user from the template,
+// returning the id of the session. Each exercise of the session is
+// pre-populated with the target sets and cardio metrics of the template.
+// If no start time is specified the current time is used.
+func (s *TemplateService) Instantiate(ctx context.Context, userID string, id string,
+	start time.Time) (string, error) {
+	template, err := s.find(ctx, userID, id)
+	if err != nil {
+		return "", err
+	}
+	workout := template.workout(start)
+	return s.workouts.Create(ctx, userID, &workout)
+}
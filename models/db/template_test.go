@@ -0,0 +1,102 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewWorkoutTemplate(t *testing.T) {
+	uid := primitive.NewObjectID()
+	squat := client.WorkoutExercise{ExerciseID: testSquatID,
+		Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 225, Unit: "lb"}}}
+	template, err := db.NewWorkoutTemplate(uid, &client.WorkoutTemplate{Name: " Leg day ",
+		Exercises: []client.WorkoutExercise{squat}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Leg day", template.Name)
+	assert.Equal(t, uid, template.UserID)
+	if assert.Equal(t, 1, len(template.Exercises)) && assert.Equal(t, 1, len(template.Exercises[0].Sets)) {
+		assert.InDelta(t, 102.058, template.Exercises[0].Sets[0].LoadKg, 0.001)
+	}
+
+	tests := []client.WorkoutTemplate{
+		client.WorkoutTemplate{Name: "", Exercises: []client.WorkoutExercise{squat}},
+		client.WorkoutTemplate{Name: strings.Repeat("x", db.MaxTemplateNameLength+1),
+			Exercises: []client.WorkoutExercise{squat}},
+		client.WorkoutTemplate{Name: "Empty"},
+		client.WorkoutTemplate{Name: "Bad exercise", Exercises: []client.WorkoutExercise{
+			client.WorkoutExercise{ExerciseID: "bad"}}},
+	}
+	for _, test := range tests {
+		_, err := db.NewWorkoutTemplate(uid, &test)
+		assert.True(t, db.IsValidation(err), "template '%s' expected validation error", test.Name)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	workouts, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, workouts, ex)
+	service, err := db.NewTemplateService(workouts.Collection.Database())
+	assert.NoError(t, err)
+	assert.NoError(t, service.Collection.Drop(context.TODO()))
+	defer service.Collection.Drop(context.TODO())
+	ctx := context.TODO()
+
+	template := client.WorkoutTemplate{Name: "Leg day", Exercises: []client.WorkoutExercise{
+		client.WorkoutExercise{ExerciseID: testSquatID,
+			Sets: []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 100}, client.StrengthSet{Reps: 5, Load: 100}}},
+		client.WorkoutExercise{ExerciseID: testRunningID,
+			Cardio: &client.CardioMetrics{DurationSeconds: 600}},
+	}}
+	id, err := service.Create(ctx, testWorkoutUserID, &template)
+	assert.NoError(t, err)
+
+	// Names are unique for each user, ignoring case
+	duplicate := template
+	duplicate.Name = "LEG DAY"
+	_, err = service.Create(ctx, testWorkoutUserID, &duplicate)
+	assert.True(t, db.IsConflict(err))
+
+	unknown := template
+	unknown.Name = "Unknown"
+	unknown.Exercises = []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: "5db8e02b0e7aa732afd7fbc1"}}
+	_, err = service.Create(ctx, testWorkoutUserID, &unknown)
+	assert.True(t, db.IsValidation(err))
+
+	// A session logged from the template holds the targets of the template
+	start := time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC)
+	workoutID, err := service.Instantiate(ctx, testWorkoutUserID, id, start)
+	assert.NoError(t, err)
+	workout, err := workouts.GetByID(ctx, testWorkoutUserID, workoutID)
+	assert.NoError(t, err)
+	assert.Equal(t, start, workout.Start)
+	if assert.Equal(t, 2, len(workout.Exercises)) {
+		assert.Equal(t, 2, len(workout.Exercises[0].Sets))
+		assert.Equal(t, 600, workout.Exercises[1].Cardio.DurationSeconds)
+	}
+
+	template.Name = "Legs"
+	template.Exercises = template.Exercises[:1]
+	assert.NoError(t, service.Update(ctx, testWorkoutUserID, id, &template))
+	updated, err := service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Legs", updated.Name)
+	assert.Equal(t, 1, len(updated.Exercises))
+
+	templates, err := service.GetAll(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(templates))
+
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, id))
+	_, err = service.Instantiate(ctx, testWorkoutUserID, id, start)
+	assert.True(t, db.IsNotFound(err))
+	// Deleting the template leaves the sessions logged from it
+	_, err = workouts.GetByID(ctx, testWorkoutUserID, workoutID)
+	assert.NoError(t, err)
+}
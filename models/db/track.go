@@ -0,0 +1,227 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enpointe/activity/fit"
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/track"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TracksCollection name of the collection used to hold the raw GPS
+// tracks and FIT files uploaded to create workout sessions
+const TracksCollection = "tracks"
+
+// MaxTrackSize the largest track file, in bytes, that can be stored.
+// Tracks are held in a single document so must remain well under the
+// 16MB document limit of MongoDB.
+const MaxTrackSize = 8 << 20
+
+// Track the raw GPS track or FIT file a workout session was created from
+type Track struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	WorkoutID primitive.ObjectID `bson:"workout_id" json:"workout_id"`
+	Filename  string             `bson:"filename" json:"filename"`
+	Format    track.Format       `bson:"format" json:"format"`
+	Uploaded  time.Time          `bson:"uploaded" json:"uploaded"`
+	Data      []byte             `bson:"data" json:"data"`
+}
+
+// CreateFromTrack create a workout session for the user from an uploaded GPX,
+// TCX or FIT file. A GPX or TCX file creates a session holding a single entry
+// with the cardio metrics computed from the trackpoints. A FIT file creates an
+// entry for each session of the activity, using the totals and laps recorded
+// by the device. If no exercise is specified the exercise is found by the name
+// of the sport recorded in the file, ie running. The raw file is stored with
+// the session and can be retrieved via GetTrack.
+func (s *WorkoutService) CreateFromTrack(ctx context.Context, userID string, exerciseID string,
+	filename string, data []byte) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	if len(data) > MaxTrackSize {
+		return "", &ValidationError{fmt.Sprintf("track file exceeds the maximum size of %d bytes", MaxTrackSize)}
+	}
+	format, err := track.DetectFormat(filename, data)
+	if err != nil {
+		return "", &ValidationError{err.Error()}
+	}
+	var w *client.Workout
+	if format == track.FIT {
+		w, err = s.workoutFromFIT(ctx, data, exerciseID)
+	} else {
+		w, err = s.workoutFromTrack(ctx, data, format, exerciseID)
+	}
+	if err != nil {
+		return "", err
+	}
+	workout, err := NewWorkout(uid, w)
+	if err != nil {
+		return "", err
+	}
+	if err = s.checkExercises(ctx, workout.UserID, workout.Exercises...); err != nil {
+		return "", err
+	}
+	if err = s.estimateEnergy(ctx, workout); err != nil {
+		return "", err
+	}
+
+	raw := Track{
+		ID:        primitive.NewObjectID(),
+		UserID:    uid,
+		WorkoutID: workout.ID,
+		Filename:  filename,
+		Format:    format,
+		Uploaded:  time.Now().UTC(),
+		Data:      data,
+	}
+	if _, err = s.tracks.InsertOne(ctx, &raw); err != nil {
+		err = fmt.Errorf("Unable to store track in database, %s", err)
+		log.Error(err)
+		return "", err
+	}
+	workout.TrackID = raw.ID
+	err = s.transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.Collection.InsertOne(ctx, workout); err != nil {
+			return err
+		}
+		if err := s.sessionAdded(ctx, uid, workout.Start); err != nil {
+			return err
+		}
+		return s.workoutChanged(ctx, workout)
+	})
+	if err != nil {
+		log.Errorf("Unable to store workout in database, %s", err)
+		s.tracks.DeleteOne(ctx, bson.M{"_id": raw.ID})
+		return "", err
+	}
+	log.Debugf("created workout %s from %s track %s", workout.ID.Hex(), format, filename)
+	return workout.ID.Hex(), nil
+}
+
+// workoutFromTrack build a workout session from the trackpoints of a GPX or TCX file
+func (s *WorkoutService) workoutFromTrack(ctx context.Context, data []byte, format track.Format,
+	exerciseID string) (*client.Workout, error) {
+	t, err := track.Parse(bytes.NewReader(data), format)
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("unable to read %s track, %s", format, err)}
+	}
+	if len(exerciseID) == 0 {
+		if exerciseID, err = s.exerciseForSport(ctx, t.Sport); err != nil {
+			return nil, err
+		}
+	}
+	summary := t.Summary()
+	return &client.Workout{
+		Start: summary.Start,
+		Notes: strings.TrimSpace(t.Name),
+		Exercises: []client.WorkoutExercise{
+			client.WorkoutExercise{
+				ExerciseID: exerciseID,
+				Cardio: &client.CardioMetrics{
+					DurationSeconds: summary.MovingSeconds,
+					Distance:        summary.DistanceM / MetersPerKilometer,
+					Unit:            UnitKilometer,
+					AvgHeartRate:    summary.AvgHeartRate,
+					MaxHeartRate:    summary.MaxHeartRate,
+					ElevationGain:   summary.ElevationGainM,
+				},
+			},
+		},
+	}, nil
+}
+
+// workoutFromFIT build a workout session from the sessions and laps of a FIT
+// activity file, each session of the activity becomes an exercise of the workout
+func (s *WorkoutService) workoutFromFIT(ctx context.Context, data []byte,
+	exerciseID string) (*client.Workout, error) {
+	a, err := fit.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("unable to read fit file, %s", err)}
+	}
+	if len(a.Sessions) == 0 {
+		return nil, &ValidationError{"fit file contains no sessions"}
+	}
+	w := client.Workout{Start: a.Sessions[0].Start}
+	for i := range a.Sessions {
+		session := &a.Sessions[i]
+		id := exerciseID
+		if len(id) == 0 {
+			if id, err = s.exerciseForSport(ctx, session.SportName()); err != nil {
+				return nil, err
+			}
+		}
+		cardio := fitMetrics(&session.Summary)
+		for _, lap := range a.LapsOf(session) {
+			cardio.Laps = append(cardio.Laps, fitMetrics(&lap.Summary))
+		}
+		w.Exercises = append(w.Exercises, client.WorkoutExercise{ExerciseID: id, Cardio: &cardio})
+	}
+	return &w, nil
+}
+
+// fitMetrics convert the totals of a FIT session or lap into cardio metrics
+func fitMetrics(s *fit.Summary) client.CardioMetrics {
+	return client.CardioMetrics{
+		DurationSeconds: int(s.TimerTime.Seconds()),
+		Distance:        s.DistanceM / MetersPerKilometer,
+		Unit:            UnitKilometer,
+		AvgHeartRate:    s.AvgHeartRate,
+		MaxHeartRate:    s.MaxHeartRate,
+		ElevationGain:   s.TotalAscentM,
+		AvgPower:        s.AvgPower,
+		MaxPower:        s.MaxPower,
+		AvgCadence:      s.AvgCadence,
+		MaxCadence:      s.MaxCadence,
+	}
+}
+
+// exerciseForSport the id of the exercise of the shared catalog named after
+// the sport, names are compared ignoring case
+func (s *WorkoutService) exerciseForSport(ctx context.Context, sport string) (string, error) {
+	sport = strings.TrimSpace(sport)
+	if len(sport) == 0 {
+		return "", &ValidationError{"the file does not record the sport, an exercise must be specified"}
+	}
+	var exercise Exercise
+	err := s.exercises.FindOne(ctx, bson.M{"name": sport, "owner": nil},
+		options.FindOne().SetCollation(caseInsensitive)).Decode(&exercise)
+	if err == mongo.ErrNoDocuments {
+		return "", &ValidationError{fmt.Sprintf("no exercise matches the sport '%s', an exercise must be specified", sport)}
+	}
+	if err != nil {
+		return "", err
+	}
+	return exercise.ID.Hex(), nil
+}
+
+// GetTrack retrieve the raw track file the workout session of the user was created from
+func (s *WorkoutService) GetTrack(ctx context.Context, userID string, id string) (*Track, error) {
+	workout, err := s.find(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if workout.TrackID.IsZero() {
+		return nil, &NotFoundError{fmt.Sprintf("workout '%s' has no track", id)}
+	}
+	var raw Track
+	err = s.tracks.FindOne(ctx, bson.M{"_id": workout.TrackID}).Decode(&raw)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{fmt.Sprintf("track of workout '%s' not found", id)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &raw, nil
+}
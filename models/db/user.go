@@ -3,6 +3,7 @@ package db
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/enpointe/activity/models/client"
 	"github.com/enpointe/activity/perm"
@@ -27,12 +28,27 @@ const UsernameMaxLength int = 30
 // PasswordMinLength the minimum length allowed for a password
 const PasswordMinLength int = 6
 
+// The range of body weights, in kilograms, accepted for a user
+const (
+	MinBodyWeightKg = 20
+	MaxBodyWeightKg = 400
+)
+
+// MinRestingHeartRate the lowest resting heart rate, in beats per minute,
+// accepted for a user
+const MinRestingHeartRate = 25
+
 // User privileges information
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,unique,omitempty" json:"_id,omitempty"`
-	Username  string             `bson:"user_id,unique,omitempty" json:"user_id,omitempty"`
-	Password  string             `bson:"password,omitempty" json:"password,omitempty"`
-	Privilege perm.Privilege     `bson:"privilege,omitempty" json:"privilege,omitempty"` // admin, staff, user
+	ID            primitive.ObjectID `bson:"_id,unique,omitempty" json:"_id,omitempty"`
+	Username      string             `bson:"user_id,unique,omitempty" json:"user_id,omitempty"`
+	Password      string             `bson:"password,omitempty" json:"password,omitempty"`
+	Privilege     perm.Privilege     `bson:"privilege,omitempty" json:"privilege,omitempty"` // admin, staff, user
+	WeightKg      float64            `bson:"weight_kg,omitempty" json:"weight_kg,omitempty"`
+	WeightUnit    string             `bson:"weight_unit,omitempty" json:"weight_unit,omitempty"`
+	MaxHR         int                `bson:"max_hr,omitempty" json:"max_hr,omitempty"`
+	RestingHR     int                `bson:"resting_hr,omitempty" json:"resting_hr,omitempty"`
+	CalendarToken string             `bson:"calendar_token,omitempty" json:"-"` // hash of the calendar feed token
 }
 
 // NewUser transforms the web facing User structure
@@ -41,8 +57,7 @@ type User struct {
 // in ID value is ignored. Username and Password fields
 // are checked for correctness.
 func NewUser(u *client.UserCreate) (*User, error) {
-	if !usernameCheck(u.Username) {
-		err := fmt.Errorf("invalid username specified, '%s'", u.Username)
+	if err := validateUsername(u.Username); err != nil {
 		return nil, err
 	}
 	// NOTE: In a real production environment a stricter password
@@ -62,6 +77,14 @@ func NewUser(u *client.UserCreate) (*User, error) {
 	return &user, err
 }
 
+// validateUsername check the username meets the username requirements
+func validateUsername(username string) error {
+	if !usernameCheck(username) {
+		return fmt.Errorf("invalid username specified, '%s'", username)
+	}
+	return nil
+}
+
 func (u *User) setHashedPassword(password string) error {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -75,11 +98,52 @@ func (u *User) comparePassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
 
-// Convert transform into a client facing UserInfo object
+// Convert transform into a client facing UserInfo object, the body weight
+// is expressed in the unit it was entered in
 func (u *User) Convert() client.UserInfo {
-	return client.UserInfo{
+	info := client.UserInfo{
 		ID:        u.ID.Hex(),
 		Username:  u.Username,
 		Privilege: u.Privilege.String(),
 	}
+	if u.WeightKg > 0 {
+		info.Weight = round(u.WeightKg/unitScale(u.WeightUnit), 2)
+		info.WeightUnit = u.WeightUnit
+	}
+	info.MaxHeartRate = u.MaxHR
+	info.RestingHeartRate = u.RestingHR
+	return info
+}
+
+// NewBodyWeight validate the body weight of a user, returning the weight in
+// kilograms along with the unit it was entered in
+func NewBodyWeight(w *client.BodyWeight) (float64, string, error) {
+	unit := strings.ToLower(w.Unit)
+	switch unit {
+	case "":
+		unit = UnitKilogram
+	case UnitKilogram, UnitPound:
+	default:
+		return 0, "", &ValidationError{fmt.Sprintf("unsupported weight unit '%s', valid units are kg, lb", w.Unit)}
+	}
+	kg := w.Weight * unitScale(unit)
+	if kg < MinBodyWeightKg || kg > MaxBodyWeightKg {
+		return 0, "", &ValidationError{fmt.Sprintf("body weight must be between %d and %d kg",
+			MinBodyWeightKg, MaxBodyWeightKg)}
+	}
+	return kg, unit, nil
+}
+
+// ValidateHeartRates ensure the maximum and resting heart rates of a user are
+// plausible, the resting heart rate must be below the maximum heart rate
+func ValidateHeartRates(h *client.HeartRates) error {
+	if h.Max < MinRestingHeartRate || h.Max > MaxHeartRate {
+		return &ValidationError{fmt.Sprintf("maximum heart rate must be between %d and %d",
+			MinRestingHeartRate, MaxHeartRate)}
+	}
+	if h.Resting < MinRestingHeartRate || h.Resting >= h.Max {
+		return &ValidationError{fmt.Sprintf("resting heart rate must be at least %d and below the maximum heart rate",
+			MinRestingHeartRate)}
+	}
+	return nil
 }
@@ -28,12 +28,12 @@ type UserService struct {
 }
 
 // NewUserService create a new instance of the User Service
-func NewUserService(database *mongo.Database) (*UserService, error) {
+func NewUserService(database *mongo.Database, opts ...ServiceOption) (*UserService, error) {
 	// Set majority write concern
 	//wMajority := writeconcern.New(writeconcern.WMajority())
 	//collectionOptions := &options.CollectionOptions{WriteConcern: wMajority}
 	//collection := database.Collection(UsersCollection, collectionOptions)
-	collection := database.Collection(UsersCollection)
+	collection := database.Collection(CollectionName(UsersCollection, opts...))
 
 	return &UserService{
 		Collection: collection}, nil
@@ -81,12 +81,19 @@ func (s *UserService) Create(ctx context.Context, user *client.UserCreate) (stri
 	cursor := s.Collection.FindOne(ctx, filter)
 	if err = cursor.Err(); err == nil {
 		// A match for that user already exists
-		err = fmt.Errorf("A entry matching the userID '%s' already exists", user.Username)
+		err = &ConflictError{fmt.Sprintf("A entry matching the userID '%s' already exists", user.Username)}
 		log.Debug(err)
 		return "", err
 	}
 
+	// The unique index on user_id guards against a concurrent create
+	// of the same user slipping in between the check above and the insert
 	result, err := s.Collection.InsertOne(ctx, &u)
+	if IsDuplicateKeyError(err) {
+		err = &ConflictError{fmt.Sprintf("A entry matching the userID '%s' already exists", user.Username)}
+		log.Debug(err)
+		return "", err
+	}
 	if err != nil {
 		log.WithFields(log.Fields{
 			"document": u,
@@ -240,7 +247,7 @@ func (s *UserService) GetAll(ctx context.Context) ([]*client.UserInfo, error) {
 		var elem User
 		err := cursor.Decode(&elem)
 		if err != nil {
-			log.Errorf("failed to decode %s", elem)
+			log.Errorf("failed to decode %v", elem)
 			return nil, err
 		}
 
@@ -266,6 +273,9 @@ func (s *UserService) update(ctx context.Context, filter bson.M, update bson.M)
 			"filter": filter,
 			"update": update,
 		}).Debugf("user collection UpdateOne()) failed: %s", err)
+		if IsDuplicateKeyError(err) {
+			err = &ConflictError{"a user with the requested username already exists"}
+		}
 		return 0, err
 	}
 	if updateResult.ModifiedCount != 1 {
@@ -292,6 +302,9 @@ func (s *UserService) Update(ctx context.Context, u *client.UserUpdate) (int, er
 	}}
 	cnt, err := s.update(ctx, filter, update)
 	if err != nil {
+		if IsConflict(err) {
+			return 0, err
+		}
 		err = fmt.Errorf("Failed to update user '%s', %s", u.ID, err)
 		return 0, err
 	}
@@ -318,6 +331,51 @@ func (s *UserService) UpdatePassword(ctx context.Context, passInfo *client.Passw
 	return int(cnt), nil
 }
 
+// SetBodyWeight set the body weight of the user, used to estimate the energy
+// expended during the workout sessions subsequently logged
+func (s *UserService) SetBodyWeight(ctx context.Context, id string, w *client.BodyWeight) error {
+	idPrimitive, err := objectID(id, "user")
+	if err != nil {
+		return err
+	}
+	kg, unit, err := NewBodyWeight(w)
+	if err != nil {
+		return err
+	}
+	result, err := s.Collection.UpdateOne(ctx, bson.M{"_id": idPrimitive},
+		bson.M{"$set": bson.M{"weight_kg": kg, "weight_unit": unit}})
+	if err != nil {
+		err = fmt.Errorf("Failed to update user '%s', %s", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("user '%s' not found", id)}
+	}
+	return nil
+}
+
+// SetHeartRates set the maximum and resting heart rates of the user, used to
+// compute the heart rate zones and training load of their workout sessions
+func (s *UserService) SetHeartRates(ctx context.Context, id string, h *client.HeartRates) error {
+	idPrimitive, err := objectID(id, "user")
+	if err != nil {
+		return err
+	}
+	if err = ValidateHeartRates(h); err != nil {
+		return err
+	}
+	result, err := s.Collection.UpdateOne(ctx, bson.M{"_id": idPrimitive},
+		bson.M{"$set": bson.M{"max_hr": h.Max, "resting_hr": h.Resting}})
+	if err != nil {
+		err = fmt.Errorf("Failed to update user '%s', %s", id, err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return &NotFoundError{fmt.Sprintf("user '%s' not found", id)}
+	}
+	return nil
+}
+
 // Validate validate the credentials of the user
 func (s *UserService) Validate(ctx context.Context, c *client.Credentials) (*client.UserInfo, error) {
 
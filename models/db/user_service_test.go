@@ -138,6 +138,7 @@ func TestCreateDuplicateUser(t *testing.T) {
 	// Attempt to add same user
 	id, err = userService.Create(ctx, &user)
 	assert.Error(t, err)
+	assert.True(t, db.IsConflict(err))
 	assert.Contains(t, err.Error(), "already exists")
 	assert.NotNil(t, id)
 }
@@ -53,3 +53,27 @@ func TestNewUserValidationFailures(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid password")
 	}
 }
+
+func TestNewBodyWeight(t *testing.T) {
+	kg, unit, err := db.NewBodyWeight(&client.BodyWeight{Weight: 80})
+	assert.NoError(t, err)
+	assert.Equal(t, 80.0, kg)
+	assert.Equal(t, db.UnitKilogram, unit)
+
+	kg, unit, err = db.NewBodyWeight(&client.BodyWeight{Weight: 200, Unit: "LB"})
+	assert.NoError(t, err)
+	assert.InDelta(t, 90.718, kg, 0.001)
+	assert.Equal(t, db.UnitPound, unit)
+
+	_, _, err = db.NewBodyWeight(&client.BodyWeight{Weight: 10})
+	assert.True(t, db.IsValidation(err))
+	_, _, err = db.NewBodyWeight(&client.BodyWeight{Weight: 80, Unit: "stone"})
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestValidateHeartRates(t *testing.T) {
+	assert.NoError(t, db.ValidateHeartRates(&client.HeartRates{Max: 190, Resting: 55}))
+	assert.True(t, db.IsValidation(db.ValidateHeartRates(&client.HeartRates{Max: 300, Resting: 55})))
+	assert.True(t, db.IsValidation(db.ValidateHeartRates(&client.HeartRates{Max: 190, Resting: 190})))
+	assert.True(t, db.IsValidation(db.ValidateHeartRates(&client.HeartRates{Max: 190})))
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// KilogramsPerPound the number of kilograms in a pound
+const KilogramsPerPound = 0.45359237
+
+// The units a load can be expressed in
+const (
+	UnitKilogram = "kg"
+	UnitPound    = "lb"
+)
+
+// MaxRPE the maximum value of the rate of perceived exertion scale
+const MaxRPE = 10
+
+// Workout a workout session logged by a user. Version is incremented
+// every time the session is modified and is used to detect concurrent
+// modification of the session.
+type Workout struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Start      time.Time          `bson:"start" json:"start"`
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Exercises  []WorkoutExercise  `bson:"exercises" json:"exercises"`
+	TrackID    primitive.ObjectID `bson:"track_id,omitempty" json:"track_id,omitempty"`
+	TemplateID primitive.ObjectID `bson:"template_id,omitempty" json:"template_id,omitempty"`
+	Version    int                `bson:"version" json:"version"`
+}
+
+// WorkoutExercise an exercise performed during a workout session. Kcal is
+// the estimated energy expended, computed when the session is stored.
+type WorkoutExercise struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id"`
+	ExerciseID primitive.ObjectID `bson:"exercise_id" json:"exercise_id"`
+	Intensity  string             `bson:"intensity,omitempty" json:"intensity,omitempty"`
+	Sets       []StrengthSet      `bson:"sets,omitempty" json:"sets,omitempty"`
+	Cardio     *CardioMetrics     `bson:"cardio,omitempty" json:"cardio,omitempty"`
+	Kcal       float64            `bson:"kcal,omitempty" json:"kcal,omitempty"`
+}
+
+// StrengthSet a single set of a strength exercise. The load is stored
+// in kilograms, Unit records the unit the load was entered in.
+type StrengthSet struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	Reps        int                `bson:"reps" json:"reps"`
+	LoadKg      float64            `bson:"load_kg,omitempty" json:"load_kg,omitempty"`
+	Unit        string             `bson:"unit,omitempty" json:"unit,omitempty"`
+	RestSeconds int                `bson:"rest_seconds,omitempty" json:"rest_seconds,omitempty"`
+	RPE         float64            `bson:"rpe,omitempty" json:"rpe,omitempty"`
+}
+
+// NewWorkout transforms the web facing Workout structure to a database
+// compatible Workout structure for the specified user. New IDs are assigned
+// to the workout, its exercises and their sets, any passed in values are
+// ignored. If no start time is specified the current time is used. The
+// template the session was logged from, if any, is recorded.
+func NewWorkout(userID primitive.ObjectID, w *client.Workout) (*Workout, error) {
+	workout := Workout{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Start:     w.Start.UTC(),
+		Notes:     strings.TrimSpace(w.Notes),
+		Exercises: []WorkoutExercise{},
+	}
+	if w.Start.IsZero() {
+		workout.Start = time.Now().UTC()
+	}
+	if len(w.TemplateID) > 0 {
+		id, err := primitive.ObjectIDFromHex(w.TemplateID)
+		if err != nil {
+			return nil, &ValidationError{fmt.Sprintf("invalid template id '%s'", w.TemplateID)}
+		}
+		workout.TemplateID = id
+	}
+	for i := range w.Exercises {
+		e, err := NewWorkoutExercise(&w.Exercises[i])
+		if err != nil {
+			return nil, err
+		}
+		workout.Exercises = append(workout.Exercises, *e)
+	}
+	return &workout, nil
+}
+
+// NewWorkoutExercise transforms the web facing WorkoutExercise structure to
+// a database compatible WorkoutExercise structure, assigning new IDs to the
+// entry and its sets. Any cardio metrics are normalized to SI units.
+func NewWorkoutExercise(e *client.WorkoutExercise) (*WorkoutExercise, error) {
+	exerciseID, err := primitive.ObjectIDFromHex(e.ExerciseID)
+	if err != nil {
+		return nil, &ValidationError{fmt.Sprintf("invalid exercise id '%s'", e.ExerciseID)}
+	}
+	entry := WorkoutExercise{
+		ID:         primitive.NewObjectID(),
+		ExerciseID: exerciseID,
+		Intensity:  strings.ToLower(e.Intensity),
+	}
+	if len(entry.Intensity) > 0 && !validIntensity(entry.Intensity) {
+		return nil, &ValidationError{fmt.Sprintf("invalid intensity '%s', must be one of %s, %s or %s",
+			e.Intensity, IntensityLight, IntensityModerate, IntensityVigorous)}
+	}
+	for i := range e.Sets {
+		set, err := NewStrengthSet(&e.Sets[i])
+		if err != nil {
+			return nil, err
+		}
+		entry.Sets = append(entry.Sets, *set)
+	}
+	if e.Cardio != nil {
+		entry.Cardio, err = NewCardioMetrics(e.Cardio)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
+
+// NewStrengthSet transforms the web facing StrengthSet structure to a
+// database compatible StrengthSet structure, assigning a new ID. The load
+// is converted to kilograms.
+func NewStrengthSet(s *client.StrengthSet) (*StrengthSet, error) {
+	if s.Reps < 0 {
+		return nil, &ValidationError{"reps can not be negative"}
+	}
+	if s.Load < 0 {
+		return nil, &ValidationError{"load can not be negative"}
+	}
+	if s.RestSeconds < 0 {
+		return nil, &ValidationError{"rest time can not be negative"}
+	}
+	if s.RPE != 0 && (s.RPE < 1 || s.RPE > MaxRPE) {
+		return nil, &ValidationError{fmt.Sprintf("rpe must be between 1 and %d", MaxRPE)}
+	}
+	set := StrengthSet{
+		ID:          primitive.NewObjectID(),
+		Reps:        s.Reps,
+		RestSeconds: s.RestSeconds,
+		RPE:         s.RPE,
+	}
+	switch strings.ToLower(s.Unit) {
+	case "", UnitKilogram:
+		set.LoadKg = s.Load
+		set.Unit = UnitKilogram
+	case UnitPound:
+		set.LoadKg = s.Load * KilogramsPerPound
+		set.Unit = UnitPound
+	default:
+		return nil, &ValidationError{fmt.Sprintf("unsupported load unit '%s', valid units are kg, lb", s.Unit)}
+	}
+	return &set, nil
+}
+
+// Convert transform into a client facing Workout object
+func (w *Workout) Convert() client.Workout {
+	workout := client.Workout{
+		ID:     w.ID.Hex(),
+		UserID: w.UserID.Hex(),
+		Start:  w.Start,
+		Notes:  w.Notes,
+	}
+	if !w.TrackID.IsZero() {
+		workout.TrackID = w.TrackID.Hex()
+	}
+	if !w.TemplateID.IsZero() {
+		workout.TemplateID = w.TemplateID.Hex()
+	}
+	for i := range w.Exercises {
+		workout.Exercises = append(workout.Exercises, w.Exercises[i].Convert())
+	}
+	return workout
+}
+
+// Convert transform into a client facing WorkoutExercise object
+func (e *WorkoutExercise) Convert() client.WorkoutExercise {
+	entry := client.WorkoutExercise{
+		ID:         e.ID.Hex(),
+		ExerciseID: e.ExerciseID.Hex(),
+		Intensity:  e.Intensity,
+		Kcal:       e.Kcal,
+	}
+	for i := range e.Sets {
+		entry.Sets = append(entry.Sets, e.Sets[i].Convert())
+	}
+	if e.Cardio != nil {
+		cardio := e.Cardio.Convert()
+		entry.Cardio = &cardio
+	}
+	return entry
+}
+
+// Convert transform into a client facing StrengthSet object, the
+// load is expressed in the unit it was entered in
+func (s *StrengthSet) Convert() client.StrengthSet {
+	load := s.LoadKg
+	if s.Unit == UnitPound {
+		load = round(s.LoadKg/KilogramsPerPound, 3)
+	}
+	return client.StrengthSet{
+		ID:          s.ID.Hex(),
+		Reps:        s.Reps,
+		Load:        load,
+		Unit:        s.Unit,
+		RestSeconds: s.RestSeconds,
+		RPE:         s.RPE,
+	}
+}
+
+// Volume the total weight lifted in kilograms for the set, reps x load
+func (s *StrengthSet) Volume() float64 {
+	return float64(s.Reps) * s.LoadKg
+}
+
+// round the value to the specified number of decimal places
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+// exercise returns the entry of the workout with the specified ID
+func (w *Workout) exercise(id primitive.ObjectID) (*WorkoutExercise, int) {
+	for i := range w.Exercises {
+		if w.Exercises[i].ID == id {
+			return &w.Exercises[i], i
+		}
+	}
+	return nil, -1
+}
+
+// set returns the set with the specified ID
+func (e *WorkoutExercise) set(id primitive.ObjectID) int {
+	for i := range e.Sets {
+		if e.Sets[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,410 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorkoutService holds a entry to the collection of workout logs in the database
+type WorkoutService struct {
+	Collection *mongo.Collection
+	exercises  *mongo.Collection
+	tracks     *mongo.Collection
+	records    *mongo.Collection
+	streaks    *mongo.Collection
+	users      *mongo.Collection
+	goals      *GoalService
+}
+
+// NewWorkoutService create a new instance of the Workout Service
+func NewWorkoutService(database *mongo.Database, opts ...ServiceOption) (*WorkoutService, error) {
+	goals, err := NewGoalService(database, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &WorkoutService{
+		Collection: database.Collection(CollectionName(LogsCollection, opts...)),
+		exercises:  database.Collection(CollectionName(ExerciseCollection, opts...)),
+		tracks:     database.Collection(CollectionName(TracksCollection, opts...)),
+		records:    database.Collection(CollectionName(RecordsCollection, opts...)),
+		streaks:    database.Collection(CollectionName(StreaksCollection, opts...)),
+		users:      database.Collection(CollectionName(UsersCollection, opts...)),
+		goals:      goals,
+	}, nil
+}
+
+// objectID convert the hex representation of an id into a ObjectID. A
+// malformed id can never match an entry so it is reported as not found.
+func objectID(hexid string, kind string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(hexid)
+	if err != nil {
+		return id, &NotFoundError{fmt.Sprintf("%s '%s' not found", kind, hexid)}
+	}
+	return id, nil
+}
+
+// checkExercises ensure each exercise referenced by the entries exists and is
+// visible to the user, part of the shared catalog or one of their private exercises
+func (s *WorkoutService) checkExercises(ctx context.Context, userID primitive.ObjectID,
+	entries ...WorkoutExercise) error {
+	for _, e := range entries {
+		cnt, err := s.exercises.CountDocuments(ctx, bson.M{"_id": e.ExerciseID, "owner": visibleTo(userID)})
+		if err != nil {
+			return err
+		}
+		if cnt == 0 {
+			return &ValidationError{fmt.Sprintf("exercise '%s' does not exist", e.ExerciseID.Hex())}
+		}
+	}
+	return nil
+}
+
+// Create add a new workout session for the user to the database
+func (s *WorkoutService) Create(ctx context.Context, userID string, w *client.Workout) (string, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", &ValidationError{fmt.Sprintf("invalid user id '%s'", userID)}
+	}
+	workout, err := NewWorkout(uid, w)
+	if err != nil {
+		return "", err
+	}
+	if err = s.checkExercises(ctx, workout.UserID, workout.Exercises...); err != nil {
+		return "", err
+	}
+	if err = s.estimateEnergy(ctx, workout); err != nil {
+		return "", err
+	}
+	// The errors of the driver are returned as is so the transaction can
+	// detect a standalone server
+	err = s.transaction(ctx, func(ctx context.Context) error {
+		if _, err := s.Collection.InsertOne(ctx, workout); err != nil {
+			return err
+		}
+		if err := s.sessionAdded(ctx, workout.UserID, workout.Start); err != nil {
+			return err
+		}
+		return s.workoutChanged(ctx, workout)
+	})
+	if err != nil {
+		log.Errorf("Unable to store workout in database, %s", err)
+		return "", err
+	}
+	return workout.ID.Hex(), nil
+}
+
+// workoutChanged update the personal records and goals of the user affected
+// by the creation, modification or removal of a workout session
+func (s *WorkoutService) workoutChanged(ctx context.Context, workout *Workout) error {
+	ids, err := s.affectedExercises(ctx, workout.ID, workout.Exercises)
+	if err != nil {
+		return err
+	}
+	if err = s.updateRecords(ctx, workout.UserID, ids...); err != nil {
+		return err
+	}
+	return s.goals.workoutChanged(ctx, workout.UserID, workout.Start)
+}
+
+// find retrieve the workout session of the user with the specified id
+func (s *WorkoutService) find(ctx context.Context, userID string, id string) (*Workout, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	wid, err := objectID(id, "workout")
+	if err != nil {
+		return nil, err
+	}
+	var workout Workout
+	err = s.Collection.FindOne(ctx, bson.M{"_id": wid, "user_id": uid}).Decode(&workout)
+	if err == mongo.ErrNoDocuments {
+		return nil, &NotFoundError{fmt.Sprintf("workout '%s' not found", id)}
+	}
+	if err != nil {
+		log.Errorf("failed to retrieve workout %s, %s", id, err)
+		return nil, err
+	}
+	return &workout, nil
+}
+
+// replace store the modified workout session and update the personal records
+// of the user. The replace only succeeds if the session has not been modified
+// since it was read, otherwise a ConflictError is returned and the caller
+// should retry with fresh data.
+func (s *WorkoutService) replace(ctx context.Context, workout *Workout) error {
+	if err := s.estimateEnergy(ctx, workout); err != nil {
+		return err
+	}
+	filter := bson.M{"_id": workout.ID, "version": workout.Version}
+	workout.Version++
+	return s.transaction(ctx, func(ctx context.Context) error {
+		result, err := s.Collection.ReplaceOne(ctx, filter, workout)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"filter": filter,
+			}).Debugf("logs collection ReplaceOne() failed: %s", err)
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return &ConflictError{fmt.Sprintf("workout '%s' was modified by another request", workout.ID.Hex())}
+		}
+		return s.workoutChanged(ctx, workout)
+	})
+}
+
+// GetByID retrieve the workout session of the user with the specified id
+func (s *WorkoutService) GetByID(ctx context.Context, userID string, id string) (*client.Workout, error) {
+	workout, err := s.find(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	cWorkout := workout.Convert()
+	return &cWorkout, nil
+}
+
+// GetAll retrieve the workout sessions of the user, most recent first.
+// If from or to are non zero only sessions started within that period
+// are returned.
+func (s *WorkoutService) GetAll(ctx context.Context, userID string, from time.Time,
+	to time.Time) ([]*client.Workout, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return nil, err
+	}
+	filter := bson.M{"user_id": uid}
+	period := bson.M{}
+	if !from.IsZero() {
+		period["$gte"] = from
+	}
+	if !to.IsZero() {
+		period["$lt"] = to
+	}
+	if len(period) > 0 {
+		filter["start"] = period
+	}
+	cursor, err := s.Collection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "start", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []*client.Workout{}
+	for cursor.Next(ctx) {
+		var elem Workout
+		if err := cursor.Decode(&elem); err != nil {
+			log.Errorf("failed to decode workout %s", err)
+			return nil, err
+		}
+		workout := elem.Convert()
+		results = append(results, &workout)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Delete remove the workout session of the user with the specified id along
+// with any track it was created from, updating the personal records of the user
+func (s *WorkoutService) Delete(ctx context.Context, userID string, id string) error {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return err
+	}
+	wid, err := objectID(id, "workout")
+	if err != nil {
+		return err
+	}
+	err = s.transaction(ctx, func(ctx context.Context) error {
+		var workout Workout
+		err := s.Collection.FindOneAndDelete(ctx, bson.M{"_id": wid, "user_id": uid}).Decode(&workout)
+		if err == mongo.ErrNoDocuments {
+			return &NotFoundError{fmt.Sprintf("workout '%s' not found", id)}
+		}
+		if err != nil {
+			return err
+		}
+		if _, err = s.tracks.DeleteMany(ctx, bson.M{"workout_id": wid}); err != nil {
+			return err
+		}
+		if err = s.sessionRemoved(ctx, uid, workout.Start); err != nil {
+			return err
+		}
+		return s.workoutChanged(ctx, &workout)
+	})
+	if err != nil && !IsNotFound(err) {
+		log.Errorf("failed to delete workout %s, %s", id, err)
+	}
+	return err
+}
+
+// DeleteByUser remove all workout sessions, their tracks, the personal records,
+// the activity streaks and the goals of the user, returning the number of sessions deleted
+func (s *WorkoutService) DeleteByUser(ctx context.Context, userID string) (int, error) {
+	uid, err := objectID(userID, "user")
+	if err != nil {
+		return 0, err
+	}
+	result, err := s.Collection.DeleteMany(ctx, bson.M{"user_id": uid})
+	if err != nil {
+		return 0, err
+	}
+	if _, err = s.tracks.DeleteMany(ctx, bson.M{"user_id": uid}); err != nil {
+		return 0, err
+	}
+	if _, err = s.records.DeleteMany(ctx, bson.M{"user_id": uid}); err != nil {
+		return 0, err
+	}
+	if _, err = s.streaks.DeleteOne(ctx, bson.M{"_id": uid}); err != nil {
+		return 0, err
+	}
+	if err = s.goals.deleteByUser(ctx, uid); err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
+
+// AddExercise append an exercise to the end of the workout session,
+// returning the id of the new entry
+func (s *WorkoutService) AddExercise(ctx context.Context, userID string, id string,
+	e *client.WorkoutExercise) (string, error) {
+	entry, err := NewWorkoutExercise(e)
+	if err != nil {
+		return "", err
+	}
+	workout, err := s.find(ctx, userID, id)
+	if err != nil {
+		return "", err
+	}
+	if err = s.checkExercises(ctx, workout.UserID, *entry); err != nil {
+		return "", err
+	}
+	workout.Exercises = append(workout.Exercises, *entry)
+	if err = s.replace(ctx, workout); err != nil {
+		return "", err
+	}
+	return entry.ID.Hex(), nil
+}
+
+// RemoveExercise remove an exercise, and all its sets, from the workout session
+func (s *WorkoutService) RemoveExercise(ctx context.Context, userID string, id string,
+	entryID string) error {
+	workout, entry, err := s.findExercise(ctx, userID, id, entryID)
+	if err != nil {
+		return err
+	}
+	_, i := workout.exercise(entry.ID)
+	workout.Exercises = append(workout.Exercises[:i], workout.Exercises[i+1:]...)
+	return s.replace(ctx, workout)
+}
+
+// findExercise retrieve the workout session and the exercise entry within it
+func (s *WorkoutService) findExercise(ctx context.Context, userID string, id string,
+	entryID string) (*Workout, *WorkoutExercise, error) {
+	eid, err := objectID(entryID, "workout exercise")
+	if err != nil {
+		return nil, nil, err
+	}
+	workout, err := s.find(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, _ := workout.exercise(eid)
+	if entry == nil {
+		return nil, nil, &NotFoundError{fmt.Sprintf("workout exercise '%s' not found", entryID)}
+	}
+	return workout, entry, nil
+}
+
+// AddSet append a set to an exercise of the workout session,
+// returning the id of the new set
+func (s *WorkoutService) AddSet(ctx context.Context, userID string, id string, entryID string,
+	set *client.StrengthSet) (string, error) {
+	newSet, err := NewStrengthSet(set)
+	if err != nil {
+		return "", err
+	}
+	workout, entry, err := s.findExercise(ctx, userID, id, entryID)
+	if err != nil {
+		return "", err
+	}
+	entry.Sets = append(entry.Sets, *newSet)
+	if err = s.replace(ctx, workout); err != nil {
+		return "", err
+	}
+	return newSet.ID.Hex(), nil
+}
+
+// UpdateSet replace the values of an existing set, the set keeps its
+// id and position within the exercise
+func (s *WorkoutService) UpdateSet(ctx context.Context, userID string, id string, entryID string,
+	setID string, set *client.StrengthSet) error {
+	updated, err := NewStrengthSet(set)
+	if err != nil {
+		return err
+	}
+	workout, entry, err := s.findExercise(ctx, userID, id, entryID)
+	if err != nil {
+		return err
+	}
+	i, err := setIndex(entry, setID)
+	if err != nil {
+		return err
+	}
+	updated.ID = entry.Sets[i].ID
+	entry.Sets[i] = *updated
+	return s.replace(ctx, workout)
+}
+
+// RemoveSet remove a set from an exercise of the workout session
+func (s *WorkoutService) RemoveSet(ctx context.Context, userID string, id string, entryID string,
+	setID string) error {
+	workout, entry, err := s.findExercise(ctx, userID, id, entryID)
+	if err != nil {
+		return err
+	}
+	i, err := setIndex(entry, setID)
+	if err != nil {
+		return err
+	}
+	entry.Sets = append(entry.Sets[:i], entry.Sets[i+1:]...)
+	return s.replace(ctx, workout)
+}
+
+// SetCardio replace the cardio metrics recorded for an exercise of the workout session
+func (s *WorkoutService) SetCardio(ctx context.Context, userID string, id string, entryID string,
+	metrics *client.CardioMetrics) error {
+	cardio, err := NewCardioMetrics(metrics)
+	if err != nil {
+		return err
+	}
+	workout, entry, err := s.findExercise(ctx, userID, id, entryID)
+	if err != nil {
+		return err
+	}
+	entry.Cardio = cardio
+	return s.replace(ctx, workout)
+}
+
+// setIndex the position of the set within the exercise entry
+func setIndex(entry *WorkoutExercise, setID string) (int, error) {
+	sid, err := objectID(setID, "set")
+	if err != nil {
+		return -1, err
+	}
+	i := entry.set(sid)
+	if i < 0 {
+		return -1, &NotFoundError{fmt.Sprintf("set '%s' not found", setID)}
+	}
+	return i, nil
+}
@@ -0,0 +1,203 @@
+package db_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const testWorkoutUserID = "5db8e02b0e7aa732afd7fbc4"
+const testSquatID = "5dab5fa871aab123354e5cb6"
+const testLungeID = "5dab5f8871aab123354e5cb5"
+const testRunningID = "5db8ddabee74c3c19010b4f6"
+
+// SetupWorkout Setup the database for testing by clearing the logs
+// collection and loading the predefined exercises
+func SetupWorkout(t *testing.T) (*db.WorkoutService, *db.ExerciseService) {
+	ctx := context.TODO()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(testDatabaseURL))
+	assert.NoError(t, err)
+	service, err := db.NewWorkoutService(client.Database(testDatabase))
+	assert.NoError(t, err)
+	err = service.Collection.Drop(ctx)
+	assert.NoError(t, err)
+	return service, SetupExercise(t, true, true)
+}
+
+// TeardownWorkout remove the workouts and exercises created by the test
+func TeardownWorkout(t *testing.T, service *db.WorkoutService, ex *db.ExerciseService) {
+	err := service.Collection.Drop(context.TODO())
+	assert.NoError(t, err)
+	TeardownExercise(t, ex)
+}
+
+func TestCreateWorkout(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	w := client.Workout{
+		Start: time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC),
+		Exercises: []client.WorkoutExercise{
+			client.WorkoutExercise{
+				ExerciseID: testSquatID,
+				Sets:       []client.StrengthSet{client.StrengthSet{Reps: 5, Load: 225, Unit: "lb"}},
+			},
+		},
+	}
+	id, err := service.Create(ctx, testWorkoutUserID, &w)
+	assert.NoError(t, err)
+
+	workout, err := service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, testSquatID, workout.Exercises[0].ExerciseID)
+	assert.Equal(t, 225.0, workout.Exercises[0].Sets[0].Load)
+
+	// The workout is only visible to the user that logged it
+	_, err = service.GetByID(ctx, "5db8e02b0e7aa732afd7fbc1", id)
+	assert.True(t, db.IsNotFound(err))
+
+	// Exercises must exist
+	w.Exercises[0].ExerciseID = "5db8e02b0e7aa732afd7fbc1"
+	_, err = service.Create(ctx, testWorkoutUserID, &w)
+	assert.True(t, db.IsValidation(err))
+}
+
+func TestGetAllWorkouts(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	start := time.Date(2019, 11, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err := service.Create(ctx, testWorkoutUserID,
+			&client.Workout{Start: start.AddDate(0, 0, i)})
+		assert.NoError(t, err)
+	}
+	workouts, err := service.GetAll(ctx, testWorkoutUserID, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	if assert.Equal(t, 3, len(workouts)) {
+		assert.True(t, workouts[0].Start.After(workouts[1].Start))
+	}
+	workouts, err = service.GetAll(ctx, testWorkoutUserID, start.AddDate(0, 0, 1), start.AddDate(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(workouts))
+
+	cnt, err := service.DeleteByUser(ctx, testWorkoutUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cnt)
+}
+
+func TestWorkoutSets(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	id, err := service.Create(ctx, testWorkoutUserID, &client.Workout{})
+	assert.NoError(t, err)
+	squat, err := service.AddExercise(ctx, testWorkoutUserID, id,
+		&client.WorkoutExercise{ExerciseID: testSquatID})
+	assert.NoError(t, err)
+	lunge, err := service.AddExercise(ctx, testWorkoutUserID, id,
+		&client.WorkoutExercise{ExerciseID: testLungeID})
+	assert.NoError(t, err)
+
+	set1, err := service.AddSet(ctx, testWorkoutUserID, id, squat, &client.StrengthSet{Reps: 5, Load: 100})
+	assert.NoError(t, err)
+	set2, err := service.AddSet(ctx, testWorkoutUserID, id, squat, &client.StrengthSet{Reps: 5, Load: 105})
+	assert.NoError(t, err)
+	_, err = service.AddSet(ctx, testWorkoutUserID, id, squat, &client.StrengthSet{Reps: 5, RPE: 12})
+	assert.True(t, db.IsValidation(err))
+
+	err = service.UpdateSet(ctx, testWorkoutUserID, id, squat, set1,
+		&client.StrengthSet{Reps: 3, Load: 110, RestSeconds: 180, RPE: 9})
+	assert.NoError(t, err)
+	err = service.RemoveSet(ctx, testWorkoutUserID, id, squat, set2)
+	assert.NoError(t, err)
+	err = service.RemoveSet(ctx, testWorkoutUserID, id, squat, set2)
+	assert.True(t, db.IsNotFound(err))
+	err = service.RemoveExercise(ctx, testWorkoutUserID, id, lunge)
+	assert.NoError(t, err)
+
+	workout, err := service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(workout.Exercises)) && assert.Equal(t, 1, len(workout.Exercises[0].Sets)) {
+		set := workout.Exercises[0].Sets[0]
+		assert.Equal(t, set1, set.ID)
+		assert.Equal(t, 3, set.Reps)
+		assert.Equal(t, 180, set.RestSeconds)
+	}
+
+	err = service.Delete(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	err = service.Delete(ctx, testWorkoutUserID, id)
+	assert.True(t, db.IsNotFound(err))
+}
+
+func TestWorkoutCardio(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+
+	id, err := service.Create(ctx, testWorkoutUserID, &client.Workout{
+		Exercises: []client.WorkoutExercise{
+			client.WorkoutExercise{
+				ExerciseID: testRunningID,
+				Cardio:     &client.CardioMetrics{DurationSeconds: 1800, Distance: 5},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	workout, err := service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 360.0, workout.Exercises[0].Cardio.Pace)
+
+	entry := workout.Exercises[0].ID
+	err = service.SetCardio(ctx, testWorkoutUserID, id, entry,
+		&client.CardioMetrics{DurationSeconds: 1440, Distance: 3, Unit: "mi", AvgHeartRate: 150})
+	assert.NoError(t, err)
+	err = service.SetCardio(ctx, testWorkoutUserID, id, entry, &client.CardioMetrics{Unit: "yd"})
+	assert.True(t, db.IsValidation(err))
+	workout, err = service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, workout.Exercises[0].Cardio.Distance)
+	assert.Equal(t, 7.5, workout.Exercises[0].Cardio.Speed)
+	assert.Equal(t, 150, workout.Exercises[0].Cardio.AvgHeartRate)
+}
+
+// TestWorkoutStandalone the writes run within a transaction fall back to
+// running without one on a standalone server, which does not support them
+func TestWorkoutStandalone(t *testing.T) {
+	service, ex := SetupWorkout(t)
+	defer TeardownWorkout(t, service, ex)
+	ctx := context.TODO()
+	var status bson.M
+	err := service.Collection.Database().RunCommand(ctx, bson.M{"isMaster": 1}).Decode(&status)
+	assert.NoError(t, err)
+	if _, replicaSet := status["setName"]; replicaSet {
+		t.Skip("connected to a replica set member, transactions are supported")
+	}
+
+	id, err := service.Create(ctx, testWorkoutUserID, &client.Workout{
+		Start:     time.Date(2019, 11, 20, 18, 30, 0, 0, time.UTC),
+		Exercises: []client.WorkoutExercise{client.WorkoutExercise{ExerciseID: testSquatID}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, service.Delete(ctx, testWorkoutUserID, id))
+	assert.True(t, db.IsNotFound(service.Delete(ctx, testWorkoutUserID, id)))
+
+	gpx, err := ioutil.ReadFile("../../track/testdata/run.gpx")
+	assert.NoError(t, err)
+	id, err = service.CreateFromTrack(ctx, testWorkoutUserID, testRunningID, "run.gpx", gpx)
+	assert.NoError(t, err)
+	_, err = service.GetByID(ctx, testWorkoutUserID, id)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+package db_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/models/client"
+	"github.com/enpointe/activity/models/db"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewStrengthSet(t *testing.T) {
+	set, err := db.NewStrengthSet(&client.StrengthSet{Reps: 5, Load: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, db.UnitKilogram, set.Unit)
+	assert.Equal(t, 100.0, set.LoadKg)
+	assert.Equal(t, 500.0, set.Volume())
+
+	// Pounds are stored as kilograms but reported in the unit entered
+	set, err = db.NewStrengthSet(&client.StrengthSet{Reps: 3, Load: 225, Unit: "LB", RPE: 9.5})
+	assert.NoError(t, err)
+	assert.InDelta(t, 102.058, set.LoadKg, 0.001)
+	cSet := set.Convert()
+	assert.Equal(t, 225.0, cSet.Load)
+	assert.Equal(t, db.UnitPound, cSet.Unit)
+	assert.Equal(t, set.ID.Hex(), cSet.ID)
+
+	invalid := []client.StrengthSet{
+		client.StrengthSet{Reps: -1},
+		client.StrengthSet{Reps: 1, Load: -5},
+		client.StrengthSet{Reps: 1, RestSeconds: -60},
+		client.StrengthSet{Reps: 1, RPE: 11},
+		client.StrengthSet{Reps: 1, RPE: 0.5},
+		client.StrengthSet{Reps: 1, Load: 10, Unit: "stone"},
+	}
+	for _, s := range invalid {
+		_, err := db.NewStrengthSet(&s)
+		assert.Truef(t, db.IsValidation(err), "expected validation error for %+v, got %v", s, err)
+	}
+}
+
+func TestNewWorkout(t *testing.T) {
+	userID := primitive.NewObjectID()
+	start := time.Date(2019, 11, 20, 18, 30, 0, 0, time.FixedZone("PST", -8*60*60))
+	w := client.Workout{
+		ID:    "ignored",
+		Start: start,
+		Notes: " Leg day ",
+		Exercises: []client.WorkoutExercise{
+			client.WorkoutExercise{
+				ExerciseID: "5dab5fa871aab123354e5cb6",
+				Sets: []client.StrengthSet{
+					client.StrengthSet{Reps: 5, Load: 100},
+					client.StrengthSet{Reps: 5, Load: 110},
+				},
+			},
+		},
+	}
+	workout, err := db.NewWorkout(userID, &w)
+	assert.NoError(t, err)
+	assert.False(t, workout.ID.IsZero())
+	assert.Equal(t, userID, workout.UserID)
+	assert.True(t, start.Equal(workout.Start))
+	assert.Equal(t, time.UTC, workout.Start.Location())
+	assert.Equal(t, "Leg day", workout.Notes)
+	if assert.Equal(t, 1, len(workout.Exercises)) {
+		assert.Equal(t, 2, len(workout.Exercises[0].Sets))
+		assert.NotEqual(t, workout.Exercises[0].Sets[0].ID, workout.Exercises[0].Sets[1].ID)
+	}
+
+	cWorkout := workout.Convert()
+	assert.Equal(t, workout.ID.Hex(), cWorkout.ID)
+	assert.Equal(t, userID.Hex(), cWorkout.UserID)
+	assert.Equal(t, 110.0, cWorkout.Exercises[0].Sets[1].Load)
+
+	// The start time defaults to now
+	workout, err = db.NewWorkout(userID, &client.Workout{})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), workout.Start, time.Minute)
+
+	w.Exercises[0].ExerciseID = "squat"
+	_, err = db.NewWorkout(userID, &w)
+	assert.True(t, db.IsValidation(err))
+}
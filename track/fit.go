@@ -0,0 +1,33 @@
+package track
+
+import (
+	"io"
+
+	"github.com/enpointe/activity/fit"
+)
+
+// ParseFIT read a track from the records of a FIT activity file. The sport
+// of the track is the sport of the first session of the activity.
+func ParseFIT(r io.Reader) (*Track, error) {
+	a, err := fit.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	var t Track
+	if len(a.Sessions) > 0 {
+		t.Sport = a.Sessions[0].SportName()
+	}
+	for _, rec := range a.Records {
+		t.Points = append(t.Points, Point{
+			Time:         rec.Time,
+			Lat:          rec.Lat,
+			Lon:          rec.Lon,
+			HasPosition:  rec.HasPosition,
+			Elevation:    rec.AltitudeM,
+			HasElevation: rec.HasAltitude,
+			HeartRate:    rec.HeartRate,
+			Distance:     rec.DistanceM,
+		})
+	}
+	return &t, nil
+}
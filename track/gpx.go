@@ -0,0 +1,65 @@
+package track
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// gpxDocument the elements of a GPX 1.1 document used to build a track.
+// Heart rate is held in the Garmin TrackPointExtension.
+type gpxDocument struct {
+	Metadata struct {
+		Name string `xml:"name"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat       float64   `xml:"lat,attr"`
+	Lon       float64   `xml:"lon,attr"`
+	Elevation *float64  `xml:"ele"`
+	Time      time.Time `xml:"time"`
+	HeartRate int       `xml:"extensions>TrackPointExtension>hr"`
+}
+
+// ParseGPX read a track from a GPX document. The points of all
+// tracks and segments in the document are joined into a single track.
+func ParseGPX(r io.Reader) (*Track, error) {
+	var doc gpxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	t := Track{Name: doc.Metadata.Name}
+	for _, trk := range doc.Tracks {
+		if len(t.Name) == 0 {
+			t.Name = trk.Name
+		}
+		if len(t.Sport) == 0 {
+			t.Sport = trk.Type
+		}
+		for _, seg := range trk.Segments {
+			for _, p := range seg.Points {
+				point := Point{
+					Time:        p.Time,
+					Lat:         p.Lat,
+					Lon:         p.Lon,
+					HasPosition: true,
+					HeartRate:   p.HeartRate,
+				}
+				if p.Elevation != nil {
+					point.Elevation = *p.Elevation
+					point.HasElevation = true
+				}
+				t.Points = append(t.Points, point)
+			}
+		}
+	}
+	return &t, nil
+}
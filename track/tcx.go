@@ -0,0 +1,67 @@
+package track
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// tcxDocument the elements of a Training Center XML document used to build a track
+type tcxDocument struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			Tracks []struct {
+				Points []tcxPoint `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxPoint struct {
+	Time     time.Time `xml:"Time"`
+	Position *struct {
+		Lat float64 `xml:"LatitudeDegrees"`
+		Lon float64 `xml:"LongitudeDegrees"`
+	} `xml:"Position"`
+	Altitude  *float64 `xml:"AltitudeMeters"`
+	Distance  float64  `xml:"DistanceMeters"`
+	HeartRate int      `xml:"HeartRateBpm>Value"`
+}
+
+// ParseTCX read a track from a TCX document. The trackpoints of all
+// laps of the first activity in the document are joined into a single track.
+func ParseTCX(r io.Reader) (*Track, error) {
+	var doc tcxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var t Track
+	if len(doc.Activities) == 0 {
+		return &t, nil
+	}
+	activity := doc.Activities[0]
+	t.Sport = activity.Sport
+	for _, lap := range activity.Laps {
+		for _, trk := range lap.Tracks {
+			for _, p := range trk.Points {
+				point := Point{
+					Time:      p.Time,
+					HeartRate: p.HeartRate,
+					Distance:  p.Distance,
+				}
+				if p.Position != nil {
+					point.Lat = p.Position.Lat
+					point.Lon = p.Position.Lon
+					point.HasPosition = true
+				}
+				if p.Altitude != nil {
+					point.Elevation = *p.Altitude
+					point.HasElevation = true
+				}
+				t.Points = append(t.Points, point)
+			}
+		}
+	}
+	return &t, nil
+}
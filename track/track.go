@@ -0,0 +1,205 @@
+// Package track parses the GPS tracks exported by watches and bike computers,
+// in GPX, TCX or FIT format, and summarizes the distance, time, elevation and
+// heart rate recorded by their trackpoints.
+package track
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format the file format a track is stored in
+type Format string
+
+const (
+	// GPX GPS Exchange Format
+	GPX Format = "gpx"
+	// TCX Garmin Training Center XML
+	TCX Format = "tcx"
+	// FIT Garmin Flexible and Interoperable Data Transfer protocol
+	FIT Format = "fit"
+)
+
+// EarthRadius the mean radius of the earth in meters
+const EarthRadius = 6371008.8
+
+// MovingSpeed the speed, in meters per second, below which the time between
+// two trackpoints is treated as a pause rather than moving time
+const MovingSpeed = 0.5
+
+// ErrNoPoints is returned when a track holds no trackpoints
+var ErrNoPoints = errors.New("track contains no trackpoints")
+
+// Point a single trackpoint. Position, elevation and heart rate are
+// optional, HasPosition and HasElevation report whether they were recorded.
+// Distance is the cumulative distance in meters reported by the device, if any.
+type Point struct {
+	Time         time.Time
+	Lat          float64
+	Lon          float64
+	HasPosition  bool
+	Elevation    float64
+	HasElevation bool
+	HeartRate    int
+	Distance     float64
+}
+
+// Track the trackpoints of a recorded activity, in the order recorded
+type Track struct {
+	Name   string
+	Sport  string
+	Points []Point
+}
+
+// Summary the totals computed from the trackpoints of a track
+type Summary struct {
+	Start          time.Time
+	End            time.Time
+	DistanceM      float64
+	ElapsedSeconds int
+	MovingSeconds  int
+	ElevationGainM float64
+	AvgHeartRate   int
+	MaxHeartRate   int
+}
+
+// ContentType the media type of a track in the format
+func (f Format) ContentType() string {
+	switch f {
+	case TCX:
+		return "application/vnd.garmin.tcx+xml"
+	case FIT:
+		return "application/vnd.ant.fit"
+	}
+	return "application/gpx+xml"
+}
+
+// DetectFormat determine the format of a track from the extension of its
+// filename, falling back to the FIT file signature or the root element
+// of the XML document
+func DetectFormat(filename string, data []byte) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gpx":
+		return GPX, nil
+	case ".tcx":
+		return TCX, nil
+	case ".fit":
+		return FIT, nil
+	}
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FIT, nil
+	}
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	switch {
+	case bytes.Contains(head, []byte("<gpx")):
+		return GPX, nil
+	case bytes.Contains(head, []byte("<TrainingCenterDatabase")):
+		return TCX, nil
+	}
+	return "", fmt.Errorf("unsupported track format '%s', valid formats are gpx, tcx, fit", filename)
+}
+
+// Parse read a track in the specified format
+func Parse(r io.Reader, format Format) (*Track, error) {
+	var t *Track
+	var err error
+	switch format {
+	case GPX:
+		t, err = ParseGPX(r)
+	case TCX:
+		t, err = ParseTCX(r)
+	case FIT:
+		t, err = ParseFIT(r)
+	default:
+		return nil, fmt.Errorf("unsupported track format '%s'", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Points) == 0 {
+		return nil, ErrNoPoints
+	}
+	return t, nil
+}
+
+// Haversine the great circle distance in meters between two points
+// specified in degrees
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Summary compute the distance, time, elevation gain and heart rate of the
+// track. The distance is computed from the positions of the trackpoints, if the
+// track has no positions, ie a treadmill run, the distance reported by the
+// device is used. Time between trackpoints only counts as moving time when the
+// speed between them is at least MovingSpeed.
+func (t *Track) Summary() Summary {
+	var s Summary
+	if len(t.Points) == 0 {
+		return s
+	}
+	s.Start = t.Points[0].Time
+	s.End = t.Points[len(t.Points)-1].Time
+	s.ElapsedSeconds = int(s.End.Sub(s.Start).Seconds())
+
+	var hrTotal, hrCount int
+	var reported float64
+	var last *Point    // the last point with a position
+	var lastEle *Point // the last point with an elevation
+	var moving float64
+	for i := range t.Points {
+		p := &t.Points[i]
+		if p.HeartRate > 0 {
+			hrTotal += p.HeartRate
+			hrCount++
+			if p.HeartRate > s.MaxHeartRate {
+				s.MaxHeartRate = p.HeartRate
+			}
+		}
+		if p.Distance > reported {
+			reported = p.Distance
+		}
+		if p.HasElevation {
+			if lastEle != nil && p.Elevation > lastEle.Elevation {
+				s.ElevationGainM += p.Elevation - lastEle.Elevation
+			}
+			lastEle = p
+		}
+		if !p.HasPosition {
+			continue
+		}
+		if last != nil {
+			d := Haversine(last.Lat, last.Lon, p.Lat, p.Lon)
+			s.DistanceM += d
+			dt := p.Time.Sub(last.Time).Seconds()
+			if dt > 0 && d/dt >= MovingSpeed {
+				moving += dt
+			}
+		}
+		last = p
+	}
+	if hrCount > 0 {
+		s.AvgHeartRate = int(math.Round(float64(hrTotal) / float64(hrCount)))
+	}
+	if s.DistanceM == 0 && reported > 0 {
+		// No positions were recorded, rely on the device
+		s.DistanceM = reported
+		moving = float64(s.ElapsedSeconds)
+	}
+	s.MovingSeconds = int(math.Round(moving))
+	return s
+}
@@ -0,0 +1,98 @@
+package track_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/enpointe/activity/track"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversine(t *testing.T) {
+	// One degree of latitude
+	assert.InDelta(t, 111195, track.Haversine(45, -122, 46, -122), 1)
+	// London to Paris
+	assert.InDelta(t, 343556, track.Haversine(51.5074, -0.1278, 48.8566, 2.3522), 500)
+	assert.Zero(t, track.Haversine(45, -122, 45, -122))
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := track.DetectFormat("run.GPX", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, track.GPX, format)
+	format, err = track.DetectFormat("upload", []byte(`<?xml version="1.0"?><TrainingCenterDatabase>`))
+	assert.NoError(t, err)
+	assert.Equal(t, track.TCX, format)
+	format, err = track.DetectFormat("upload", []byte("\x0e\x20\x54\x08\x00\x00\x00\x00.FIT"))
+	assert.NoError(t, err)
+	assert.Equal(t, track.FIT, format)
+	_, err = track.DetectFormat("run.csv", []byte("time,lat,lon"))
+	assert.Error(t, err)
+}
+
+func testSummary(t *testing.T, filename string, format track.Format) {
+	f, err := os.Open(filename)
+	assert.NoError(t, err)
+	defer f.Close()
+	trk, err := track.Parse(f, format)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 6, len(trk.Points))
+	s := trk.Summary()
+	assert.Equal(t, time.Date(2019, 11, 20, 8, 0, 0, 0, time.UTC), s.Start)
+	assert.InDelta(t, 444.78, s.DistanceM, 0.1)
+	assert.Equal(t, 180, s.ElapsedSeconds)
+	assert.Equal(t, 120, s.MovingSeconds)
+	assert.Equal(t, 7.0, s.ElevationGainM)
+	assert.Equal(t, 142, s.AvgHeartRate)
+	assert.Equal(t, 160, s.MaxHeartRate)
+}
+
+func TestParseGPX(t *testing.T) {
+	testSummary(t, "testdata/run.gpx", track.GPX)
+}
+
+func TestParseTCX(t *testing.T) {
+	testSummary(t, "testdata/run.tcx", track.TCX)
+}
+
+func TestParseFIT(t *testing.T) {
+	f, err := os.Open("../fit/testdata/run.fit")
+	assert.NoError(t, err)
+	defer f.Close()
+	trk, err := track.Parse(f, track.FIT)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "running", trk.Sport)
+	s := trk.Summary()
+	assert.Equal(t, 61, len(trk.Points))
+	assert.Equal(t, 600, s.ElapsedSeconds)
+	assert.InDelta(t, 1801, s.DistanceM, 5)
+	assert.Equal(t, 160, s.MaxHeartRate)
+}
+
+func TestTreadmillTCX(t *testing.T) {
+	// Without positions the distance reported by the device is used
+	data := `<TrainingCenterDatabase><Activities><Activity Sport="Running"><Lap><Track>
+		<Trackpoint><Time>2019-11-20T08:00:00Z</Time><DistanceMeters>0</DistanceMeters></Trackpoint>
+		<Trackpoint><Time>2019-11-20T08:05:00Z</Time><DistanceMeters>1000</DistanceMeters></Trackpoint>
+	</Track></Lap></Activity></Activities></TrainingCenterDatabase>`
+	trk, err := track.Parse(strings.NewReader(data), track.TCX)
+	assert.NoError(t, err)
+	assert.Equal(t, "Running", trk.Sport)
+	s := trk.Summary()
+	assert.Equal(t, 1000.0, s.DistanceM)
+	assert.Equal(t, 300, s.MovingSeconds)
+	assert.Zero(t, s.AvgHeartRate)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := track.Parse(strings.NewReader(`<gpx><trk>`), track.GPX)
+	assert.Error(t, err)
+	_, err = track.Parse(strings.NewReader(`<gpx></gpx>`), track.GPX)
+	assert.Equal(t, track.ErrNoPoints, err)
+}